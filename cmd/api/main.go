@@ -9,9 +9,13 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/htchan/WebHistory/internal/config"
-	"github.com/htchan/WebHistory/internal/repository/sqlc"
+	"github.com/htchan/WebHistory/internal/repository/cache"
+	repohelper "github.com/htchan/WebHistory/internal/repository/helpers"
 	"github.com/htchan/WebHistory/internal/router/website"
+	websiteevents "github.com/htchan/WebHistory/internal/tasks/nats/website_events"
+	websiteimport "github.com/htchan/WebHistory/internal/tasks/nats/website_import"
 	websiteupdate "github.com/htchan/WebHistory/internal/tasks/nats/website_update"
+	"github.com/htchan/WebHistory/internal/userservice"
 	"github.com/htchan/WebHistory/internal/utils"
 	vendorhelper "github.com/htchan/WebHistory/internal/vendors/helpers"
 	shutdown "github.com/htchan/goshutdown"
@@ -57,13 +61,28 @@ func main() {
 
 	defer db.Close()
 
-	rpo := sqlc.NewRepo(db, &conf.WebsiteConfig)
+	rpo, err := repohelper.NewRepo(db, &conf.DatabaseConfig, &conf.WebsiteConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create repository")
+	}
 
 	nc, err := utils.ConnectNatsQueue(&conf.NatsConfig)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to connect to nats queue")
 	}
 
+	if conf.CacheConfig.Enabled {
+		cacheRepo := cache.NewRepo(rpo, &conf.CacheConfig, nc)
+		if _, err := cacheRepo.Subscribe(); err != nil {
+			log.Fatal().Err(err).Msg("failed to subscribe cache invalidation")
+		}
+
+		rpo = cacheRepo
+	} else {
+		// replicas caching reads still need the invalidation of writes here
+		rpo = cache.NewPublisher(rpo, nc)
+	}
+
 	services, err := vendorhelper.NewServiceSet(nil, rpo, conf.BinConfig.VendorServiceConfigs)
 	if err != nil {
 		log.Fatal().Err(err).Msg("create vendor services failed")
@@ -73,9 +92,18 @@ func main() {
 	shutdownHandler := shutdown.New(syscall.SIGINT, syscall.SIGTERM)
 
 	websiteUpdateTasks := websiteupdate.NewTaskSet(nc, services, rpo, &conf.WebsiteConfig)
+	websiteImportTask := websiteimport.NewTask(nc, websiteUpdateTasks, rpo, &conf.WebsiteConfig)
+
+	websiteEvents := websiteevents.NewBroker(nc)
+	eventsConsumer, err := websiteEvents.Start(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to subscribe website events")
+	}
+
+	authenticator := userservice.NewClient(&conf.UserServiceConfig)
 
 	r := chi.NewRouter()
-	website.AddRoutes(r, rpo, websiteUpdateTasks, conf)
+	website.AddRoutes(r, rpo, websiteUpdateTasks, websiteImportTask, websiteEvents, authenticator, conf)
 
 	server := http.Server{
 		Addr:         conf.BinConfig.Addr,
@@ -84,6 +112,8 @@ func main() {
 		IdleTimeout:  conf.BinConfig.IdleTimeout,
 		Handler:      r,
 	}
+	// release event streams, otherwise shutdown waits for clients to disconnect
+	server.RegisterOnShutdown(websiteEvents.Close)
 
 	go func() {
 		log.Debug().Msg("start http server")
@@ -98,6 +128,11 @@ func main() {
 
 		return nil
 	})
+	shutdownHandler.Register("website events", func() error {
+		eventsConsumer.Stop()
+
+		return nil
+	})
 	shutdownHandler.Register("nats connection", func() error {
 		nc.Close()
 
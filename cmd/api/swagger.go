@@ -4,5 +4,10 @@ package main
 //	@version		1.0
 //	@description	This is a service checking last update of specific websites
 
+//	@securityDefinitions.apikey	UserToken
+//	@in							header
+//	@name						Authorization
+//	@description				Bearer token verified by user service, the session cookie is accepted as well
+
 //go:generate go tool swag fmt -d .,../../internal
 //go:generate go tool swag init -d ../../ -g cmd/api/swagger.go -o ../../docs -ot go,json
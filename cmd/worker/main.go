@@ -12,8 +12,13 @@ import (
 	"github.com/nats-io/nats.go/jetstream"
 
 	// "github.com/htchan/WebHistory/internal/jobs/websiteupdate"
-	"github.com/htchan/WebHistory/internal/repository/sqlc"
+	"github.com/htchan/WebHistory/internal/repository/cache"
+	repohelper "github.com/htchan/WebHistory/internal/repository/helpers"
+	webhookdelivery "github.com/htchan/WebHistory/internal/tasks/nats/webhook_delivery"
 	websitebatchupdate "github.com/htchan/WebHistory/internal/tasks/nats/website_batch_update"
+	websiteevents "github.com/htchan/WebHistory/internal/tasks/nats/website_events"
+	websiteimport "github.com/htchan/WebHistory/internal/tasks/nats/website_import"
+	websiteorphancleanup "github.com/htchan/WebHistory/internal/tasks/nats/website_orphan_cleanup"
 	websiteupdate "github.com/htchan/WebHistory/internal/tasks/nats/website_update"
 	"github.com/htchan/WebHistory/internal/utils"
 	vendorhelper "github.com/htchan/WebHistory/internal/vendors/helpers"
@@ -65,13 +70,9 @@ func main() {
 		log.Fatal().Err(err).Msg("failed to open database")
 	}
 
-	rpo := sqlc.NewRepo(db, &conf.WebsiteConfig)
-
-	cli := &http.Client{Timeout: conf.BinConfig.ClientTimeout}
-
-	services, err := vendorhelper.NewServiceSet(cli, rpo, conf.BinConfig.VendorServiceConfigs)
+	rpo, err := repohelper.NewRepo(db, &conf.DatabaseConfig, &conf.WebsiteConfig)
 	if err != nil {
-		log.Fatal().Err(err).Msg("create vendor services failed")
+		log.Fatal().Err(err).Msg("failed to create repository")
 	}
 
 	nc, err := utils.ConnectNatsQueue(&conf.NatsConfig)
@@ -79,8 +80,32 @@ func main() {
 		log.Fatal().Err(err).Msg("failed to connect to nats server")
 	}
 
+	if conf.CacheConfig.Enabled {
+		cacheRepo := cache.NewRepo(rpo, &conf.CacheConfig, nc)
+		if _, err := cacheRepo.Subscribe(); err != nil {
+			log.Fatal().Err(err).Msg("failed to subscribe cache invalidation")
+		}
+
+		rpo = cacheRepo
+	} else {
+		// replicas caching reads still need the invalidation of writes here
+		rpo = cache.NewPublisher(rpo, nc)
+	}
+
+	cli := &http.Client{Timeout: conf.BinConfig.ClientTimeout}
+
+	services, err := vendorhelper.NewServiceSet(cli, rpo, conf.BinConfig.VendorServiceConfigs)
+	if err != nil {
+		log.Fatal().Err(err).Msg("create vendor services failed")
+	}
+
 	ctx := context.Background()
 
+	// update tasks publish website changes to the stream served by api
+	if _, err := websiteevents.CreateStream(ctx, nc); err != nil {
+		log.Fatal().Err(err).Msg("failed to create website events stream")
+	}
+
 	updateTasks := make([]jetstream.ConsumeContext, 0, len(services))
 	websiteUpdateTasks := websiteupdate.NewTaskSet(nc, services, rpo, &conf.WebsiteConfig)
 	for _, task := range websiteUpdateTasks {
@@ -101,11 +126,58 @@ func main() {
 			Msg("failed to subscribe to nats server")
 	}
 
+	orphanCleanupTask := websiteorphancleanup.NewTask(nc, rpo, &conf.WebsiteConfig)
+	orphanCleanupConsumer, err := orphanCleanupTask.Subscribe(ctx)
+	if err != nil {
+		log.Fatal().Err(err).
+			Str("task", "website-orphan-cleanup").
+			Msg("failed to subscribe to nats server")
+	}
+
+	importTask := websiteimport.NewTask(nc, websiteUpdateTasks, rpo, &conf.WebsiteConfig)
+	importConsumer, err := importTask.Subscribe(ctx)
+	if err != nil {
+		log.Fatal().Err(err).
+			Str("task", "website-import").
+			Msg("failed to subscribe to nats server")
+	}
+
+	webhookTask := webhookdelivery.NewTask(nc, rpo, &conf.WebhookConfig)
+	webhookChangeConsumer, err := webhookTask.SubscribeChanges(ctx)
+	if err != nil {
+		log.Fatal().Err(err).
+			Str("task", "webhook-delivery-changes").
+			Msg("failed to subscribe to nats server")
+	}
+
+	webhookConsumer, err := webhookTask.Subscribe(ctx)
+	if err != nil {
+		log.Fatal().Err(err).
+			Str("task", "webhook-delivery").
+			Msg("failed to subscribe to nats server")
+	}
+
 	shutdownHandler.Register("batch update task", func() error {
 		batchConsumer.Stop()
 
 		return nil
 	})
+	shutdownHandler.Register("orphan cleanup task", func() error {
+		orphanCleanupConsumer.Stop()
+
+		return nil
+	})
+	shutdownHandler.Register("import task", func() error {
+		importConsumer.Stop()
+
+		return nil
+	})
+	shutdownHandler.Register("webhook delivery task", func() error {
+		webhookChangeConsumer.Stop()
+		webhookConsumer.Stop()
+
+		return nil
+	})
 	for _, updateTask := range updateTasks {
 		shutdownHandler.Register("update task", func() error {
 			updateTask.Stop()
@@ -0,0 +1,2 @@
+ALTER TABLE user_websites DROP COLUMN note;
+ALTER TABLE user_websites DROP COLUMN display_title;
@@ -0,0 +1,2 @@
+ALTER TABLE user_websites ADD display_title TEXT DEFAULT '' NOT NULL;
+ALTER TABLE user_websites ADD note TEXT DEFAULT '' NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE user_websites DROP COLUMN last_read_chapter;
@@ -0,0 +1 @@
+ALTER TABLE user_websites ADD last_read_chapter TEXT DEFAULT '' NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE websites DROP COLUMN redirect_uuid;
@@ -0,0 +1 @@
+ALTER TABLE websites ADD redirect_uuid VARCHAR(64);
@@ -0,0 +1 @@
+ALTER TABLE websites ADD missing_count INTEGER DEFAULT 0 NOT NULL;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS user_websites__note_trgm;
+DROP INDEX IF EXISTS user_websites__display_title_trgm;
+DROP INDEX IF EXISTS user_websites__group_name_trgm;
+DROP INDEX IF EXISTS websites__title_tsv;
+DROP INDEX IF EXISTS websites__url_trgm;
+DROP INDEX IF EXISTS websites__title_trgm;
+
+DROP EXTENSION IF EXISTS pg_trgm;
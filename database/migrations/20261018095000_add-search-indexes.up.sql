@@ -0,0 +1,8 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS websites__title_trgm ON websites USING gin (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS websites__url_trgm ON websites USING gin (url gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS websites__title_tsv ON websites USING gin (to_tsvector('simple', coalesce(title, '')));
+CREATE INDEX IF NOT EXISTS user_websites__group_name_trgm ON user_websites USING gin (group_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS user_websites__display_title_trgm ON user_websites USING gin (display_title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS user_websites__note_trgm ON user_websites USING gin (note gin_trgm_ops);
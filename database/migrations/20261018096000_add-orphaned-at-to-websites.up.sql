@@ -0,0 +1 @@
+ALTER TABLE websites ADD orphaned_at TIMESTAMP;
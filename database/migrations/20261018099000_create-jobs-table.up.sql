@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS jobs (
+  uuid VARCHAR(64) NOT NULL,
+  user_uuid VARCHAR(64) NOT NULL,
+  kind TEXT NOT NULL,
+  status TEXT NOT NULL,
+  result TEXT DEFAULT '' NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS jobs__uuid ON jobs (uuid);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS user_feed_tokens (
+  user_uuid VARCHAR(64) NOT NULL,
+  token VARCHAR(64) NOT NULL,
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_feed_tokens__user_uuid ON user_feed_tokens (user_uuid);
+CREATE UNIQUE INDEX IF NOT EXISTS user_feed_tokens__token ON user_feed_tokens (token);
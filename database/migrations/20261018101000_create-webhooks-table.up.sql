@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+  uuid VARCHAR(64) NOT NULL,
+  user_uuid VARCHAR(64) NOT NULL,
+  url TEXT NOT NULL,
+  group_name TEXT DEFAULT '' NOT NULL,
+  secret VARCHAR(64) NOT NULL,
+  enabled BOOLEAN DEFAULT TRUE NOT NULL,
+  failure_count INTEGER DEFAULT 0 NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhooks__uuid ON webhooks (uuid);
+CREATE INDEX IF NOT EXISTS webhooks__user_uuid ON webhooks (user_uuid);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  uuid VARCHAR(64) NOT NULL,
+  webhook_uuid VARCHAR(64) NOT NULL,
+  website_uuid VARCHAR(64) NOT NULL,
+  status TEXT NOT NULL,
+  payload TEXT NOT NULL,
+  attempts INTEGER DEFAULT 0 NOT NULL,
+  response_status INTEGER DEFAULT 0 NOT NULL,
+  error TEXT DEFAULT '' NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_deliveries__uuid ON webhook_deliveries (uuid);
+CREATE INDEX IF NOT EXISTS webhook_deliveries__webhook_and_created_at ON webhook_deliveries (webhook_uuid, created_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS jobs__user_uuid_kind_created_at;
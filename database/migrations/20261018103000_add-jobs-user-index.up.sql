@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS jobs__user_uuid_kind_created_at ON jobs (user_uuid, kind, created_at);
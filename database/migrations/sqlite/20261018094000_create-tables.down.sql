@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_websites;
+DROP TABLE IF EXISTS websites;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS websites (
+  uuid VARCHAR(64),
+  url TEXT,
+  title TEXT,
+  content TEXT,
+  update_time TIMESTAMP,
+  status TEXT DEFAULT 'active' NOT NULL,
+  redirect_uuid VARCHAR(64),
+  missing_count INTEGER DEFAULT 0 NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS websites__uuid ON websites (uuid);
+CREATE UNIQUE INDEX IF NOT EXISTS websites__url ON websites (url);
+CREATE INDEX IF NOT EXISTS idx_websites_status ON websites (status);
+
+CREATE TABLE IF NOT EXISTS user_websites (
+  website_uuid VARCHAR(64),
+  user_uuid VARCHAR(64),
+  access_time TIMESTAMP,
+  group_name TEXT,
+  display_title TEXT DEFAULT '' NOT NULL,
+  note TEXT DEFAULT '' NOT NULL,
+  last_read_chapter TEXT DEFAULT '' NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_websites__user_and_uuid ON user_websites (user_uuid, website_uuid);
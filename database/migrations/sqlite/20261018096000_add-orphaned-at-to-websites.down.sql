@@ -0,0 +1 @@
+ALTER TABLE websites DROP COLUMN orphaned_at;
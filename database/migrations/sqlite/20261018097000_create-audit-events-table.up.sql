@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+  uuid VARCHAR(64) NOT NULL,
+  user_uuid VARCHAR(64) NOT NULL,
+  website_uuid VARCHAR(64) DEFAULT '' NOT NULL,
+  action TEXT NOT NULL,
+  before TEXT DEFAULT '' NOT NULL,
+  after TEXT DEFAULT '' NOT NULL,
+  request_id VARCHAR(64) DEFAULT '' NOT NULL,
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS audit_events__uuid ON audit_events (uuid);
+CREATE INDEX IF NOT EXISTS audit_events__user_and_created_at ON audit_events (user_uuid, created_at);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS user_website_tags (
+  user_uuid VARCHAR(64) NOT NULL,
+  website_uuid VARCHAR(64) NOT NULL,
+  tag TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_website_tags__user_and_website_and_tag ON user_website_tags (user_uuid, website_uuid, tag);
+CREATE INDEX IF NOT EXISTS user_website_tags__user_and_tag ON user_website_tags (user_uuid, tag);
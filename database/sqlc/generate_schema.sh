@@ -7,7 +7,7 @@ while ! docker exec -i webhistory-sqlc-generator pg_isready -U web_history; do s
 
 # run migration and dump schema
 docker exec webhistory-sqlc-generator bash -c 'for filename in /migrations/*.up.sql; do psql -U web_history -d db -f $filename; done' && \
-docker exec webhistory-sqlc-generator bash -c "pg_dump -U web_history -d db -t websites -t user_websites -t website_settings --schema-only > /sqlc/schema.sql"
+docker exec webhistory-sqlc-generator bash -c "pg_dump -U web_history -d db -t websites -t user_websites -t website_settings -t audit_events -t user_website_tags -t jobs -t user_feed_tokens -t webhooks -t webhook_deliveries -e pg_trgm --schema-only > /sqlc/schema.sql"
 
 # kill container
 docker kill webhistory-sqlc-generator
\ No newline at end of file
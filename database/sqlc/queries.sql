@@ -19,43 +19,418 @@ DELETE FROM websites WHERE uuid=$1;
 -- name: ListActiveWebsites :many
 SELECT * FROM websites WHERE status='active';
 
+-- name: ListActiveWebsitesPage :many
+SELECT * FROM websites
+WHERE status='active' and uuid COLLATE "C" > sqlc.arg(after_uuid)::text
+and (sqlc.arg(host)::text = ''
+  or right('.' || split_part(split_part(url, '://', 2), '/', 1), length(sqlc.arg(host)::text) + 1) = '.' || sqlc.arg(host)::text)
+ORDER BY uuid COLLATE "C" ASC
+LIMIT sqlc.arg(page_limit);
+
 -- name: GetWebsite :one
 SELECT * from websites WHERE uuid=$1 and status != 'inactive';
 
+-- name: RecordWebsiteMissing :one
+UPDATE websites SET
+missing_count=missing_count+1,
+status=(CASE WHEN status='active' and missing_count+1 >= sqlc.arg(threshold)::integer THEN 'gone' ELSE status END)
+WHERE uuid=sqlc.arg(uuid)
+RETURNING *;
+
+-- name: ResetWebsiteMissing :one
+UPDATE websites SET
+missing_count=0,
+status=(CASE WHEN status='gone' THEN 'active' ELSE status END)
+WHERE uuid=$1
+RETURNING *;
+
+-- name: MoveUserWebsites :exec
+UPDATE user_websites SET website_uuid=sqlc.arg(to_uuid)
+WHERE user_websites.website_uuid=sqlc.arg(from_uuid) and NOT EXISTS (
+  SELECT 1 FROM user_websites existing
+  WHERE existing.website_uuid=sqlc.arg(to_uuid) and existing.user_uuid=user_websites.user_uuid
+);
+
+-- name: DeleteUserWebsitesByWebsite :exec
+DELETE FROM user_websites WHERE website_uuid=$1;
+
+-- name: MoveUserWebsiteTags :exec
+UPDATE user_website_tags SET website_uuid=sqlc.arg(to_uuid)
+WHERE user_website_tags.website_uuid=sqlc.arg(from_uuid) and NOT EXISTS (
+  SELECT 1 FROM user_website_tags existing
+  WHERE existing.website_uuid=sqlc.arg(to_uuid) and existing.user_uuid=user_website_tags.user_uuid
+  and existing.tag=user_website_tags.tag
+);
+
+-- name: DeleteUserWebsiteTagsByWebsite :exec
+DELETE FROM user_website_tags WHERE website_uuid=$1;
+
+-- name: RedirectWebsite :exec
+UPDATE websites SET status='inactive', redirect_uuid=sqlc.arg(to_uuid), orphaned_at=NULL
+WHERE uuid=sqlc.arg(from_uuid) or redirect_uuid=sqlc.arg(from_uuid);
+
+-- name: MarkOrphanedWebsites :execrows
+UPDATE websites SET orphaned_at=sqlc.arg(orphaned_at)
+WHERE status='active' and orphaned_at IS NULL and NOT EXISTS (
+  SELECT 1 FROM user_websites WHERE user_websites.website_uuid=websites.uuid
+);
+
+-- name: ClearOrphanedWebsites :execrows
+UPDATE websites SET orphaned_at=NULL
+WHERE status='active' and orphaned_at IS NOT NULL and EXISTS (
+  SELECT 1 FROM user_websites WHERE user_websites.website_uuid=websites.uuid
+);
+
+-- name: DeactivateOrphanedWebsites :execrows
+UPDATE websites SET status='inactive'
+WHERE status='active' and orphaned_at < sqlc.arg(orphaned_before) and NOT EXISTS (
+  SELECT 1 FROM user_websites WHERE user_websites.website_uuid=websites.uuid
+);
+
+-- name: CountDeactivatedOrphanedWebsites :one
+SELECT COUNT(*) FROM websites WHERE status='inactive' and orphaned_at IS NOT NULL;
+
+-- name: ReactivateOrphanedWebsite :exec
+UPDATE websites SET
+status=(CASE WHEN status='inactive' THEN 'active' ELSE status END),
+orphaned_at=NULL
+WHERE uuid=$1 and orphaned_at IS NOT NULL;
+
 -- name: CreateUserWebsite :one
 INSERT INTO user_websites
-(user_uuid, website_uuid, access_time, group_name)
+(user_uuid, website_uuid, access_time, group_name, display_title, note, last_read_chapter)
 VALUES
-($1, $2, $3, $4)
+($1, $2, $3, $4, $5, $6, $7)
 ON CONFLICT(user_uuid, website_uuid) DO
 UPDATE SET user_uuid=$1, website_uuid=$2
 RETURNing *;
 
 -- name: UpdateUserWebsite :one
 UPDATE user_websites SET
-access_time=$1, group_name=$2
-WHERE user_uuid=$3 and website_uuid=$4
+access_time=$1, group_name=$2, display_title=$3, note=$4, last_read_chapter=$5
+WHERE user_uuid=$6 and website_uuid=$7
 RETURNING *;
 
 -- name: DeleteUserWebsite :exec
 DELETE FROM user_websites
 where user_uuid=$1 and website_uuid=$2;
 
+-- name: RenameUserGroup :exec
+UPDATE user_websites SET group_name=sqlc.arg(to_group)
+WHERE user_uuid=sqlc.arg(user_uuid) and group_name=sqlc.arg(from_group);
+
+-- name: UngroupUserGroup :exec
+UPDATE user_websites SET group_name=(
+  SELECT title FROM websites WHERE websites.uuid=user_websites.website_uuid
+)
+WHERE user_uuid=$1 and group_name=$2;
+
+-- name: DeleteUserGroup :exec
+DELETE FROM user_websites
+WHERE user_uuid=$1 and group_name=$2;
+
+-- name: RefreshUserWebsites :execrows
+UPDATE user_websites SET access_time=sqlc.arg(access_time)
+WHERE user_uuid=sqlc.arg(user_uuid)
+and (sqlc.arg(group_name)::text = '' or group_name=sqlc.arg(group_name)::text);
+
 -- name: ListUserWebsites :many
-SELECT website_uuid, user_uuid, access_time, group_name,
-uuid, url, title, update_time 
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
 FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid 
 WHERE user_uuid=$1 and websites.status != 'inactive'
 ORDER BY (update_time > access_time) DESC, update_time DESC, access_time DESC;
 
 -- name: ListUserWebsitesByGroup :many
-SELECT website_uuid, user_uuid, access_time, group_name ,
-uuid, url, title, update_time 
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
 FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid 
 WHERE user_uuid=$1 and group_name=$2 and websites.status != 'inactive';
 
 -- name: GetUserWebsite :one
-SELECT website_uuid, user_uuid, access_time, group_name ,
-uuid, url, title, update_time 
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
 FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid 
 WHERE user_uuid=$1 and website_uuid=$2 and websites.status != 'inactive';
+
+-- name: ListWebsiteUserUUIDs :many
+SELECT user_uuid FROM user_websites
+WHERE website_uuid=$1
+ORDER BY user_uuid COLLATE "C";
+
+-- name: ListUserWebsitesPageByUnread :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=sqlc.arg(user_uuid) and websites.status != 'inactive'
+and (sqlc.arg(group_name)::text = '' or group_name=sqlc.arg(group_name)::text)
+and (cardinality(sqlc.arg(tags)::text[]) = 0 or cardinality(sqlc.arg(tags)::text[]) = (
+  SELECT count(*) FROM user_website_tags
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+  and tag = ANY(sqlc.arg(tags)::text[])
+))
+and (not sqlc.arg(has_cursor)::boolean
+  or (update_time > access_time) < sqlc.arg(cursor_unread)::boolean
+  or ((update_time > access_time) = sqlc.arg(cursor_unread)::boolean and (
+    update_time < sqlc.arg(cursor_time)::timestamp
+    or (update_time = sqlc.arg(cursor_time)::timestamp and user_websites.website_uuid > sqlc.arg(cursor_uuid)::text)
+  )))
+ORDER BY (update_time > access_time) DESC, update_time DESC, website_uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListUserWebsitesPageByUpdateTime :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=sqlc.arg(user_uuid) and websites.status != 'inactive'
+and (sqlc.arg(group_name)::text = '' or group_name=sqlc.arg(group_name)::text)
+and (cardinality(sqlc.arg(tags)::text[]) = 0 or cardinality(sqlc.arg(tags)::text[]) = (
+  SELECT count(*) FROM user_website_tags
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+  and tag = ANY(sqlc.arg(tags)::text[])
+))
+and (not sqlc.arg(has_cursor)::boolean
+  or update_time < sqlc.arg(cursor_time)::timestamp
+  or (update_time = sqlc.arg(cursor_time)::timestamp and user_websites.website_uuid > sqlc.arg(cursor_uuid)::text))
+ORDER BY update_time DESC, website_uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListUserWebsitesPageByAccessTime :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=sqlc.arg(user_uuid) and websites.status != 'inactive'
+and (sqlc.arg(group_name)::text = '' or group_name=sqlc.arg(group_name)::text)
+and (cardinality(sqlc.arg(tags)::text[]) = 0 or cardinality(sqlc.arg(tags)::text[]) = (
+  SELECT count(*) FROM user_website_tags
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+  and tag = ANY(sqlc.arg(tags)::text[])
+))
+and (not sqlc.arg(has_cursor)::boolean
+  or access_time < sqlc.arg(cursor_time)::timestamp
+  or (access_time = sqlc.arg(cursor_time)::timestamp and user_websites.website_uuid > sqlc.arg(cursor_uuid)::text))
+ORDER BY access_time DESC, website_uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListUserWebsitesPageByTitle :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=sqlc.arg(user_uuid) and websites.status != 'inactive'
+and (sqlc.arg(group_name)::text = '' or group_name=sqlc.arg(group_name)::text)
+and (cardinality(sqlc.arg(tags)::text[]) = 0 or cardinality(sqlc.arg(tags)::text[]) = (
+  SELECT count(*) FROM user_website_tags
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+  and tag = ANY(sqlc.arg(tags)::text[])
+))
+and (not sqlc.arg(has_cursor)::boolean
+  or coalesce(nullif(display_title, ''), title, '') COLLATE "C" > sqlc.arg(cursor_title)::text
+  or (coalesce(nullif(display_title, ''), title, '') COLLATE "C" = sqlc.arg(cursor_title)::text and user_websites.website_uuid > sqlc.arg(cursor_uuid)::text))
+ORDER BY coalesce(nullif(display_title, ''), title, '') COLLATE "C" ASC, website_uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: SearchUserWebsites :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status,
+CAST(
+  (CASE WHEN coalesce(nullif(display_title, ''), title, '') ILIKE sqlc.arg(pattern)::text THEN 1 ELSE 0 END)
+  + (CASE WHEN title ILIKE sqlc.arg(pattern)::text or display_title ILIKE sqlc.arg(pattern)::text
+    or group_name ILIKE sqlc.arg(pattern)::text or url ILIKE sqlc.arg(pattern)::text
+    or note ILIKE sqlc.arg(pattern)::text THEN 0.5 ELSE 0 END)
+  + similarity(coalesce(nullif(display_title, ''), title, ''), sqlc.arg(query)::text)
+  + ts_rank(to_tsvector('simple', coalesce(title, '')), plainto_tsquery('simple', sqlc.arg(query)::text))
+  + (SELECT count(*) FROM unnest(sqlc.arg(terms)::text[]) AS term
+    WHERE concat_ws(' ', title, display_title, group_name, url, note) ILIKE '%' || term || '%')::float
+    / greatest(cardinality(sqlc.arg(terms)::text[]), 1)
+AS double precision) AS rank
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=sqlc.arg(user_uuid) and websites.status != 'inactive' and (
+  title ILIKE sqlc.arg(pattern)::text or display_title ILIKE sqlc.arg(pattern)::text
+  or group_name ILIKE sqlc.arg(pattern)::text or url ILIKE sqlc.arg(pattern)::text
+  or note ILIKE sqlc.arg(pattern)::text
+  or to_tsvector('simple', coalesce(title, '')) @@ plainto_tsquery('simple', sqlc.arg(query)::text)
+  or title % sqlc.arg(query)::text or display_title % sqlc.arg(query)::text
+  or (cardinality(sqlc.arg(terms)::text[]) > 0 and NOT EXISTS (
+    SELECT 1 FROM unnest(sqlc.arg(terms)::text[]) AS term
+    WHERE concat_ws(' ', title, display_title, group_name, url, note) NOT ILIKE '%' || term || '%'
+  ))
+)
+ORDER BY rank DESC, update_time DESC, website_uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: CountUserUnreadWebsitesByGroup :many
+SELECT group_name, count(*) AS unread_count
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=$1 and websites.status != 'inactive' and update_time > access_time
+GROUP BY group_name
+ORDER BY unread_count DESC, group_name COLLATE "C";
+
+-- name: ListUserUnreadWebsites :many
+SELECT website_uuid, group_name, display_title, title, update_time
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=sqlc.arg(user_uuid) and websites.status != 'inactive' and update_time > access_time
+ORDER BY update_time DESC, website_uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: CreateUserWebsiteTag :exec
+INSERT INTO user_website_tags
+(user_uuid, website_uuid, tag)
+VALUES
+($1, $2, $3)
+ON CONFLICT(user_uuid, website_uuid, tag) DO NOTHING;
+
+-- name: DeleteUserWebsiteTag :exec
+DELETE FROM user_website_tags
+WHERE user_uuid=$1 and website_uuid=$2 and tag=$3;
+
+-- name: DeleteUserWebsiteTags :exec
+DELETE FROM user_website_tags
+WHERE user_uuid=$1 and website_uuid=$2;
+
+-- name: DeleteUserGroupTags :exec
+DELETE FROM user_website_tags
+WHERE user_website_tags.user_uuid=sqlc.arg(user_uuid) and website_uuid IN (
+  SELECT website_uuid FROM user_websites
+  WHERE user_websites.user_uuid=sqlc.arg(user_uuid) and group_name=sqlc.arg(group_name)
+);
+
+-- name: ListUserWebsiteTags :many
+SELECT tag FROM user_website_tags
+WHERE user_uuid=$1 and website_uuid=$2
+ORDER BY tag COLLATE "C";
+
+-- name: ListUserTags :many
+SELECT tag, count(*) AS website_count
+FROM user_website_tags
+JOIN user_websites ON user_websites.user_uuid=user_website_tags.user_uuid and user_websites.website_uuid=user_website_tags.website_uuid
+JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_website_tags.user_uuid=$1 and websites.status != 'inactive'
+GROUP BY tag
+ORDER BY tag COLLATE "C";
+
+-- name: RenameUserTag :exec
+UPDATE user_website_tags SET tag=sqlc.arg(to_tag)
+WHERE user_website_tags.user_uuid=sqlc.arg(user_uuid) and user_website_tags.tag=sqlc.arg(from_tag) and NOT EXISTS (
+  SELECT 1 FROM user_website_tags existing
+  WHERE existing.user_uuid=user_website_tags.user_uuid and existing.website_uuid=user_website_tags.website_uuid
+  and existing.tag=sqlc.arg(to_tag)
+);
+
+-- name: DeleteUserTag :exec
+DELETE FROM user_website_tags
+WHERE user_uuid=$1 and tag=$2;
+
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events
+(uuid, user_uuid, website_uuid, action, before, after, request_id, created_at)
+VALUES
+($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE (sqlc.arg(user_uuid)::text = '' or user_uuid=sqlc.arg(user_uuid)::text)
+and (sqlc.arg(website_uuid)::text = '' or website_uuid=sqlc.arg(website_uuid)::text)
+ORDER BY created_at DESC, uuid DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: CreateJob :one
+INSERT INTO jobs
+(uuid, user_uuid, kind, status, result, created_at, updated_at)
+VALUES
+($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: UpdateJob :one
+UPDATE jobs SET status=sqlc.arg(status), result=sqlc.arg(result), updated_at=sqlc.arg(updated_at)
+WHERE uuid=sqlc.arg(uuid)
+RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM jobs
+WHERE uuid=sqlc.arg(uuid) and user_uuid=sqlc.arg(user_uuid);
+
+-- name: CountUserJobs :one
+SELECT count(*) FROM jobs
+WHERE user_uuid=sqlc.arg(user_uuid) and kind=sqlc.arg(kind) and created_at >= sqlc.arg(since);
+
+-- name: LockUserJobs :exec
+SELECT pg_advisory_xact_lock(hashtext('jobs:' || sqlc.arg(user_uuid)::text));
+
+-- name: SaveUserFeedToken :one
+INSERT INTO user_feed_tokens
+(user_uuid, token, created_at)
+VALUES
+($1, $2, $3)
+ON CONFLICT(user_uuid) DO
+UPDATE SET token=excluded.token, created_at=excluded.created_at
+RETURNING *;
+
+-- name: GetUserFeedToken :one
+SELECT * FROM user_feed_tokens WHERE user_uuid=sqlc.arg(user_uuid);
+
+-- name: GetUserFeedTokenByToken :one
+SELECT * FROM user_feed_tokens WHERE token=sqlc.arg(token);
+
+-- name: CreateWebhook :one
+INSERT INTO webhooks
+(uuid, user_uuid, url, group_name, secret, enabled, failure_count, created_at, updated_at)
+VALUES
+($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: UpdateWebhook :one
+UPDATE webhooks SET
+url=sqlc.arg(url), group_name=sqlc.arg(group_name), enabled=sqlc.arg(enabled),
+failure_count=sqlc.arg(failure_count), updated_at=sqlc.arg(updated_at)
+WHERE uuid=sqlc.arg(uuid)
+RETURNING *;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks WHERE uuid=sqlc.arg(uuid);
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks
+WHERE uuid=sqlc.arg(uuid) and user_uuid=sqlc.arg(user_uuid);
+
+-- name: ListUserWebhooks :many
+SELECT * FROM webhooks
+WHERE user_uuid=sqlc.arg(user_uuid)
+ORDER BY created_at, uuid COLLATE "C";
+
+-- name: ListWebsiteWebhooks :many
+SELECT webhooks.* FROM webhooks
+JOIN user_websites ON user_websites.user_uuid=webhooks.user_uuid
+WHERE user_websites.website_uuid=sqlc.arg(website_uuid) and webhooks.enabled
+and (webhooks.group_name='' or webhooks.group_name=user_websites.group_name)
+ORDER BY webhooks.uuid COLLATE "C";
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries
+(uuid, webhook_uuid, website_uuid, status, payload, attempts, response_status, error, created_at, updated_at)
+VALUES
+($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: UpdateWebhookDelivery :one
+UPDATE webhook_deliveries SET
+status=sqlc.arg(status), attempts=sqlc.arg(attempts), response_status=sqlc.arg(response_status),
+error=sqlc.arg(error), updated_at=sqlc.arg(updated_at)
+WHERE uuid=sqlc.arg(uuid)
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries WHERE uuid=sqlc.arg(uuid);
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_uuid=sqlc.arg(webhook_uuid)
+ORDER BY created_at DESC, uuid COLLATE "C" DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: DeleteWebhookDeliveries :exec
+DELETE FROM webhook_deliveries WHERE webhook_uuid=sqlc.arg(webhook_uuid);
@@ -17,10 +17,85 @@ SET xmloption = content;
 SET client_min_messages = warning;
 SET row_security = off;
 
+--
+-- Name: pg_trgm; Type: EXTENSION; Schema: -; Owner: -
+--
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA public;
+
+
+--
+-- Name: EXTENSION pg_trgm; Type: COMMENT; Schema: -; Owner: 
+--
+
+COMMENT ON EXTENSION pg_trgm IS 'text similarity measurement and index searching based on trigrams';
+
+
 SET default_tablespace = '';
 
 SET default_table_access_method = heap;
 
+--
+-- Name: audit_events; Type: TABLE; Schema: public; Owner: web_history
+--
+
+CREATE TABLE public.audit_events (
+    uuid character varying(64) NOT NULL,
+    user_uuid character varying(64) NOT NULL,
+    website_uuid character varying(64) DEFAULT ''::character varying NOT NULL,
+    action text NOT NULL,
+    before text DEFAULT ''::text NOT NULL,
+    after text DEFAULT ''::text NOT NULL,
+    request_id character varying(64) DEFAULT ''::character varying NOT NULL,
+    created_at timestamp without time zone NOT NULL
+);
+
+
+ALTER TABLE public.audit_events OWNER TO web_history;
+
+--
+-- Name: jobs; Type: TABLE; Schema: public; Owner: web_history
+--
+
+CREATE TABLE public.jobs (
+    uuid character varying(64) NOT NULL,
+    user_uuid character varying(64) NOT NULL,
+    kind text NOT NULL,
+    status text NOT NULL,
+    result text DEFAULT ''::text NOT NULL,
+    created_at timestamp without time zone NOT NULL,
+    updated_at timestamp without time zone NOT NULL
+);
+
+
+ALTER TABLE public.jobs OWNER TO web_history;
+
+--
+-- Name: user_feed_tokens; Type: TABLE; Schema: public; Owner: web_history
+--
+
+CREATE TABLE public.user_feed_tokens (
+    user_uuid character varying(64) NOT NULL,
+    token character varying(64) NOT NULL,
+    created_at timestamp without time zone NOT NULL
+);
+
+
+ALTER TABLE public.user_feed_tokens OWNER TO web_history;
+
+--
+-- Name: user_website_tags; Type: TABLE; Schema: public; Owner: web_history
+--
+
+CREATE TABLE public.user_website_tags (
+    user_uuid character varying(64) NOT NULL,
+    website_uuid character varying(64) NOT NULL,
+    tag text NOT NULL
+);
+
+
+ALTER TABLE public.user_website_tags OWNER TO web_history;
+
 --
 -- Name: user_websites; Type: TABLE; Schema: public; Owner: web_history
 --
@@ -29,12 +104,54 @@ CREATE TABLE public.user_websites (
     website_uuid character varying(64),
     user_uuid character varying(64),
     access_time timestamp without time zone,
-    group_name text
+    group_name text,
+    display_title text DEFAULT ''::text NOT NULL,
+    note text DEFAULT ''::text NOT NULL,
+    last_read_chapter text DEFAULT ''::text NOT NULL
 );
 
 
 ALTER TABLE public.user_websites OWNER TO web_history;
 
+--
+-- Name: webhook_deliveries; Type: TABLE; Schema: public; Owner: web_history
+--
+
+CREATE TABLE public.webhook_deliveries (
+    uuid character varying(64) NOT NULL,
+    webhook_uuid character varying(64) NOT NULL,
+    website_uuid character varying(64) NOT NULL,
+    status text NOT NULL,
+    payload text NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    response_status integer DEFAULT 0 NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    created_at timestamp without time zone NOT NULL,
+    updated_at timestamp without time zone NOT NULL
+);
+
+
+ALTER TABLE public.webhook_deliveries OWNER TO web_history;
+
+--
+-- Name: webhooks; Type: TABLE; Schema: public; Owner: web_history
+--
+
+CREATE TABLE public.webhooks (
+    uuid character varying(64) NOT NULL,
+    user_uuid character varying(64) NOT NULL,
+    url text NOT NULL,
+    group_name text DEFAULT ''::text NOT NULL,
+    secret character varying(64) NOT NULL,
+    enabled boolean DEFAULT true NOT NULL,
+    failure_count integer DEFAULT 0 NOT NULL,
+    created_at timestamp without time zone NOT NULL,
+    updated_at timestamp without time zone NOT NULL
+);
+
+
+ALTER TABLE public.webhooks OWNER TO web_history;
+
 --
 -- Name: websites; Type: TABLE; Schema: public; Owner: web_history
 --
@@ -45,12 +162,29 @@ CREATE TABLE public.websites (
     title text,
     content text,
     update_time timestamp without time zone,
-    status text DEFAULT 'active'::text NOT NULL
+    status text DEFAULT 'active'::text NOT NULL,
+    redirect_uuid character varying(64),
+    missing_count integer DEFAULT 0 NOT NULL,
+    orphaned_at timestamp without time zone
 );
 
 
 ALTER TABLE public.websites OWNER TO web_history;
 
+--
+-- Name: audit_events__user_and_created_at; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE INDEX audit_events__user_and_created_at ON public.audit_events USING btree (user_uuid, created_at);
+
+
+--
+-- Name: audit_events__uuid; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE UNIQUE INDEX audit_events__uuid ON public.audit_events USING btree (uuid);
+
+
 --
 -- Name: idx_websites_status; Type: INDEX; Schema: public; Owner: web_history
 --
@@ -58,6 +192,69 @@ ALTER TABLE public.websites OWNER TO web_history;
 CREATE INDEX idx_websites_status ON public.websites USING btree (status);
 
 
+--
+-- Name: jobs__uuid; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE UNIQUE INDEX jobs__uuid ON public.jobs USING btree (uuid);
+
+
+--
+-- Name: jobs__user_uuid_kind_created_at; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE INDEX jobs__user_uuid_kind_created_at ON public.jobs USING btree (user_uuid, kind, created_at);
+
+
+--
+-- Name: user_feed_tokens__token; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE UNIQUE INDEX user_feed_tokens__token ON public.user_feed_tokens USING btree (token);
+
+
+--
+-- Name: user_feed_tokens__user_uuid; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE UNIQUE INDEX user_feed_tokens__user_uuid ON public.user_feed_tokens USING btree (user_uuid);
+
+
+--
+-- Name: user_website_tags__user_and_tag; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE INDEX user_website_tags__user_and_tag ON public.user_website_tags USING btree (user_uuid, tag);
+
+
+--
+-- Name: user_website_tags__user_and_website_and_tag; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE UNIQUE INDEX user_website_tags__user_and_website_and_tag ON public.user_website_tags USING btree (user_uuid, website_uuid, tag);
+
+
+--
+-- Name: user_websites__display_title_trgm; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE INDEX user_websites__display_title_trgm ON public.user_websites USING gin (display_title public.gin_trgm_ops);
+
+
+--
+-- Name: user_websites__group_name_trgm; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE INDEX user_websites__group_name_trgm ON public.user_websites USING gin (group_name public.gin_trgm_ops);
+
+
+--
+-- Name: user_websites__note_trgm; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE INDEX user_websites__note_trgm ON public.user_websites USING gin (note public.gin_trgm_ops);
+
+
 --
 -- Name: user_websites__user_and_uuid; Type: INDEX; Schema: public; Owner: web_history
 --
@@ -65,6 +262,48 @@ CREATE INDEX idx_websites_status ON public.websites USING btree (status);
 CREATE UNIQUE INDEX user_websites__user_and_uuid ON public.user_websites USING btree (user_uuid, website_uuid);
 
 
+--
+-- Name: webhook_deliveries__uuid; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE UNIQUE INDEX webhook_deliveries__uuid ON public.webhook_deliveries USING btree (uuid);
+
+
+--
+-- Name: webhook_deliveries__webhook_and_created_at; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE INDEX webhook_deliveries__webhook_and_created_at ON public.webhook_deliveries USING btree (webhook_uuid, created_at);
+
+
+--
+-- Name: webhooks__user_uuid; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE INDEX webhooks__user_uuid ON public.webhooks USING btree (user_uuid);
+
+
+--
+-- Name: webhooks__uuid; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE UNIQUE INDEX webhooks__uuid ON public.webhooks USING btree (uuid);
+
+
+--
+-- Name: websites__title_trgm; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE INDEX websites__title_trgm ON public.websites USING gin (title public.gin_trgm_ops);
+
+
+--
+-- Name: websites__title_tsv; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE INDEX websites__title_tsv ON public.websites USING gin (to_tsvector('simple'::regconfig, COALESCE(title, ''::text)));
+
+
 --
 -- Name: websites__url; Type: INDEX; Schema: public; Owner: web_history
 --
@@ -72,6 +311,13 @@ CREATE UNIQUE INDEX user_websites__user_and_uuid ON public.user_websites USING b
 CREATE UNIQUE INDEX websites__url ON public.websites USING btree (url);
 
 
+--
+-- Name: websites__url_trgm; Type: INDEX; Schema: public; Owner: web_history
+--
+
+CREATE INDEX websites__url_trgm ON public.websites USING gin (url public.gin_trgm_ops);
+
+
 --
 -- Name: websites__uuid; Type: INDEX; Schema: public; Owner: web_history
 --
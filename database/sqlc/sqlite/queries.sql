@@ -0,0 +1,396 @@
+-- name: CreateWebsite :one
+INSERT INTO websites
+(uuid, url, title, content, update_time)
+VALUES
+(?, ?, ?, ?, ?)
+ON CONFLICT (url) DO
+UPDATE SET url=excluded.url
+RETURNING *;
+
+-- name: UpdateWebsite :one
+UPDATE websites SET
+url=?, title=?, content=?, update_time=?
+WHERE uuid=?
+RETURNING *;
+
+-- name: DeleteWebsite :exec
+DELETE FROM websites WHERE uuid=?;
+
+-- name: ListActiveWebsites :many
+SELECT * FROM websites WHERE status='active';
+
+-- name: ListActiveWebsitesPage :many
+SELECT * FROM websites
+WHERE status='active' and uuid > CAST(sqlc.arg(after_uuid) AS TEXT)
+and (CAST(sqlc.arg(host) AS TEXT) = ''
+  or substr(
+    '.' || substr(substr(url, instr(url, '://') + 3), 1, instr(substr(url, instr(url, '://') + 3) || '/', '/') - 1),
+    0 - length(CAST(sqlc.arg(host) AS TEXT)) - 1
+  ) = '.' || CAST(sqlc.arg(host) AS TEXT))
+ORDER BY uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetWebsite :one
+SELECT * from websites WHERE uuid=? and status != 'inactive';
+
+-- name: RecordWebsiteMissing :one
+UPDATE websites SET
+missing_count=missing_count+1,
+status=(CASE WHEN status='active' and missing_count+1 >= CAST(sqlc.arg(threshold) AS INTEGER) THEN 'gone' ELSE status END)
+WHERE uuid=sqlc.arg(uuid)
+RETURNING *;
+
+-- name: ResetWebsiteMissing :one
+UPDATE websites SET
+missing_count=0,
+status=(CASE WHEN status='gone' THEN 'active' ELSE status END)
+WHERE uuid=?
+RETURNING *;
+
+-- name: MoveUserWebsites :exec
+UPDATE OR IGNORE user_websites SET website_uuid=sqlc.arg(to_uuid)
+WHERE website_uuid=sqlc.arg(from_uuid);
+
+-- name: DeleteUserWebsitesByWebsite :exec
+DELETE FROM user_websites WHERE website_uuid=?;
+
+-- name: MoveUserWebsiteTags :exec
+UPDATE OR IGNORE user_website_tags SET website_uuid=sqlc.arg(to_uuid)
+WHERE website_uuid=sqlc.arg(from_uuid);
+
+-- name: DeleteUserWebsiteTagsByWebsite :exec
+DELETE FROM user_website_tags WHERE website_uuid=?;
+
+-- name: RedirectWebsite :exec
+UPDATE websites SET status='inactive', redirect_uuid=sqlc.arg(to_uuid), orphaned_at=NULL
+WHERE uuid=sqlc.arg(from_uuid) or redirect_uuid=sqlc.arg(from_uuid);
+
+-- name: MarkOrphanedWebsites :execrows
+UPDATE websites SET orphaned_at=sqlc.arg(orphaned_at)
+WHERE status='active' and orphaned_at IS NULL and NOT EXISTS (
+  SELECT 1 FROM user_websites WHERE user_websites.website_uuid=websites.uuid
+);
+
+-- name: ClearOrphanedWebsites :execrows
+UPDATE websites SET orphaned_at=NULL
+WHERE status='active' and orphaned_at IS NOT NULL and EXISTS (
+  SELECT 1 FROM user_websites WHERE user_websites.website_uuid=websites.uuid
+);
+
+-- name: DeactivateOrphanedWebsites :execrows
+UPDATE websites SET status='inactive'
+WHERE status='active' and orphaned_at < sqlc.arg(orphaned_before) and NOT EXISTS (
+  SELECT 1 FROM user_websites WHERE user_websites.website_uuid=websites.uuid
+);
+
+-- name: CountDeactivatedOrphanedWebsites :one
+SELECT COUNT(*) FROM websites WHERE status='inactive' and orphaned_at IS NOT NULL;
+
+-- name: ReactivateOrphanedWebsite :exec
+UPDATE websites SET
+status=(CASE WHEN status='inactive' THEN 'active' ELSE status END),
+orphaned_at=NULL
+WHERE uuid=? and orphaned_at IS NOT NULL;
+
+-- name: CreateUserWebsite :one
+INSERT INTO user_websites
+(user_uuid, website_uuid, access_time, group_name, display_title, note, last_read_chapter)
+VALUES
+(?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(user_uuid, website_uuid) DO
+UPDATE SET user_uuid=excluded.user_uuid, website_uuid=excluded.website_uuid
+RETURNING *;
+
+-- name: UpdateUserWebsite :one
+UPDATE user_websites SET
+access_time=?, group_name=?, display_title=?, note=?, last_read_chapter=?
+WHERE user_uuid=? and website_uuid=?
+RETURNING *;
+
+-- name: DeleteUserWebsite :exec
+DELETE FROM user_websites
+where user_uuid=? and website_uuid=?;
+
+-- name: RenameUserGroup :exec
+UPDATE user_websites SET group_name=sqlc.arg(to_group)
+WHERE user_uuid=sqlc.arg(user_uuid) and group_name=sqlc.arg(from_group);
+
+-- name: UngroupUserGroup :exec
+UPDATE user_websites SET group_name=(
+  SELECT title FROM websites WHERE websites.uuid=user_websites.website_uuid
+)
+WHERE user_uuid=? and group_name=?;
+
+-- name: DeleteUserGroup :exec
+DELETE FROM user_websites
+WHERE user_uuid=? and group_name=?;
+
+-- name: RefreshUserWebsites :execrows
+UPDATE user_websites SET access_time=sqlc.arg(access_time)
+WHERE user_uuid=sqlc.arg(user_uuid)
+and (CAST(sqlc.arg(group_name) AS TEXT) = '' or group_name=CAST(sqlc.arg(group_name) AS TEXT));
+
+-- name: ListUserWebsites :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=? and websites.status != 'inactive'
+ORDER BY (update_time > access_time) DESC, update_time DESC, access_time DESC;
+
+-- name: ListUserWebsitesByGroup :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=? and group_name=? and websites.status != 'inactive';
+
+-- name: GetUserWebsite :one
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=? and website_uuid=? and websites.status != 'inactive';
+
+-- name: ListWebsiteUserUUIDs :many
+SELECT user_uuid FROM user_websites
+WHERE website_uuid=?
+ORDER BY user_uuid;
+
+-- name: ListUserWebsitesPageByUnread :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=sqlc.arg(user_uuid) and websites.status != 'inactive'
+and (CAST(sqlc.arg(group_name) AS TEXT) = '' or group_name=CAST(sqlc.arg(group_name) AS TEXT))
+and (json_array_length(CAST(sqlc.arg(tags) AS TEXT)) = 0 or json_array_length(CAST(sqlc.arg(tags) AS TEXT)) = (
+  -- sqlc does not number named arg in subquery, so tags is referred as ?3
+  SELECT count(*) FROM user_website_tags JOIN json_each(CAST(?3 AS TEXT)) filter ON tag=filter.value
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+))
+and (CAST(sqlc.arg(has_cursor) AS BOOLEAN) = FALSE
+  or (update_time > access_time) < CAST(sqlc.arg(cursor_unread) AS BOOLEAN)
+  or ((update_time > access_time) = CAST(sqlc.arg(cursor_unread) AS BOOLEAN) and (
+    update_time < sqlc.arg(cursor_time)
+    or (update_time = sqlc.arg(cursor_time) and user_websites.website_uuid > sqlc.arg(cursor_uuid))
+  )))
+ORDER BY (update_time > access_time) DESC, update_time DESC, website_uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListUserWebsitesPageByUpdateTime :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=sqlc.arg(user_uuid) and websites.status != 'inactive'
+and (CAST(sqlc.arg(group_name) AS TEXT) = '' or group_name=CAST(sqlc.arg(group_name) AS TEXT))
+and (json_array_length(CAST(sqlc.arg(tags) AS TEXT)) = 0 or json_array_length(CAST(sqlc.arg(tags) AS TEXT)) = (
+  -- sqlc does not number named arg in subquery, so tags is referred as ?3
+  SELECT count(*) FROM user_website_tags JOIN json_each(CAST(?3 AS TEXT)) filter ON tag=filter.value
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+))
+and (CAST(sqlc.arg(has_cursor) AS BOOLEAN) = FALSE
+  or update_time < sqlc.arg(cursor_time)
+  or (update_time = sqlc.arg(cursor_time) and user_websites.website_uuid > sqlc.arg(cursor_uuid)))
+ORDER BY update_time DESC, website_uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListUserWebsitesPageByAccessTime :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=sqlc.arg(user_uuid) and websites.status != 'inactive'
+and (CAST(sqlc.arg(group_name) AS TEXT) = '' or group_name=CAST(sqlc.arg(group_name) AS TEXT))
+and (json_array_length(CAST(sqlc.arg(tags) AS TEXT)) = 0 or json_array_length(CAST(sqlc.arg(tags) AS TEXT)) = (
+  -- sqlc does not number named arg in subquery, so tags is referred as ?3
+  SELECT count(*) FROM user_website_tags JOIN json_each(CAST(?3 AS TEXT)) filter ON tag=filter.value
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+))
+and (CAST(sqlc.arg(has_cursor) AS BOOLEAN) = FALSE
+  or access_time < sqlc.arg(cursor_time)
+  or (access_time = sqlc.arg(cursor_time) and user_websites.website_uuid > sqlc.arg(cursor_uuid)))
+ORDER BY access_time DESC, website_uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListUserWebsitesPageByTitle :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=sqlc.arg(user_uuid) and websites.status != 'inactive'
+and (CAST(sqlc.arg(group_name) AS TEXT) = '' or group_name=CAST(sqlc.arg(group_name) AS TEXT))
+and (json_array_length(CAST(sqlc.arg(tags) AS TEXT)) = 0 or json_array_length(CAST(sqlc.arg(tags) AS TEXT)) = (
+  -- sqlc does not number named arg in subquery, so tags is referred as ?3
+  SELECT count(*) FROM user_website_tags JOIN json_each(CAST(?3 AS TEXT)) filter ON tag=filter.value
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+))
+and (CAST(sqlc.arg(has_cursor) AS BOOLEAN) = FALSE
+  or coalesce(nullif(display_title, ''), title, '') > CAST(sqlc.arg(cursor_title) AS TEXT)
+  or (coalesce(nullif(display_title, ''), title, '') = CAST(sqlc.arg(cursor_title) AS TEXT) and user_websites.website_uuid > sqlc.arg(cursor_uuid)))
+ORDER BY coalesce(nullif(display_title, ''), title, '') ASC, website_uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: CountUserUnreadWebsitesByGroup :many
+SELECT group_name, count(*) AS unread_count
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=? and websites.status != 'inactive' and update_time > access_time
+GROUP BY group_name
+ORDER BY unread_count DESC, group_name;
+
+-- name: ListUserUnreadWebsites :many
+SELECT website_uuid, group_name, display_title, title, update_time
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=sqlc.arg(user_uuid) and websites.status != 'inactive' and update_time > access_time
+ORDER BY update_time DESC, website_uuid ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: CreateUserWebsiteTag :exec
+INSERT INTO user_website_tags
+(user_uuid, website_uuid, tag)
+VALUES
+(?, ?, ?)
+ON CONFLICT(user_uuid, website_uuid, tag) DO NOTHING;
+
+-- name: DeleteUserWebsiteTag :exec
+DELETE FROM user_website_tags
+WHERE user_uuid=? and website_uuid=? and tag=?;
+
+-- name: DeleteUserWebsiteTags :exec
+DELETE FROM user_website_tags
+WHERE user_uuid=? and website_uuid=?;
+
+-- name: DeleteUserGroupTags :exec
+DELETE FROM user_website_tags
+WHERE user_website_tags.user_uuid=sqlc.arg(user_uuid) and website_uuid IN (
+  SELECT website_uuid FROM user_websites
+  WHERE user_websites.user_uuid=sqlc.arg(user_uuid) and group_name=sqlc.arg(group_name)
+);
+
+-- name: ListUserWebsiteTags :many
+SELECT tag FROM user_website_tags
+WHERE user_uuid=? and website_uuid=?
+ORDER BY tag;
+
+-- name: ListUserTags :many
+SELECT tag, count(*) AS website_count
+FROM user_website_tags
+JOIN user_websites ON user_websites.user_uuid=user_website_tags.user_uuid and user_websites.website_uuid=user_website_tags.website_uuid
+JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_website_tags.user_uuid=? and websites.status != 'inactive'
+GROUP BY tag
+ORDER BY tag;
+
+-- name: RenameUserTag :exec
+UPDATE OR IGNORE user_website_tags SET tag=sqlc.arg(to_tag)
+WHERE user_uuid=sqlc.arg(user_uuid) and tag=sqlc.arg(from_tag);
+
+-- name: DeleteUserTag :exec
+DELETE FROM user_website_tags
+WHERE user_uuid=? and tag=?;
+
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events
+(uuid, user_uuid, website_uuid, action, before, after, request_id, created_at)
+VALUES
+(?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE (CAST(sqlc.arg(user_uuid) AS TEXT) = '' or user_uuid=CAST(sqlc.arg(user_uuid) AS TEXT))
+and (CAST(sqlc.arg(website_uuid) AS TEXT) = '' or website_uuid=CAST(sqlc.arg(website_uuid) AS TEXT))
+ORDER BY created_at DESC, uuid DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: CreateJob :one
+INSERT INTO jobs
+(uuid, user_uuid, kind, status, result, created_at, updated_at)
+VALUES
+(?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpdateJob :one
+UPDATE jobs SET status=sqlc.arg(status), result=sqlc.arg(result), updated_at=sqlc.arg(updated_at)
+WHERE uuid=sqlc.arg(uuid)
+RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM jobs
+WHERE uuid=sqlc.arg(uuid) and user_uuid=sqlc.arg(user_uuid);
+
+-- name: CountUserJobs :one
+SELECT count(*) FROM jobs
+WHERE user_uuid=sqlc.arg(user_uuid) and kind=sqlc.arg(kind) and created_at >= sqlc.arg(since);
+
+-- name: SaveUserFeedToken :one
+INSERT INTO user_feed_tokens
+(user_uuid, token, created_at)
+VALUES
+(?, ?, ?)
+ON CONFLICT(user_uuid) DO
+UPDATE SET token=excluded.token, created_at=excluded.created_at
+RETURNING *;
+
+-- name: GetUserFeedToken :one
+SELECT * FROM user_feed_tokens WHERE user_uuid=sqlc.arg(user_uuid);
+
+-- name: GetUserFeedTokenByToken :one
+SELECT * FROM user_feed_tokens WHERE token=sqlc.arg(token);
+
+-- name: CreateWebhook :one
+INSERT INTO webhooks
+(uuid, user_uuid, url, group_name, secret, enabled, failure_count, created_at, updated_at)
+VALUES
+(?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpdateWebhook :one
+UPDATE webhooks SET
+url=sqlc.arg(url), group_name=sqlc.arg(group_name), enabled=sqlc.arg(enabled),
+failure_count=sqlc.arg(failure_count), updated_at=sqlc.arg(updated_at)
+WHERE uuid=sqlc.arg(uuid)
+RETURNING *;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks WHERE uuid=sqlc.arg(uuid);
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks
+WHERE uuid=sqlc.arg(uuid) and user_uuid=sqlc.arg(user_uuid);
+
+-- name: ListUserWebhooks :many
+SELECT * FROM webhooks
+WHERE user_uuid=sqlc.arg(user_uuid)
+ORDER BY created_at, uuid;
+
+-- name: ListWebsiteWebhooks :many
+SELECT webhooks.* FROM webhooks
+JOIN user_websites ON user_websites.user_uuid=webhooks.user_uuid
+WHERE user_websites.website_uuid=sqlc.arg(website_uuid) and webhooks.enabled
+and (webhooks.group_name='' or webhooks.group_name=user_websites.group_name)
+ORDER BY webhooks.uuid;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries
+(uuid, webhook_uuid, website_uuid, status, payload, attempts, response_status, error, created_at, updated_at)
+VALUES
+(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpdateWebhookDelivery :one
+UPDATE webhook_deliveries SET
+status=sqlc.arg(status), attempts=sqlc.arg(attempts), response_status=sqlc.arg(response_status),
+error=sqlc.arg(error), updated_at=sqlc.arg(updated_at)
+WHERE uuid=sqlc.arg(uuid)
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries WHERE uuid=sqlc.arg(uuid);
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_uuid=sqlc.arg(webhook_uuid)
+ORDER BY created_at DESC, uuid DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: DeleteWebhookDeliveries :exec
+DELETE FROM webhook_deliveries WHERE webhook_uuid=sqlc.arg(webhook_uuid);
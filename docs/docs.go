@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/web-watcher/websites": {
-            "post": {
-                "description": "create website",
+        "/api/web-watcher/admin/audit-events": {
+            "get": {
+                "description": "list audit events of all users from the latest",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,30 +25,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "web-history"
+                    "web-history-admin"
                 ],
-                "summary": "Create website",
+                "summary": "List audit events",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user uuid",
-                        "name": "X-USER-UUID",
+                        "description": "admin token",
+                        "name": "X-ADMIN-TOKEN",
                         "in": "header",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "url",
-                        "name": "url",
-                        "in": "formData",
-                        "required": true
+                        "description": "only list events of the user",
+                        "name": "user_uuid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list events of the website",
+                        "name": "website_uuid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max number of result",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/website.createWebsiteResp"
+                            "$ref": "#/definitions/website.listAuditEventsResp"
                         }
                     },
                     "400": {
@@ -60,9 +71,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/web-watcher/websites/groups": {
-            "get": {
-                "description": "get website group",
+        "/api/web-watcher/admin/websites/{websiteUUID}/merge": {
+            "post": {
+                "description": "merge all subscriptions of website into the website of given url",
                 "consumes": [
                     "application/json"
                 ],
@@ -70,23 +81,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "web-history"
+                    "web-history-admin"
                 ],
-                "summary": "Get website group",
+                "summary": "Merge website",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user uuid",
-                        "name": "X-USER-UUID",
+                        "description": "admin token",
+                        "name": "X-ADMIN-TOKEN",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "website uuid to be merged",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "url of target website",
+                        "name": "url",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/website.listAllWebsiteGroupsResp"
+                            "$ref": "#/definitions/website.mergeWebsiteResp"
                         }
                     },
                     "400": {
@@ -98,97 +123,103 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/web-watcher/websites/groups/{groupName}": {
+        "/api/web-watcher/feeds/{feedToken}": {
             "get": {
-                "description": "get website group",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "atom feed of the latest website updates of the user owning the token,\nthe token in url authenticates feed readers",
                 "produces": [
-                    "application/json"
+                    "application/atom+xml"
                 ],
                 "tags": [
                     "web-history"
                 ],
-                "summary": "Get website group",
+                "summary": "Atom feed",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user uuid",
-                        "name": "X-USER-UUID",
-                        "in": "header",
+                        "description": "feed token",
+                        "name": "feedToken",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "group name",
-                        "name": "groupName",
-                        "in": "path",
-                        "required": true
+                        "description": "only list websites of the group",
+                        "name": "group",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/website.getWebsiteGroupResp"
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/website.errResp"
+                            "$ref": "#/definitions/website.apiErrResp"
                         }
                     }
                 }
             }
         },
-        "/api/web-watcher/websites/{websiteUUID}": {
+        "/api/web-watcher/v2/admin/audit-events": {
             "get": {
-                "description": "get user website",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "list audit events of all users from the latest",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "web-history"
+                    "web-history-admin-v2"
                 ],
-                "summary": "Get user website",
+                "summary": "List audit events",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user uuid",
-                        "name": "X-USER-UUID",
+                        "description": "admin token",
+                        "name": "X-ADMIN-TOKEN",
                         "in": "header",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "website uuid",
-                        "name": "websiteUUID",
-                        "in": "path",
-                        "required": true
+                        "description": "only list events of the user",
+                        "name": "user_uuid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list events of the website",
+                        "name": "website_uuid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max number of result",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/website.getUserWebsiteResp"
+                            "$ref": "#/definitions/website.listAuditEventsResp"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/website.errResp"
+                            "$ref": "#/definitions/website.apiErrResp"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete user website",
+            }
+        },
+        "/api/web-watcher/v2/admin/websites/{websiteUUID}/merge": {
+            "post": {
+                "description": "merge all subscriptions of website into the website of given url",
                 "consumes": [
                     "application/json"
                 ],
@@ -196,44 +227,64 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "web-history"
+                    "web-history-admin-v2"
                 ],
-                "summary": "Delete user website",
+                "summary": "Merge website",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user uuid",
-                        "name": "X-USER-UUID",
+                        "description": "admin token",
+                        "name": "X-ADMIN-TOKEN",
                         "in": "header",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "website uuid",
+                        "description": "website uuid to be merged",
                         "name": "websiteUUID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "url of target website",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/website.mergeWebsiteReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/website.deleteWebsiteResp"
+                            "$ref": "#/definitions/website.mergeWebsiteResp"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/website.errResp"
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
                         }
                     }
                 }
             }
         },
-        "/api/web-watcher/websites/{websiteUUID}/change-group": {
-            "put": {
-                "description": "change website group",
+        "/api/web-watcher/v2/websites": {
+            "post": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "subscribe user to website of url",
                 "consumes": [
                     "application/json"
                 ],
@@ -241,122 +292,3563 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "web-history"
+                    "web-history-v2"
                 ],
-                "summary": "Change website group",
+                "summary": "Create website",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "user uuid",
-                        "name": "X-USER-UUID",
-                        "in": "header",
-                        "required": true
+                        "description": "website url",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/website.createWebsiteReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/website.getUserWebsiteResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/audit-events": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list audit events of user from the latest",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "List audit events",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "website uuid",
-                        "name": "websiteUUID",
-                        "in": "path",
-                        "required": true
+                        "description": "only list events of the website",
+                        "name": "website_uuid",
+                        "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "group name",
-                        "name": "group_name",
-                        "in": "formData",
-                        "required": true
+                        "type": "integer",
+                        "description": "max number of result",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/website.changeWebsiteGroupResp"
+                            "$ref": "#/definitions/website.listAuditEventsResp"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/website.errResp"
+                            "$ref": "#/definitions/website.apiErrResp"
                         }
                     }
                 }
             }
         },
-        "/api/web-watcher/websites/{websiteUUID}/refresh": {
-            "put": {
-                "description": "update user website",
-                "consumes": [
-                    "application/json"
+        "/api/web-watcher/v2/websites/events": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
                 ],
+                "description": "stream server-sent events of the changes of user websites, the\nevents after Last-Event-ID header are replayed on reconnect",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "web-history"
+                    "web-history-v2"
                 ],
-                "summary": "Update user website",
+                "summary": "Stream website events",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user uuid",
-                        "name": "X-USER-UUID",
-                        "in": "header",
-                        "required": true
+                        "description": "id of last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.websiteEventResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/export": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "export all websites of user as json, csv or opml file",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/x-opml"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Export websites",
+                "parameters": [
                     {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "opml"
+                        ],
                         "type": "string",
-                        "description": "website uuid",
-                        "name": "websiteUUID",
-                        "in": "path",
-                        "required": true
+                        "description": "file format",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/website.refreshWebsiteResp"
+                            "type": "file"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/website.errResp"
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "website.UserWebsiteResp": {
-            "type": "object",
-            "properties": {
-                "access_time": {
-                    "type": "string"
-                },
-                "group_name": {
-                    "type": "string"
-                },
-                "title": {
-                    "type": "string"
-                },
-                "update_time": {
-                    "type": "string"
-                },
-                "url": {
-                    "type": "string"
-                },
-                "user_uuid": {
-                    "type": "string"
-                },
-                "uuid": {
-                    "type": "string"
+        },
+        "/api/web-watcher/v2/websites/feed-token": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "get the secret token of user atom feed, the token is created on first access",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Get feed token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.feedTokenResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "replace the secret token of user atom feed, the old feed url stops working",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Rotate feed token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.feedTokenResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
                 }
             }
         },
-        "website.changeWebsiteGroupResp": {
+        "/api/web-watcher/v2/websites/group-counts": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list groups of user with the number of websites and unread chapters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "List groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listGroupsResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/groups": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list website groups of user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "List website groups",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page size, enables pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "unread",
+                            "update_time",
+                            "access_time",
+                            "title"
+                        ],
+                        "type": "string",
+                        "description": "sort key",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "only list websites having all the tags",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listAllWebsiteGroupsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/groups/{groupName}": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "get website group of user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Get website group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, enables pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "unread",
+                            "update_time",
+                            "access_time",
+                            "title"
+                        ],
+                        "type": "string",
+                        "description": "sort key",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "only list websites having all the tags",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.getWebsiteGroupResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "delete group by unsubscribing its websites or moving them back to the group named by website title",
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Delete group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "unsubscribe",
+                            "ungroup"
+                        ],
+                        "type": "string",
+                        "description": "delete mode",
+                        "name": "mode",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/groups/{groupName}/merge": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "move all websites of group into another existing group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Merge group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "name of group merged into",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/website.groupNameReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.mergeGroupResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/groups/{groupName}/refresh": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "mark all websites of group as read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Refresh group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.refreshWebsitesResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/groups/{groupName}/rename": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "rename group of all its websites, new group name must not be used",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Rename group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new group name",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/website.groupNameReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.renameGroupResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/import": {
+            "post": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "import websites from json, csv or opml file in background, the\nreport of each website is kept in the returned job",
+                "consumes": [
+                    "application/json",
+                    "text/csv",
+                    "text/x-opml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Import websites",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "opml"
+                        ],
+                        "type": "string",
+                        "description": "file format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "description": "imported file",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/website.importWebsitesResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/jobs/{jobUUID}": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "get status of background job, with the report once it is done",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Get job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "job uuid",
+                        "name": "jobUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.getJobResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/refresh": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "mark all websites of user as read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Refresh all websites",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.refreshWebsitesResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/search": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "search user websites by title, group name, url and note",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Search user websites",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max number of result",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.searchWebsitesResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/summary": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "count websites updated after last access by group, and list the latest updated ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Summarize unread websites",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.websiteSummaryResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/tags": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list tags of user with the number of tagged websites",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "List tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listTagsResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/tags/{tag}": {
+            "delete": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "remove tag from all websites",
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Delete tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/tags/{tag}/rename": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "rename tag of all websites, the tags are merged if new tag already exists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Rename tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new tag",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/website.tagReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listTagsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list webhooks of user, the secret of webhook is not listed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listWebhooksResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "create webhook receiving the updates of user websites, or only\nthe websites of group_name if it is given. The secret signing the\npayloads is only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "description": "webhook",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/website.webhookReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/website.webhookResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/webhooks/{webhookUUID}": {
+            "delete": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "delete webhook with its delivery logs",
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook uuid",
+                        "name": "webhookUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "update the given fields of webhook, enabling a disabled webhook resets its failure count",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Update webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook uuid",
+                        "name": "webhookUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "updated fields",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/website.webhookReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.webhookResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/webhooks/{webhookUUID}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list the latest deliveries of webhook with their status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook uuid",
+                        "name": "webhookUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listWebhookDeliveriesResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/{webUUID}/check": {
+            "post": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "update website in background immediately, the result is kept in the returned job",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Check website",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "webUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/website.checkWebsiteResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/{websiteUUID}": {
+            "delete": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "unsubscribe user from website",
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Delete user website",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "update display title or note of user website",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Update user website",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "title and note, empty title to use website title",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/website.updateUserWebsiteReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.updateUserWebsiteResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/{websiteUUID}/change-group": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "move user website to another group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Change website group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "group name",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/website.groupNameReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.changeWebsiteGroupResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/{websiteUUID}/read-progress": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "record the last read chapter of user website, empty chapter to clear the progress",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Update read progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "last read chapter",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/website.updateReadProgressReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.updateReadProgressResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/{websiteUUID}/refresh": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "mark user website as read now",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Refresh user website",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.refreshWebsiteResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/{websiteUUID}/tags": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list tags of user website",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "List website tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.websiteTagsResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/v2/websites/{websiteUUID}/tags/{tag}": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "add tag to user website",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Add website tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.websiteTagsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "remove tag from user website",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history-v2"
+                ],
+                "summary": "Remove website tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.websiteTagsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/website.apiErrResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites": {
+            "post": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "create website",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Create website",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "url",
+                        "name": "url",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.createWebsiteResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/audit-events": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list audit events of user from the latest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "List audit events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only list events of the website",
+                        "name": "website_uuid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max number of result",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listAuditEventsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/events": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "stream server-sent events of the changes of user websites, the\nevents after Last-Event-ID header are replayed on reconnect",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Stream website events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.websiteEventResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/export": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "export all websites of user as json, csv or opml file",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/x-opml"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Export websites",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "opml"
+                        ],
+                        "type": "string",
+                        "description": "file format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/feed-token": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "get the secret token of user atom feed, the token is created on first access",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Get feed token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.feedTokenResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "replace the secret token of user atom feed, the old feed url stops working",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Rotate feed token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.feedTokenResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/group-counts": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list groups of user with the number of websites and unread chapters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "List groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listGroupsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/groups": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "get website group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Get website group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page size, enables pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "unread",
+                            "update_time",
+                            "access_time",
+                            "title"
+                        ],
+                        "type": "string",
+                        "description": "sort key",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "only list websites having all the tags",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listAllWebsiteGroupsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/groups/{groupName}": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "get website group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Get website group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, enables pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "unread",
+                            "update_time",
+                            "access_time",
+                            "title"
+                        ],
+                        "type": "string",
+                        "description": "sort key",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "only list websites having all the tags",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.getWebsiteGroupResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "delete group by unsubscribing its websites or moving them back to the group named by website title",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Delete group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "unsubscribe",
+                            "ungroup"
+                        ],
+                        "type": "string",
+                        "description": "delete mode",
+                        "name": "mode",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.deleteGroupResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/groups/{groupName}/merge": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "move all websites of group into another existing group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Merge group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of group merged into",
+                        "name": "group_name",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.mergeGroupResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/groups/{groupName}/refresh": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "mark all websites of group as read",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Refresh group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.refreshWebsitesResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/groups/{groupName}/rename": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "rename group of all its websites, new group name must not be used",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Rename group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "groupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "new group name",
+                        "name": "group_name",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.renameGroupResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/import": {
+            "post": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "import websites from json, csv or opml file in background, the\nreport of each website is kept in the returned job",
+                "consumes": [
+                    "application/json",
+                    "text/csv",
+                    "text/x-opml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Import websites",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "opml"
+                        ],
+                        "type": "string",
+                        "description": "file format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "description": "imported file",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/website.importWebsitesResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/jobs/{jobUUID}": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "get status of background job, with the report once it is done",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Get job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "job uuid",
+                        "name": "jobUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.getJobResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/refresh": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "mark all websites of user as read",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Refresh all websites",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.refreshWebsitesResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/search": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "search user websites by title, group name, url and note",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Search user websites",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max number of result",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.searchWebsitesResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/summary": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "count websites updated after last access by group, and list the latest updated ones",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Summarize unread websites",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.websiteSummaryResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/tags": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list tags of user with the number of tagged websites",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "List tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listTagsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/tags/{tag}": {
+            "delete": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "remove tag from all websites",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Delete tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.deleteTagResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/tags/{tag}/rename": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "rename tag of all websites, the tags are merged if new tag already exists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Rename tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "new tag",
+                        "name": "tag",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listTagsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list webhooks of user, the secret of webhook is not listed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listWebhooksResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "create webhook receiving the updates of user websites, or only\nthe websites of group_name if it is given. The secret signing the\npayloads is only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook url",
+                        "name": "url",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "only send updates of websites in the group",
+                        "name": "group_name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "enabled",
+                        "name": "enabled",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.webhookResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/webhooks/{webhookUUID}": {
+            "delete": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "delete webhook with its delivery logs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook uuid",
+                        "name": "webhookUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.deleteWebhookResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "update the given fields of webhook, enabling a disabled webhook resets its failure count",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Update webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook uuid",
+                        "name": "webhookUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "webhook url",
+                        "name": "url",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only send updates of websites in the group",
+                        "name": "group_name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "enabled",
+                        "name": "enabled",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.webhookResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/webhooks/{webhookUUID}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list the latest deliveries of webhook with their status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook uuid",
+                        "name": "webhookUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.listWebhookDeliveriesResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/{webUUID}/check": {
+            "post": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "update website in background immediately, the result is kept in the returned job",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Check website",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "webUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/website.checkWebsiteResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/{websiteUUID}": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "get user website",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Get user website",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.getUserWebsiteResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "delete user website",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Delete user website",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.deleteWebsiteResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "update display title or note of user website",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Update user website",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "display title, empty to use website title",
+                        "name": "title",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "note",
+                        "name": "note",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.updateUserWebsiteResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/{websiteUUID}/change-group": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "change website group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Change website group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "group_name",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.changeWebsiteGroupResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/{websiteUUID}/read-progress": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "record the last read chapter of user website, empty chapter to clear the progress",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Update read progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "last read chapter",
+                        "name": "chapter",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.updateReadProgressResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/{websiteUUID}/refresh": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "update user website",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Update user website",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.refreshWebsiteResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/{websiteUUID}/tags": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "list tags of user website",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "List website tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.websiteTagsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/web-watcher/websites/{websiteUUID}/tags/{tag}": {
+            "put": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "add tag to user website",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Add website tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.websiteTagsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "remove tag from user website",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "web-history"
+                ],
+                "summary": "Remove website tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "website uuid",
+                        "name": "websiteUUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "tag",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/website.websiteTagsResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/website.errResp"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "website.APIError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.AuditEventResp": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "user_uuid": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                },
+                "website_uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.FeedTokenResp": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "feed_path": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.GroupUnreadCountResp": {
+            "type": "object",
+            "properties": {
+                "group_name": {
+                    "type": "string"
+                },
+                "unread_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "website.JobResp": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "object"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.TagResp": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "website_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "website.UnreadWebsiteResp": {
+            "type": "object",
+            "properties": {
+                "group_name": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "update_time": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.UserWebsiteResp": {
+            "type": "object",
+            "properties": {
+                "access_time": {
+                    "type": "string"
+                },
+                "gone": {
+                    "type": "boolean"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "last_read_chapter": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "unread_count": {
+                    "type": "integer"
+                },
+                "update_time": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_uuid": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.WebhookDeliveryResp": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_status": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                },
+                "website_uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.WebhookResp": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "failure_count": {
+                    "type": "integer"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.WebsiteGroupSummaryResp": {
+            "type": "object",
+            "properties": {
+                "group_name": {
+                    "type": "string"
+                },
+                "unread_count": {
+                    "type": "integer"
+                },
+                "website_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "website.WebsiteResp": {
+            "type": "object",
+            "properties": {
+                "title": {
+                    "type": "string"
+                },
+                "update_time": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.apiErrResp": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/website.APIError"
+                }
+            }
+        },
+        "website.changeWebsiteGroupResp": {
             "type": "object",
             "properties": {
                 "website": {
@@ -364,6 +3856,22 @@ const docTemplate = `{
                 }
             }
         },
+        "website.checkWebsiteResp": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/website.JobResp"
+                }
+            }
+        },
+        "website.createWebsiteReq": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "website.createWebsiteResp": {
             "type": "object",
             "properties": {
@@ -372,6 +3880,30 @@ const docTemplate = `{
                 }
             }
         },
+        "website.deleteGroupResp": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.deleteTagResp": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.deleteWebhookResp": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
         "website.deleteWebsiteResp": {
             "type": "object",
             "properties": {
@@ -388,6 +3920,22 @@ const docTemplate = `{
                 }
             }
         },
+        "website.feedTokenResp": {
+            "type": "object",
+            "properties": {
+                "feed_token": {
+                    "$ref": "#/definitions/website.FeedTokenResp"
+                }
+            }
+        },
+        "website.getJobResp": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/website.JobResp"
+                }
+            }
+        },
         "website.getUserWebsiteResp": {
             "type": "object",
             "properties": {
@@ -399,6 +3947,9 @@ const docTemplate = `{
         "website.getWebsiteGroupResp": {
             "type": "object",
             "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
                 "website_group": {
                     "type": "array",
                     "items": {
@@ -407,9 +3958,28 @@ const docTemplate = `{
                 }
             }
         },
+        "website.groupNameReq": {
+            "type": "object",
+            "properties": {
+                "group_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.importWebsitesResp": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/website.JobResp"
+                }
+            }
+        },
         "website.listAllWebsiteGroupsResp": {
             "type": "object",
             "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
                 "website_groups": {
                     "type": "array",
                     "items": {
@@ -421,6 +3991,91 @@ const docTemplate = `{
                 }
             }
         },
+        "website.listAuditEventsResp": {
+            "type": "object",
+            "properties": {
+                "audit_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/website.AuditEventResp"
+                    }
+                }
+            }
+        },
+        "website.listGroupsResp": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/website.WebsiteGroupSummaryResp"
+                    }
+                }
+            }
+        },
+        "website.listTagsResp": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/website.TagResp"
+                    }
+                }
+            }
+        },
+        "website.listWebhookDeliveriesResp": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/website.WebhookDeliveryResp"
+                    }
+                }
+            }
+        },
+        "website.listWebhooksResp": {
+            "type": "object",
+            "properties": {
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/website.WebhookResp"
+                    }
+                }
+            }
+        },
+        "website.mergeGroupResp": {
+            "type": "object",
+            "properties": {
+                "website_group": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/website.UserWebsiteResp"
+                    }
+                }
+            }
+        },
+        "website.mergeWebsiteReq": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.mergeWebsiteResp": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "$ref": "#/definitions/website.WebsiteResp"
+                },
+                "to": {
+                    "$ref": "#/definitions/website.WebsiteResp"
+                }
+            }
+        },
         "website.refreshWebsiteResp": {
             "type": "object",
             "properties": {
@@ -428,6 +4083,148 @@ const docTemplate = `{
                     "$ref": "#/definitions/website.UserWebsiteResp"
                 }
             }
+        },
+        "website.refreshWebsitesResp": {
+            "type": "object",
+            "properties": {
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "website.renameGroupResp": {
+            "type": "object",
+            "properties": {
+                "website_group": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/website.UserWebsiteResp"
+                    }
+                }
+            }
+        },
+        "website.searchWebsitesResp": {
+            "type": "object",
+            "properties": {
+                "websites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/website.UserWebsiteResp"
+                    }
+                }
+            }
+        },
+        "website.tagReq": {
+            "type": "object",
+            "properties": {
+                "tag": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.updateReadProgressReq": {
+            "type": "object",
+            "properties": {
+                "chapter": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.updateReadProgressResp": {
+            "type": "object",
+            "properties": {
+                "website": {
+                    "$ref": "#/definitions/website.UserWebsiteResp"
+                }
+            }
+        },
+        "website.updateUserWebsiteReq": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.updateUserWebsiteResp": {
+            "type": "object",
+            "properties": {
+                "website": {
+                    "$ref": "#/definitions/website.UserWebsiteResp"
+                }
+            }
+        },
+        "website.webhookReq": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "website.webhookResp": {
+            "type": "object",
+            "properties": {
+                "webhook": {
+                    "$ref": "#/definitions/website.WebhookResp"
+                }
+            }
+        },
+        "website.websiteEventResp": {
+            "type": "object",
+            "properties": {
+                "website": {
+                    "$ref": "#/definitions/website.WebsiteResp"
+                }
+            }
+        },
+        "website.websiteSummaryResp": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/website.GroupUnreadCountResp"
+                    }
+                },
+                "latest_unread": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/website.UnreadWebsiteResp"
+                    }
+                },
+                "unread_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "website.websiteTagsResp": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "UserToken": {
+            "description": "Bearer token verified by user service, the session cookie is accepted as well",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
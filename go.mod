@@ -27,6 +27,7 @@ require (
 	go.uber.org/mock v0.5.2
 	golang.org/x/sync v0.19.0
 	gopkg.in/yaml.v3 v3.0.1
+	modernc.org/sqlite v1.37.0
 )
 
 require (
@@ -129,7 +130,6 @@ require (
 	modernc.org/libc v1.62.1 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
 	modernc.org/memory v1.9.1 // indirect
-	modernc.org/sqlite v1.37.0 // indirect
 	sigs.k8s.io/yaml v1.3.0 // indirect
 )
 
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -14,8 +15,11 @@ type APIConfig struct {
 	DatabaseConfig    DatabaseConfig
 	TraceConfig       TraceConfig
 	UserServiceConfig UserServiceConfig
+	AdminConfig       AdminConfig
 	WebsiteConfig     WebsiteConfig
 	NatsConfig        NatsConfig
+	CacheConfig       CacheConfig
+	CheckConfig       WebsiteCheckConfig
 	VendorConfigPath  string `env:"VENDOR_CONFIG_PATH" envDefault:"/config/vendors.yaml"`
 }
 
@@ -34,6 +38,8 @@ type WorkerConfig struct {
 	TraceConfig      TraceConfig
 	WebsiteConfig    WebsiteConfig
 	NatsConfig       NatsConfig
+	CacheConfig      CacheConfig
+	WebhookConfig    WebhookConfig
 	VendorConfigPath string `env:"VENDOR_CONFIG_PATH" envDefault:"/config/vendors.yaml"`
 }
 
@@ -50,29 +56,94 @@ type TraceConfig struct {
 	OtelServiceName string `env:"OTEL_SERVICE_NAME"`
 }
 
+// api and worker run in separate processes and share data through database,
+// so memory repository is not selectable and is only used in tests
+const (
+	DatabaseDriverPostgres = "postgres"
+	DatabaseDriverSqlite   = "sqlite"
+)
+
 type DatabaseConfig struct {
-	Driver   string `env:"DRIVER" envDefault:"postgres"`
-	Host     string `env:"PSQL_HOST,required"`
-	Port     string `env:"PSQL_PORT,required"`
-	User     string `env:"PSQL_USER,required"`
-	Password string `env:"PSQL_PASSWORD,required"`
-	Database string `env:"PSQL_NAME,required"`
+	Driver     string `env:"DRIVER" envDefault:"postgres"`
+	Host       string `env:"PSQL_HOST"`
+	Port       string `env:"PSQL_PORT"`
+	User       string `env:"PSQL_USER"`
+	Password   string `env:"PSQL_PASSWORD"`
+	Database   string `env:"PSQL_NAME"`
+	SqlitePath string `env:"SQLITE_PATH" envDefault:"/data/web-history.db"`
+}
+
+// validate reports the missing PSQL_* env, they are only required by postgres
+func (conf DatabaseConfig) validate() error {
+	if conf.Driver != DatabaseDriverPostgres {
+		return nil
+	}
+
+	var errs []error
+	for _, field := range []struct {
+		key   string
+		value string
+	}{
+		{key: "PSQL_HOST", value: conf.Host},
+		{key: "PSQL_PORT", value: conf.Port},
+		{key: "PSQL_USER", value: conf.User},
+		{key: "PSQL_PASSWORD", value: conf.Password},
+		{key: "PSQL_NAME", value: conf.Database},
+	} {
+		if field.value == "" {
+			errs = append(errs, env.EnvVarIsNotSetError{Key: field.key})
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 type UserServiceConfig struct {
-	Addr  string `env:"USER_SERVICE_ADDR,required"`
-	Token string `env:"USER_SERVICE_TOKEN,required"`
+	Addr        string        `env:"USER_SERVICE_ADDR,required"`
+	Token       string        `env:"USER_SERVICE_TOKEN,required"`
+	Timeout     time.Duration `env:"USER_SERVICE_TIMEOUT" envDefault:"5s"`
+	CacheTTL    time.Duration `env:"USER_SERVICE_CACHE_TTL" envDefault:"1m"` // revoked token is accepted until its cache expires
+	CacheSize   int           `env:"USER_SERVICE_CACHE_SIZE" envDefault:"10000"`
+	CookieName  string        `env:"USER_SERVICE_COOKIE_NAME" envDefault:"token"`
+	LoginURL    string        `env:"LOGIN_URL"` // browser without valid token is redirected to login page if it is set
+	ServiceUUID string        `env:"SERVICE_UUID"`
+}
+
+type AdminConfig struct {
+	Token string `env:"ADMIN_TOKEN"`
 }
 
 type WebsiteConfig struct {
-	Separator     string `env:"WEB_WATCHER_SEPARATOR" envDefault:"\n"`      // to be deprecated:  move it to a const in model package
-	MaxDateLength int    `env:"WEB_WATCHER_DATE_MAX_LENGTH" envDefault:"2"` // to be deprecated
+	Separator         string        `env:"WEB_WATCHER_SEPARATOR" envDefault:"\n"`      // to be deprecated:  move it to a const in model package
+	MaxDateLength     int           `env:"WEB_WATCHER_DATE_MAX_LENGTH" envDefault:"2"` // to be deprecated
+	GoneThreshold     int           `env:"WEB_WATCHER_GONE_THRESHOLD" envDefault:"5"`
+	OrphanGracePeriod time.Duration `env:"WEB_WATCHER_ORPHAN_GRACE_PERIOD" envDefault:"720h"` // keep updating website without subscriber for this period
+	SeriesGrouping    bool          `env:"WEB_WATCHER_SERIES_GROUPING"`                       // only allow group name sharing a character with website title
 }
 
 type NatsConfig struct {
 	URL string `env:"NATS_URL,required"`
 }
 
+type CacheConfig struct {
+	Enabled bool          `env:"CACHE_ENABLED"`
+	TTL     time.Duration `env:"CACHE_TTL" envDefault:"1m"`
+	Size    int           `env:"CACHE_SIZE" envDefault:"10000"`
+}
+
+// WebsiteCheckConfig throttles the update of website requested by user
+type WebsiteCheckConfig struct {
+	Limit  int           `env:"WEBSITE_CHECK_LIMIT" envDefault:"10"`
+	Window time.Duration `env:"WEBSITE_CHECK_WINDOW" envDefault:"1h"` // user can request at most limit checks in this period
+}
+
+type WebhookConfig struct {
+	Timeout          time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	MaxAttempts      int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"5"`
+	RetryInterval    time.Duration `env:"WEBHOOK_RETRY_INTERVAL" envDefault:"30s"`   // doubled after every failed attempt
+	DisableThreshold int           `env:"WEBHOOK_DISABLE_THRESHOLD" envDefault:"10"` // disable webhook after this number of failed deliveries in a row
+}
+
 func LoadAPIConfig() (*APIConfig, error) {
 	var conf APIConfig
 
@@ -80,10 +151,14 @@ func LoadAPIConfig() (*APIConfig, error) {
 		func() error { return env.Parse(&conf) },
 		func() error { return env.Parse(&conf.BinConfig) },
 		func() error { return env.Parse(&conf.DatabaseConfig) },
+		func() error { return conf.DatabaseConfig.validate() },
 		func() error { return env.Parse(&conf.TraceConfig) },
 		func() error { return env.Parse(&conf.UserServiceConfig) },
+		func() error { return env.Parse(&conf.AdminConfig) },
 		func() error { return env.Parse(&conf.WebsiteConfig) },
 		func() error { return env.Parse(&conf.NatsConfig) },
+		func() error { return env.Parse(&conf.CacheConfig) },
+		func() error { return env.Parse(&conf.CheckConfig) },
 		func() error {
 			contentBytes, err := os.ReadFile(conf.VendorConfigPath)
 			if err != nil {
@@ -115,9 +190,12 @@ func LoadWorkerConfig() (*WorkerConfig, error) {
 		func() error { return env.Parse(&conf) },
 		func() error { return env.Parse(&conf.BinConfig) },
 		func() error { return env.Parse(&conf.DatabaseConfig) },
+		func() error { return conf.DatabaseConfig.validate() },
 		func() error { return env.Parse(&conf.TraceConfig) },
 		func() error { return env.Parse(&conf.WebsiteConfig) },
 		func() error { return env.Parse(&conf.NatsConfig) },
+		func() error { return env.Parse(&conf.CacheConfig) },
+		func() error { return env.Parse(&conf.WebhookConfig) },
 		func() error {
 			contentBytes, err := os.ReadFile(conf.VendorConfigPath)
 			if err != nil {
@@ -13,8 +13,10 @@ import (
 	context "context"
 	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	model "github.com/htchan/WebHistory/internal/model"
+	repository "github.com/htchan/WebHistory/internal/repository"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -42,6 +44,78 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddUserWebsiteTag mocks base method.
+func (m *MockRepository) AddUserWebsiteTag(ctx context.Context, userUUID, websiteUUID, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUserWebsiteTag", ctx, userUUID, websiteUUID, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUserWebsiteTag indicates an expected call of AddUserWebsiteTag.
+func (mr *MockRepositoryMockRecorder) AddUserWebsiteTag(ctx, userUUID, websiteUUID, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserWebsiteTag", reflect.TypeOf((*MockRepository)(nil).AddUserWebsiteTag), ctx, userUUID, websiteUUID, tag)
+}
+
+// CleanupOrphanedWebsites mocks base method.
+func (m *MockRepository) CleanupOrphanedWebsites(ctx context.Context, now time.Time, gracePeriod time.Duration) (repository.OrphanCleanupResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanupOrphanedWebsites", ctx, now, gracePeriod)
+	ret0, _ := ret[0].(repository.OrphanCleanupResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanupOrphanedWebsites indicates an expected call of CleanupOrphanedWebsites.
+func (mr *MockRepositoryMockRecorder) CleanupOrphanedWebsites(ctx, now, gracePeriod any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupOrphanedWebsites", reflect.TypeOf((*MockRepository)(nil).CleanupOrphanedWebsites), ctx, now, gracePeriod)
+}
+
+// CountUserJobs mocks base method.
+func (m *MockRepository) CountUserJobs(ctx context.Context, userUUID, kind string, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserJobs", ctx, userUUID, kind, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserJobs indicates an expected call of CountUserJobs.
+func (mr *MockRepositoryMockRecorder) CountUserJobs(ctx, userUUID, kind, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserJobs", reflect.TypeOf((*MockRepository)(nil).CountUserJobs), ctx, userUUID, kind, since)
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockRepository) CreateAuditEvent(arg0 context.Context, arg1 *model.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockRepositoryMockRecorder) CreateAuditEvent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockRepository)(nil).CreateAuditEvent), arg0, arg1)
+}
+
+// CreateJob mocks base method.
+func (m *MockRepository) CreateJob(arg0 context.Context, arg1 *model.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockRepositoryMockRecorder) CreateJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockRepository)(nil).CreateJob), arg0, arg1)
+}
+
 // CreateUserWebsite mocks base method.
 func (m *MockRepository) CreateUserWebsite(arg0 context.Context, arg1 *model.UserWebsite) error {
 	m.ctrl.T.Helper()
@@ -56,6 +130,34 @@ func (mr *MockRepositoryMockRecorder) CreateUserWebsite(arg0, arg1 any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserWebsite", reflect.TypeOf((*MockRepository)(nil).CreateUserWebsite), arg0, arg1)
 }
 
+// CreateWebhook mocks base method.
+func (m *MockRepository) CreateWebhook(arg0 context.Context, arg1 *model.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockRepositoryMockRecorder) CreateWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockRepository)(nil).CreateWebhook), arg0, arg1)
+}
+
+// CreateWebhookDelivery mocks base method.
+func (m *MockRepository) CreateWebhookDelivery(arg0 context.Context, arg1 *model.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDelivery indicates an expected call of CreateWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) CreateWebhookDelivery(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).CreateWebhookDelivery), arg0, arg1)
+}
+
 // CreateWebsite mocks base method.
 func (m *MockRepository) CreateWebsite(arg0 context.Context, arg1 *model.Website) error {
 	m.ctrl.T.Helper()
@@ -70,6 +172,34 @@ func (mr *MockRepositoryMockRecorder) CreateWebsite(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebsite", reflect.TypeOf((*MockRepository)(nil).CreateWebsite), arg0, arg1)
 }
 
+// DeleteUserGroup mocks base method.
+func (m *MockRepository) DeleteUserGroup(ctx context.Context, userUUID, group string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserGroup", ctx, userUUID, group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserGroup indicates an expected call of DeleteUserGroup.
+func (mr *MockRepositoryMockRecorder) DeleteUserGroup(ctx, userUUID, group any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserGroup", reflect.TypeOf((*MockRepository)(nil).DeleteUserGroup), ctx, userUUID, group)
+}
+
+// DeleteUserTag mocks base method.
+func (m *MockRepository) DeleteUserTag(ctx context.Context, userUUID, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTag", ctx, userUUID, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTag indicates an expected call of DeleteUserTag.
+func (mr *MockRepositoryMockRecorder) DeleteUserTag(ctx, userUUID, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTag", reflect.TypeOf((*MockRepository)(nil).DeleteUserTag), ctx, userUUID, tag)
+}
+
 // DeleteUserWebsite mocks base method.
 func (m *MockRepository) DeleteUserWebsite(arg0 context.Context, arg1 *model.UserWebsite) error {
 	m.ctrl.T.Helper()
@@ -84,6 +214,20 @@ func (mr *MockRepositoryMockRecorder) DeleteUserWebsite(arg0, arg1 any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserWebsite", reflect.TypeOf((*MockRepository)(nil).DeleteUserWebsite), arg0, arg1)
 }
 
+// DeleteWebhook mocks base method.
+func (m *MockRepository) DeleteWebhook(arg0 context.Context, arg1 *model.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockRepositoryMockRecorder) DeleteWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockRepository)(nil).DeleteWebhook), arg0, arg1)
+}
+
 // DeleteWebsite mocks base method.
 func (m *MockRepository) DeleteWebsite(arg0 context.Context, arg1 *model.Website) error {
 	m.ctrl.T.Helper()
@@ -98,6 +242,96 @@ func (mr *MockRepositoryMockRecorder) DeleteWebsite(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebsite", reflect.TypeOf((*MockRepository)(nil).DeleteWebsite), arg0, arg1)
 }
 
+// FindAuditEvents mocks base method.
+func (m *MockRepository) FindAuditEvents(ctx context.Context, query repository.AuditEventsQuery) ([]model.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAuditEvents", ctx, query)
+	ret0, _ := ret[0].([]model.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAuditEvents indicates an expected call of FindAuditEvents.
+func (mr *MockRepositoryMockRecorder) FindAuditEvents(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAuditEvents", reflect.TypeOf((*MockRepository)(nil).FindAuditEvents), ctx, query)
+}
+
+// FindFeedToken mocks base method.
+func (m *MockRepository) FindFeedToken(ctx context.Context, userUUID string) (*model.FeedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFeedToken", ctx, userUUID)
+	ret0, _ := ret[0].(*model.FeedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindFeedToken indicates an expected call of FindFeedToken.
+func (mr *MockRepositoryMockRecorder) FindFeedToken(ctx, userUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFeedToken", reflect.TypeOf((*MockRepository)(nil).FindFeedToken), ctx, userUUID)
+}
+
+// FindFeedTokenByToken mocks base method.
+func (m *MockRepository) FindFeedTokenByToken(ctx context.Context, token string) (*model.FeedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFeedTokenByToken", ctx, token)
+	ret0, _ := ret[0].(*model.FeedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindFeedTokenByToken indicates an expected call of FindFeedTokenByToken.
+func (mr *MockRepositoryMockRecorder) FindFeedTokenByToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFeedTokenByToken", reflect.TypeOf((*MockRepository)(nil).FindFeedTokenByToken), ctx, token)
+}
+
+// FindJob mocks base method.
+func (m *MockRepository) FindJob(ctx context.Context, userUUID, uuid string) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindJob", ctx, userUUID, uuid)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindJob indicates an expected call of FindJob.
+func (mr *MockRepositoryMockRecorder) FindJob(ctx, userUUID, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindJob", reflect.TypeOf((*MockRepository)(nil).FindJob), ctx, userUUID, uuid)
+}
+
+// FindUserTags mocks base method.
+func (m *MockRepository) FindUserTags(ctx context.Context, userUUID string) ([]model.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserTags", ctx, userUUID)
+	ret0, _ := ret[0].([]model.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserTags indicates an expected call of FindUserTags.
+func (mr *MockRepositoryMockRecorder) FindUserTags(ctx, userUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserTags", reflect.TypeOf((*MockRepository)(nil).FindUserTags), ctx, userUUID)
+}
+
+// FindUserUnreadSummary mocks base method.
+func (m *MockRepository) FindUserUnreadSummary(ctx context.Context, userUUID string, limit int) (model.UnreadSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserUnreadSummary", ctx, userUUID, limit)
+	ret0, _ := ret[0].(model.UnreadSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserUnreadSummary indicates an expected call of FindUserUnreadSummary.
+func (mr *MockRepositoryMockRecorder) FindUserUnreadSummary(ctx, userUUID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserUnreadSummary", reflect.TypeOf((*MockRepository)(nil).FindUserUnreadSummary), ctx, userUUID, limit)
+}
+
 // FindUserWebsite mocks base method.
 func (m *MockRepository) FindUserWebsite(ctx context.Context, userUUID, websiteUUID string) (*model.UserWebsite, error) {
 	m.ctrl.T.Helper()
@@ -113,6 +347,21 @@ func (mr *MockRepositoryMockRecorder) FindUserWebsite(ctx, userUUID, websiteUUID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserWebsite", reflect.TypeOf((*MockRepository)(nil).FindUserWebsite), ctx, userUUID, websiteUUID)
 }
 
+// FindUserWebsiteTags mocks base method.
+func (m *MockRepository) FindUserWebsiteTags(ctx context.Context, userUUID, websiteUUID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserWebsiteTags", ctx, userUUID, websiteUUID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserWebsiteTags indicates an expected call of FindUserWebsiteTags.
+func (mr *MockRepositoryMockRecorder) FindUserWebsiteTags(ctx, userUUID, websiteUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserWebsiteTags", reflect.TypeOf((*MockRepository)(nil).FindUserWebsiteTags), ctx, userUUID, websiteUUID)
+}
+
 // FindUserWebsites mocks base method.
 func (m *MockRepository) FindUserWebsites(ctx context.Context, userUUID string) (model.UserWebsites, error) {
 	m.ctrl.T.Helper()
@@ -143,6 +392,82 @@ func (mr *MockRepositoryMockRecorder) FindUserWebsitesByGroup(ctx, userUUID, gro
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserWebsitesByGroup", reflect.TypeOf((*MockRepository)(nil).FindUserWebsitesByGroup), ctx, userUUID, group)
 }
 
+// FindUserWebsitesPage mocks base method.
+func (m *MockRepository) FindUserWebsitesPage(ctx context.Context, query repository.UserWebsitesQuery) (model.UserWebsites, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserWebsitesPage", ctx, query)
+	ret0, _ := ret[0].(model.UserWebsites)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindUserWebsitesPage indicates an expected call of FindUserWebsitesPage.
+func (mr *MockRepositoryMockRecorder) FindUserWebsitesPage(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserWebsitesPage", reflect.TypeOf((*MockRepository)(nil).FindUserWebsitesPage), ctx, query)
+}
+
+// FindWebhook mocks base method.
+func (m *MockRepository) FindWebhook(ctx context.Context, userUUID, uuid string) (*model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebhook", ctx, userUUID, uuid)
+	ret0, _ := ret[0].(*model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhook indicates an expected call of FindWebhook.
+func (mr *MockRepositoryMockRecorder) FindWebhook(ctx, userUUID, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhook", reflect.TypeOf((*MockRepository)(nil).FindWebhook), ctx, userUUID, uuid)
+}
+
+// FindWebhookDeliveries mocks base method.
+func (m *MockRepository) FindWebhookDeliveries(ctx context.Context, webhookUUID string, limit int) ([]model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebhookDeliveries", ctx, webhookUUID, limit)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhookDeliveries indicates an expected call of FindWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) FindWebhookDeliveries(ctx, webhookUUID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).FindWebhookDeliveries), ctx, webhookUUID, limit)
+}
+
+// FindWebhookDelivery mocks base method.
+func (m *MockRepository) FindWebhookDelivery(ctx context.Context, uuid string) (*model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebhookDelivery", ctx, uuid)
+	ret0, _ := ret[0].(*model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhookDelivery indicates an expected call of FindWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) FindWebhookDelivery(ctx, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).FindWebhookDelivery), ctx, uuid)
+}
+
+// FindWebhooks mocks base method.
+func (m *MockRepository) FindWebhooks(ctx context.Context, userUUID string) ([]model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebhooks", ctx, userUUID)
+	ret0, _ := ret[0].([]model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhooks indicates an expected call of FindWebhooks.
+func (mr *MockRepositoryMockRecorder) FindWebhooks(ctx, userUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhooks", reflect.TypeOf((*MockRepository)(nil).FindWebhooks), ctx, userUUID)
+}
+
 // FindWebsite mocks base method.
 func (m *MockRepository) FindWebsite(ctx context.Context, uuid string) (*model.Website, error) {
 	m.ctrl.T.Helper()
@@ -158,6 +483,36 @@ func (mr *MockRepositoryMockRecorder) FindWebsite(ctx, uuid any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebsite", reflect.TypeOf((*MockRepository)(nil).FindWebsite), ctx, uuid)
 }
 
+// FindWebsiteUserUUIDs mocks base method.
+func (m *MockRepository) FindWebsiteUserUUIDs(ctx context.Context, websiteUUID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebsiteUserUUIDs", ctx, websiteUUID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebsiteUserUUIDs indicates an expected call of FindWebsiteUserUUIDs.
+func (mr *MockRepositoryMockRecorder) FindWebsiteUserUUIDs(ctx, websiteUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebsiteUserUUIDs", reflect.TypeOf((*MockRepository)(nil).FindWebsiteUserUUIDs), ctx, websiteUUID)
+}
+
+// FindWebsiteWebhooks mocks base method.
+func (m *MockRepository) FindWebsiteWebhooks(ctx context.Context, websiteUUID string) ([]model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebsiteWebhooks", ctx, websiteUUID)
+	ret0, _ := ret[0].([]model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebsiteWebhooks indicates an expected call of FindWebsiteWebhooks.
+func (mr *MockRepositoryMockRecorder) FindWebsiteWebhooks(ctx, websiteUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebsiteWebhooks", reflect.TypeOf((*MockRepository)(nil).FindWebsiteWebhooks), ctx, websiteUUID)
+}
+
 // FindWebsites mocks base method.
 func (m *MockRepository) FindWebsites(arg0 context.Context) ([]model.Website, error) {
 	m.ctrl.T.Helper()
@@ -173,6 +528,163 @@ func (mr *MockRepositoryMockRecorder) FindWebsites(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebsites", reflect.TypeOf((*MockRepository)(nil).FindWebsites), arg0)
 }
 
+// FindWebsitesPage mocks base method.
+func (m *MockRepository) FindWebsitesPage(ctx context.Context, query repository.WebsitesQuery) ([]model.Website, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebsitesPage", ctx, query)
+	ret0, _ := ret[0].([]model.Website)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebsitesPage indicates an expected call of FindWebsitesPage.
+func (mr *MockRepositoryMockRecorder) FindWebsitesPage(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebsitesPage", reflect.TypeOf((*MockRepository)(nil).FindWebsitesPage), ctx, query)
+}
+
+// LockUserJobs mocks base method.
+func (m *MockRepository) LockUserJobs(ctx context.Context, userUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUserJobs", ctx, userUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUserJobs indicates an expected call of LockUserJobs.
+func (mr *MockRepositoryMockRecorder) LockUserJobs(ctx, userUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUserJobs", reflect.TypeOf((*MockRepository)(nil).LockUserJobs), ctx, userUUID)
+}
+
+// MergeWebsite mocks base method.
+func (m *MockRepository) MergeWebsite(ctx context.Context, from, to *model.Website) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeWebsite", ctx, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeWebsite indicates an expected call of MergeWebsite.
+func (mr *MockRepositoryMockRecorder) MergeWebsite(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeWebsite", reflect.TypeOf((*MockRepository)(nil).MergeWebsite), ctx, from, to)
+}
+
+// RecordWebsiteMissing mocks base method.
+func (m *MockRepository) RecordWebsiteMissing(ctx context.Context, web *model.Website, threshold int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordWebsiteMissing", ctx, web, threshold)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordWebsiteMissing indicates an expected call of RecordWebsiteMissing.
+func (mr *MockRepositoryMockRecorder) RecordWebsiteMissing(ctx, web, threshold any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordWebsiteMissing", reflect.TypeOf((*MockRepository)(nil).RecordWebsiteMissing), ctx, web, threshold)
+}
+
+// RefreshUserWebsites mocks base method.
+func (m *MockRepository) RefreshUserWebsites(ctx context.Context, userUUID, group string, accessTime time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshUserWebsites", ctx, userUUID, group, accessTime)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshUserWebsites indicates an expected call of RefreshUserWebsites.
+func (mr *MockRepositoryMockRecorder) RefreshUserWebsites(ctx, userUUID, group, accessTime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshUserWebsites", reflect.TypeOf((*MockRepository)(nil).RefreshUserWebsites), ctx, userUUID, group, accessTime)
+}
+
+// RemoveUserWebsiteTag mocks base method.
+func (m *MockRepository) RemoveUserWebsiteTag(ctx context.Context, userUUID, websiteUUID, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveUserWebsiteTag", ctx, userUUID, websiteUUID, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveUserWebsiteTag indicates an expected call of RemoveUserWebsiteTag.
+func (mr *MockRepositoryMockRecorder) RemoveUserWebsiteTag(ctx, userUUID, websiteUUID, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUserWebsiteTag", reflect.TypeOf((*MockRepository)(nil).RemoveUserWebsiteTag), ctx, userUUID, websiteUUID, tag)
+}
+
+// RenameUserGroup mocks base method.
+func (m *MockRepository) RenameUserGroup(ctx context.Context, userUUID, from, to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameUserGroup", ctx, userUUID, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameUserGroup indicates an expected call of RenameUserGroup.
+func (mr *MockRepositoryMockRecorder) RenameUserGroup(ctx, userUUID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUserGroup", reflect.TypeOf((*MockRepository)(nil).RenameUserGroup), ctx, userUUID, from, to)
+}
+
+// RenameUserTag mocks base method.
+func (m *MockRepository) RenameUserTag(ctx context.Context, userUUID, from, to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameUserTag", ctx, userUUID, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameUserTag indicates an expected call of RenameUserTag.
+func (mr *MockRepositoryMockRecorder) RenameUserTag(ctx, userUUID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUserTag", reflect.TypeOf((*MockRepository)(nil).RenameUserTag), ctx, userUUID, from, to)
+}
+
+// ResetWebsiteMissing mocks base method.
+func (m *MockRepository) ResetWebsiteMissing(ctx context.Context, web *model.Website) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetWebsiteMissing", ctx, web)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetWebsiteMissing indicates an expected call of ResetWebsiteMissing.
+func (mr *MockRepositoryMockRecorder) ResetWebsiteMissing(ctx, web any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetWebsiteMissing", reflect.TypeOf((*MockRepository)(nil).ResetWebsiteMissing), ctx, web)
+}
+
+// SaveFeedToken mocks base method.
+func (m *MockRepository) SaveFeedToken(arg0 context.Context, arg1 *model.FeedToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFeedToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFeedToken indicates an expected call of SaveFeedToken.
+func (mr *MockRepositoryMockRecorder) SaveFeedToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFeedToken", reflect.TypeOf((*MockRepository)(nil).SaveFeedToken), arg0, arg1)
+}
+
+// SearchUserWebsites mocks base method.
+func (m *MockRepository) SearchUserWebsites(ctx context.Context, userUUID, query string, limit int) (model.UserWebsites, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUserWebsites", ctx, userUUID, query, limit)
+	ret0, _ := ret[0].(model.UserWebsites)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUserWebsites indicates an expected call of SearchUserWebsites.
+func (mr *MockRepositoryMockRecorder) SearchUserWebsites(ctx, userUUID, query, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUserWebsites", reflect.TypeOf((*MockRepository)(nil).SearchUserWebsites), ctx, userUUID, query, limit)
+}
+
 // Stats mocks base method.
 func (m *MockRepository) Stats() sql.DBStats {
 	m.ctrl.T.Helper()
@@ -187,6 +699,34 @@ func (mr *MockRepositoryMockRecorder) Stats() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockRepository)(nil).Stats))
 }
 
+// UngroupUserGroup mocks base method.
+func (m *MockRepository) UngroupUserGroup(ctx context.Context, userUUID, group string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UngroupUserGroup", ctx, userUUID, group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UngroupUserGroup indicates an expected call of UngroupUserGroup.
+func (mr *MockRepositoryMockRecorder) UngroupUserGroup(ctx, userUUID, group any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UngroupUserGroup", reflect.TypeOf((*MockRepository)(nil).UngroupUserGroup), ctx, userUUID, group)
+}
+
+// UpdateJob mocks base method.
+func (m *MockRepository) UpdateJob(arg0 context.Context, arg1 *model.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateJob indicates an expected call of UpdateJob.
+func (mr *MockRepositoryMockRecorder) UpdateJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJob", reflect.TypeOf((*MockRepository)(nil).UpdateJob), arg0, arg1)
+}
+
 // UpdateUserWebsite mocks base method.
 func (m *MockRepository) UpdateUserWebsite(arg0 context.Context, arg1 *model.UserWebsite) error {
 	m.ctrl.T.Helper()
@@ -201,6 +741,34 @@ func (mr *MockRepositoryMockRecorder) UpdateUserWebsite(arg0, arg1 any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserWebsite", reflect.TypeOf((*MockRepository)(nil).UpdateUserWebsite), arg0, arg1)
 }
 
+// UpdateWebhook mocks base method.
+func (m *MockRepository) UpdateWebhook(arg0 context.Context, arg1 *model.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockRepositoryMockRecorder) UpdateWebhook(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockRepository)(nil).UpdateWebhook), arg0, arg1)
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockRepository) UpdateWebhookDelivery(arg0 context.Context, arg1 *model.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) UpdateWebhookDelivery(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).UpdateWebhookDelivery), arg0, arg1)
+}
+
 // UpdateWebsite mocks base method.
 func (m *MockRepository) UpdateWebsite(arg0 context.Context, arg1 *model.Website) error {
 	m.ctrl.T.Helper()
@@ -214,3 +782,17 @@ func (mr *MockRepositoryMockRecorder) UpdateWebsite(arg0, arg1 any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebsite", reflect.TypeOf((*MockRepository)(nil).UpdateWebsite), arg0, arg1)
 }
+
+// WithTx mocks base method.
+func (m *MockRepository) WithTx(ctx context.Context, fn func(repository.Repository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockRepositoryMockRecorder) WithTx(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockRepository)(nil).WithTx), ctx, fn)
+}
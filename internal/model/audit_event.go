@@ -0,0 +1,80 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionCreate       = "create"
+	AuditActionDelete       = "delete"
+	AuditActionUpdate       = "update"
+	AuditActionRefresh      = "refresh"
+	AuditActionChangeGroup  = "change_group"
+	AuditActionReadProgress = "read_progress"
+	AuditActionImport       = "import"
+)
+
+// AuditEvent records one mutating action of user. Before and After keep the
+// json snapshot of the user website, empty if it does not exist at that time.
+type AuditEvent struct {
+	UUID        string
+	UserUUID    string
+	WebsiteUUID string
+	Action      string
+	Before      string
+	After       string
+	RequestID   string
+	CreatedAt   time.Time
+}
+
+// AuditSnapshot is the state of user website kept in audit event
+type AuditSnapshot struct {
+	WebsiteUUID     string    `json:"website_uuid"`
+	URL             string    `json:"url"`
+	Title           string    `json:"title"`
+	GroupName       string    `json:"group_name"`
+	Note            string    `json:"note,omitempty"`
+	LastReadChapter string    `json:"last_read_chapter,omitempty"`
+	AccessTime      time.Time `json:"access_time"`
+}
+
+func NewAuditSnapshot(web UserWebsite) AuditSnapshot {
+	return AuditSnapshot{
+		WebsiteUUID:     web.WebsiteUUID,
+		URL:             web.Website.URL,
+		Title:           web.Title(),
+		GroupName:       web.GroupName,
+		Note:            web.Note,
+		LastReadChapter: web.LastReadChapter,
+		AccessTime:      web.AccessTime,
+	}
+}
+
+// NewAuditEvent creates audit event of action changing user website from
+// before to after, nil before or after means the user website is created or
+// deleted by the action
+func NewAuditEvent(userUUID, websiteUUID, action, requestID string, before, after *UserWebsite) AuditEvent {
+	return AuditEvent{
+		UUID:        uuid.New().String(),
+		UserUUID:    userUUID,
+		WebsiteUUID: websiteUUID,
+		Action:      action,
+		Before:      auditPayload(before),
+		After:       auditPayload(after),
+		RequestID:   requestID,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+func auditPayload(web *UserWebsite) string {
+	if web == nil {
+		return ""
+	}
+
+	data, _ := json.Marshal(NewAuditSnapshot(*web))
+
+	return string(data)
+}
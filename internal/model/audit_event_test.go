@@ -0,0 +1,61 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewAuditEvent(t *testing.T) {
+	t.Parallel()
+
+	web := UserWebsite{
+		WebsiteUUID:     "web uuid",
+		UserUUID:        "user uuid",
+		GroupName:       "group",
+		DisplayTitle:    "display title",
+		LastReadChapter: "chapter 1",
+		AccessTime:      time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		Website:         Website{UUID: "web uuid", URL: "http://example.com", Title: "title", RawContent: "content"},
+	}
+
+	tests := []struct {
+		name         string
+		before       *UserWebsite
+		after        *UserWebsite
+		expectBefore string
+		expectAfter  string
+	}{
+		{
+			name:         "created user website",
+			before:       nil,
+			after:        &web,
+			expectBefore: "",
+			expectAfter:  `{"website_uuid":"web uuid","url":"http://example.com","title":"display title","group_name":"group","last_read_chapter":"chapter 1","access_time":"2020-01-02T00:00:00Z"}`,
+		},
+		{
+			name:         "deleted user website",
+			before:       &web,
+			after:        nil,
+			expectBefore: `{"website_uuid":"web uuid","url":"http://example.com","title":"display title","group_name":"group","last_read_chapter":"chapter 1","access_time":"2020-01-02T00:00:00Z"}`,
+			expectAfter:  "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			event := NewAuditEvent("user uuid", "web uuid", AuditActionCreate, "request id", test.before, test.after)
+			assert.NotEmpty(t, event.UUID)
+			assert.Equal(t, "user uuid", event.UserUUID)
+			assert.Equal(t, "web uuid", event.WebsiteUUID)
+			assert.Equal(t, AuditActionCreate, event.Action)
+			assert.Equal(t, "request id", event.RequestID)
+			assert.Equal(t, test.expectBefore, event.Before)
+			assert.Equal(t, test.expectAfter, event.After)
+			assert.WithinDuration(t, time.Now().UTC(), event.CreatedAt, time.Minute)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// FeedToken is the secret of user feed url, so feed readers can read the
+// feed without authentication. Each user has at most one token.
+type FeedToken struct {
+	UserUUID  string
+	Token     string
+	CreatedAt time.Time
+}
+
+func NewFeedToken(userUUID string) FeedToken {
+	secret := make([]byte, 24)
+	rand.Read(secret)
+
+	return FeedToken{
+		UserUUID:  userUUID,
+		Token:     hex.EncodeToString(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	JobKindImport = "import"
+	JobKindCheck  = "check"
+)
+
+const (
+	JobStatusQueued  = "queued"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed"
+)
+
+// Job tracks the progress of background work requested by user. Result keeps
+// the json report of the job once it is done or failed.
+type Job struct {
+	UUID      string
+	UserUUID  string
+	Kind      string
+	Status    string
+	Result    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewJob(userUUID, kind string) Job {
+	now := time.Now().UTC()
+
+	return Job{
+		UUID:      uuid.New().String(),
+		UserUUID:  userUUID,
+		Kind:      kind,
+		Status:    JobStatusQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
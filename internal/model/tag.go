@@ -0,0 +1,7 @@
+package model
+
+// Tag is a label of user websites. Unlike group, a website can have many tags.
+type Tag struct {
+	Name         string
+	WebsiteCount int
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// UnreadSummary counts websites of user updated after the user last accessed
+// them, so clients can show unread badges without listing all websites
+type UnreadSummary struct {
+	UnreadCount  int
+	Groups       []GroupUnreadCount
+	LatestUnread []UnreadWebsite
+}
+
+// GroupUnreadCount is the number of unread websites in group, groups without
+// unread website are not counted
+type GroupUnreadCount struct {
+	GroupName   string
+	UnreadCount int
+}
+
+type UnreadWebsite struct {
+	WebsiteUUID string
+	GroupName   string
+	Title       string
+	UpdateTime  time.Time
+}
+
+func NewUnreadSummary(groups []GroupUnreadCount, latestUnread []UnreadWebsite) UnreadSummary {
+	summary := UnreadSummary{Groups: groups, LatestUnread: latestUnread}
+	for _, group := range groups {
+		summary.UnreadCount += group.UnreadCount
+	}
+
+	return summary
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUnreadSummary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		groups []GroupUnreadCount
+		webs   []UnreadWebsite
+		expect int
+	}{
+		{
+			name:   "sum unread count of groups",
+			groups: []GroupUnreadCount{{GroupName: "novel", UnreadCount: 2}, {GroupName: "comic", UnreadCount: 1}},
+			webs:   []UnreadWebsite{{WebsiteUUID: "web_1"}},
+			expect: 3,
+		},
+		{
+			name:   "no unread group",
+			groups: []GroupUnreadCount{},
+			webs:   []UnreadWebsite{},
+			expect: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			summary := NewUnreadSummary(test.groups, test.webs)
+			assert.Equal(t, test.expect, summary.UnreadCount)
+			assert.Equal(t, test.groups, summary.Groups)
+			assert.Equal(t, test.webs, summary.LatestUnread)
+		})
+	}
+}
@@ -5,11 +5,14 @@ import (
 )
 
 type UserWebsite struct {
-	WebsiteUUID string
-	UserUUID    string
-	GroupName   string
-	AccessTime  time.Time
-	Website     Website
+	WebsiteUUID     string
+	UserUUID        string
+	GroupName       string
+	DisplayTitle    string
+	Note            string
+	LastReadChapter string
+	AccessTime      time.Time
+	Website         Website
 }
 
 type UserWebsites []UserWebsite
@@ -24,6 +27,64 @@ func NewUserWebsite(web Website, userUUID string) UserWebsite {
 	}
 }
 
+// Title returns the user defined display title if exist, otherwise the website title
+func (web UserWebsite) Title() string {
+	if web.DisplayTitle != "" {
+		return web.DisplayTitle
+	}
+
+	return web.Website.Title
+}
+
+// Merge keeps the later access time and the user data of the other
+// subscription which the user website does not have. Ungrouped website is
+// grouped by its own title, so group is only taken from a grouped website.
+func (web *UserWebsite) Merge(other UserWebsite) {
+	if other.AccessTime.After(web.AccessTime) {
+		web.AccessTime = other.AccessTime
+	}
+
+	ungrouped := web.GroupName == "" || web.GroupName == web.Website.Title
+	if ungrouped && other.GroupName != "" && other.GroupName != other.Website.Title {
+		web.GroupName = other.GroupName
+	}
+
+	if web.DisplayTitle == "" {
+		web.DisplayTitle = other.DisplayTitle
+	}
+
+	if web.Note == "" {
+		web.Note = other.Note
+	}
+
+	if web.LastReadChapter == "" {
+		web.LastReadChapter = other.LastReadChapter
+	}
+}
+
+// UnreadCount returns number of chapters listed by vendor which are newer than
+// the last read chapter. Vendor lists chapters from the newest to the oldest.
+func (web UserWebsite) UnreadCount() int {
+	return len(web.UnreadChapters())
+}
+
+// UnreadChapters returns chapters newer than the last read chapter from the
+// newest, nothing is unread if user has not recorded read progress
+func (web UserWebsite) UnreadChapters() []string {
+	if web.LastReadChapter == "" || web.Website.RawContent == "" || web.Website.Conf == nil {
+		return nil
+	}
+
+	chapters := web.Website.Content()
+	for i, chapter := range chapters {
+		if chapter == web.LastReadChapter {
+			return chapters[:i]
+		}
+	}
+
+	return chapters
+}
+
 func (webs UserWebsites) WebsiteGroups() WebsiteGroups {
 	indexMap := make(map[string]int)
 	var groups WebsiteGroups
@@ -45,6 +106,9 @@ func (web UserWebsite) Equal(compare UserWebsite) bool {
 	return web.UserUUID == compare.UserUUID &&
 		web.WebsiteUUID == compare.WebsiteUUID &&
 		web.GroupName == compare.GroupName &&
+		web.DisplayTitle == compare.DisplayTitle &&
+		web.Note == compare.Note &&
+		web.LastReadChapter == compare.LastReadChapter &&
 		web.AccessTime.Unix()/1000 == compare.AccessTime.Unix()/1000 &&
 		web.Website.UUID == compare.Website.UUID &&
 		web.Website.URL == compare.Website.URL &&
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/htchan/WebHistory/internal/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -58,7 +59,7 @@ func TestUserWebsite_MarshalJSON(t *testing.T) {
 				GroupName:  "group",
 				AccessTime: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
 			},
-			expect: `{"WebsiteUUID":"","UserUUID":"user uuid","GroupName":"group","AccessTime":"2020-01-02T00:00:00Z","Website":{"uuid":"uuid","url":"http://example.com","title":"title","raw_content":"","update_time":"2020-01-02T00:00:00Z"}}`,
+			expect: `{"WebsiteUUID":"","UserUUID":"user uuid","GroupName":"group","DisplayTitle":"","Note":"","LastReadChapter":"","AccessTime":"2020-01-02T00:00:00Z","Website":{"uuid":"uuid","url":"http://example.com","title":"title","raw_content":"","update_time":"2020-01-02T00:00:00Z"}}`,
 		},
 	}
 
@@ -71,6 +72,240 @@ func TestUserWebsite_MarshalJSON(t *testing.T) {
 	}
 }
 
+func TestUserWebsite_Title(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		web    UserWebsite
+		expect string
+	}{
+		{
+			name: "return display title if exist",
+			web: UserWebsite{
+				DisplayTitle: "display title",
+				Website:      Website{Title: "title"},
+			},
+			expect: "display title",
+		},
+		{
+			name: "return website title if display title is empty",
+			web: UserWebsite{
+				Website: Website{Title: "title"},
+			},
+			expect: "title",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, test.web.Title())
+		})
+	}
+}
+
+func TestUserWebsite_Merge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		web    UserWebsite
+		other  UserWebsite
+		expect UserWebsite
+	}{
+		{
+			name: "take user data which the user website does not have",
+			web: UserWebsite{
+				WebsiteUUID: "to",
+				GroupName:   "to title",
+				AccessTime:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				Website:     Website{UUID: "to", Title: "to title"},
+			},
+			other: UserWebsite{
+				WebsiteUUID:     "from",
+				GroupName:       "group",
+				DisplayTitle:    "display title",
+				Note:            "note",
+				LastReadChapter: "chapter",
+				AccessTime:      time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				Website:         Website{UUID: "from", Title: "from title"},
+			},
+			expect: UserWebsite{
+				WebsiteUUID:     "to",
+				GroupName:       "group",
+				DisplayTitle:    "display title",
+				Note:            "note",
+				LastReadChapter: "chapter",
+				AccessTime:      time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				Website:         Website{UUID: "to", Title: "to title"},
+			},
+		},
+		{
+			name: "keep own user data",
+			web: UserWebsite{
+				WebsiteUUID:     "to",
+				GroupName:       "to group",
+				DisplayTitle:    "to display title",
+				Note:            "to note",
+				LastReadChapter: "to chapter",
+				AccessTime:      time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				Website:         Website{UUID: "to", Title: "to title"},
+			},
+			other: UserWebsite{
+				WebsiteUUID:     "from",
+				GroupName:       "from group",
+				DisplayTitle:    "from display title",
+				Note:            "from note",
+				LastReadChapter: "from chapter",
+				AccessTime:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				Website:         Website{UUID: "from", Title: "from title"},
+			},
+			expect: UserWebsite{
+				WebsiteUUID:     "to",
+				GroupName:       "to group",
+				DisplayTitle:    "to display title",
+				Note:            "to note",
+				LastReadChapter: "to chapter",
+				AccessTime:      time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				Website:         Website{UUID: "to", Title: "to title"},
+			},
+		},
+		{
+			name: "not taking group of ungrouped website",
+			web: UserWebsite{
+				WebsiteUUID: "to",
+				GroupName:   "to title",
+				Website:     Website{UUID: "to", Title: "to title"},
+			},
+			other: UserWebsite{
+				WebsiteUUID: "from",
+				GroupName:   "from title",
+				Website:     Website{UUID: "from", Title: "from title"},
+			},
+			expect: UserWebsite{
+				WebsiteUUID: "to",
+				GroupName:   "to title",
+				Website:     Website{UUID: "to", Title: "to title"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.web.Merge(test.other)
+			assert.Equal(t, test.expect, test.web)
+		})
+	}
+}
+
+func TestUserWebsite_UnreadCount(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.WebsiteConfig{Separator: "\n"}
+
+	tests := []struct {
+		name   string
+		web    UserWebsite
+		expect int
+	}{
+		{
+			name: "last read chapter is the newest chapter",
+			web: UserWebsite{
+				LastReadChapter: "3",
+				Website:         Website{RawContent: "3\n2\n1", Conf: conf},
+			},
+			expect: 0,
+		},
+		{
+			name: "last read chapter is an older chapter",
+			web: UserWebsite{
+				LastReadChapter: "1",
+				Website:         Website{RawContent: "3\n2\n1", Conf: conf},
+			},
+			expect: 2,
+		},
+		{
+			name: "last read chapter is not listed",
+			web: UserWebsite{
+				LastReadChapter: "0",
+				Website:         Website{RawContent: "3\n2\n1", Conf: conf},
+			},
+			expect: 3,
+		},
+		{
+			name: "no reading progress",
+			web: UserWebsite{
+				Website: Website{RawContent: "3\n2\n1", Conf: conf},
+			},
+			expect: 0,
+		},
+		{
+			name: "website without content",
+			web: UserWebsite{
+				LastReadChapter: "1",
+				Website:         Website{Conf: conf},
+			},
+			expect: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, test.web.UnreadCount())
+		})
+	}
+}
+
+func TestUserWebsite_UnreadChapters(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.WebsiteConfig{Separator: "\n"}
+
+	tests := []struct {
+		name   string
+		web    UserWebsite
+		expect []string
+	}{
+		{
+			name: "last read chapter is an older chapter",
+			web: UserWebsite{
+				LastReadChapter: "1",
+				Website:         Website{RawContent: "3\n2\n1", Conf: conf},
+			},
+			expect: []string{"3", "2"},
+		},
+		{
+			name: "last read chapter is the newest chapter",
+			web: UserWebsite{
+				LastReadChapter: "3",
+				Website:         Website{RawContent: "3\n2\n1", Conf: conf},
+			},
+			expect: []string{},
+		},
+		{
+			name: "no reading progress",
+			web: UserWebsite{
+				Website: Website{RawContent: "3\n2\n1", Conf: conf},
+			},
+			expect: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, test.web.UnreadChapters())
+		})
+	}
+}
+
 func TestUserWebsites_WebsiteGroups(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusRetrying  = "retrying"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// Webhook receives the updates of user websites, or only the websites in
+// GroupName if it is not empty. It is disabled once FailureCount deliveries
+// failed in a row.
+type Webhook struct {
+	UUID         string
+	UserUUID     string
+	URL          string
+	GroupName    string
+	Secret       string
+	Enabled      bool
+	FailureCount int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func NewWebhook(userUUID, url, groupName string) Webhook {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+
+	now := time.Now().UTC()
+
+	return Webhook{
+		UUID:      uuid.New().String(),
+		UserUUID:  userUUID,
+		URL:       url,
+		GroupName: groupName,
+		Secret:    hex.EncodeToString(secret),
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<payload>" keyed
+// by secret of webhook, so receiver can verify the payload is sent by us and
+// reject the replay of old delivery by its unix timestamp
+func (webhook Webhook) Sign(timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Matches reports whether webhook receives the update of website in group
+func (webhook Webhook) Matches(groupName string) bool {
+	return webhook.Enabled && (webhook.GroupName == "" || webhook.GroupName == groupName)
+}
+
+// WebhookDelivery is the log of sending Payload to webhook
+type WebhookDelivery struct {
+	UUID           string
+	WebhookUUID    string
+	WebsiteUUID    string
+	Status         string
+	Payload        string
+	Attempts       int
+	ResponseStatus int
+	Error          string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func NewWebhookDelivery(webhookUUID, websiteUUID, payload string) WebhookDelivery {
+	now := time.Now().UTC()
+
+	return WebhookDelivery{
+		UUID:        uuid.New().String(),
+		WebhookUUID: webhookUUID,
+		WebsiteUUID: websiteUUID,
+		Status:      WebhookDeliveryStatusPending,
+		Payload:     payload,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewWebhook(t *testing.T) {
+	t.Parallel()
+
+	webhook := NewWebhook("user uuid", "http://example.com/hook", "group")
+	other := NewWebhook("user uuid", "http://example.com/hook", "group")
+
+	assert.NotEmpty(t, webhook.UUID)
+	assert.Equal(t, "user uuid", webhook.UserUUID)
+	assert.Equal(t, "http://example.com/hook", webhook.URL)
+	assert.Equal(t, "group", webhook.GroupName)
+	assert.Len(t, webhook.Secret, 64)
+	assert.NotEqual(t, webhook.Secret, other.Secret)
+	assert.True(t, webhook.Enabled)
+	assert.Zero(t, webhook.FailureCount)
+	assert.WithinDuration(t, time.Now().UTC(), webhook.CreatedAt, time.Minute)
+}
+
+func TestWebhook_Sign(t *testing.T) {
+	t.Parallel()
+
+	webhook := Webhook{Secret: "secret"}
+
+	assert.Equal(
+		t,
+		"28567a4ad498c2bf46c0b78fbe57197a74cdeefe85b1b7f80b3876cee8941112",
+		webhook.Sign(1577934245, []byte(`{"event":"website_updated"}`)),
+	)
+}
+
+func TestWebhook_Matches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		webhook   Webhook
+		groupName string
+		expect    bool
+	}{
+		{
+			name:      "webhook without group filter",
+			webhook:   Webhook{Enabled: true},
+			groupName: "group",
+			expect:    true,
+		},
+		{
+			name:      "webhook of same group",
+			webhook:   Webhook{Enabled: true, GroupName: "group"},
+			groupName: "group",
+			expect:    true,
+		},
+		{
+			name:      "webhook of other group",
+			webhook:   Webhook{Enabled: true, GroupName: "other"},
+			groupName: "group",
+			expect:    false,
+		},
+		{
+			name:      "disabled webhook",
+			webhook:   Webhook{Enabled: false},
+			groupName: "group",
+			expect:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, test.webhook.Matches(test.groupName))
+		})
+	}
+}
@@ -10,14 +10,23 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
+const (
+	WebsiteStatusActive   = "active"
+	WebsiteStatusReadOnly = "read_only"
+	WebsiteStatusInactive = "inactive"
+	WebsiteStatusGone     = "gone"
+)
+
 type Website struct {
-	UUID       string                `json:"uuid"`
-	URL        string                `json:"url"`
-	Title      string                `json:"title"`
-	RawContent string                `json:"raw_content"`
-	UpdateTime time.Time             `json:"update_time"`
-	Status     string                `json:"-"`
-	Conf       *config.WebsiteConfig `json:"-"`
+	UUID         string                `json:"uuid"`
+	URL          string                `json:"url"`
+	Title        string                `json:"title"`
+	RawContent   string                `json:"raw_content"`
+	UpdateTime   time.Time             `json:"update_time"`
+	Status       string                `json:"-"`
+	RedirectUUID string                `json:"-"`
+	MissingCount int                   `json:"missing_count,omitempty"`
+	Conf         *config.WebsiteConfig `json:"-"`
 }
 
 func NewWebsite(url string, conf *config.WebsiteConfig) Website {
@@ -61,6 +70,22 @@ func (web Website) Content() []string {
 	return strings.Split(web.RawContent, web.Conf.Separator)
 }
 
+func (web Website) Gone() bool {
+	return web.Status == WebsiteStatusGone
+}
+
+// Merge keeps the newest update data between the website and the given one
+func (web *Website) Merge(other Website) {
+	if web.Title == "" {
+		web.Title = other.Title
+	}
+
+	if other.UpdateTime.After(web.UpdateTime) {
+		web.RawContent = other.RawContent
+		web.UpdateTime = other.UpdateTime
+	}
+}
+
 func (web Website) Equal(compare Website) bool {
 	return web.UUID == compare.UUID &&
 		web.URL == compare.URL &&
@@ -1,5 +1,36 @@
 package model
 
+import "slices"
+
 type WebsiteGroup []UserWebsite
 
 type WebsiteGroups []WebsiteGroup
+
+func (group WebsiteGroup) UnreadCount() int {
+	count := 0
+	for _, web := range group {
+		count += web.UnreadCount()
+	}
+
+	return count
+}
+
+// SortByUnread orders websites with the most unread chapters first.
+// Websites having same unread count keep their original order.
+func (group WebsiteGroup) SortByUnread() {
+	slices.SortStableFunc(group, func(a, b UserWebsite) int {
+		return b.UnreadCount() - a.UnreadCount()
+	})
+}
+
+// SortByUnread orders groups and websites within each group with the most
+// unread chapters first. Groups having same unread count keep their original order.
+func (groups WebsiteGroups) SortByUnread() {
+	for _, group := range groups {
+		group.SortByUnread()
+	}
+
+	slices.SortStableFunc(groups, func(a, b WebsiteGroup) int {
+		return b.UnreadCount() - a.UnreadCount()
+	})
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newUnreadUserWebsite(uuid string, unread int) UserWebsite {
+	chapters := []string{"4", "3", "2", "1"}
+
+	return UserWebsite{
+		WebsiteUUID:     uuid,
+		LastReadChapter: chapters[unread],
+		Website: Website{
+			RawContent: "4\n3\n2\n1",
+			Conf:       &config.WebsiteConfig{Separator: "\n"},
+		},
+	}
+}
+
+func TestWebsiteGroup_UnreadCount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		group  WebsiteGroup
+		expect int
+	}{
+		{
+			name: "sum unread count of all websites",
+			group: WebsiteGroup{
+				newUnreadUserWebsite("1", 1),
+				newUnreadUserWebsite("2", 2),
+			},
+			expect: 3,
+		},
+		{
+			name:   "empty group",
+			group:  WebsiteGroup{},
+			expect: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, test.group.UnreadCount())
+		})
+	}
+}
+
+func TestWebsiteGroups_SortByUnread(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		groups WebsiteGroups
+		expect WebsiteGroups
+	}{
+		{
+			name: "sort groups and websites by unread count",
+			groups: WebsiteGroups{
+				{newUnreadUserWebsite("1", 0)},
+				{newUnreadUserWebsite("2", 1), newUnreadUserWebsite("3", 3)},
+				{newUnreadUserWebsite("4", 0)},
+			},
+			expect: WebsiteGroups{
+				{newUnreadUserWebsite("3", 3), newUnreadUserWebsite("2", 1)},
+				{newUnreadUserWebsite("1", 0)},
+				{newUnreadUserWebsite("4", 0)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.groups.SortByUnread()
+			assert.Equal(t, test.expect, test.groups)
+		})
+	}
+}
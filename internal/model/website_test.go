@@ -182,3 +182,65 @@ func TestWebsite_Content(t *testing.T) {
 		})
 	}
 }
+
+func TestWebsite_Merge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		web    Website
+		other  Website
+		expect Website
+	}{
+		{
+			name: "keep newer update data from other website",
+			web: Website{
+				UUID:       "to",
+				Title:      "to title",
+				RawContent: "old",
+				UpdateTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			other: Website{
+				UUID:       "from",
+				Title:      "from title",
+				RawContent: "new",
+				UpdateTime: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+			expect: Website{
+				UUID:       "to",
+				Title:      "to title",
+				RawContent: "new",
+				UpdateTime: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "keep own update data if it is newer",
+			web: Website{
+				UUID:       "to",
+				RawContent: "new",
+				UpdateTime: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+			other: Website{
+				UUID:       "from",
+				Title:      "from title",
+				RawContent: "old",
+				UpdateTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			expect: Website{
+				UUID:       "to",
+				Title:      "from title",
+				RawContent: "new",
+				UpdateTime: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.web.Merge(test.other)
+			assert.Equal(t, test.expect, test.web)
+		})
+	}
+}
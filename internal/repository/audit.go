@@ -0,0 +1,9 @@
+package repository
+
+// AuditEventsQuery lists the latest audit events first. Empty UserUUID or
+// WebsiteUUID lists events of all users or websites.
+type AuditEventsQuery struct {
+	UserUUID    string
+	WebsiteUUID string
+	Limit       int
+}
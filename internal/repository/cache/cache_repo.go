@@ -0,0 +1,489 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// InvalidationSubject broadcasts cache invalidation to every replica
+const InvalidationSubject = "web_history.cache.invalidate"
+
+// invalidation lists the cached entries to drop. Users drops every entry of
+// the users and Websites drops every entry containing the websites.
+type invalidation struct {
+	Source   string   `json:"source"`
+	All      bool     `json:"all,omitempty"`
+	Users    []string `json:"users,omitempty"`
+	Websites []string `json:"websites,omitempty"`
+}
+
+func (inv invalidation) empty() bool {
+	return !inv.All && len(inv.Users) == 0 && len(inv.Websites) == 0
+}
+
+// CacheRepo caches the reads of single user websites and websites of the
+// wrapped repository. Writes drop the affected entries locally and publish
+// the invalidation to other replicas through nats.
+type CacheRepo struct {
+	repo  repository.Repository
+	cache *lru
+	nc    *nats.Conn
+	id    string
+
+	// pending collects invalidation within transaction, which is only
+	// applied after commit
+	pending *invalidation
+}
+
+var _ repository.Repository = &CacheRepo{}
+
+func NewRepo(repo repository.Repository, conf *config.CacheConfig, nc *nats.Conn) *CacheRepo {
+	return &CacheRepo{
+		repo:  repo,
+		cache: newLRU(conf.Size, conf.TTL),
+		nc:    nc,
+		id:    uuid.New().String(),
+	}
+}
+
+// NewPublisher keeps nothing but publishes the invalidation of writes, so
+// the replicas caching reads are notified even if caching is disabled here
+func NewPublisher(repo repository.Repository, nc *nats.Conn) *CacheRepo {
+	return NewRepo(repo, &config.CacheConfig{}, nc)
+}
+
+// Subscribe applies the invalidation published by other replicas
+func (r *CacheRepo) Subscribe() (*nats.Subscription, error) {
+	return r.nc.Subscribe(InvalidationSubject, func(msg *nats.Msg) {
+		r.handleInvalidation(msg.Data)
+	})
+}
+
+func (r *CacheRepo) handleInvalidation(data []byte) {
+	var inv invalidation
+	if err := json.Unmarshal(data, &inv); err != nil {
+		log.Error().Err(err).Msg("decode cache invalidation failed")
+		r.cache.clear()
+
+		return
+	}
+
+	if inv.Source == r.id {
+		return
+	}
+
+	r.apply(inv)
+}
+
+func (r *CacheRepo) apply(inv invalidation) {
+	if inv.All {
+		r.cache.clear()
+
+		return
+	}
+
+	for _, userUUID := range inv.Users {
+		r.cache.removePrefix(userKeyPrefix(userUUID))
+	}
+
+	for _, websiteUUID := range inv.Websites {
+		r.cache.removeWebsite(websiteUUID)
+	}
+}
+
+func (r *CacheRepo) invalidate(ctx context.Context, inv invalidation) {
+	if r.pending != nil {
+		r.pending.All = r.pending.All || inv.All
+		r.pending.Users = append(r.pending.Users, inv.Users...)
+		r.pending.Websites = append(r.pending.Websites, inv.Websites...)
+
+		return
+	}
+
+	r.apply(inv)
+
+	if r.nc == nil {
+		return
+	}
+
+	inv.Source = r.id
+
+	data, err := json.Marshal(inv)
+	if err == nil {
+		err = r.nc.Publish(InvalidationSubject, data)
+	}
+
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("publish cache invalidation failed")
+	}
+}
+
+func invalidateUser(userUUID string, websiteUUIDs ...string) invalidation {
+	return invalidation{Users: []string{userUUID}, Websites: websiteUUIDs}
+}
+
+func invalidateWebsites(websiteUUIDs ...string) invalidation {
+	return invalidation{Websites: websiteUUIDs}
+}
+
+func userKeyPrefix(userUUID string) string {
+	return "user:" + userUUID + ":"
+}
+
+func websiteKey(uuid string) string {
+	return "website:" + uuid
+}
+
+func userWebsiteKey(userUUID, websiteUUID string) string {
+	return userKeyPrefix(userUUID) + "website:" + websiteUUID
+}
+
+func userWebsitesKey(userUUID string) string {
+	return userKeyPrefix(userUUID) + "websites"
+}
+
+func userGroupKey(userUUID, group string) string {
+	return userKeyPrefix(userUUID) + "group:" + group
+}
+
+func websiteUUIDs(webs []model.UserWebsite) []string {
+	uuids := make([]string, len(webs))
+	for i, web := range webs {
+		uuids[i] = web.WebsiteUUID
+	}
+
+	return uuids
+}
+
+// cached reads the key from cache before calling find, reads within
+// transaction always call find to see the uncommitted writes
+func cached[T any](ctx context.Context, r *CacheRepo, name, key string, find func() (T, error), websites func(T) []string) (T, error) {
+	_, cacheSpan := repository.GetTracer().Start(ctx, name)
+	defer cacheSpan.End()
+
+	if r.pending == nil {
+		if value, ok := r.cache.get(key); ok {
+			cacheSpan.SetAttributes(attribute.Bool("cache_hit", true))
+
+			return value.(T), nil
+		}
+	}
+
+	cacheSpan.SetAttributes(attribute.Bool("cache_hit", false))
+
+	value, err := find()
+	if err != nil || r.pending != nil {
+		return value, err
+	}
+
+	r.cache.set(key, value, websites(value))
+
+	return value, nil
+}
+
+func (r *CacheRepo) CreateWebsite(ctx context.Context, web *model.Website) error {
+	return r.repo.CreateWebsite(ctx, web)
+}
+
+func (r *CacheRepo) UpdateWebsite(ctx context.Context, web *model.Website) error {
+	defer r.invalidate(ctx, invalidateWebsites(web.UUID))
+
+	return r.repo.UpdateWebsite(ctx, web)
+}
+
+func (r *CacheRepo) DeleteWebsite(ctx context.Context, web *model.Website) error {
+	defer r.invalidate(ctx, invalidateWebsites(web.UUID))
+
+	return r.repo.DeleteWebsite(ctx, web)
+}
+
+func (r *CacheRepo) FindWebsites(ctx context.Context) ([]model.Website, error) {
+	return r.repo.FindWebsites(ctx)
+}
+
+func (r *CacheRepo) FindWebsitesPage(ctx context.Context, query repository.WebsitesQuery) ([]model.Website, error) {
+	return r.repo.FindWebsitesPage(ctx, query)
+}
+
+func (r *CacheRepo) FindWebsite(ctx context.Context, uuid string) (*model.Website, error) {
+	web, err := cached(ctx, r, "cache find website", websiteKey(uuid),
+		func() (model.Website, error) {
+			web, err := r.repo.FindWebsite(ctx, uuid)
+			if err != nil {
+				return model.Website{}, err
+			}
+
+			return *web, nil
+		},
+		func(web model.Website) []string { return []string{web.UUID} },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &web, nil
+}
+
+func (r *CacheRepo) MergeWebsite(ctx context.Context, from, to *model.Website) error {
+	defer r.invalidate(ctx, invalidateWebsites(from.UUID, to.UUID))
+
+	return r.repo.MergeWebsite(ctx, from, to)
+}
+
+func (r *CacheRepo) RecordWebsiteMissing(ctx context.Context, web *model.Website, threshold int) error {
+	defer r.invalidate(ctx, invalidateWebsites(web.UUID))
+
+	return r.repo.RecordWebsiteMissing(ctx, web, threshold)
+}
+
+func (r *CacheRepo) ResetWebsiteMissing(ctx context.Context, web *model.Website) error {
+	defer r.invalidate(ctx, invalidateWebsites(web.UUID))
+
+	return r.repo.ResetWebsiteMissing(ctx, web)
+}
+
+func (r *CacheRepo) CleanupOrphanedWebsites(ctx context.Context, now time.Time, gracePeriod time.Duration) (repository.OrphanCleanupResult, error) {
+	defer r.invalidate(ctx, invalidation{All: true})
+
+	return r.repo.CleanupOrphanedWebsites(ctx, now, gracePeriod)
+}
+
+func (r *CacheRepo) CreateUserWebsite(ctx context.Context, web *model.UserWebsite) error {
+	// subscribing may reactivate the website, which changes its cached status
+	defer r.invalidate(ctx, invalidateUser(web.UserUUID, web.WebsiteUUID))
+
+	return r.repo.CreateUserWebsite(ctx, web)
+}
+
+func (r *CacheRepo) UpdateUserWebsite(ctx context.Context, web *model.UserWebsite) error {
+	defer r.invalidate(ctx, invalidateUser(web.UserUUID))
+
+	return r.repo.UpdateUserWebsite(ctx, web)
+}
+
+func (r *CacheRepo) DeleteUserWebsite(ctx context.Context, web *model.UserWebsite) error {
+	defer r.invalidate(ctx, invalidateUser(web.UserUUID))
+
+	return r.repo.DeleteUserWebsite(ctx, web)
+}
+
+func (r *CacheRepo) RenameUserGroup(ctx context.Context, userUUID, from, to string) error {
+	defer r.invalidate(ctx, invalidateUser(userUUID))
+
+	return r.repo.RenameUserGroup(ctx, userUUID, from, to)
+}
+
+func (r *CacheRepo) UngroupUserGroup(ctx context.Context, userUUID, group string) error {
+	defer r.invalidate(ctx, invalidateUser(userUUID))
+
+	return r.repo.UngroupUserGroup(ctx, userUUID, group)
+}
+
+func (r *CacheRepo) DeleteUserGroup(ctx context.Context, userUUID, group string) error {
+	defer r.invalidate(ctx, invalidateUser(userUUID))
+
+	return r.repo.DeleteUserGroup(ctx, userUUID, group)
+}
+
+func (r *CacheRepo) RefreshUserWebsites(ctx context.Context, userUUID, group string, accessTime time.Time) (int, error) {
+	defer r.invalidate(ctx, invalidateUser(userUUID))
+
+	return r.repo.RefreshUserWebsites(ctx, userUUID, group, accessTime)
+}
+
+func (r *CacheRepo) FindUserWebsites(ctx context.Context, userUUID string) (model.UserWebsites, error) {
+	webs, err := cached(ctx, r, "cache find user websites", userWebsitesKey(userUUID),
+		func() (model.UserWebsites, error) { return r.repo.FindUserWebsites(ctx, userUUID) },
+		func(webs model.UserWebsites) []string { return websiteUUIDs(webs) },
+	)
+
+	return slices.Clone(webs), err
+}
+
+func (r *CacheRepo) FindUserWebsitesByGroup(ctx context.Context, userUUID, group string) (model.WebsiteGroup, error) {
+	webs, err := cached(ctx, r, "cache find user websites by group", userGroupKey(userUUID, group),
+		func() (model.WebsiteGroup, error) { return r.repo.FindUserWebsitesByGroup(ctx, userUUID, group) },
+		func(webs model.WebsiteGroup) []string { return websiteUUIDs(webs) },
+	)
+
+	return slices.Clone(webs), err
+}
+
+func (r *CacheRepo) FindUserWebsitesPage(ctx context.Context, query repository.UserWebsitesQuery) (model.UserWebsites, string, error) {
+	return r.repo.FindUserWebsitesPage(ctx, query)
+}
+
+func (r *CacheRepo) FindUserWebsite(ctx context.Context, userUUID, websiteUUID string) (*model.UserWebsite, error) {
+	web, err := cached(ctx, r, "cache find user website", userWebsiteKey(userUUID, websiteUUID),
+		func() (model.UserWebsite, error) {
+			web, err := r.repo.FindUserWebsite(ctx, userUUID, websiteUUID)
+			if err != nil {
+				return model.UserWebsite{}, err
+			}
+
+			return *web, nil
+		},
+		func(web model.UserWebsite) []string { return []string{web.WebsiteUUID} },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &web, nil
+}
+
+func (r *CacheRepo) SearchUserWebsites(ctx context.Context, userUUID, query string, limit int) (model.UserWebsites, error) {
+	return r.repo.SearchUserWebsites(ctx, userUUID, query, limit)
+}
+
+func (r *CacheRepo) FindUserUnreadSummary(ctx context.Context, userUUID string, limit int) (model.UnreadSummary, error) {
+	return r.repo.FindUserUnreadSummary(ctx, userUUID, limit)
+}
+
+func (r *CacheRepo) FindWebsiteUserUUIDs(ctx context.Context, websiteUUID string) ([]string, error) {
+	return r.repo.FindWebsiteUserUUIDs(ctx, websiteUUID)
+}
+
+// tags are not part of cached records, so tag methods skip the cache
+
+func (r *CacheRepo) FindUserTags(ctx context.Context, userUUID string) ([]model.Tag, error) {
+	return r.repo.FindUserTags(ctx, userUUID)
+}
+
+func (r *CacheRepo) FindUserWebsiteTags(ctx context.Context, userUUID, websiteUUID string) ([]string, error) {
+	return r.repo.FindUserWebsiteTags(ctx, userUUID, websiteUUID)
+}
+
+func (r *CacheRepo) AddUserWebsiteTag(ctx context.Context, userUUID, websiteUUID, tag string) error {
+	return r.repo.AddUserWebsiteTag(ctx, userUUID, websiteUUID, tag)
+}
+
+func (r *CacheRepo) RemoveUserWebsiteTag(ctx context.Context, userUUID, websiteUUID, tag string) error {
+	return r.repo.RemoveUserWebsiteTag(ctx, userUUID, websiteUUID, tag)
+}
+
+func (r *CacheRepo) RenameUserTag(ctx context.Context, userUUID, from, to string) error {
+	return r.repo.RenameUserTag(ctx, userUUID, from, to)
+}
+
+func (r *CacheRepo) DeleteUserTag(ctx context.Context, userUUID, tag string) error {
+	return r.repo.DeleteUserTag(ctx, userUUID, tag)
+}
+
+func (r *CacheRepo) CreateAuditEvent(ctx context.Context, event *model.AuditEvent) error {
+	return r.repo.CreateAuditEvent(ctx, event)
+}
+
+func (r *CacheRepo) FindAuditEvents(ctx context.Context, query repository.AuditEventsQuery) ([]model.AuditEvent, error) {
+	return r.repo.FindAuditEvents(ctx, query)
+}
+
+func (r *CacheRepo) CreateJob(ctx context.Context, job *model.Job) error {
+	return r.repo.CreateJob(ctx, job)
+}
+
+func (r *CacheRepo) UpdateJob(ctx context.Context, job *model.Job) error {
+	return r.repo.UpdateJob(ctx, job)
+}
+
+func (r *CacheRepo) FindJob(ctx context.Context, userUUID, uuid string) (*model.Job, error) {
+	return r.repo.FindJob(ctx, userUUID, uuid)
+}
+
+func (r *CacheRepo) CountUserJobs(ctx context.Context, userUUID, kind string, since time.Time) (int, error) {
+	return r.repo.CountUserJobs(ctx, userUUID, kind, since)
+}
+
+func (r *CacheRepo) LockUserJobs(ctx context.Context, userUUID string) error {
+	return r.repo.LockUserJobs(ctx, userUUID)
+}
+
+func (r *CacheRepo) SaveFeedToken(ctx context.Context, token *model.FeedToken) error {
+	return r.repo.SaveFeedToken(ctx, token)
+}
+
+func (r *CacheRepo) FindFeedToken(ctx context.Context, userUUID string) (*model.FeedToken, error) {
+	return r.repo.FindFeedToken(ctx, userUUID)
+}
+
+func (r *CacheRepo) FindFeedTokenByToken(ctx context.Context, token string) (*model.FeedToken, error) {
+	return r.repo.FindFeedTokenByToken(ctx, token)
+}
+
+func (r *CacheRepo) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	return r.repo.CreateWebhook(ctx, webhook)
+}
+
+func (r *CacheRepo) UpdateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	return r.repo.UpdateWebhook(ctx, webhook)
+}
+
+func (r *CacheRepo) DeleteWebhook(ctx context.Context, webhook *model.Webhook) error {
+	return r.repo.DeleteWebhook(ctx, webhook)
+}
+
+func (r *CacheRepo) FindWebhook(ctx context.Context, userUUID, uuid string) (*model.Webhook, error) {
+	return r.repo.FindWebhook(ctx, userUUID, uuid)
+}
+
+func (r *CacheRepo) FindWebhooks(ctx context.Context, userUUID string) ([]model.Webhook, error) {
+	return r.repo.FindWebhooks(ctx, userUUID)
+}
+
+func (r *CacheRepo) FindWebsiteWebhooks(ctx context.Context, websiteUUID string) ([]model.Webhook, error) {
+	return r.repo.FindWebsiteWebhooks(ctx, websiteUUID)
+}
+
+func (r *CacheRepo) CreateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return r.repo.CreateWebhookDelivery(ctx, delivery)
+}
+
+func (r *CacheRepo) UpdateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return r.repo.UpdateWebhookDelivery(ctx, delivery)
+}
+
+func (r *CacheRepo) FindWebhookDelivery(ctx context.Context, uuid string) (*model.WebhookDelivery, error) {
+	return r.repo.FindWebhookDelivery(ctx, uuid)
+}
+
+func (r *CacheRepo) FindWebhookDeliveries(ctx context.Context, webhookUUID string, limit int) ([]model.WebhookDelivery, error) {
+	return r.repo.FindWebhookDeliveries(ctx, webhookUUID, limit)
+}
+
+// WithTx wraps the transaction repository so reads skip the cache, and the
+// invalidation of writes is applied once the transaction is committed
+func (r *CacheRepo) WithTx(ctx context.Context, fn func(repository.Repository) error) error {
+	pending := r.pending
+	if pending == nil {
+		pending = &invalidation{}
+	}
+
+	err := r.repo.WithTx(ctx, func(txRepo repository.Repository) error {
+		return fn(&CacheRepo{repo: txRepo, cache: r.cache, nc: r.nc, id: r.id, pending: pending})
+	})
+	if err != nil || r.pending != nil {
+		return err
+	}
+
+	if !pending.empty() {
+		r.invalidate(ctx, *pending)
+	}
+
+	return nil
+}
+
+func (r *CacheRepo) Stats() sql.DBStats {
+	return r.repo.Stats()
+}
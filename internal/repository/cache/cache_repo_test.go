@@ -0,0 +1,254 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/htchan/WebHistory/internal/repository/memory"
+	"github.com/htchan/WebHistory/internal/repository/repotest"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var cacheConf = &config.CacheConfig{Enabled: true, TTL: time.Minute, Size: 100}
+
+func TestCacheRepo_FindUserWebsite(t *testing.T) {
+	t.Parallel()
+
+	web := model.UserWebsite{
+		WebsiteUUID: "web-uuid",
+		UserUUID:    "user-uuid",
+		GroupName:   "group",
+		Website:     model.Website{UUID: "web-uuid", Title: "title"},
+	}
+
+	tests := []struct {
+		name   string
+		setup  func(*mockrepo.MockRepository)
+		action func(*testing.T, *CacheRepo)
+	}{
+		{
+			name: "second read hits cache",
+			setup: func(rpo *mockrepo.MockRepository) {
+				rpo.EXPECT().FindUserWebsite(gomock.Any(), "user-uuid", "web-uuid").Return(&web, nil).Times(1)
+			},
+			action: func(t *testing.T, r *CacheRepo) {
+				got, err := r.FindUserWebsite(context.Background(), "user-uuid", "web-uuid")
+				assert.NoError(t, err)
+				assert.Equal(t, web, *got)
+
+				// modifying result does not affect cached value
+				got.GroupName = "modified"
+
+				got, err = r.FindUserWebsite(context.Background(), "user-uuid", "web-uuid")
+				assert.NoError(t, err)
+				assert.Equal(t, web, *got)
+			},
+		},
+		{
+			name: "error is not cached",
+			setup: func(rpo *mockrepo.MockRepository) {
+				rpo.EXPECT().FindUserWebsite(gomock.Any(), "user-uuid", "web-uuid").Return(nil, errors.New("some error")).Times(2)
+			},
+			action: func(t *testing.T, r *CacheRepo) {
+				for range 2 {
+					_, err := r.FindUserWebsite(context.Background(), "user-uuid", "web-uuid")
+					assert.Error(t, err)
+				}
+			},
+		},
+		{
+			name: "user website write invalidates user entries",
+			setup: func(rpo *mockrepo.MockRepository) {
+				rpo.EXPECT().FindUserWebsite(gomock.Any(), "user-uuid", "web-uuid").Return(&web, nil).Times(2)
+				rpo.EXPECT().UpdateUserWebsite(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			action: func(t *testing.T, r *CacheRepo) {
+				_, err := r.FindUserWebsite(context.Background(), "user-uuid", "web-uuid")
+				assert.NoError(t, err)
+
+				updated := web
+				assert.NoError(t, r.UpdateUserWebsite(context.Background(), &updated))
+
+				_, err = r.FindUserWebsite(context.Background(), "user-uuid", "web-uuid")
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "website write invalidates entries containing website",
+			setup: func(rpo *mockrepo.MockRepository) {
+				rpo.EXPECT().FindUserWebsite(gomock.Any(), "user-uuid", "web-uuid").Return(&web, nil).Times(2)
+				rpo.EXPECT().UpdateWebsite(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			action: func(t *testing.T, r *CacheRepo) {
+				_, err := r.FindUserWebsite(context.Background(), "user-uuid", "web-uuid")
+				assert.NoError(t, err)
+
+				assert.NoError(t, r.UpdateWebsite(context.Background(), &model.Website{UUID: "web-uuid"}))
+
+				_, err = r.FindUserWebsite(context.Background(), "user-uuid", "web-uuid")
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "write of other user keeps entries",
+			setup: func(rpo *mockrepo.MockRepository) {
+				rpo.EXPECT().FindUserWebsite(gomock.Any(), "user-uuid", "web-uuid").Return(&web, nil).Times(1)
+				rpo.EXPECT().DeleteUserWebsite(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			action: func(t *testing.T, r *CacheRepo) {
+				_, err := r.FindUserWebsite(context.Background(), "user-uuid", "web-uuid")
+				assert.NoError(t, err)
+
+				other := model.UserWebsite{UserUUID: "other-user-uuid", WebsiteUUID: "other-web-uuid"}
+				assert.NoError(t, r.DeleteUserWebsite(context.Background(), &other))
+
+				_, err = r.FindUserWebsite(context.Background(), "user-uuid", "web-uuid")
+				assert.NoError(t, err)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rpo := mockrepo.NewMockRepository(ctrl)
+			test.setup(rpo)
+
+			test.action(t, NewRepo(rpo, cacheConf, nil))
+		})
+	}
+}
+
+func TestCacheRepo_WithTx(t *testing.T) {
+	t.Parallel()
+
+	errRollback := errors.New("rollback")
+
+	tests := []struct {
+		name          string
+		fnErr         error
+		expectEntries int
+	}{
+		{
+			name:          "invalidate after commit",
+			fnErr:         nil,
+			expectEntries: 0,
+		},
+		{
+			name:          "keep entries after rollback",
+			fnErr:         errRollback,
+			expectEntries: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewRepo(memory.NewRepo(&config.WebsiteConfig{}), cacheConf, nil)
+
+			web := model.Website{UUID: "web-uuid", URL: "http://example.com/with-tx"}
+			assert.NoError(t, r.CreateWebsite(context.Background(), &web))
+
+			_, err := r.FindWebsite(context.Background(), web.UUID)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, r.cache.len())
+
+			err = r.WithTx(context.Background(), func(txRepo repository.Repository) error {
+				web.Title = "updated"
+				assert.NoError(t, txRepo.UpdateWebsite(context.Background(), &web))
+
+				// read within transaction sees the uncommitted write
+				got, err := txRepo.FindWebsite(context.Background(), web.UUID)
+				assert.NoError(t, err)
+				assert.Equal(t, "updated", got.Title)
+				assert.Equal(t, 1, r.cache.len())
+
+				return test.fnErr
+			})
+			assert.ErrorIs(t, err, test.fnErr)
+			assert.Equal(t, test.expectEntries, r.cache.len())
+		})
+	}
+}
+
+func TestCacheRepo_handleInvalidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		inv           invalidation
+		expectEntries int
+	}{
+		{
+			name:          "invalidate user from other replica",
+			inv:           invalidation{Source: "other", Users: []string{"user-uuid"}},
+			expectEntries: 1,
+		},
+		{
+			name:          "invalidate website from other replica",
+			inv:           invalidation{Source: "other", Websites: []string{"web-1"}},
+			expectEntries: 1,
+		},
+		{
+			name:          "invalidate all from other replica",
+			inv:           invalidation{Source: "other", All: true},
+			expectEntries: 0,
+		},
+		{
+			name:          "ignore invalidation from itself",
+			inv:           invalidation{Source: "self", All: true},
+			expectEntries: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewRepo(nil, cacheConf, nil)
+			r.id = "self"
+			r.cache.set(userWebsiteKey("user-uuid", "web-1"), model.UserWebsite{}, []string{"web-1"})
+			r.cache.set(websiteKey("web-2"), model.Website{}, []string{"web-2"})
+
+			data, err := json.Marshal(test.inv)
+			assert.NoError(t, err)
+
+			r.handleInvalidation(data)
+			assert.Equal(t, test.expectEntries, r.cache.len())
+		})
+	}
+}
+
+func TestNewPublisher(t *testing.T) {
+	t.Parallel()
+
+	web := model.Website{UUID: "web-uuid", URL: "http://example.com"}
+	r := NewPublisher(memory.NewRepo(&config.WebsiteConfig{}), nil)
+	assert.NoError(t, r.CreateWebsite(context.Background(), &web))
+
+	got, err := r.FindWebsite(context.Background(), web.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, web.URL, got.URL)
+	assert.Equal(t, 0, r.cache.len())
+}
+
+func TestCacheRepo_Contract(t *testing.T) {
+	t.Parallel()
+
+	repotest.Run(t, func(t *testing.T, conf *config.WebsiteConfig) repository.Repository {
+		return NewRepo(memory.NewRepo(conf), cacheConf, nil)
+	})
+}
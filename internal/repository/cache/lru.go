@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"container/list"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	key      string
+	value    any
+	websites []string
+	expireAt time.Time
+}
+
+// lru keeps at most size entries for ttl, the least recently used entry is
+// evicted first once it is full
+type lru struct {
+	lock    sync.Mutex
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newLRU(size int, ttl time.Duration) *lru {
+	return &lru{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *lru) get(key string) (any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	ent := elem.Value.(*entry)
+	if !c.now().Before(ent.expireAt) {
+		c.remove(elem)
+
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return ent.value, true
+}
+
+// set stores value with the uuid of websites it contains, so it can be
+// invalidated once any of the websites is updated
+func (c *lru) set(key string, value any, websites []string) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{
+		key:      key,
+		value:    value,
+		websites: websites,
+		expireAt: c.now().Add(c.ttl),
+	})
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *lru) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*entry).key)
+}
+
+func (c *lru) removeFunc(match func(*entry) bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if match(elem.Value.(*entry)) {
+			c.remove(elem)
+		}
+
+		elem = next
+	}
+}
+
+func (c *lru) removePrefix(prefix string) {
+	c.removeFunc(func(ent *entry) bool { return strings.HasPrefix(ent.key, prefix) })
+}
+
+func (c *lru) removeWebsite(websiteUUID string) {
+	c.removeFunc(func(ent *entry) bool { return slices.Contains(ent.websites, websiteUUID) })
+}
+
+func (c *lru) clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.order.Init()
+	clear(c.entries)
+}
+
+func (c *lru) len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_lru(t *testing.T) {
+	t.Parallel()
+
+	t.Run("evict least recently used entry", func(t *testing.T) {
+		t.Parallel()
+
+		c := newLRU(2, time.Minute)
+		c.set("a", 1, nil)
+		c.set("b", 2, nil)
+
+		_, ok := c.get("a")
+		assert.True(t, ok)
+
+		c.set("c", 3, nil)
+
+		_, ok = c.get("b")
+		assert.False(t, ok)
+		assert.Equal(t, 2, c.len())
+	})
+
+	t.Run("expire entry after ttl", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		c := newLRU(2, time.Minute)
+		c.now = func() time.Time { return now }
+		c.set("a", 1, nil)
+
+		value, ok := c.get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+
+		now = now.Add(time.Minute)
+
+		_, ok = c.get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, c.len())
+	})
+
+	t.Run("remove entries by prefix and website", func(t *testing.T) {
+		t.Parallel()
+
+		c := newLRU(10, time.Minute)
+		c.set("user:1:a", 1, []string{"web-1"})
+		c.set("user:1:b", 2, []string{"web-2"})
+		c.set("user:2:a", 3, []string{"web-1", "web-3"})
+		c.set("user:2:b", 4, []string{"web-2"})
+
+		c.removePrefix("user:1:")
+		c.removeWebsite("web-1")
+
+		_, ok := c.get("user:2:b")
+		assert.True(t, ok)
+		assert.Equal(t, 1, c.len())
+
+		c.clear()
+		assert.Equal(t, 0, c.len())
+	})
+
+	t.Run("disabled with zero size", func(t *testing.T) {
+		t.Parallel()
+
+		c := newLRU(0, time.Minute)
+		c.set("a", 1, nil)
+
+		_, ok := c.get("a")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,23 @@
+package repohelper
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/htchan/WebHistory/internal/repository/sqlc"
+	"github.com/htchan/WebHistory/internal/repository/sqlite"
+	"github.com/htchan/WebHistory/internal/utils"
+)
+
+func NewRepo(db *sql.DB, dbConf *config.DatabaseConfig, webConf *config.WebsiteConfig) (repository.Repository, error) {
+	switch dbConf.Driver {
+	case config.DatabaseDriverPostgres:
+		return sqlc.NewRepo(db, webConf), nil
+	case config.DatabaseDriverSqlite:
+		return sqlite.NewRepo(db, webConf), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", utils.ErrUnsupportedDriver, dbConf.Driver)
+	}
+}
@@ -0,0 +1,1616 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const MinTimeUnit = 5 * time.Second
+
+var ErrDuplicateKey = errors.New("duplicate key value violates unique constraint")
+
+// MemoryRepo keeps records in slices to preserve insertion order, which is the
+// order postgres returns rows without ORDER BY for a freshly populated table.
+// Records are not shared between processes, so it is for tests only.
+type MemoryRepo struct {
+	lock         sync.RWMutex
+	websites     []model.Website
+	userWebsites []model.UserWebsite
+	orphanedAt   map[string]time.Time
+	tags         []userWebsiteTag
+	auditEvents  []model.AuditEvent
+	jobs         []model.Job
+	feedTokens   []model.FeedToken
+	webhooks     []model.Webhook
+	deliveries   []model.WebhookDelivery
+	conf         *config.WebsiteConfig
+}
+
+type userWebsiteTag struct {
+	UserUUID    string
+	WebsiteUUID string
+	Tag         string
+}
+
+var _ repository.Repository = &MemoryRepo{}
+
+func NewRepo(conf *config.WebsiteConfig) *MemoryRepo {
+	return &MemoryRepo{orphanedAt: make(map[string]time.Time), conf: conf}
+}
+
+func (r *MemoryRepo) websiteIndex(match func(model.Website) bool) int {
+	return slices.IndexFunc(r.websites, match)
+}
+
+func (r *MemoryRepo) userWebsiteIndex(userUUID, websiteUUID string) int {
+	return slices.IndexFunc(r.userWebsites, func(web model.UserWebsite) bool {
+		return web.UserUUID == userUUID && web.WebsiteUUID == websiteUUID
+	})
+}
+
+// getWebsite matches the visibility of GetWebsite query
+func (r *MemoryRepo) getWebsite(uuid string) (model.Website, error) {
+	i := r.websiteIndex(func(web model.Website) bool {
+		return web.UUID == uuid && web.Status != model.WebsiteStatusInactive
+	})
+	if i < 0 {
+		return model.Website{}, sql.ErrNoRows
+	}
+
+	return r.toModelWebsite(r.websites[i]), nil
+}
+
+func (r *MemoryRepo) toModelWebsite(web model.Website) model.Website {
+	web.UpdateTime = web.UpdateTime.UTC().Truncate(MinTimeUnit)
+	web.Conf = r.conf
+
+	return web
+}
+
+func (r *MemoryRepo) toModelUserWebsite(userWeb model.UserWebsite, web model.Website) model.UserWebsite {
+	userWeb.AccessTime = userWeb.AccessTime.UTC().Truncate(MinTimeUnit)
+	userWeb.Website = model.Website{
+		UUID:       web.UUID,
+		URL:        web.URL,
+		Title:      web.Title,
+		RawContent: web.RawContent,
+		UpdateTime: web.UpdateTime.UTC().Truncate(MinTimeUnit),
+		Status:     web.Status,
+		Conf:       r.conf,
+	}
+
+	return userWeb
+}
+
+// listUserWebsites joins user websites with visible websites in insertion order
+func (r *MemoryRepo) listUserWebsites(match func(model.UserWebsite) bool) []model.UserWebsite {
+	var result []model.UserWebsite
+	for _, userWeb := range r.userWebsites {
+		if !match(userWeb) {
+			continue
+		}
+
+		i := r.websiteIndex(func(web model.Website) bool {
+			return web.UUID == userWeb.WebsiteUUID && web.Status != model.WebsiteStatusInactive
+		})
+		if i < 0 {
+			continue
+		}
+
+		result = append(result, r.toModelUserWebsite(userWeb, r.websites[i]))
+	}
+
+	return result
+}
+
+func (r *MemoryRepo) CreateWebsite(ctx context.Context, web *model.Website) error {
+	_, createWebsiteSpan := repository.GetTracer().Start(ctx, "create website")
+	defer createWebsiteSpan.End()
+
+	createWebsiteSpan.SetAttributes(attribute.String("params.url", web.URL))
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// return web if url exist
+	i := r.websiteIndex(func(stored model.Website) bool { return stored.URL == web.URL })
+	if i < 0 {
+		if r.websiteIndex(func(stored model.Website) bool { return stored.UUID == web.UUID }) >= 0 {
+			createWebsiteSpan.SetStatus(codes.Error, ErrDuplicateKey.Error())
+			createWebsiteSpan.RecordError(ErrDuplicateKey)
+
+			return ErrDuplicateKey
+		}
+
+		r.websites = append(r.websites, model.Website{
+			UUID:       web.UUID,
+			URL:        web.URL,
+			Title:      web.Title,
+			RawContent: web.RawContent,
+			UpdateTime: web.UpdateTime,
+			Status:     model.WebsiteStatusActive,
+		})
+		i = len(r.websites) - 1
+	}
+
+	stored := r.toModelWebsite(r.websites[i])
+
+	// follow redirect of merged website
+	if stored.RedirectUUID != "" {
+		createWebsiteSpan.SetAttributes(attribute.String("redirect_uuid", stored.RedirectUUID))
+
+		var err error
+		stored, err = r.getWebsite(stored.RedirectUUID)
+		if err != nil {
+			createWebsiteSpan.SetStatus(codes.Error, err.Error())
+			createWebsiteSpan.RecordError(err)
+
+			return fmt.Errorf("follow website redirect fail: %w", err)
+		}
+
+		web.URL = stored.URL
+	}
+
+	web.UUID = stored.UUID
+	web.Title, web.RawContent = stored.Title, stored.RawContent
+	web.UpdateTime = stored.UpdateTime
+	web.Conf = r.conf
+
+	return nil
+}
+
+func (r *MemoryRepo) UpdateWebsite(ctx context.Context, web *model.Website) error {
+	_, updateWebsiteSpan := repository.GetTracer().Start(ctx, "update website")
+	defer updateWebsiteSpan.End()
+
+	updateWebsiteSpan.SetAttributes(attribute.String("params.website_uuid", web.UUID))
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	i := r.websiteIndex(func(stored model.Website) bool { return stored.UUID == web.UUID })
+	if i < 0 {
+		updateWebsiteSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		updateWebsiteSpan.RecordError(sql.ErrNoRows)
+
+		return fmt.Errorf("update website fail: %w", sql.ErrNoRows)
+	}
+
+	r.websites[i].URL, r.websites[i].Title = web.URL, web.Title
+	r.websites[i].RawContent, r.websites[i].UpdateTime = web.RawContent, web.UpdateTime
+
+	return nil
+}
+
+func (r *MemoryRepo) DeleteWebsite(ctx context.Context, web *model.Website) error {
+	_, deleteWebsiteSpan := repository.GetTracer().Start(ctx, "delete website")
+	defer deleteWebsiteSpan.End()
+
+	deleteWebsiteSpan.SetAttributes(attribute.String("params.website_uuid", web.UUID))
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.websites = slices.DeleteFunc(r.websites, func(stored model.Website) bool {
+		return stored.UUID == web.UUID
+	})
+	delete(r.orphanedAt, web.UUID)
+
+	return nil
+}
+
+func (r *MemoryRepo) FindWebsites(ctx context.Context) ([]model.Website, error) {
+	_, listWebsitesSpan := repository.GetTracer().Start(ctx, "find websites")
+	defer listWebsitesSpan.End()
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	webs := make([]model.Website, 0, len(r.websites))
+	for _, web := range r.websites {
+		if web.Status == model.WebsiteStatusActive {
+			webs = append(webs, r.toModelWebsite(web))
+		}
+	}
+
+	return webs, nil
+}
+
+// matchHost follows the postgres query, which compares the host name of url
+// with host and its sub domains
+func matchHost(url, host string) bool {
+	_, rest, _ := strings.Cut(url, "://")
+	hostname, _, _ := strings.Cut(rest, "/")
+
+	return strings.HasSuffix("."+hostname, "."+host)
+}
+
+func (r *MemoryRepo) FindWebsitesPage(ctx context.Context, query repository.WebsitesQuery) ([]model.Website, error) {
+	_, listWebsitesPageSpan := repository.GetTracer().Start(ctx, "find websites page")
+	defer listWebsitesPageSpan.End()
+
+	listWebsitesPageSpan.SetAttributes(
+		attribute.String("params.host", query.Host),
+		attribute.String("params.after_uuid", query.AfterUUID),
+		attribute.Int("params.limit", query.Limit),
+	)
+
+	if query.Limit <= 0 {
+		listWebsitesPageSpan.SetStatus(codes.Error, repository.ErrInvalidLimit.Error())
+		listWebsitesPageSpan.RecordError(repository.ErrInvalidLimit)
+
+		return nil, fmt.Errorf("list websites page fail: %w", repository.ErrInvalidLimit)
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	webs := make([]model.Website, 0, query.Limit)
+	for _, web := range r.websites {
+		if web.Status == model.WebsiteStatusActive && web.UUID > query.AfterUUID &&
+			(query.Host == "" || matchHost(web.URL, query.Host)) {
+			webs = append(webs, r.toModelWebsite(web))
+		}
+	}
+
+	slices.SortFunc(webs, func(a, b model.Website) int { return strings.Compare(a.UUID, b.UUID) })
+
+	return webs[:min(query.Limit, len(webs))], nil
+}
+
+func (r *MemoryRepo) FindWebsite(ctx context.Context, uuid string) (*model.Website, error) {
+	_, findWebsiteSpan := repository.GetTracer().Start(ctx, "find website")
+	defer findWebsiteSpan.End()
+
+	findWebsiteSpan.SetAttributes(attribute.String("params.website_uuid", uuid))
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	web, err := r.getWebsite(uuid)
+	if err != nil {
+		return nil, fmt.Errorf("get website fail: %w", err)
+	}
+
+	return &web, nil
+}
+
+func (r *MemoryRepo) RecordWebsiteMissing(ctx context.Context, web *model.Website, threshold int) error {
+	_, recordMissingSpan := repository.GetTracer().Start(ctx, "record website missing")
+	defer recordMissingSpan.End()
+
+	recordMissingSpan.SetAttributes(
+		attribute.String("params.uuid", web.UUID),
+		attribute.Int("params.threshold", threshold),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	i := r.websiteIndex(func(stored model.Website) bool { return stored.UUID == web.UUID })
+	if i < 0 {
+		recordMissingSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		recordMissingSpan.RecordError(sql.ErrNoRows)
+
+		return fmt.Errorf("record website missing fail: %w", sql.ErrNoRows)
+	}
+
+	stored := &r.websites[i]
+	stored.MissingCount++
+	if stored.Status == model.WebsiteStatusActive && stored.MissingCount >= threshold {
+		stored.Status = model.WebsiteStatusGone
+	}
+
+	web.Status, web.MissingCount = stored.Status, stored.MissingCount
+
+	return nil
+}
+
+func (r *MemoryRepo) ResetWebsiteMissing(ctx context.Context, web *model.Website) error {
+	_, resetMissingSpan := repository.GetTracer().Start(ctx, "reset website missing")
+	defer resetMissingSpan.End()
+
+	resetMissingSpan.SetAttributes(attribute.String("params.uuid", web.UUID))
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	i := r.websiteIndex(func(stored model.Website) bool { return stored.UUID == web.UUID })
+	if i < 0 {
+		resetMissingSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		resetMissingSpan.RecordError(sql.ErrNoRows)
+
+		return fmt.Errorf("reset website missing fail: %w", sql.ErrNoRows)
+	}
+
+	stored := &r.websites[i]
+	stored.MissingCount = 0
+	if stored.Status == model.WebsiteStatusGone {
+		stored.Status = model.WebsiteStatusActive
+	}
+
+	web.Status, web.MissingCount = stored.Status, stored.MissingCount
+
+	return nil
+}
+
+func (r *MemoryRepo) subscribed(websiteUUID string) bool {
+	return slices.ContainsFunc(r.userWebsites, func(userWeb model.UserWebsite) bool {
+		return userWeb.WebsiteUUID == websiteUUID
+	})
+}
+
+func (r *MemoryRepo) CleanupOrphanedWebsites(ctx context.Context, now time.Time, gracePeriod time.Duration) (repository.OrphanCleanupResult, error) {
+	_, cleanupSpan := repository.GetTracer().Start(ctx, "cleanup orphaned websites")
+	defer cleanupSpan.End()
+
+	cleanupSpan.SetAttributes(
+		attribute.String("params.now", now.String()),
+		attribute.String("params.grace_period", gracePeriod.String()),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var result repository.OrphanCleanupResult
+	for i := range r.websites {
+		web := &r.websites[i]
+		orphanedAt, orphaned := r.orphanedAt[web.UUID]
+
+		switch {
+		case web.Status != model.WebsiteStatusActive:
+		case r.subscribed(web.UUID):
+			delete(r.orphanedAt, web.UUID)
+		case !orphaned:
+			r.orphanedAt[web.UUID] = now
+			result.Marked++
+		case orphanedAt.Before(now.Add(-gracePeriod)):
+			web.Status = model.WebsiteStatusInactive
+			result.Deactivated++
+		}
+
+		if _, ok := r.orphanedAt[web.UUID]; ok && web.Status == model.WebsiteStatusInactive {
+			result.FetchesSaved++
+		}
+	}
+
+	return result, nil
+}
+
+// WithTx runs fn against a copy of the stored data and keeps the copy only if
+// fn succeeds. Other callers are blocked until the transaction finishes.
+func (r *MemoryRepo) WithTx(ctx context.Context, fn func(repository.Repository) error) error {
+	_, withTxSpan := repository.GetTracer().Start(ctx, "with tx")
+	defer withTxSpan.End()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	txRepo := &MemoryRepo{
+		websites:     slices.Clone(r.websites),
+		userWebsites: slices.Clone(r.userWebsites),
+		orphanedAt:   maps.Clone(r.orphanedAt),
+		tags:         slices.Clone(r.tags),
+		auditEvents:  slices.Clone(r.auditEvents),
+		jobs:         slices.Clone(r.jobs),
+		feedTokens:   slices.Clone(r.feedTokens),
+		webhooks:     slices.Clone(r.webhooks),
+		deliveries:   slices.Clone(r.deliveries),
+		conf:         r.conf,
+	}
+
+	if err := fn(txRepo); err != nil {
+		withTxSpan.SetStatus(codes.Error, err.Error())
+		withTxSpan.RecordError(err)
+
+		return err
+	}
+
+	r.websites, r.userWebsites, r.orphanedAt = txRepo.websites, txRepo.userWebsites, txRepo.orphanedAt
+	r.tags, r.auditEvents, r.jobs = txRepo.tags, txRepo.auditEvents, txRepo.jobs
+	r.feedTokens, r.webhooks, r.deliveries = txRepo.feedTokens, txRepo.webhooks, txRepo.deliveries
+
+	return nil
+}
+
+func (r *MemoryRepo) MergeWebsite(ctx context.Context, from, to *model.Website) error {
+	_, mergeWebsiteSpan := repository.GetTracer().Start(ctx, "merge website")
+	defer mergeWebsiteSpan.End()
+
+	mergeWebsiteSpan.SetAttributes(
+		attribute.String("params.from_uuid", from.UUID),
+		attribute.String("params.to_uuid", to.UUID),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	toIndex := r.websiteIndex(func(stored model.Website) bool { return stored.UUID == to.UUID })
+	if toIndex < 0 {
+		mergeWebsiteSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		mergeWebsiteSpan.RecordError(sql.ErrNoRows)
+
+		return fmt.Errorf("merge website fail: %w", sql.ErrNoRows)
+	}
+
+	merged := *to
+	merged.Merge(*from)
+
+	r.websites[toIndex].URL, r.websites[toIndex].Title = merged.URL, merged.Title
+	r.websites[toIndex].RawContent, r.websites[toIndex].UpdateTime = merged.RawContent, merged.UpdateTime
+
+	// merge subscriptions of users subscribing both websites, and move the
+	// others to target website
+	subscribed := make(map[string]int)
+	for i, userWeb := range r.userWebsites {
+		if userWeb.WebsiteUUID == to.UUID {
+			subscribed[userWeb.UserUUID] = i
+		}
+	}
+
+	for _, userWeb := range r.userWebsites {
+		i, ok := subscribed[userWeb.UserUUID]
+		if userWeb.WebsiteUUID != from.UUID || !ok {
+			continue
+		}
+
+		merging := r.userWebsites[i]
+		merging.Website, userWeb.Website = *to, *from
+		merging.Merge(userWeb)
+		merging.Website = model.Website{}
+		r.userWebsites[i] = merging
+	}
+
+	r.userWebsites = slices.DeleteFunc(r.userWebsites, func(userWeb model.UserWebsite) bool {
+		_, ok := subscribed[userWeb.UserUUID]
+
+		return userWeb.WebsiteUUID == from.UUID && ok
+	})
+	for i := range r.userWebsites {
+		if r.userWebsites[i].WebsiteUUID == from.UUID {
+			r.userWebsites[i].WebsiteUUID = to.UUID
+		}
+	}
+
+	// move tags which target website does not have yet
+	r.tags = slices.DeleteFunc(r.tags, func(tag userWebsiteTag) bool {
+		return tag.WebsiteUUID == from.UUID && r.hasTag(tag.UserUUID, to.UUID, tag.Tag)
+	})
+	for i := range r.tags {
+		if r.tags[i].WebsiteUUID == from.UUID {
+			r.tags[i].WebsiteUUID = to.UUID
+		}
+	}
+
+	for i := range r.websites {
+		if r.websites[i].UUID == from.UUID || r.websites[i].RedirectUUID == from.UUID {
+			r.websites[i].Status = model.WebsiteStatusInactive
+			r.websites[i].RedirectUUID = to.UUID
+			delete(r.orphanedAt, r.websites[i].UUID)
+		}
+	}
+
+	*to = merged
+	from.Status, from.RedirectUUID = model.WebsiteStatusInactive, to.UUID
+
+	return nil
+}
+
+func (r *MemoryRepo) CreateUserWebsite(ctx context.Context, web *model.UserWebsite) error {
+	_, createUserWebsiteSpan := repository.GetTracer().Start(ctx, "create user website")
+	defer createUserWebsiteSpan.End()
+
+	createUserWebsiteSpan.SetAttributes(
+		attribute.String("params.user_uuid", web.UserUUID),
+		attribute.String("params.website_uuid", web.WebsiteUUID),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// subscribing again brings back website deactivated for having no subscriber
+	if _, ok := r.orphanedAt[web.WebsiteUUID]; ok {
+		delete(r.orphanedAt, web.WebsiteUUID)
+
+		j := r.websiteIndex(func(stored model.Website) bool { return stored.UUID == web.WebsiteUUID })
+		if j >= 0 && r.websites[j].Status == model.WebsiteStatusInactive {
+			r.websites[j].Status = model.WebsiteStatusActive
+		}
+	}
+
+	i := r.userWebsiteIndex(web.UserUUID, web.WebsiteUUID)
+	if i < 0 {
+		stored := *web
+		stored.Website = model.Website{}
+		r.userWebsites = append(r.userWebsites, stored)
+		i = len(r.userWebsites) - 1
+	}
+
+	stored := r.userWebsites[i]
+	web.GroupName = stored.GroupName
+	web.DisplayTitle, web.Note = stored.DisplayTitle, stored.Note
+	web.LastReadChapter = stored.LastReadChapter
+	web.AccessTime = stored.AccessTime.UTC().Truncate(MinTimeUnit)
+
+	tempWeb, err := r.getWebsite(web.WebsiteUUID)
+	if err != nil {
+		createUserWebsiteSpan.SetStatus(codes.Error, fmt.Errorf("assign website fail:%w", err).Error())
+		createUserWebsiteSpan.RecordError(err)
+
+		return fmt.Errorf("assign website fail: %w", err)
+	}
+
+	web.Website = tempWeb
+
+	return nil
+}
+
+func (r *MemoryRepo) UpdateUserWebsite(ctx context.Context, web *model.UserWebsite) error {
+	_, updateUserWebsiteSpan := repository.GetTracer().Start(ctx, "update user website")
+	defer updateUserWebsiteSpan.End()
+
+	updateUserWebsiteSpan.SetAttributes(
+		attribute.String("params.user_uuid", web.UserUUID),
+		attribute.String("params.website_uuid", web.WebsiteUUID),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	i := r.userWebsiteIndex(web.UserUUID, web.WebsiteUUID)
+	if i < 0 {
+		updateUserWebsiteSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		updateUserWebsiteSpan.RecordError(sql.ErrNoRows)
+
+		return fmt.Errorf("fail to update user website: %w", sql.ErrNoRows)
+	}
+
+	stored := &r.userWebsites[i]
+	stored.AccessTime, stored.GroupName = web.AccessTime, web.GroupName
+	stored.DisplayTitle, stored.Note = web.DisplayTitle, web.Note
+	stored.LastReadChapter = web.LastReadChapter
+
+	return nil
+}
+
+func (r *MemoryRepo) DeleteUserWebsite(ctx context.Context, web *model.UserWebsite) error {
+	_, deleteUserWebsiteSpan := repository.GetTracer().Start(ctx, "delete user website")
+	defer deleteUserWebsiteSpan.End()
+
+	deleteUserWebsiteSpan.SetAttributes(
+		attribute.String("params.user_uuid", web.UserUUID),
+		attribute.String("params.website_uuid", web.WebsiteUUID),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.userWebsites = slices.DeleteFunc(r.userWebsites, func(stored model.UserWebsite) bool {
+		return stored.UserUUID == web.UserUUID && stored.WebsiteUUID == web.WebsiteUUID
+	})
+	r.tags = slices.DeleteFunc(r.tags, func(tag userWebsiteTag) bool {
+		return tag.UserUUID == web.UserUUID && tag.WebsiteUUID == web.WebsiteUUID
+	})
+
+	return nil
+}
+
+func (r *MemoryRepo) RenameUserGroup(ctx context.Context, userUUID, from, to string) error {
+	_, renameUserGroupSpan := repository.GetTracer().Start(ctx, "rename user group")
+	defer renameUserGroupSpan.End()
+
+	renameUserGroupSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.from", from),
+		attribute.String("params.to", to),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i := range r.userWebsites {
+		stored := &r.userWebsites[i]
+		if stored.UserUUID == userUUID && stored.GroupName == from {
+			stored.GroupName = to
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryRepo) UngroupUserGroup(ctx context.Context, userUUID, group string) error {
+	_, ungroupUserGroupSpan := repository.GetTracer().Start(ctx, "ungroup user group")
+	defer ungroupUserGroupSpan.End()
+
+	ungroupUserGroupSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.group", group),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i := range r.userWebsites {
+		stored := &r.userWebsites[i]
+		if stored.UserUUID != userUUID || stored.GroupName != group {
+			continue
+		}
+
+		web, _ := r.getWebsite(stored.WebsiteUUID)
+		stored.GroupName = web.Title
+	}
+
+	return nil
+}
+
+func (r *MemoryRepo) DeleteUserGroup(ctx context.Context, userUUID, group string) error {
+	_, deleteUserGroupSpan := repository.GetTracer().Start(ctx, "delete user group")
+	defer deleteUserGroupSpan.End()
+
+	deleteUserGroupSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.group", group),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.tags = slices.DeleteFunc(r.tags, func(tag userWebsiteTag) bool {
+		i := r.userWebsiteIndex(tag.UserUUID, tag.WebsiteUUID)
+
+		return tag.UserUUID == userUUID && i >= 0 && r.userWebsites[i].GroupName == group
+	})
+	r.userWebsites = slices.DeleteFunc(r.userWebsites, func(stored model.UserWebsite) bool {
+		return stored.UserUUID == userUUID && stored.GroupName == group
+	})
+
+	return nil
+}
+
+func (r *MemoryRepo) RefreshUserWebsites(ctx context.Context, userUUID, group string, accessTime time.Time) (int, error) {
+	_, refreshUserWebsitesSpan := repository.GetTracer().Start(ctx, "refresh user websites")
+	defer refreshUserWebsitesSpan.End()
+
+	refreshUserWebsitesSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.group", group),
+		attribute.String("params.access_time", accessTime.String()),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	updated := 0
+	for i := range r.userWebsites {
+		stored := &r.userWebsites[i]
+		if stored.UserUUID == userUUID && (group == "" || stored.GroupName == group) {
+			stored.AccessTime = accessTime.UTC().Truncate(MinTimeUnit)
+			updated++
+		}
+	}
+
+	return updated, nil
+}
+
+func (r *MemoryRepo) FindUserWebsites(ctx context.Context, userUUID string) (model.UserWebsites, error) {
+	_, listUserWebsitesSpan := repository.GetTracer().Start(ctx, "find user websites")
+	defer listUserWebsitesSpan.End()
+
+	listUserWebsitesSpan.SetAttributes(attribute.String("params.user_uuid", userUUID))
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	webs := model.UserWebsites(r.listUserWebsites(func(web model.UserWebsite) bool {
+		return web.UserUUID == userUUID
+	}))
+	if webs == nil {
+		webs = model.UserWebsites{}
+	}
+
+	// same as ORDER BY (update_time > access_time) DESC, update_time DESC, access_time DESC
+	slices.SortStableFunc(webs, func(a, b model.UserWebsite) int {
+		aUnread := a.Website.UpdateTime.After(a.AccessTime)
+		bUnread := b.Website.UpdateTime.After(b.AccessTime)
+		if aUnread != bUnread {
+			if aUnread {
+				return -1
+			}
+
+			return 1
+		}
+
+		if c := b.Website.UpdateTime.Compare(a.Website.UpdateTime); c != 0 {
+			return c
+		}
+
+		return b.AccessTime.Compare(a.AccessTime)
+	})
+
+	return webs, nil
+}
+
+func (r *MemoryRepo) FindUserWebsitesByGroup(ctx context.Context, userUUID, groupName string) (model.WebsiteGroup, error) {
+	_, listUserWebsitesByGroupSpan := repository.GetTracer().Start(ctx, "find user websites by group")
+	defer listUserWebsitesByGroupSpan.End()
+
+	listUserWebsitesByGroupSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.group_name", groupName),
+	)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	group := model.WebsiteGroup(r.listUserWebsites(func(web model.UserWebsite) bool {
+		return web.UserUUID == userUUID && web.GroupName == groupName
+	}))
+	if group == nil {
+		group = model.WebsiteGroup{}
+	}
+
+	return group, nil
+}
+
+// compareCursor orders cursors the same way as ListUserWebsitesPage queries,
+// titles are compared by bytes as the C collation does
+func compareCursor(a, b repository.UserWebsitesCursor) int {
+	switch a.SortBy {
+	case repository.SortByUnread:
+		if a.Unread != b.Unread {
+			if a.Unread {
+				return -1
+			}
+
+			return 1
+		}
+
+		fallthrough
+	case repository.SortByUpdateTime, repository.SortByAccessTime:
+		if c := b.Time.Compare(a.Time); c != 0 {
+			return c
+		}
+	case repository.SortByTitle:
+		if c := strings.Compare(a.Title, b.Title); c != 0 {
+			return c
+		}
+	}
+
+	return strings.Compare(a.WebsiteUUID, b.WebsiteUUID)
+}
+
+func (r *MemoryRepo) FindUserWebsitesPage(ctx context.Context, query repository.UserWebsitesQuery) (model.UserWebsites, string, error) {
+	_, listUserWebsitesPageSpan := repository.GetTracer().Start(ctx, "find user websites page")
+	defer listUserWebsitesPageSpan.End()
+
+	listUserWebsitesPageSpan.SetAttributes(
+		attribute.String("params.user_uuid", query.UserUUID),
+		attribute.String("params.group_name", query.GroupName),
+		attribute.StringSlice("params.tags", query.Tags),
+		attribute.String("params.sort_by", query.SortBy),
+		attribute.String("params.cursor", query.Cursor),
+		attribute.Int("params.limit", query.Limit),
+	)
+
+	cursor, err := query.Validate()
+	if err != nil {
+		listUserWebsitesPageSpan.SetStatus(codes.Error, err.Error())
+		listUserWebsitesPageSpan.RecordError(err)
+
+		return nil, "", fmt.Errorf("list user websites page fail: %w", err)
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	webs := model.UserWebsites(r.listUserWebsites(func(web model.UserWebsite) bool {
+		return web.UserUUID == query.UserUUID && (query.GroupName == "" || web.GroupName == query.GroupName) &&
+			!slices.ContainsFunc(query.Tags, func(tag string) bool { return !r.hasTag(web.UserUUID, web.WebsiteUUID, tag) })
+	}))
+
+	webs = slices.DeleteFunc(webs, func(web model.UserWebsite) bool {
+		return cursor != nil && compareCursor(repository.NewUserWebsitesCursor(query.SortBy, web), *cursor) <= 0
+	})
+	slices.SortFunc(webs, func(a, b model.UserWebsite) int {
+		return compareCursor(
+			repository.NewUserWebsitesCursor(query.SortBy, a),
+			repository.NewUserWebsitesCursor(query.SortBy, b),
+		)
+	})
+
+	webs, nextCursor := query.NextPage(webs)
+	if webs == nil {
+		webs = model.UserWebsites{}
+	}
+
+	return webs, nextCursor, nil
+}
+
+func (r *MemoryRepo) SearchUserWebsites(ctx context.Context, userUUID, query string, limit int) (model.UserWebsites, error) {
+	_, searchUserWebsitesSpan := repository.GetTracer().Start(ctx, "search user websites")
+	defer searchUserWebsitesSpan.End()
+
+	searchUserWebsitesSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.query", query),
+		attribute.Int("params.limit", limit),
+	)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	webs := r.listUserWebsites(func(web model.UserWebsite) bool {
+		return web.UserUUID == userUUID
+	})
+
+	return repository.RankUserWebsites(webs, query, limit), nil
+}
+
+func (r *MemoryRepo) FindUserWebsite(ctx context.Context, userUUID, websiteUUID string) (*model.UserWebsite, error) {
+	_, findUserWebsiteSpan := repository.GetTracer().Start(ctx, "find user website")
+	defer findUserWebsiteSpan.End()
+
+	findUserWebsiteSpan.SetAttributes(attribute.String("params.user_uuid", userUUID), attribute.String("params.website_uuid", websiteUUID))
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	webs := r.listUserWebsites(func(web model.UserWebsite) bool {
+		return web.UserUUID == userUUID && web.WebsiteUUID == websiteUUID
+	})
+	if len(webs) == 0 {
+		findUserWebsiteSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		findUserWebsiteSpan.RecordError(sql.ErrNoRows)
+
+		return nil, fmt.Errorf("get user website fail: %w", sql.ErrNoRows)
+	}
+
+	return &webs[0], nil
+}
+
+func (r *MemoryRepo) FindUserUnreadSummary(ctx context.Context, userUUID string, limit int) (model.UnreadSummary, error) {
+	_, findUserUnreadSummarySpan := repository.GetTracer().Start(ctx, "find user unread summary")
+	defer findUserUnreadSummarySpan.End()
+
+	findUserUnreadSummarySpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.Int("params.limit", limit),
+	)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	webs := r.listUserWebsites(func(web model.UserWebsite) bool {
+		return web.UserUUID == userUUID
+	})
+
+	groups := []model.GroupUnreadCount{}
+	unreadWebs := []model.UnreadWebsite{}
+	for _, web := range webs {
+		if !web.Website.UpdateTime.After(web.AccessTime) {
+			continue
+		}
+
+		i := slices.IndexFunc(groups, func(group model.GroupUnreadCount) bool {
+			return group.GroupName == web.GroupName
+		})
+		if i < 0 {
+			groups = append(groups, model.GroupUnreadCount{GroupName: web.GroupName})
+			i = len(groups) - 1
+		}
+		groups[i].UnreadCount++
+
+		unreadWebs = append(unreadWebs, model.UnreadWebsite{
+			WebsiteUUID: web.WebsiteUUID,
+			GroupName:   web.GroupName,
+			Title:       web.Title(),
+			UpdateTime:  web.Website.UpdateTime,
+		})
+	}
+
+	// same as ORDER BY unread_count DESC, group_name
+	slices.SortFunc(groups, func(a, b model.GroupUnreadCount) int {
+		if c := b.UnreadCount - a.UnreadCount; c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.GroupName, b.GroupName)
+	})
+
+	// same as ORDER BY update_time DESC, website_uuid ASC
+	slices.SortFunc(unreadWebs, func(a, b model.UnreadWebsite) int {
+		if c := b.UpdateTime.Compare(a.UpdateTime); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.WebsiteUUID, b.WebsiteUUID)
+	})
+	if len(unreadWebs) > limit {
+		unreadWebs = unreadWebs[:limit]
+	}
+
+	return model.NewUnreadSummary(groups, unreadWebs), nil
+}
+
+func (r *MemoryRepo) FindWebsiteUserUUIDs(ctx context.Context, websiteUUID string) ([]string, error) {
+	_, listWebsiteUserUUIDsSpan := repository.GetTracer().Start(ctx, "find website user uuids")
+	defer listWebsiteUserUUIDsSpan.End()
+
+	listWebsiteUserUUIDsSpan.SetAttributes(attribute.String("params.website_uuid", websiteUUID))
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	userUUIDs := []string{}
+	for _, userWeb := range r.userWebsites {
+		if userWeb.WebsiteUUID == websiteUUID {
+			userUUIDs = append(userUUIDs, userWeb.UserUUID)
+		}
+	}
+
+	slices.Sort(userUUIDs)
+
+	return userUUIDs, nil
+}
+
+func (r *MemoryRepo) hasTag(userUUID, websiteUUID, tag string) bool {
+	return slices.Contains(r.tags, userWebsiteTag{UserUUID: userUUID, WebsiteUUID: websiteUUID, Tag: tag})
+}
+
+func (r *MemoryRepo) FindUserTags(ctx context.Context, userUUID string) ([]model.Tag, error) {
+	_, listUserTagsSpan := repository.GetTracer().Start(ctx, "find user tags")
+	defer listUserTagsSpan.End()
+
+	listUserTagsSpan.SetAttributes(attribute.String("params.user_uuid", userUUID))
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	counts := make(map[string]int)
+	for _, tag := range r.tags {
+		if tag.UserUUID != userUUID || r.userWebsiteIndex(tag.UserUUID, tag.WebsiteUUID) < 0 {
+			continue
+		}
+
+		if _, err := r.getWebsite(tag.WebsiteUUID); err == nil {
+			counts[tag.Tag]++
+		}
+	}
+
+	tags := make([]model.Tag, 0, len(counts))
+	for _, name := range slices.Sorted(maps.Keys(counts)) {
+		tags = append(tags, model.Tag{Name: name, WebsiteCount: counts[name]})
+	}
+
+	return tags, nil
+}
+
+func (r *MemoryRepo) FindUserWebsiteTags(ctx context.Context, userUUID, websiteUUID string) ([]string, error) {
+	_, listUserWebsiteTagsSpan := repository.GetTracer().Start(ctx, "find user website tags")
+	defer listUserWebsiteTagsSpan.End()
+
+	listUserWebsiteTagsSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.website_uuid", websiteUUID),
+	)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	tags := []string{}
+	for _, tag := range r.tags {
+		if tag.UserUUID == userUUID && tag.WebsiteUUID == websiteUUID {
+			tags = append(tags, tag.Tag)
+		}
+	}
+	slices.Sort(tags)
+
+	return tags, nil
+}
+
+func (r *MemoryRepo) AddUserWebsiteTag(ctx context.Context, userUUID, websiteUUID, tag string) error {
+	_, addUserWebsiteTagSpan := repository.GetTracer().Start(ctx, "add user website tag")
+	defer addUserWebsiteTagSpan.End()
+
+	addUserWebsiteTagSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.website_uuid", websiteUUID),
+		attribute.String("params.tag", tag),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.hasTag(userUUID, websiteUUID, tag) {
+		r.tags = append(r.tags, userWebsiteTag{UserUUID: userUUID, WebsiteUUID: websiteUUID, Tag: tag})
+	}
+
+	return nil
+}
+
+func (r *MemoryRepo) RemoveUserWebsiteTag(ctx context.Context, userUUID, websiteUUID, tag string) error {
+	_, removeUserWebsiteTagSpan := repository.GetTracer().Start(ctx, "remove user website tag")
+	defer removeUserWebsiteTagSpan.End()
+
+	removeUserWebsiteTagSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.website_uuid", websiteUUID),
+		attribute.String("params.tag", tag),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.tags = slices.DeleteFunc(r.tags, func(stored userWebsiteTag) bool {
+		return stored == userWebsiteTag{UserUUID: userUUID, WebsiteUUID: websiteUUID, Tag: tag}
+	})
+
+	return nil
+}
+
+func (r *MemoryRepo) RenameUserTag(ctx context.Context, userUUID, from, to string) error {
+	_, renameUserTagSpan := repository.GetTracer().Start(ctx, "rename user tag")
+	defer renameUserTagSpan.End()
+
+	renameUserTagSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.from", from),
+		attribute.String("params.to", to),
+	)
+
+	if from == to {
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// websites already having tag to keep the existing one
+	r.tags = slices.DeleteFunc(r.tags, func(tag userWebsiteTag) bool {
+		return tag.UserUUID == userUUID && tag.Tag == from && r.hasTag(userUUID, tag.WebsiteUUID, to)
+	})
+	for i := range r.tags {
+		if r.tags[i].UserUUID == userUUID && r.tags[i].Tag == from {
+			r.tags[i].Tag = to
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryRepo) DeleteUserTag(ctx context.Context, userUUID, tag string) error {
+	_, deleteUserTagSpan := repository.GetTracer().Start(ctx, "delete user tag")
+	defer deleteUserTagSpan.End()
+
+	deleteUserTagSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.tag", tag),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.tags = slices.DeleteFunc(r.tags, func(stored userWebsiteTag) bool {
+		return stored.UserUUID == userUUID && stored.Tag == tag
+	})
+
+	return nil
+}
+
+func (r *MemoryRepo) CreateAuditEvent(ctx context.Context, event *model.AuditEvent) error {
+	_, createAuditEventSpan := repository.GetTracer().Start(ctx, "create audit event")
+	defer createAuditEventSpan.End()
+
+	createAuditEventSpan.SetAttributes(
+		attribute.String("params.user_uuid", event.UserUUID),
+		attribute.String("params.website_uuid", event.WebsiteUUID),
+		attribute.String("params.action", event.Action),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if slices.ContainsFunc(r.auditEvents, func(e model.AuditEvent) bool { return e.UUID == event.UUID }) {
+		createAuditEventSpan.SetStatus(codes.Error, ErrDuplicateKey.Error())
+		createAuditEventSpan.RecordError(ErrDuplicateKey)
+
+		return fmt.Errorf("create audit event fail: %w", ErrDuplicateKey)
+	}
+
+	event.CreatedAt = event.CreatedAt.UTC()
+	r.auditEvents = append(r.auditEvents, *event)
+
+	return nil
+}
+
+func (r *MemoryRepo) FindAuditEvents(ctx context.Context, query repository.AuditEventsQuery) ([]model.AuditEvent, error) {
+	_, listAuditEventsSpan := repository.GetTracer().Start(ctx, "find audit events")
+	defer listAuditEventsSpan.End()
+
+	listAuditEventsSpan.SetAttributes(
+		attribute.String("params.user_uuid", query.UserUUID),
+		attribute.String("params.website_uuid", query.WebsiteUUID),
+		attribute.Int("params.limit", query.Limit),
+	)
+
+	if query.Limit <= 0 {
+		listAuditEventsSpan.SetStatus(codes.Error, repository.ErrInvalidLimit.Error())
+		listAuditEventsSpan.RecordError(repository.ErrInvalidLimit)
+
+		return nil, fmt.Errorf("list audit events fail: %w", repository.ErrInvalidLimit)
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	events := make([]model.AuditEvent, 0, query.Limit)
+	for _, event := range r.auditEvents {
+		if (query.UserUUID == "" || event.UserUUID == query.UserUUID) &&
+			(query.WebsiteUUID == "" || event.WebsiteUUID == query.WebsiteUUID) {
+			events = append(events, event)
+		}
+	}
+
+	slices.SortFunc(events, func(a, b model.AuditEvent) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+
+		return strings.Compare(b.UUID, a.UUID)
+	})
+
+	return events[:min(query.Limit, len(events))], nil
+}
+
+func (r *MemoryRepo) CreateJob(ctx context.Context, job *model.Job) error {
+	_, createJobSpan := repository.GetTracer().Start(ctx, "create job")
+	defer createJobSpan.End()
+
+	createJobSpan.SetAttributes(
+		attribute.String("params.user_uuid", job.UserUUID),
+		attribute.String("params.kind", job.Kind),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if slices.ContainsFunc(r.jobs, func(stored model.Job) bool { return stored.UUID == job.UUID }) {
+		createJobSpan.SetStatus(codes.Error, ErrDuplicateKey.Error())
+		createJobSpan.RecordError(ErrDuplicateKey)
+
+		return fmt.Errorf("create job fail: %w", ErrDuplicateKey)
+	}
+
+	job.CreatedAt, job.UpdatedAt = job.CreatedAt.UTC(), job.UpdatedAt.UTC()
+	r.jobs = append(r.jobs, *job)
+
+	return nil
+}
+
+func (r *MemoryRepo) UpdateJob(ctx context.Context, job *model.Job) error {
+	_, updateJobSpan := repository.GetTracer().Start(ctx, "update job")
+	defer updateJobSpan.End()
+
+	updateJobSpan.SetAttributes(
+		attribute.String("params.uuid", job.UUID),
+		attribute.String("params.status", job.Status),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	i := slices.IndexFunc(r.jobs, func(stored model.Job) bool { return stored.UUID == job.UUID })
+	if i < 0 {
+		updateJobSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		updateJobSpan.RecordError(sql.ErrNoRows)
+
+		return fmt.Errorf("update job fail: %w", sql.ErrNoRows)
+	}
+
+	stored := &r.jobs[i]
+	stored.Status, stored.Result, stored.UpdatedAt = job.Status, job.Result, job.UpdatedAt.UTC()
+	*job = *stored
+
+	return nil
+}
+
+func (r *MemoryRepo) FindJob(ctx context.Context, userUUID, uuid string) (*model.Job, error) {
+	_, getJobSpan := repository.GetTracer().Start(ctx, "find job")
+	defer getJobSpan.End()
+
+	getJobSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.uuid", uuid),
+	)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	i := slices.IndexFunc(r.jobs, func(stored model.Job) bool { return stored.UUID == uuid && stored.UserUUID == userUUID })
+	if i < 0 {
+		getJobSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		getJobSpan.RecordError(sql.ErrNoRows)
+
+		return nil, fmt.Errorf("find job fail: %w", sql.ErrNoRows)
+	}
+
+	job := r.jobs[i]
+
+	return &job, nil
+}
+
+func (r *MemoryRepo) CountUserJobs(ctx context.Context, userUUID, kind string, since time.Time) (int, error) {
+	_, countUserJobsSpan := repository.GetTracer().Start(ctx, "count user jobs")
+	defer countUserJobsSpan.End()
+
+	countUserJobsSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.kind", kind),
+		attribute.String("params.since", since.String()),
+	)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	count := 0
+	for _, job := range r.jobs {
+		if job.UserUUID == userUUID && job.Kind == kind && !job.CreatedAt.Before(since) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// LockUserJobs does nothing as WithTx holds the lock of repository until the
+// end of transaction
+func (r *MemoryRepo) LockUserJobs(ctx context.Context, userUUID string) error {
+	return nil
+}
+
+func (r *MemoryRepo) SaveFeedToken(ctx context.Context, token *model.FeedToken) error {
+	_, saveFeedTokenSpan := repository.GetTracer().Start(ctx, "save feed token")
+	defer saveFeedTokenSpan.End()
+
+	saveFeedTokenSpan.SetAttributes(attribute.String("params.user_uuid", token.UserUUID))
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if slices.ContainsFunc(r.feedTokens, func(stored model.FeedToken) bool {
+		return stored.Token == token.Token && stored.UserUUID != token.UserUUID
+	}) {
+		saveFeedTokenSpan.SetStatus(codes.Error, ErrDuplicateKey.Error())
+		saveFeedTokenSpan.RecordError(ErrDuplicateKey)
+
+		return fmt.Errorf("save feed token fail: %w", ErrDuplicateKey)
+	}
+
+	r.feedTokens = slices.DeleteFunc(r.feedTokens, func(stored model.FeedToken) bool {
+		return stored.UserUUID == token.UserUUID
+	})
+	r.feedTokens = append(r.feedTokens, *token)
+
+	return nil
+}
+
+func (r *MemoryRepo) FindFeedToken(ctx context.Context, userUUID string) (*model.FeedToken, error) {
+	_, findFeedTokenSpan := repository.GetTracer().Start(ctx, "find feed token")
+	defer findFeedTokenSpan.End()
+
+	findFeedTokenSpan.SetAttributes(attribute.String("params.user_uuid", userUUID))
+
+	token, ok := r.findFeedToken(func(stored model.FeedToken) bool { return stored.UserUUID == userUUID })
+	if !ok {
+		findFeedTokenSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		findFeedTokenSpan.RecordError(sql.ErrNoRows)
+
+		return nil, fmt.Errorf("find feed token fail: %w", sql.ErrNoRows)
+	}
+
+	return token, nil
+}
+
+func (r *MemoryRepo) FindFeedTokenByToken(ctx context.Context, token string) (*model.FeedToken, error) {
+	_, findFeedTokenSpan := repository.GetTracer().Start(ctx, "find feed token by token")
+	defer findFeedTokenSpan.End()
+
+	feedToken, ok := r.findFeedToken(func(stored model.FeedToken) bool { return stored.Token == token })
+	if !ok {
+		findFeedTokenSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		findFeedTokenSpan.RecordError(sql.ErrNoRows)
+
+		return nil, fmt.Errorf("find feed token fail: %w", sql.ErrNoRows)
+	}
+
+	return feedToken, nil
+}
+
+func (r *MemoryRepo) findFeedToken(match func(model.FeedToken) bool) (*model.FeedToken, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	i := slices.IndexFunc(r.feedTokens, match)
+	if i < 0 {
+		return nil, false
+	}
+
+	token := r.feedTokens[i]
+
+	return &token, true
+}
+
+func (r *MemoryRepo) Stats() sql.DBStats {
+	return sql.DBStats{}
+}
+
+func (r *MemoryRepo) webhookIndex(match func(model.Webhook) bool) int {
+	return slices.IndexFunc(r.webhooks, match)
+}
+
+func (r *MemoryRepo) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	_, createWebhookSpan := repository.GetTracer().Start(ctx, "create webhook")
+	defer createWebhookSpan.End()
+
+	createWebhookSpan.SetAttributes(
+		attribute.String("params.user_uuid", webhook.UserUUID),
+		attribute.String("params.group_name", webhook.GroupName),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.webhookIndex(func(stored model.Webhook) bool { return stored.UUID == webhook.UUID }) >= 0 {
+		createWebhookSpan.SetStatus(codes.Error, ErrDuplicateKey.Error())
+		createWebhookSpan.RecordError(ErrDuplicateKey)
+
+		return fmt.Errorf("create webhook fail: %w", ErrDuplicateKey)
+	}
+
+	webhook.CreatedAt, webhook.UpdatedAt = webhook.CreatedAt.UTC(), webhook.UpdatedAt.UTC()
+	r.webhooks = append(r.webhooks, *webhook)
+
+	return nil
+}
+
+func (r *MemoryRepo) UpdateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	_, updateWebhookSpan := repository.GetTracer().Start(ctx, "update webhook")
+	defer updateWebhookSpan.End()
+
+	updateWebhookSpan.SetAttributes(
+		attribute.String("params.uuid", webhook.UUID),
+		attribute.Bool("params.enabled", webhook.Enabled),
+		attribute.Int("params.failure_count", webhook.FailureCount),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	i := r.webhookIndex(func(stored model.Webhook) bool { return stored.UUID == webhook.UUID })
+	if i < 0 {
+		updateWebhookSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		updateWebhookSpan.RecordError(sql.ErrNoRows)
+
+		return fmt.Errorf("update webhook fail: %w", sql.ErrNoRows)
+	}
+
+	stored := &r.webhooks[i]
+	stored.URL, stored.GroupName, stored.Enabled = webhook.URL, webhook.GroupName, webhook.Enabled
+	stored.FailureCount, stored.UpdatedAt = webhook.FailureCount, webhook.UpdatedAt.UTC()
+	*webhook = *stored
+
+	return nil
+}
+
+func (r *MemoryRepo) DeleteWebhook(ctx context.Context, webhook *model.Webhook) error {
+	_, deleteWebhookSpan := repository.GetTracer().Start(ctx, "delete webhook")
+	defer deleteWebhookSpan.End()
+
+	deleteWebhookSpan.SetAttributes(attribute.String("params.uuid", webhook.UUID))
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(stored model.WebhookDelivery) bool {
+		return stored.WebhookUUID == webhook.UUID
+	})
+	r.webhooks = slices.DeleteFunc(r.webhooks, func(stored model.Webhook) bool {
+		return stored.UUID == webhook.UUID
+	})
+
+	return nil
+}
+
+func (r *MemoryRepo) FindWebhook(ctx context.Context, userUUID, uuid string) (*model.Webhook, error) {
+	_, getWebhookSpan := repository.GetTracer().Start(ctx, "find webhook")
+	defer getWebhookSpan.End()
+
+	getWebhookSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.uuid", uuid),
+	)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	i := r.webhookIndex(func(stored model.Webhook) bool { return stored.UUID == uuid && stored.UserUUID == userUUID })
+	if i < 0 {
+		getWebhookSpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		getWebhookSpan.RecordError(sql.ErrNoRows)
+
+		return nil, fmt.Errorf("find webhook fail: %w", sql.ErrNoRows)
+	}
+
+	webhook := r.webhooks[i]
+
+	return &webhook, nil
+}
+
+func (r *MemoryRepo) FindWebhooks(ctx context.Context, userUUID string) ([]model.Webhook, error) {
+	_, listWebhooksSpan := repository.GetTracer().Start(ctx, "find webhooks")
+	defer listWebhooksSpan.End()
+
+	listWebhooksSpan.SetAttributes(attribute.String("params.user_uuid", userUUID))
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	webhooks := []model.Webhook{}
+	for _, webhook := range r.webhooks {
+		if webhook.UserUUID == userUUID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	slices.SortStableFunc(webhooks, func(a, b model.Webhook) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.UUID, b.UUID))
+	})
+
+	return webhooks, nil
+}
+
+func (r *MemoryRepo) FindWebsiteWebhooks(ctx context.Context, websiteUUID string) ([]model.Webhook, error) {
+	_, listWebsiteWebhooksSpan := repository.GetTracer().Start(ctx, "find website webhooks")
+	defer listWebsiteWebhooksSpan.End()
+
+	listWebsiteWebhooksSpan.SetAttributes(attribute.String("params.website_uuid", websiteUUID))
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	webhooks := []model.Webhook{}
+	for _, userWeb := range r.userWebsites {
+		if userWeb.WebsiteUUID != websiteUUID {
+			continue
+		}
+
+		for _, webhook := range r.webhooks {
+			if webhook.UserUUID == userWeb.UserUUID && webhook.Matches(userWeb.GroupName) {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+	}
+
+	slices.SortStableFunc(webhooks, func(a, b model.Webhook) int { return strings.Compare(a.UUID, b.UUID) })
+
+	return webhooks, nil
+}
+
+func (r *MemoryRepo) CreateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	_, createWebhookDeliverySpan := repository.GetTracer().Start(ctx, "create webhook delivery")
+	defer createWebhookDeliverySpan.End()
+
+	createWebhookDeliverySpan.SetAttributes(
+		attribute.String("params.webhook_uuid", delivery.WebhookUUID),
+		attribute.String("params.website_uuid", delivery.WebsiteUUID),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if slices.ContainsFunc(r.deliveries, func(stored model.WebhookDelivery) bool { return stored.UUID == delivery.UUID }) {
+		createWebhookDeliverySpan.SetStatus(codes.Error, ErrDuplicateKey.Error())
+		createWebhookDeliverySpan.RecordError(ErrDuplicateKey)
+
+		return fmt.Errorf("create webhook delivery fail: %w", ErrDuplicateKey)
+	}
+
+	delivery.CreatedAt, delivery.UpdatedAt = delivery.CreatedAt.UTC(), delivery.UpdatedAt.UTC()
+	r.deliveries = append(r.deliveries, *delivery)
+
+	return nil
+}
+
+func (r *MemoryRepo) UpdateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	_, updateWebhookDeliverySpan := repository.GetTracer().Start(ctx, "update webhook delivery")
+	defer updateWebhookDeliverySpan.End()
+
+	updateWebhookDeliverySpan.SetAttributes(
+		attribute.String("params.uuid", delivery.UUID),
+		attribute.String("params.status", delivery.Status),
+	)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	i := slices.IndexFunc(r.deliveries, func(stored model.WebhookDelivery) bool { return stored.UUID == delivery.UUID })
+	if i < 0 {
+		updateWebhookDeliverySpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		updateWebhookDeliverySpan.RecordError(sql.ErrNoRows)
+
+		return fmt.Errorf("update webhook delivery fail: %w", sql.ErrNoRows)
+	}
+
+	stored := &r.deliveries[i]
+	stored.Status, stored.Attempts, stored.ResponseStatus = delivery.Status, delivery.Attempts, delivery.ResponseStatus
+	stored.Error, stored.UpdatedAt = delivery.Error, delivery.UpdatedAt.UTC()
+	*delivery = *stored
+
+	return nil
+}
+
+func (r *MemoryRepo) FindWebhookDelivery(ctx context.Context, uuid string) (*model.WebhookDelivery, error) {
+	_, getWebhookDeliverySpan := repository.GetTracer().Start(ctx, "find webhook delivery")
+	defer getWebhookDeliverySpan.End()
+
+	getWebhookDeliverySpan.SetAttributes(attribute.String("params.uuid", uuid))
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	i := slices.IndexFunc(r.deliveries, func(stored model.WebhookDelivery) bool { return stored.UUID == uuid })
+	if i < 0 {
+		getWebhookDeliverySpan.SetStatus(codes.Error, sql.ErrNoRows.Error())
+		getWebhookDeliverySpan.RecordError(sql.ErrNoRows)
+
+		return nil, fmt.Errorf("find webhook delivery fail: %w", sql.ErrNoRows)
+	}
+
+	delivery := r.deliveries[i]
+
+	return &delivery, nil
+}
+
+func (r *MemoryRepo) FindWebhookDeliveries(ctx context.Context, webhookUUID string, limit int) ([]model.WebhookDelivery, error) {
+	_, listWebhookDeliveriesSpan := repository.GetTracer().Start(ctx, "find webhook deliveries")
+	defer listWebhookDeliveriesSpan.End()
+
+	listWebhookDeliveriesSpan.SetAttributes(
+		attribute.String("params.webhook_uuid", webhookUUID),
+		attribute.Int("params.limit", limit),
+	)
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	deliveries := []model.WebhookDelivery{}
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookUUID == webhookUUID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	slices.SortStableFunc(deliveries, func(a, b model.WebhookDelivery) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(b.UUID, a.UUID))
+	})
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,309 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/htchan/WebHistory/internal/repository/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func populateData(r *MemoryRepo, uuid, title, userUUID, status string) {
+	r.websites = append(r.websites, model.Website{
+		UUID:       uuid,
+		URL:        "http://example.com/" + title,
+		Title:      title,
+		RawContent: "content",
+		UpdateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Status:     status,
+	})
+	r.userWebsites = append(r.userWebsites, model.UserWebsite{
+		WebsiteUUID: uuid,
+		UserUUID:    userUUID,
+		GroupName:   title,
+		AccessTime:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+}
+
+func TestMemoryRepo_CreateWebsite(t *testing.T) {
+	t.Parallel()
+
+	r := NewRepo(&config.WebsiteConfig{})
+
+	uuid := "create-website-uuid"
+	userUUID := "create-website-user-uuid"
+	title := "create website"
+	populateData(r, uuid, title, userUUID, "active")
+
+	tests := []struct {
+		name        string
+		web         model.Website
+		expect      model.Website
+		expectError error
+	}{
+		{
+			name: "create a new website",
+			web: model.Website{
+				UUID:       "dcb12928-5b5b-43f3-9d0e-ddb526d9794d",
+				URL:        "http://example.com",
+				Title:      "unknown",
+				UpdateTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			expect: model.Website{
+				UUID:       "dcb12928-5b5b-43f3-9d0e-ddb526d9794d",
+				URL:        "http://example.com",
+				Title:      "unknown",
+				UpdateTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				Conf:       &config.WebsiteConfig{},
+			},
+			expectError: nil,
+		},
+		{
+			name: "create an existing website",
+			web: model.Website{
+				UUID:       "new-uuid",
+				URL:        "http://example.com/" + title,
+				UpdateTime: time.Now().UTC().Truncate(MinTimeUnit),
+			},
+			expect: model.Website{
+				UUID:       uuid,
+				URL:        "http://example.com/" + title,
+				Title:      title,
+				RawContent: "content",
+				UpdateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+				Conf:       &config.WebsiteConfig{},
+			},
+			expectError: nil,
+		},
+		{
+			name: "create website with existing uuid",
+			web: model.Website{
+				UUID: uuid,
+				URL:  "http://example.com/other",
+			},
+			expect: model.Website{
+				UUID: uuid,
+				URL:  "http://example.com/other",
+			},
+			expectError: ErrDuplicateKey,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := r.CreateWebsite(context.Background(), &test.web)
+			assert.ErrorIs(t, err, test.expectError)
+			assert.Equal(t, test.expect, test.web)
+		})
+	}
+}
+
+func TestMemoryRepo_FindWebsites(t *testing.T) {
+	t.Parallel()
+
+	r := NewRepo(&config.WebsiteConfig{})
+
+	title := "find websites"
+	populateData(r, "active-uuid", title, "user-uuid", "active")
+	populateData(r, "read-only-uuid", title+"-readonly", "user-uuid", "read_only")
+	populateData(r, "inactive-uuid", title+"-inactive", "user-uuid", "inactive")
+	populateData(r, "gone-uuid", title+"-gone", "user-uuid", "gone")
+
+	webs, err := r.FindWebsites(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Website{
+		{
+			UUID:       "active-uuid",
+			URL:        "http://example.com/" + title,
+			Title:      title,
+			RawContent: "content",
+			UpdateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+			Status:     "active",
+			Conf:       &config.WebsiteConfig{},
+		},
+	}, webs)
+
+	for _, uuid := range []string{"active-uuid", "read-only-uuid", "gone-uuid"} {
+		_, err := r.FindWebsite(context.Background(), uuid)
+		assert.NoError(t, err, uuid)
+	}
+
+	_, err = r.FindWebsite(context.Background(), "inactive-uuid")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestMemoryRepo_FindUserWebsites(t *testing.T) {
+	t.Parallel()
+
+	r := NewRepo(&config.WebsiteConfig{})
+
+	userUUID := "find-user-websites-user-uuid"
+	populateData(r, "read-uuid", "read", userUUID, "active")
+	populateData(r, "unread-old-uuid", "unread old", userUUID, "active")
+	populateData(r, "unread-new-uuid", "unread new", userUUID, "active")
+	populateData(r, "inactive-uuid", "inactive", userUUID, "inactive")
+	populateData(r, "other-user-uuid", "other user", "other-user", "active")
+
+	r.websites[1].UpdateTime = time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)
+	r.websites[2].UpdateTime = time.Date(2020, 1, 4, 0, 0, 0, 0, time.UTC)
+
+	webs, err := r.FindUserWebsites(context.Background(), userUUID)
+	assert.NoError(t, err)
+
+	uuids := make([]string, len(webs))
+	for i, web := range webs {
+		uuids[i] = web.WebsiteUUID
+	}
+
+	assert.Equal(t, []string{"unread-new-uuid", "unread-old-uuid", "read-uuid"}, uuids)
+
+	webs, err = r.FindUserWebsites(context.Background(), "not exist")
+	assert.NoError(t, err)
+	assert.Equal(t, model.UserWebsites{}, webs)
+}
+
+func TestMemoryRepo_UserWebsite(t *testing.T) {
+	t.Parallel()
+
+	r := NewRepo(&config.WebsiteConfig{})
+
+	uuid := "user-website-uuid"
+	userUUID := "user-website-user-uuid"
+	title := "user website"
+	populateData(r, uuid, title, "other-user", "active")
+
+	web := model.UserWebsite{
+		WebsiteUUID: uuid,
+		UserUUID:    userUUID,
+		GroupName:   title,
+		AccessTime:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	err := r.CreateUserWebsite(context.Background(), &web)
+	assert.NoError(t, err)
+	assert.Equal(t, title, web.Website.Title)
+
+	web.Note = "some note"
+	err = r.UpdateUserWebsite(context.Background(), &web)
+	assert.NoError(t, err)
+
+	group, err := r.FindUserWebsitesByGroup(context.Background(), userUUID, title)
+	assert.NoError(t, err)
+	if assert.Len(t, group, 1) {
+		assert.Equal(t, "some note", group[0].Note)
+	}
+
+	err = r.DeleteUserWebsite(context.Background(), &web)
+	assert.NoError(t, err)
+
+	_, err = r.FindUserWebsite(context.Background(), userUUID, uuid)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = r.UpdateUserWebsite(context.Background(), &web)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestMemoryRepo_MergeWebsite(t *testing.T) {
+	t.Parallel()
+
+	r := NewRepo(&config.WebsiteConfig{})
+
+	title := "merge website"
+	populateData(r, "from-uuid", title+"-from", "user-uuid", "active")
+	populateData(r, "to-uuid", title+"-to", "shared-user-uuid", "active")
+	r.userWebsites = append(r.userWebsites, model.UserWebsite{WebsiteUUID: "from-uuid", UserUUID: "shared-user-uuid"})
+	r.websites[0].UpdateTime = time.Date(2020, 1, 3, 3, 4, 5, 0, time.UTC)
+
+	from, _ := r.FindWebsite(context.Background(), "from-uuid")
+	to, _ := r.FindWebsite(context.Background(), "to-uuid")
+
+	err := r.MergeWebsite(context.Background(), from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 1, 3, 3, 4, 5, 0, time.UTC), to.UpdateTime)
+
+	_, err = r.FindWebsite(context.Background(), "from-uuid")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	for _, user := range []string{"user-uuid", "shared-user-uuid"} {
+		webs, err := r.FindUserWebsites(context.Background(), user)
+		assert.NoError(t, err)
+		if assert.Len(t, webs, 1) {
+			assert.Equal(t, "to-uuid", webs[0].WebsiteUUID)
+		}
+	}
+
+	web := model.Website{UUID: "new-uuid", URL: "http://example.com/" + title + "-from"}
+	err = r.CreateWebsite(context.Background(), &web)
+	assert.NoError(t, err)
+	assert.Equal(t, "to-uuid", web.UUID)
+}
+
+func TestMemoryRepo_RecordWebsiteMissing(t *testing.T) {
+	t.Parallel()
+
+	r := NewRepo(&config.WebsiteConfig{})
+	populateData(r, "missing-uuid", "missing", "user-uuid", "active")
+
+	web := &model.Website{UUID: "missing-uuid"}
+	for range 2 {
+		err := r.RecordWebsiteMissing(context.Background(), web, 2)
+		assert.NoError(t, err)
+	}
+
+	assert.True(t, web.Gone())
+
+	webs, err := r.FindWebsites(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, webs)
+
+	err = r.ResetWebsiteMissing(context.Background(), web)
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebsiteStatusActive, web.Status)
+	assert.Equal(t, 0, web.MissingCount)
+}
+
+func TestMemoryRepo_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	r := NewRepo(&config.WebsiteConfig{})
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			web := model.Website{
+				UUID: fmt.Sprintf("uuid-%d", i),
+				URL:  "http://example.com/concurrency",
+			}
+			assert.NoError(t, r.CreateWebsite(context.Background(), &web))
+
+			userWeb := model.NewUserWebsite(web, fmt.Sprintf("user-%d", i))
+			assert.NoError(t, r.CreateUserWebsite(context.Background(), &userWeb))
+
+			_, err := r.FindUserWebsites(context.Background(), userWeb.UserUUID)
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	webs, err := r.FindWebsites(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, webs, 1)
+	assert.Len(t, r.userWebsites, 20)
+}
+
+func TestMemoryRepo_Contract(t *testing.T) {
+	t.Parallel()
+
+	repotest.Run(t, func(t *testing.T, conf *config.WebsiteConfig) repository.Repository {
+		return NewRepo(conf)
+	})
+}
@@ -0,0 +1,47 @@
+package repository
+
+import "context"
+
+// MergeUserWebsites merges the subscription of from website into the one of
+// to website for users subscribing both, so their data is kept once the
+// subscriptions of from website are deleted. r should be bound to the
+// transaction merging the websites.
+func MergeUserWebsites(ctx context.Context, r Repository, fromUUID, toUUID string) error {
+	fromUserUUIDs, err := r.FindWebsiteUserUUIDs(ctx, fromUUID)
+	if err != nil {
+		return err
+	}
+
+	toUserUUIDs, err := r.FindWebsiteUserUUIDs(ctx, toUUID)
+	if err != nil {
+		return err
+	}
+
+	subscribed := make(map[string]bool, len(toUserUUIDs))
+	for _, userUUID := range toUserUUIDs {
+		subscribed[userUUID] = true
+	}
+
+	for _, userUUID := range fromUserUUIDs {
+		if !subscribed[userUUID] {
+			continue
+		}
+
+		from, err := r.FindUserWebsite(ctx, userUUID, fromUUID)
+		if err != nil {
+			return err
+		}
+
+		to, err := r.FindUserWebsite(ctx, userUUID, toUUID)
+		if err != nil {
+			return err
+		}
+
+		to.Merge(*from)
+		if err := r.UpdateUserWebsite(ctx, to); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
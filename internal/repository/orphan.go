@@ -0,0 +1,10 @@
+package repository
+
+// OrphanCleanupResult counts the websites handled by one orphaned website
+// cleanup. FetchesSaved is the number of websites currently deactivated for
+// having no subscriber, each of them skips one fetch per batch update.
+type OrphanCleanupResult struct {
+	Marked       int
+	Deactivated  int
+	FetchesSaved int
+}
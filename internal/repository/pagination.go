@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/model"
+)
+
+const (
+	SortByUnread     = "unread"
+	SortByUpdateTime = "update_time"
+	SortByAccessTime = "access_time"
+	SortByTitle      = "title"
+)
+
+var SortKeys = []string{SortByUnread, SortByUpdateTime, SortByAccessTime, SortByTitle}
+
+var (
+	ErrInvalidSortKey = errors.New("invalid sort key")
+	ErrInvalidCursor  = errors.New("invalid cursor")
+	ErrInvalidLimit   = errors.New("invalid limit")
+)
+
+// UserWebsitesQuery lists one page of user websites. Empty GroupName lists
+// websites of all groups, non empty Tags only lists websites having all the
+// tags and empty Cursor starts from the first page.
+type UserWebsitesQuery struct {
+	UserUUID  string
+	GroupName string
+	Tags      []string
+	SortBy    string
+	Cursor    string
+	Limit     int
+}
+
+// UserWebsitesCursor keeps the sort values of the last website of a page so
+// the next page continues after it even if websites are added in between
+type UserWebsitesCursor struct {
+	SortBy      string    `json:"sort"`
+	Unread      bool      `json:"unread,omitempty"`
+	Time        time.Time `json:"time,omitzero"`
+	Title       string    `json:"title,omitempty"`
+	WebsiteUUID string    `json:"uuid"`
+}
+
+func ValidSortKey(sortBy string) bool {
+	return slices.Contains(SortKeys, sortBy)
+}
+
+func NewUserWebsitesCursor(sortBy string, web model.UserWebsite) UserWebsitesCursor {
+	cursor := UserWebsitesCursor{SortBy: sortBy, WebsiteUUID: web.WebsiteUUID}
+
+	switch sortBy {
+	case SortByUnread:
+		cursor.Unread = web.Website.UpdateTime.After(web.AccessTime)
+		cursor.Time = web.Website.UpdateTime
+	case SortByUpdateTime:
+		cursor.Time = web.Website.UpdateTime
+	case SortByAccessTime:
+		cursor.Time = web.AccessTime
+	case SortByTitle:
+		cursor.Title = web.Title()
+	}
+
+	return cursor
+}
+
+func (cursor UserWebsitesCursor) Encode() string {
+	data, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeUserWebsitesCursor(token string) (UserWebsitesCursor, error) {
+	var cursor UserWebsitesCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if !ValidSortKey(cursor.SortBy) || cursor.WebsiteUUID == "" {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// Validate fills the default sort key and returns the decoded cursor if the
+// query continues from a previous page
+func (query *UserWebsitesQuery) Validate() (*UserWebsitesCursor, error) {
+	if query.SortBy == "" {
+		query.SortBy = SortByUnread
+	}
+
+	if !ValidSortKey(query.SortBy) {
+		return nil, ErrInvalidSortKey
+	}
+
+	if query.Limit <= 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	if query.Cursor == "" {
+		return nil, nil
+	}
+
+	cursor, err := DecodeUserWebsitesCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor.SortBy != query.SortBy {
+		return nil, fmt.Errorf("%w: cursor sorted by %s", ErrInvalidCursor, cursor.SortBy)
+	}
+
+	return &cursor, nil
+}
+
+// FilterTags returns the unique tags to filter, never nil
+func (query UserWebsitesQuery) FilterTags() []string {
+	tags := slices.Sorted(slices.Values(query.Tags))
+	if tags == nil {
+		return []string{}
+	}
+
+	return slices.Compact(tags)
+}
+
+// NextPage trims the extra website fetched to detect the next page and
+// returns the cursor of next page, or empty string for the last page
+func (query UserWebsitesQuery) NextPage(webs model.UserWebsites) (model.UserWebsites, string) {
+	return NextPageOf(query, webs, func(web model.UserWebsite) model.UserWebsite { return web })
+}
+
+// NextPageOf works as NextPage on the rows read from database. cursorWebsite
+// must keep the exact stored times of the row, as the page query compares the
+// cursor with stored value and truncated time skips or repeats websites.
+func NextPageOf[T any](query UserWebsitesQuery, rows []T, cursorWebsite func(T) model.UserWebsite) ([]T, string) {
+	if len(rows) <= query.Limit {
+		return rows, ""
+	}
+
+	rows = rows[:query.Limit]
+
+	return rows, NewUserWebsitesCursor(query.SortBy, cursorWebsite(rows[len(rows)-1])).Encode()
+}
+
+// WebsitesQuery lists one page of active websites ordered by uuid, starting
+// after AfterUUID. Non empty Host only lists websites of that host or its
+// sub domains, so each host can be processed separately.
+type WebsitesQuery struct {
+	Host      string
+	AfterUUID string
+	Limit     int
+}
+
+// IterateWebsites streams the websites matched by query page by page, so at
+// most one page is kept in memory. The iteration stops at the first error.
+func IterateWebsites(ctx context.Context, r Repository, query WebsitesQuery) iter.Seq2[model.Website, error] {
+	return func(yield func(model.Website, error) bool) {
+		if query.Limit <= 0 {
+			yield(model.Website{}, ErrInvalidLimit)
+
+			return
+		}
+
+		for {
+			webs, err := r.FindWebsitesPage(ctx, query)
+			if err != nil {
+				yield(model.Website{}, err)
+
+				return
+			}
+
+			for _, web := range webs {
+				if !yield(web, nil) {
+					return
+				}
+			}
+
+			if len(webs) < query.Limit {
+				return
+			}
+
+			query.AfterUUID = webs[len(webs)-1].UUID
+		}
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/htchan/WebHistory/internal/model"
 	"go.opentelemetry.io/otel"
@@ -16,15 +17,87 @@ type Repository interface {
 	DeleteWebsite(context.Context, *model.Website) error
 
 	FindWebsites(context.Context) ([]model.Website, error)
+	FindWebsitesPage(ctx context.Context, query WebsitesQuery) ([]model.Website, error)
 	FindWebsite(ctx context.Context, uuid string) (*model.Website, error)
+	MergeWebsite(ctx context.Context, from, to *model.Website) error
+	RecordWebsiteMissing(ctx context.Context, web *model.Website, threshold int) error
+	ResetWebsiteMissing(ctx context.Context, web *model.Website) error
+	// CleanupOrphanedWebsites marks websites without subscriber at now and
+	// deactivates those staying unsubscribed longer than gracePeriod
+	CleanupOrphanedWebsites(ctx context.Context, now time.Time, gracePeriod time.Duration) (OrphanCleanupResult, error)
 
 	CreateUserWebsite(context.Context, *model.UserWebsite) error
 	UpdateUserWebsite(context.Context, *model.UserWebsite) error
 	DeleteUserWebsite(context.Context, *model.UserWebsite) error
+	// RenameUserGroup moves websites of group from into group to, the groups
+	// are merged if group to already exists
+	RenameUserGroup(ctx context.Context, userUUID, from, to string) error
+	// UngroupUserGroup moves websites of group back to the group named by
+	// their website title
+	UngroupUserGroup(ctx context.Context, userUUID, group string) error
+	DeleteUserGroup(ctx context.Context, userUUID, group string) error
+	// RefreshUserWebsites marks websites of user in group, or all websites of
+	// user if group is empty, as read at accessTime and returns the number of
+	// updated websites
+	RefreshUserWebsites(ctx context.Context, userUUID, group string, accessTime time.Time) (int, error)
 
 	FindUserWebsites(ctx context.Context, userUUID string) (model.UserWebsites, error)
 	FindUserWebsitesByGroup(ctx context.Context, userUUID, group string) (model.WebsiteGroup, error)
+	FindUserWebsitesPage(ctx context.Context, query UserWebsitesQuery) (webs model.UserWebsites, nextCursor string, err error)
 	FindUserWebsite(ctx context.Context, userUUID, websiteUUID string) (*model.UserWebsite, error)
+	SearchUserWebsites(ctx context.Context, userUUID, query string, limit int) (model.UserWebsites, error)
+	// FindUserUnreadSummary counts unread websites of user by group and lists
+	// the latest limit unread websites
+	FindUserUnreadSummary(ctx context.Context, userUUID string, limit int) (model.UnreadSummary, error)
+	// FindWebsiteUserUUIDs lists users subscribing the website
+	FindWebsiteUserUUIDs(ctx context.Context, websiteUUID string) ([]string, error)
+
+	FindUserTags(ctx context.Context, userUUID string) ([]model.Tag, error)
+	FindUserWebsiteTags(ctx context.Context, userUUID, websiteUUID string) ([]string, error)
+	AddUserWebsiteTag(ctx context.Context, userUUID, websiteUUID, tag string) error
+	RemoveUserWebsiteTag(ctx context.Context, userUUID, websiteUUID, tag string) error
+	// RenameUserTag replaces tag from by tag to, the tags are merged if tag to
+	// already exists
+	RenameUserTag(ctx context.Context, userUUID, from, to string) error
+	DeleteUserTag(ctx context.Context, userUUID, tag string) error
+
+	CreateAuditEvent(context.Context, *model.AuditEvent) error
+	FindAuditEvents(ctx context.Context, query AuditEventsQuery) ([]model.AuditEvent, error)
+
+	CreateJob(context.Context, *model.Job) error
+	// UpdateJob saves status and result of job
+	UpdateJob(context.Context, *model.Job) error
+	FindJob(ctx context.Context, userUUID, uuid string) (*model.Job, error)
+	// CountUserJobs counts jobs of kind created by user since the given time
+	CountUserJobs(ctx context.Context, userUUID, kind string, since time.Time) (int, error)
+	// LockUserJobs blocks other transactions creating jobs of user until the
+	// end of transaction, it takes effect within WithTx only
+	LockUserJobs(ctx context.Context, userUUID string) error
+	// SaveFeedToken replaces the feed token of user
+	SaveFeedToken(context.Context, *model.FeedToken) error
+	FindFeedToken(ctx context.Context, userUUID string) (*model.FeedToken, error)
+	FindFeedTokenByToken(ctx context.Context, token string) (*model.FeedToken, error)
+
+	CreateWebhook(context.Context, *model.Webhook) error
+	// UpdateWebhook saves url, group, enabled and failure count of webhook
+	UpdateWebhook(context.Context, *model.Webhook) error
+	// DeleteWebhook deletes webhook together with its deliveries
+	DeleteWebhook(context.Context, *model.Webhook) error
+	FindWebhook(ctx context.Context, userUUID, uuid string) (*model.Webhook, error)
+	FindWebhooks(ctx context.Context, userUUID string) ([]model.Webhook, error)
+	// FindWebsiteWebhooks lists the enabled webhooks of users subscribing the
+	// website, whose group filter matches the group of website
+	FindWebsiteWebhooks(ctx context.Context, websiteUUID string) ([]model.Webhook, error)
+	CreateWebhookDelivery(context.Context, *model.WebhookDelivery) error
+	// UpdateWebhookDelivery saves status and result of the latest attempt
+	UpdateWebhookDelivery(context.Context, *model.WebhookDelivery) error
+	FindWebhookDelivery(ctx context.Context, uuid string) (*model.WebhookDelivery, error)
+	// FindWebhookDeliveries lists the latest deliveries of webhook
+	FindWebhookDeliveries(ctx context.Context, webhookUUID string, limit int) ([]model.WebhookDelivery, error)
+
+	// WithTx runs fn with a repository whose writes are committed together
+	// only if fn returns nil
+	WithTx(ctx context.Context, fn func(Repository) error) error
 
 	Stats() sql.DBStats
 }
@@ -0,0 +1,2004 @@
+// Package repotest provides the behaviour every repository.Repository
+// implementation has to follow, so backends can be verified by one call.
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// NewRepoFunc returns the repository under test. Repositories returned by
+// different calls may share storage, every test case uses its own records.
+type NewRepoFunc func(t *testing.T, conf *config.WebsiteConfig) repository.Repository
+
+var (
+	updateTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	accessTime = time.Date(2020, 1, 3, 3, 4, 5, 0, time.UTC)
+)
+
+// Run executes the contract test suite against the repository created by newRepo
+func Run(t *testing.T, newRepo NewRepoFunc) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, newRepo NewRepoFunc)
+	}{
+		{name: "CreateWebsite", test: testCreateWebsite},
+		{name: "UpdateWebsite", test: testUpdateWebsite},
+		{name: "DeleteWebsite", test: testDeleteWebsite},
+		{name: "FindWebsites", test: testFindWebsites},
+		{name: "FindWebsite", test: testFindWebsite},
+		{name: "FindWebsitesPage", test: testFindWebsitesPage},
+		{name: "MergeWebsite", test: testMergeWebsite},
+		{name: "RecordWebsiteMissing", test: testRecordWebsiteMissing},
+		{name: "CleanupOrphanedWebsites", test: testCleanupOrphanedWebsites},
+		{name: "CreateUserWebsite", test: testCreateUserWebsite},
+		{name: "UpdateUserWebsite", test: testUpdateUserWebsite},
+		{name: "DeleteUserWebsite", test: testDeleteUserWebsite},
+		{name: "RenameUserGroup", test: testRenameUserGroup},
+		{name: "UngroupUserGroup", test: testUngroupUserGroup},
+		{name: "DeleteUserGroup", test: testDeleteUserGroup},
+		{name: "RefreshUserWebsites", test: testRefreshUserWebsites},
+		{name: "FindUserUnreadSummary", test: testFindUserUnreadSummary},
+		{name: "FindUserWebsites", test: testFindUserWebsites},
+		{name: "FindUserWebsitesByGroup", test: testFindUserWebsitesByGroup},
+		{name: "FindUserWebsitesPage", test: testFindUserWebsitesPage},
+		{name: "FindUserWebsite", test: testFindUserWebsite},
+		{name: "SearchUserWebsites", test: testSearchUserWebsites},
+		{name: "FindWebsiteUserUUIDs", test: testFindWebsiteUserUUIDs},
+		{name: "UserWebsiteTags", test: testUserWebsiteTags},
+		{name: "RenameUserTag", test: testRenameUserTag},
+		{name: "DeleteUserTag", test: testDeleteUserTag},
+		{name: "CreateAuditEvent", test: testCreateAuditEvent},
+		{name: "FindAuditEvents", test: testFindAuditEvents},
+		{name: "Job", test: testJob},
+		{name: "CountUserJobs", test: testCountUserJobs},
+		{name: "LockUserJobs", test: testLockUserJobs},
+		{name: "FeedToken", test: testFeedToken},
+		{name: "Webhook", test: testWebhook},
+		{name: "FindWebsiteWebhooks", test: testFindWebsiteWebhooks},
+		{name: "WebhookDelivery", test: testWebhookDelivery},
+		{name: "WithTx", test: testWithTx},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.test(t, newRepo)
+		})
+	}
+}
+
+func uniqueID(prefix string) string {
+	return prefix + "-" + uuid.New().String()
+}
+
+func createWebsite(t *testing.T, r repository.Repository, title string) model.Website {
+	t.Helper()
+
+	web := model.Website{
+		UUID:       uniqueID(title),
+		URL:        "http://example.com/" + uniqueID(title),
+		Title:      title,
+		RawContent: "content",
+		UpdateTime: updateTime,
+	}
+
+	if err := r.CreateWebsite(context.Background(), &web); err != nil {
+		t.Fatalf("create website fail: %v", err)
+	}
+
+	return web
+}
+
+func createUserWebsite(t *testing.T, r repository.Repository, web model.Website, userUUID string) model.UserWebsite {
+	t.Helper()
+
+	userWeb := model.UserWebsite{
+		WebsiteUUID: web.UUID,
+		UserUUID:    userUUID,
+		GroupName:   web.Title,
+		AccessTime:  accessTime,
+	}
+
+	if err := r.CreateUserWebsite(context.Background(), &userWeb); err != nil {
+		t.Fatalf("create user website fail: %v", err)
+	}
+
+	return userWeb
+}
+
+func websiteUUIDs(webs []model.Website) []string {
+	uuids := make([]string, len(webs))
+	for i, web := range webs {
+		uuids[i] = web.UUID
+	}
+
+	return uuids
+}
+
+func userWebsiteUUIDs(webs []model.UserWebsite) []string {
+	uuids := make([]string, len(webs))
+	for i, web := range webs {
+		uuids[i] = web.WebsiteUUID
+	}
+
+	return uuids
+}
+
+func testCreateWebsite(t *testing.T, newRepo NewRepoFunc) {
+	conf := &config.WebsiteConfig{}
+	r := newRepo(t, conf)
+	existing := createWebsite(t, r, "create website")
+
+	tests := []struct {
+		name        string
+		web         model.Website
+		expect      model.Website
+		expectError bool
+	}{
+		{
+			name: "create a new website",
+			web: model.Website{
+				UUID:       "create-website-new-uuid-" + existing.UUID,
+				URL:        existing.URL + "/new",
+				Title:      "new",
+				UpdateTime: updateTime,
+			},
+			expect: model.Website{
+				UUID:       "create-website-new-uuid-" + existing.UUID,
+				URL:        existing.URL + "/new",
+				Title:      "new",
+				UpdateTime: updateTime,
+				Conf:       conf,
+			},
+		},
+		{
+			name: "create a website with existing url returns stored website",
+			web: model.Website{
+				UUID:       uniqueID("create website"),
+				URL:        existing.URL,
+				UpdateTime: time.Now().UTC().Truncate(5 * time.Second),
+			},
+			expect: model.Website{
+				UUID:       existing.UUID,
+				URL:        existing.URL,
+				Title:      existing.Title,
+				RawContent: existing.RawContent,
+				UpdateTime: updateTime,
+				Conf:       conf,
+			},
+		},
+		{
+			name: "create a website with existing uuid and new url",
+			web: model.Website{
+				UUID: existing.UUID,
+				URL:  existing.URL + "/duplicate",
+			},
+			expect: model.Website{
+				UUID: existing.UUID,
+				URL:  existing.URL + "/duplicate",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := r.CreateWebsite(context.Background(), &test.web)
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expect, test.web)
+		})
+	}
+}
+
+func testUpdateWebsite(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	web := createWebsite(t, r, "update website")
+
+	web.Title = "updated title"
+	web.RawContent = "updated content"
+	web.UpdateTime = accessTime
+
+	err := r.UpdateWebsite(context.Background(), &web)
+	assert.NoError(t, err)
+
+	stored, err := r.FindWebsite(context.Background(), web.UUID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, "updated title", stored.Title)
+		assert.Equal(t, "updated content", stored.RawContent)
+		assert.Equal(t, accessTime, stored.UpdateTime)
+	}
+
+	notExist := model.Website{UUID: uniqueID("not exist")}
+	err = r.UpdateWebsite(context.Background(), &notExist)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteWebsite(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	web := createWebsite(t, r, "delete website")
+
+	err := r.DeleteWebsite(context.Background(), &web)
+	assert.NoError(t, err)
+
+	_, err = r.FindWebsite(context.Background(), web.UUID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	notExist := model.Website{UUID: uniqueID("not exist")}
+	err = r.DeleteWebsite(context.Background(), &notExist)
+	assert.NoError(t, err)
+}
+
+func testFindWebsites(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	active := createWebsite(t, r, "find websites")
+	gone := createWebsite(t, r, "find websites gone")
+	merged := createWebsite(t, r, "find websites merged")
+
+	if err := r.RecordWebsiteMissing(context.Background(), &gone, 1); err != nil {
+		t.Fatalf("record website missing fail: %v", err)
+	}
+
+	target := active
+	if err := r.MergeWebsite(context.Background(), &merged, &target); err != nil {
+		t.Fatalf("merge website fail: %v", err)
+	}
+
+	webs, err := r.FindWebsites(context.Background())
+	assert.NoError(t, err)
+
+	uuids := websiteUUIDs(webs)
+	assert.Contains(t, uuids, active.UUID)
+	assert.NotContains(t, uuids, gone.UUID)
+	assert.NotContains(t, uuids, merged.UUID)
+}
+
+func testFindWebsite(t *testing.T, newRepo NewRepoFunc) {
+	conf := &config.WebsiteConfig{}
+	r := newRepo(t, conf)
+	active := createWebsite(t, r, "find website")
+	gone := createWebsite(t, r, "find website gone")
+	merged := createWebsite(t, r, "find website merged")
+
+	if err := r.RecordWebsiteMissing(context.Background(), &gone, 1); err != nil {
+		t.Fatalf("record website missing fail: %v", err)
+	}
+
+	target := active
+	if err := r.MergeWebsite(context.Background(), &merged, &target); err != nil {
+		t.Fatalf("merge website fail: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		uuid        string
+		expect      *model.Website
+		expectError error
+	}{
+		{
+			name: "find active website",
+			uuid: active.UUID,
+			expect: &model.Website{
+				UUID:       active.UUID,
+				URL:        active.URL,
+				Title:      active.Title,
+				RawContent: active.RawContent,
+				UpdateTime: updateTime,
+				Status:     model.WebsiteStatusActive,
+				Conf:       conf,
+			},
+		},
+		{
+			name: "find gone website",
+			uuid: gone.UUID,
+			expect: &model.Website{
+				UUID:         gone.UUID,
+				URL:          gone.URL,
+				Title:        gone.Title,
+				RawContent:   gone.RawContent,
+				UpdateTime:   updateTime,
+				Status:       model.WebsiteStatusGone,
+				MissingCount: 1,
+				Conf:         conf,
+			},
+		},
+		{
+			name:        "find merged website",
+			uuid:        merged.UUID,
+			expectError: sql.ErrNoRows,
+		},
+		{
+			name:        "find not exist website",
+			uuid:        uniqueID("not exist"),
+			expectError: sql.ErrNoRows,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			web, err := r.FindWebsite(context.Background(), test.uuid)
+			assert.ErrorIs(t, err, test.expectError)
+			assert.Equal(t, test.expect, web)
+		})
+	}
+}
+
+func testFindWebsitesPage(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	host := uniqueID("host") + ".test"
+
+	var expected []string
+	for _, url := range []string{
+		"http://" + host + "/a",
+		"https://www." + host + "/b",
+		"https://m.www." + host,
+		"https://" + host + "/c?from=other.test",
+	} {
+		web := model.Website{UUID: uniqueID("find websites page"), URL: url, UpdateTime: updateTime}
+		if err := r.CreateWebsite(context.Background(), &web); err != nil {
+			t.Fatalf("create website fail: %v", err)
+		}
+
+		expected = append(expected, web.UUID)
+	}
+	slices.Sort(expected)
+
+	for _, url := range []string{
+		"http://other" + host + "/a",
+		"http://other.test/" + host + "/",
+	} {
+		web := model.Website{UUID: uniqueID("find websites page other"), URL: url}
+		if err := r.CreateWebsite(context.Background(), &web); err != nil {
+			t.Fatalf("create website fail: %v", err)
+		}
+	}
+
+	gone := model.Website{UUID: uniqueID("find websites page gone"), URL: "http://" + host + "/gone"}
+	if err := r.CreateWebsite(context.Background(), &gone); err != nil {
+		t.Fatalf("create website fail: %v", err)
+	}
+	if err := r.RecordWebsiteMissing(context.Background(), &gone, 1); err != nil {
+		t.Fatalf("record website missing fail: %v", err)
+	}
+
+	t.Run("list first page of host", func(t *testing.T) {
+		webs, err := r.FindWebsitesPage(context.Background(), repository.WebsitesQuery{Host: host, Limit: 3})
+		assert.NoError(t, err)
+		assert.Equal(t, expected[:3], websiteUUIDs(webs))
+	})
+
+	t.Run("list page after uuid", func(t *testing.T) {
+		webs, err := r.FindWebsitesPage(context.Background(), repository.WebsitesQuery{
+			Host:      host,
+			AfterUUID: expected[1],
+			Limit:     3,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, expected[2:], websiteUUIDs(webs))
+	})
+
+	t.Run("iterate all pages", func(t *testing.T) {
+		var uuids []string
+		for web, err := range repository.IterateWebsites(context.Background(), r, repository.WebsitesQuery{Host: host, Limit: 2}) {
+			assert.NoError(t, err)
+			uuids = append(uuids, web.UUID)
+		}
+		assert.Equal(t, expected, uuids)
+	})
+
+	t.Run("list websites of all hosts", func(t *testing.T) {
+		webs, err := r.FindWebsitesPage(context.Background(), repository.WebsitesQuery{Limit: 1000000})
+		assert.NoError(t, err)
+		assert.Subset(t, websiteUUIDs(webs), expected)
+		assert.NotContains(t, websiteUUIDs(webs), gone.UUID)
+		assert.True(t, slices.IsSorted(websiteUUIDs(webs)))
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, err := r.FindWebsitesPage(context.Background(), repository.WebsitesQuery{Host: host})
+		assert.ErrorIs(t, err, repository.ErrInvalidLimit)
+	})
+}
+
+func testMergeWebsite(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	from := createWebsite(t, r, "merge website from")
+	to := createWebsite(t, r, "merge website to")
+	userUUID, sharedUserUUID := uniqueID("merge-user"), uniqueID("merge-shared-user")
+
+	createUserWebsite(t, r, from, userUUID)
+	sharedFrom := createUserWebsite(t, r, from, sharedUserUUID)
+	sharedTo := createUserWebsite(t, r, to, sharedUserUUID)
+	addTag(t, r, userUUID, from.UUID, "merged")
+	addTag(t, r, sharedUserUUID, from.UUID, "merged")
+	addTag(t, r, sharedUserUUID, from.UUID, "from")
+	addTag(t, r, sharedUserUUID, to.UUID, "merged")
+
+	// user data of subscription to from website is kept in the merged one
+	sharedFrom.GroupName, sharedFrom.DisplayTitle, sharedFrom.Note = "merge group", "merge display title", "merge note"
+	sharedFrom.AccessTime = accessTime.Add(time.Hour)
+	if err := r.UpdateUserWebsite(context.Background(), &sharedFrom); err != nil {
+		t.Fatalf("update user website fail: %v", err)
+	}
+
+	sharedTo.LastReadChapter = "merge chapter"
+	if err := r.UpdateUserWebsite(context.Background(), &sharedTo); err != nil {
+		t.Fatalf("update user website fail: %v", err)
+	}
+
+	from.RawContent, from.UpdateTime = "newer content", accessTime
+	if err := r.UpdateWebsite(context.Background(), &from); err != nil {
+		t.Fatalf("update website fail: %v", err)
+	}
+
+	err := r.MergeWebsite(context.Background(), &from, &to)
+	assert.NoError(t, err)
+	assert.Equal(t, "merge website to", to.Title)
+	assert.Equal(t, "newer content", to.RawContent)
+	assert.Equal(t, accessTime, to.UpdateTime)
+	assert.Equal(t, model.WebsiteStatusInactive, from.Status)
+	assert.Equal(t, to.UUID, from.RedirectUUID)
+
+	_, err = r.FindWebsite(context.Background(), from.UUID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	for _, user := range []string{userUUID, sharedUserUUID} {
+		webs, err := r.FindUserWebsites(context.Background(), user)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{to.UUID}, userWebsiteUUIDs(webs), user)
+	}
+
+	merged, err := r.FindUserWebsite(context.Background(), sharedUserUUID, to.UUID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "merge group", merged.GroupName)
+		assert.Equal(t, "merge display title", merged.DisplayTitle)
+		assert.Equal(t, "merge note", merged.Note)
+		assert.Equal(t, "merge chapter", merged.LastReadChapter)
+		assert.Equal(t, accessTime.Add(time.Hour), merged.AccessTime)
+	}
+
+	assert.Equal(t, []string{"merged"}, websiteTags(t, r, userUUID, to.UUID))
+	assert.Equal(t, []string{"from", "merged"}, websiteTags(t, r, sharedUserUUID, to.UUID))
+	assert.Equal(t, []string{}, websiteTags(t, r, sharedUserUUID, from.UUID))
+
+	recreated := model.Website{UUID: uniqueID("merge website"), URL: from.URL}
+	err = r.CreateWebsite(context.Background(), &recreated)
+	assert.NoError(t, err)
+	assert.Equal(t, to.UUID, recreated.UUID)
+	assert.Equal(t, to.URL, recreated.URL)
+}
+
+func testRecordWebsiteMissing(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	web := createWebsite(t, r, "record website missing")
+
+	err := r.RecordWebsiteMissing(context.Background(), &web, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebsiteStatusActive, web.Status)
+	assert.Equal(t, 1, web.MissingCount)
+
+	err = r.RecordWebsiteMissing(context.Background(), &web, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebsiteStatusGone, web.Status)
+	assert.Equal(t, 2, web.MissingCount)
+
+	err = r.ResetWebsiteMissing(context.Background(), &web)
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebsiteStatusActive, web.Status)
+	assert.Equal(t, 0, web.MissingCount)
+
+	notExist := model.Website{UUID: uniqueID("not exist")}
+	err = r.RecordWebsiteMissing(context.Background(), &notExist, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = r.ResetWebsiteMissing(context.Background(), &notExist)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testCleanupOrphanedWebsites(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	subscribed := createWebsite(t, r, "cleanup orphaned subscribed")
+	orphan := createWebsite(t, r, "cleanup orphaned orphan")
+	createUserWebsite(t, r, subscribed, uniqueID("cleanup-orphaned-user"))
+
+	// run in a rolled back transaction so websites of other tests stay active
+	errRollback := errors.New("rollback")
+	now := time.Now().UTC()
+	err := r.WithTx(context.Background(), func(txRepo repository.Repository) error {
+		result, err := txRepo.CleanupOrphanedWebsites(context.Background(), now, time.Hour)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, result.Marked, 1)
+
+		_, err = txRepo.FindWebsite(context.Background(), orphan.UUID)
+		assert.NoError(t, err, "orphan stays active within grace period")
+
+		result, err = txRepo.CleanupOrphanedWebsites(context.Background(), now.Add(2*time.Hour), time.Hour)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, result.Deactivated, 1)
+		assert.GreaterOrEqual(t, result.FetchesSaved, 1)
+
+		_, err = txRepo.FindWebsite(context.Background(), orphan.UUID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		webs, err := txRepo.FindWebsites(context.Background())
+		assert.NoError(t, err)
+		assert.NotContains(t, websiteUUIDs(webs), orphan.UUID)
+		assert.Contains(t, websiteUUIDs(webs), subscribed.UUID)
+
+		userWeb := createUserWebsite(t, txRepo, orphan, uniqueID("cleanup-orphaned-resubscribe-user"))
+		assert.Equal(t, model.WebsiteStatusActive, userWeb.Website.Status)
+
+		_, err = txRepo.CleanupOrphanedWebsites(context.Background(), now.Add(4*time.Hour), time.Hour)
+		assert.NoError(t, err)
+
+		_, err = txRepo.FindWebsite(context.Background(), orphan.UUID)
+		assert.NoError(t, err, "resubscribed website stays active")
+
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+}
+
+func testCreateUserWebsite(t *testing.T, newRepo NewRepoFunc) {
+	conf := &config.WebsiteConfig{}
+	r := newRepo(t, conf)
+	web := createWebsite(t, r, "create user website")
+	userUUID := uniqueID("create-user-website-user")
+	existing := createUserWebsite(t, r, web, userUUID)
+
+	expectWebsite := model.Website{
+		UUID:       web.UUID,
+		URL:        web.URL,
+		Title:      web.Title,
+		RawContent: web.RawContent,
+		UpdateTime: updateTime,
+		Status:     model.WebsiteStatusActive,
+		Conf:       conf,
+	}
+
+	tests := []struct {
+		name        string
+		web         model.UserWebsite
+		expect      model.UserWebsite
+		expectError bool
+	}{
+		{
+			name: "create a new user website",
+			web: model.UserWebsite{
+				WebsiteUUID: web.UUID,
+				UserUUID:    userUUID + "-new",
+				GroupName:   "new group",
+				AccessTime:  updateTime,
+			},
+			expect: model.UserWebsite{
+				WebsiteUUID: web.UUID,
+				UserUUID:    userUUID + "-new",
+				GroupName:   "new group",
+				AccessTime:  updateTime,
+				Website:     expectWebsite,
+			},
+		},
+		{
+			name: "create an existing user website returns stored record",
+			web: model.UserWebsite{
+				WebsiteUUID: web.UUID,
+				UserUUID:    userUUID,
+				GroupName:   "other group",
+				AccessTime:  updateTime,
+			},
+			expect: model.UserWebsite{
+				WebsiteUUID: web.UUID,
+				UserUUID:    userUUID,
+				GroupName:   existing.GroupName,
+				AccessTime:  accessTime,
+				Website:     expectWebsite,
+			},
+		},
+		{
+			name: "create user website of not exist website",
+			web: model.UserWebsite{
+				WebsiteUUID: uniqueID("not exist"),
+				UserUUID:    userUUID,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := r.CreateUserWebsite(context.Background(), &test.web)
+			if test.expectError {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expect, test.web)
+		})
+	}
+}
+
+func testUpdateUserWebsite(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	web := createWebsite(t, r, "update user website")
+	userWeb := createUserWebsite(t, r, web, uniqueID("update-user-website-user"))
+
+	userWeb.GroupName = "updated group"
+	userWeb.DisplayTitle = "display title"
+	userWeb.Note = "note"
+	userWeb.LastReadChapter = "chapter"
+	userWeb.AccessTime = updateTime
+
+	err := r.UpdateUserWebsite(context.Background(), &userWeb)
+	assert.NoError(t, err)
+
+	stored, err := r.FindUserWebsite(context.Background(), userWeb.UserUUID, web.UUID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, "updated group", stored.GroupName)
+		assert.Equal(t, "display title", stored.DisplayTitle)
+		assert.Equal(t, "note", stored.Note)
+		assert.Equal(t, "chapter", stored.LastReadChapter)
+		assert.Equal(t, updateTime, stored.AccessTime)
+	}
+
+	notExist := model.UserWebsite{WebsiteUUID: web.UUID, UserUUID: uniqueID("not exist")}
+	err = r.UpdateUserWebsite(context.Background(), &notExist)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteUserWebsite(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	web := createWebsite(t, r, "delete user website")
+	userWeb := createUserWebsite(t, r, web, uniqueID("delete-user-website-user"))
+	addTag(t, r, userWeb.UserUUID, web.UUID, "deleted")
+
+	err := r.DeleteUserWebsite(context.Background(), &userWeb)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, websiteTags(t, r, userWeb.UserUUID, web.UUID))
+
+	_, err = r.FindUserWebsite(context.Background(), userWeb.UserUUID, web.UUID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = r.FindWebsite(context.Background(), web.UUID)
+	assert.NoError(t, err)
+}
+
+func setUserGroup(t *testing.T, r repository.Repository, userWeb *model.UserWebsite, group string) {
+	t.Helper()
+
+	userWeb.GroupName = group
+	if err := r.UpdateUserWebsite(context.Background(), userWeb); err != nil {
+		t.Fatalf("update user website fail: %v", err)
+	}
+}
+
+func userGroupNames(t *testing.T, r repository.Repository, userUUID string) map[string]string {
+	t.Helper()
+
+	webs, err := r.FindUserWebsites(context.Background(), userUUID)
+	if err != nil {
+		t.Fatalf("find user websites fail: %v", err)
+	}
+
+	groups := make(map[string]string, len(webs))
+	for _, web := range webs {
+		groups[web.WebsiteUUID] = web.GroupName
+	}
+
+	return groups
+}
+
+func testRenameUserGroup(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("rename-user-group-user")
+	otherUserUUID := uniqueID("rename-user-group-other-user")
+	group := uniqueID("rename user group")
+
+	first := createUserWebsite(t, r, createWebsite(t, r, "rename user group first"), userUUID)
+	second := createUserWebsite(t, r, createWebsite(t, r, "rename user group second"), userUUID)
+	existing := createUserWebsite(t, r, createWebsite(t, r, "rename user group existing"), userUUID)
+	otherUser := createUserWebsite(t, r, createWebsite(t, r, "rename user group other user"), otherUserUUID)
+	for _, userWeb := range []*model.UserWebsite{&first, &second, &otherUser} {
+		setUserGroup(t, r, userWeb, group)
+	}
+
+	err := r.RenameUserGroup(context.Background(), userUUID, group, existing.GroupName)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		first.WebsiteUUID:    existing.GroupName,
+		second.WebsiteUUID:   existing.GroupName,
+		existing.WebsiteUUID: existing.GroupName,
+	}, userGroupNames(t, r, userUUID))
+	assert.Equal(t, map[string]string{otherUser.WebsiteUUID: group}, userGroupNames(t, r, otherUserUUID))
+}
+
+func testUngroupUserGroup(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("ungroup-user-group-user")
+	group := uniqueID("ungroup user group")
+
+	first := createUserWebsite(t, r, createWebsite(t, r, "ungroup user group first"), userUUID)
+	second := createUserWebsite(t, r, createWebsite(t, r, "ungroup user group second"), userUUID)
+	other := createUserWebsite(t, r, createWebsite(t, r, "ungroup user group other"), userUUID)
+	for _, userWeb := range []*model.UserWebsite{&first, &second} {
+		setUserGroup(t, r, userWeb, group)
+	}
+	setUserGroup(t, r, &other, "other group")
+
+	err := r.UngroupUserGroup(context.Background(), userUUID, group)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		first.WebsiteUUID:  "ungroup user group first",
+		second.WebsiteUUID: "ungroup user group second",
+		other.WebsiteUUID:  "other group",
+	}, userGroupNames(t, r, userUUID))
+}
+
+func testDeleteUserGroup(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("delete-user-group-user")
+	otherUserUUID := uniqueID("delete-user-group-other-user")
+	group := uniqueID("delete user group")
+
+	deleted := createWebsite(t, r, "delete user group deleted")
+	first := createUserWebsite(t, r, deleted, userUUID)
+	other := createUserWebsite(t, r, createWebsite(t, r, "delete user group other"), userUUID)
+	otherUser := createUserWebsite(t, r, createWebsite(t, r, "delete user group other user"), otherUserUUID)
+	for _, userWeb := range []*model.UserWebsite{&first, &otherUser} {
+		setUserGroup(t, r, userWeb, group)
+		addTag(t, r, userWeb.UserUUID, userWeb.WebsiteUUID, "tag")
+	}
+	addTag(t, r, userUUID, other.WebsiteUUID, "tag")
+
+	err := r.DeleteUserGroup(context.Background(), userUUID, group)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, websiteTags(t, r, userUUID, deleted.UUID))
+	assert.Equal(t, []string{"tag"}, websiteTags(t, r, userUUID, other.WebsiteUUID))
+	assert.Equal(t, []string{"tag"}, websiteTags(t, r, otherUserUUID, otherUser.WebsiteUUID))
+
+	assert.Equal(t, map[string]string{other.WebsiteUUID: other.GroupName}, userGroupNames(t, r, userUUID))
+	assert.Equal(t, map[string]string{otherUser.WebsiteUUID: group}, userGroupNames(t, r, otherUserUUID))
+
+	_, err = r.FindWebsite(context.Background(), deleted.UUID)
+	assert.NoError(t, err)
+}
+
+func testRefreshUserWebsites(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("refresh-user-websites-user")
+	otherUserUUID := uniqueID("refresh-user-websites-other-user")
+	group := uniqueID("refresh user websites")
+	refreshTime := accessTime.Add(24 * time.Hour)
+
+	first := createUserWebsite(t, r, createWebsite(t, r, "refresh user websites first"), userUUID)
+	second := createUserWebsite(t, r, createWebsite(t, r, "refresh user websites second"), userUUID)
+	other := createUserWebsite(t, r, createWebsite(t, r, "refresh user websites other"), userUUID)
+	otherUser := createUserWebsite(t, r, createWebsite(t, r, "refresh user websites other user"), otherUserUUID)
+	for _, userWeb := range []*model.UserWebsite{&first, &second, &otherUser} {
+		setUserGroup(t, r, userWeb, group)
+	}
+
+	accessTimes := func(userUUID string) map[string]time.Time {
+		t.Helper()
+
+		webs, err := r.FindUserWebsites(context.Background(), userUUID)
+		assert.NoError(t, err)
+
+		times := make(map[string]time.Time, len(webs))
+		for _, web := range webs {
+			times[web.WebsiteUUID] = web.AccessTime
+		}
+
+		return times
+	}
+
+	updated, err := r.RefreshUserWebsites(context.Background(), userUUID, group, refreshTime)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, updated)
+	assert.Equal(t, map[string]time.Time{
+		first.WebsiteUUID:  refreshTime,
+		second.WebsiteUUID: refreshTime,
+		other.WebsiteUUID:  accessTime,
+	}, accessTimes(userUUID))
+
+	updated, err = r.RefreshUserWebsites(context.Background(), userUUID, "", refreshTime.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, updated)
+	assert.Equal(t, map[string]time.Time{
+		first.WebsiteUUID:  refreshTime.Add(time.Hour),
+		second.WebsiteUUID: refreshTime.Add(time.Hour),
+		other.WebsiteUUID:  refreshTime.Add(time.Hour),
+	}, accessTimes(userUUID))
+	assert.Equal(t, map[string]time.Time{otherUser.WebsiteUUID: accessTime}, accessTimes(otherUserUUID))
+
+	updated, err = r.RefreshUserWebsites(context.Background(), userUUID, uniqueID("not exist group"), refreshTime)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, updated)
+}
+
+func testFindUserUnreadSummary(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("unread-summary-user")
+	otherUserUUID := uniqueID("unread-summary-other-user")
+	group, otherGroup := uniqueID("unread summary group"), uniqueID("unread summary other group")
+
+	createUnread := func(title, userUUID, group string, updated time.Duration) model.UserWebsite {
+		t.Helper()
+
+		web := createWebsite(t, r, title)
+		web.UpdateTime = accessTime.Add(updated)
+		if err := r.UpdateWebsite(context.Background(), &web); err != nil {
+			t.Fatalf("update website fail: %v", err)
+		}
+
+		userWeb := createUserWebsite(t, r, web, userUUID)
+		setUserGroup(t, r, &userWeb, group)
+
+		return userWeb
+	}
+
+	first := createUnread("unread summary first", userUUID, group, time.Hour)
+	second := createUnread("unread summary second", userUUID, group, 2*time.Hour)
+	third := createUnread("unread summary third", userUUID, otherGroup, 3*time.Hour)
+	third.DisplayTitle = "display title"
+	if err := r.UpdateUserWebsite(context.Background(), &third); err != nil {
+		t.Fatalf("update user website fail: %v", err)
+	}
+	createUserWebsite(t, r, createWebsite(t, r, "unread summary read"), userUUID)
+	createUnread("unread summary other user", otherUserUUID, group, time.Hour)
+
+	summary, err := r.FindUserUnreadSummary(context.Background(), userUUID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, model.UnreadSummary{
+		UnreadCount: 3,
+		Groups: []model.GroupUnreadCount{
+			{GroupName: group, UnreadCount: 2},
+			{GroupName: otherGroup, UnreadCount: 1},
+		},
+		LatestUnread: []model.UnreadWebsite{
+			{WebsiteUUID: third.WebsiteUUID, GroupName: otherGroup, Title: "display title", UpdateTime: accessTime.Add(3 * time.Hour)},
+			{WebsiteUUID: second.WebsiteUUID, GroupName: group, Title: "unread summary second", UpdateTime: accessTime.Add(2 * time.Hour)},
+		},
+	}, summary)
+
+	_, err = r.RefreshUserWebsites(context.Background(), userUUID, group, accessTime.Add(24*time.Hour))
+	assert.NoError(t, err)
+
+	summary, err = r.FindUserUnreadSummary(context.Background(), userUUID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.UnreadCount)
+	assert.Equal(t, []model.GroupUnreadCount{{GroupName: otherGroup, UnreadCount: 1}}, summary.Groups)
+	assert.Len(t, summary.LatestUnread, 1)
+	assert.NotContains(t, []string{first.WebsiteUUID, second.WebsiteUUID}, summary.LatestUnread[0].WebsiteUUID)
+
+	summary, err = r.FindUserUnreadSummary(context.Background(), uniqueID("unread-summary-no-website-user"), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, model.UnreadSummary{Groups: []model.GroupUnreadCount{}, LatestUnread: []model.UnreadWebsite{}}, summary)
+}
+
+func testFindUserWebsites(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("find-user-websites-user")
+
+	read := createWebsite(t, r, "find user websites read")
+	unreadOld := createWebsite(t, r, "find user websites unread old")
+	unreadNew := createWebsite(t, r, "find user websites unread new")
+	gone := createWebsite(t, r, "find user websites gone")
+	merged := createWebsite(t, r, "find user websites merged")
+	other := createWebsite(t, r, "find user websites other")
+
+	for _, web := range []model.Website{read, unreadOld, unreadNew, gone, merged} {
+		createUserWebsite(t, r, web, userUUID)
+	}
+	createUserWebsite(t, r, other, uniqueID("find-user-websites-other-user"))
+
+	unreadOld.UpdateTime = accessTime.Add(time.Hour)
+	unreadNew.UpdateTime = accessTime.Add(2 * time.Hour)
+	for _, web := range []*model.Website{&unreadOld, &unreadNew} {
+		if err := r.UpdateWebsite(context.Background(), web); err != nil {
+			t.Fatalf("update website fail: %v", err)
+		}
+	}
+
+	if err := r.RecordWebsiteMissing(context.Background(), &gone, 1); err != nil {
+		t.Fatalf("record website missing fail: %v", err)
+	}
+
+	target := other
+	if err := r.MergeWebsite(context.Background(), &merged, &target); err != nil {
+		t.Fatalf("merge website fail: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		userUUID string
+		expect   []string
+	}{
+		{
+			name:     "unread websites first then latest update",
+			userUUID: userUUID,
+			expect:   []string{unreadNew.UUID, unreadOld.UUID, other.UUID, read.UUID, gone.UUID},
+		},
+		{
+			name:     "not exist user",
+			userUUID: uniqueID("not exist"),
+			expect:   []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			webs, err := r.FindUserWebsites(context.Background(), test.userUUID)
+			assert.NoError(t, err)
+			assert.NotNil(t, webs)
+			assert.ElementsMatch(t, test.expect, userWebsiteUUIDs(webs))
+			if len(webs) >= 2 {
+				assert.Equal(t, test.expect[:2], userWebsiteUUIDs(webs)[:2])
+			}
+		})
+	}
+}
+
+func testFindUserWebsitesByGroup(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("find-user-websites-group-user")
+	group := uniqueID("find user websites group")
+
+	first := createWebsite(t, r, group)
+	second := createWebsite(t, r, group)
+	merged := createWebsite(t, r, group)
+	other := createWebsite(t, r, "find user websites group other")
+	target := createWebsite(t, r, "find user websites group target")
+
+	for _, web := range []model.Website{first, second, merged, other} {
+		createUserWebsite(t, r, web, userUUID)
+	}
+
+	if err := r.MergeWebsite(context.Background(), &merged, &target); err != nil {
+		t.Fatalf("merge website fail: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		userUUID string
+		group    string
+		expect   []string
+	}{
+		{
+			name:     "find websites of existing group",
+			userUUID: userUUID,
+			group:    group,
+			expect:   []string{first.UUID, second.UUID, target.UUID},
+		},
+		{
+			name:     "find websites of not exist group",
+			userUUID: userUUID,
+			group:    uniqueID("not exist"),
+			expect:   []string{},
+		},
+		{
+			name:     "find websites of not exist user",
+			userUUID: uniqueID("not exist"),
+			group:    group,
+			expect:   []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			webs, err := r.FindUserWebsitesByGroup(context.Background(), test.userUUID, test.group)
+			assert.NoError(t, err)
+			assert.NotNil(t, webs)
+			assert.ElementsMatch(t, test.expect, userWebsiteUUIDs(webs))
+			for _, web := range webs {
+				assert.Equal(t, test.group, web.GroupName)
+			}
+		})
+	}
+}
+
+func testFindUserWebsitesPage(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("find-user-websites-page-user")
+	group := uniqueID("find user websites page group")
+
+	webs := make([]model.Website, 5)
+	for i, setting := range []struct {
+		title        string
+		displayTitle string
+		group        string
+		updateTime   time.Time
+		accessTime   time.Time
+	}{
+		{title: "page c", updateTime: accessTime.Add(6 * time.Hour), accessTime: accessTime.Add(7 * time.Hour)},
+		{title: "page a", group: group, updateTime: updateTime, accessTime: accessTime.Add(4 * time.Hour)},
+		{title: "page e", updateTime: accessTime.Add(time.Hour), accessTime: accessTime},
+		{title: "page b", group: group, updateTime: accessTime.Add(3 * time.Hour), accessTime: accessTime.Add(-time.Hour)},
+		{title: "page d", displayTitle: "page 0", updateTime: accessTime.Add(2 * time.Hour), accessTime: accessTime.Add(-2 * time.Hour)},
+	} {
+		webs[i] = createWebsite(t, r, setting.title)
+		userWeb := createUserWebsite(t, r, webs[i], userUUID)
+
+		webs[i].UpdateTime = setting.updateTime
+		if err := r.UpdateWebsite(context.Background(), &webs[i]); err != nil {
+			t.Fatalf("update website fail: %v", err)
+		}
+
+		userWeb.DisplayTitle, userWeb.AccessTime = setting.displayTitle, setting.accessTime
+		if setting.group != "" {
+			userWeb.GroupName = setting.group
+		}
+
+		if err := r.UpdateUserWebsite(context.Background(), &userWeb); err != nil {
+			t.Fatalf("update user website fail: %v", err)
+		}
+	}
+
+	createUserWebsite(t, r, createWebsite(t, r, "page other user"), uniqueID("find-user-websites-page-other-user"))
+
+	addTag(t, r, userUUID, webs[1].UUID, "first")
+	addTag(t, r, userUUID, webs[2].UUID, "first")
+	addTag(t, r, userUUID, webs[2].UUID, "second")
+	addTag(t, r, userUUID, webs[3].UUID, "second")
+
+	tests := []struct {
+		name   string
+		query  repository.UserWebsitesQuery
+		expect []string
+	}{
+		{
+			name:   "sort by unread",
+			query:  repository.UserWebsitesQuery{UserUUID: userUUID, SortBy: repository.SortByUnread, Limit: 2},
+			expect: []string{webs[3].UUID, webs[4].UUID, webs[2].UUID, webs[0].UUID, webs[1].UUID},
+		},
+		{
+			name:   "sort by unread as default",
+			query:  repository.UserWebsitesQuery{UserUUID: userUUID, Limit: 2},
+			expect: []string{webs[3].UUID, webs[4].UUID, webs[2].UUID, webs[0].UUID, webs[1].UUID},
+		},
+		{
+			name:   "sort by update time",
+			query:  repository.UserWebsitesQuery{UserUUID: userUUID, SortBy: repository.SortByUpdateTime, Limit: 2},
+			expect: []string{webs[0].UUID, webs[3].UUID, webs[4].UUID, webs[2].UUID, webs[1].UUID},
+		},
+		{
+			name:   "sort by access time",
+			query:  repository.UserWebsitesQuery{UserUUID: userUUID, SortBy: repository.SortByAccessTime, Limit: 2},
+			expect: []string{webs[0].UUID, webs[1].UUID, webs[2].UUID, webs[3].UUID, webs[4].UUID},
+		},
+		{
+			name:   "sort by title",
+			query:  repository.UserWebsitesQuery{UserUUID: userUUID, SortBy: repository.SortByTitle, Limit: 2},
+			expect: []string{webs[4].UUID, webs[1].UUID, webs[3].UUID, webs[0].UUID, webs[2].UUID},
+		},
+		{
+			name:   "filter by group",
+			query:  repository.UserWebsitesQuery{UserUUID: userUUID, GroupName: group, SortBy: repository.SortByTitle, Limit: 1},
+			expect: []string{webs[1].UUID, webs[3].UUID},
+		},
+		{
+			name:   "filter by tag",
+			query:  repository.UserWebsitesQuery{UserUUID: userUUID, Tags: []string{"first"}, SortBy: repository.SortByTitle, Limit: 1},
+			expect: []string{webs[1].UUID, webs[2].UUID},
+		},
+		{
+			name:   "filter by all tags",
+			query:  repository.UserWebsitesQuery{UserUUID: userUUID, Tags: []string{"second", "first", "second"}, SortBy: repository.SortByTitle, Limit: 1},
+			expect: []string{webs[2].UUID},
+		},
+		{
+			name:   "filter by group and tag",
+			query:  repository.UserWebsitesQuery{UserUUID: userUUID, GroupName: group, Tags: []string{"second"}, Limit: 2},
+			expect: []string{webs[3].UUID},
+		},
+		{
+			name:   "filter by not exist tag",
+			query:  repository.UserWebsitesQuery{UserUUID: userUUID, Tags: []string{"not exist"}, Limit: 2},
+			expect: []string{},
+		},
+		{
+			name:   "page larger than result",
+			query:  repository.UserWebsitesQuery{UserUUID: userUUID, SortBy: repository.SortByAccessTime, Limit: 10},
+			expect: []string{webs[0].UUID, webs[1].UUID, webs[2].UUID, webs[3].UUID, webs[4].UUID},
+		},
+		{
+			name:   "not exist user",
+			query:  repository.UserWebsitesQuery{UserUUID: uniqueID("not exist"), Limit: 2},
+			expect: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := []string{}
+			pages := 0
+			for {
+				page, nextCursor, err := r.FindUserWebsitesPage(context.Background(), test.query)
+				assert.NoError(t, err)
+				assert.NotNil(t, page)
+				assert.LessOrEqual(t, len(page), test.query.Limit)
+
+				result = append(result, userWebsiteUUIDs(page)...)
+				pages++
+				if nextCursor == "" || err != nil || pages > len(test.expect) {
+					break
+				}
+
+				test.query.Cursor = nextCursor
+			}
+
+			assert.Equal(t, test.expect, result)
+		})
+	}
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, nextCursor, err := r.FindUserWebsitesPage(context.Background(), repository.UserWebsitesQuery{UserUUID: userUUID, Limit: 2})
+		assert.NoError(t, err)
+
+		_, _, err = r.FindUserWebsitesPage(context.Background(), repository.UserWebsitesQuery{UserUUID: userUUID, SortBy: "unknown", Limit: 2})
+		assert.ErrorIs(t, err, repository.ErrInvalidSortKey)
+
+		_, _, err = r.FindUserWebsitesPage(context.Background(), repository.UserWebsitesQuery{UserUUID: userUUID})
+		assert.ErrorIs(t, err, repository.ErrInvalidLimit)
+
+		_, _, err = r.FindUserWebsitesPage(context.Background(), repository.UserWebsitesQuery{UserUUID: userUUID, Cursor: "invalid", Limit: 2})
+		assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+
+		_, _, err = r.FindUserWebsitesPage(context.Background(), repository.UserWebsitesQuery{UserUUID: userUUID, SortBy: repository.SortByTitle, Cursor: nextCursor, Limit: 2})
+		assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+	})
+
+	t.Run("times within min time unit", func(t *testing.T) {
+		closeUserUUID := uniqueID("find-user-websites-page-close-user")
+		base := accessTime.Add(24 * time.Hour)
+
+		expect := make([]string, 4)
+		for i := range expect {
+			web := createWebsite(t, r, "page close")
+			userWeb := createUserWebsite(t, r, web, closeUserUUID)
+
+			web.UpdateTime = base.Add(time.Duration(i) * time.Second)
+			if err := r.UpdateWebsite(context.Background(), &web); err != nil {
+				t.Fatalf("update website fail: %v", err)
+			}
+
+			userWeb.AccessTime = base.Add(time.Duration(3-i) * time.Second)
+			if err := r.UpdateUserWebsite(context.Background(), &userWeb); err != nil {
+				t.Fatalf("update user website fail: %v", err)
+			}
+
+			expect[i] = web.UUID
+		}
+
+		for _, sortBy := range []string{repository.SortByUnread, repository.SortByUpdateTime, repository.SortByAccessTime} {
+			query := repository.UserWebsitesQuery{UserUUID: closeUserUUID, SortBy: sortBy, Limit: 1}
+			result := []string{}
+			for range len(expect) + 1 {
+				page, nextCursor, err := r.FindUserWebsitesPage(context.Background(), query)
+				assert.NoError(t, err)
+
+				result = append(result, userWebsiteUUIDs(page)...)
+				if nextCursor == "" || err != nil {
+					break
+				}
+
+				query.Cursor = nextCursor
+			}
+
+			assert.ElementsMatch(t, expect, result, sortBy)
+		}
+	})
+}
+
+func testFindUserWebsite(t *testing.T, newRepo NewRepoFunc) {
+	conf := &config.WebsiteConfig{}
+	r := newRepo(t, conf)
+	userUUID := uniqueID("find-user-website-user")
+	web := createWebsite(t, r, "find user website")
+	merged := createWebsite(t, r, "find user website merged")
+	target := createWebsite(t, r, "find user website target")
+
+	createUserWebsite(t, r, web, userUUID)
+	createUserWebsite(t, r, merged, userUUID)
+
+	if err := r.MergeWebsite(context.Background(), &merged, &target); err != nil {
+		t.Fatalf("merge website fail: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		userUUID    string
+		websiteUUID string
+		expect      *model.UserWebsite
+		expectError error
+	}{
+		{
+			name:        "find existing user website",
+			userUUID:    userUUID,
+			websiteUUID: web.UUID,
+			expect: &model.UserWebsite{
+				WebsiteUUID: web.UUID,
+				UserUUID:    userUUID,
+				GroupName:   web.Title,
+				AccessTime:  accessTime,
+				Website: model.Website{
+					UUID:       web.UUID,
+					URL:        web.URL,
+					Title:      web.Title,
+					RawContent: web.RawContent,
+					UpdateTime: updateTime,
+					Status:     model.WebsiteStatusActive,
+					Conf:       conf,
+				},
+			},
+		},
+		{
+			name:        "find user website of merged website",
+			userUUID:    userUUID,
+			websiteUUID: merged.UUID,
+			expectError: sql.ErrNoRows,
+		},
+		{
+			name:        "find user website of not exist user",
+			userUUID:    uniqueID("not exist"),
+			websiteUUID: web.UUID,
+			expectError: sql.ErrNoRows,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			web, err := r.FindUserWebsite(context.Background(), test.userUUID, test.websiteUUID)
+			assert.ErrorIs(t, err, test.expectError)
+			assert.Equal(t, test.expect, web)
+		})
+	}
+}
+
+func testSearchUserWebsites(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("search-user-websites-user")
+
+	onePiece := createWebsite(t, r, "One Piece")
+	onePunch := createWebsite(t, r, "One Punch Man")
+	cjk := createWebsite(t, r, "海贼王 航海")
+	noted := createWebsite(t, r, "Noted Website")
+
+	for _, web := range []model.Website{onePiece, onePunch, cjk, noted} {
+		createUserWebsite(t, r, web, userUUID)
+	}
+
+	userWeb, err := r.FindUserWebsite(context.Background(), userUUID, noted.UUID)
+	if err != nil {
+		t.Fatalf("find user website fail: %v", err)
+	}
+
+	userWeb.Note, userWeb.GroupName = "my favourite_story", "weekly manga"
+	if err := r.UpdateUserWebsite(context.Background(), userWeb); err != nil {
+		t.Fatalf("update user website fail: %v", err)
+	}
+
+	createUserWebsite(t, r, createWebsite(t, r, "One Other User"), uniqueID("search-user-websites-other-user"))
+
+	tests := []struct {
+		name   string
+		query  string
+		limit  int
+		expect []string
+	}{
+		{
+			name:   "match title",
+			query:  "piece",
+			limit:  10,
+			expect: []string{onePiece.UUID},
+		},
+		{
+			name:   "rank closer title first",
+			query:  "one",
+			limit:  10,
+			expect: []string{onePiece.UUID, onePunch.UUID},
+		},
+		{
+			name:   "limit result",
+			query:  "one",
+			limit:  1,
+			expect: []string{onePiece.UUID},
+		},
+		{
+			name:   "match note with like wildcard",
+			query:  "favourite_story",
+			limit:  10,
+			expect: []string{noted.UUID},
+		},
+		{
+			name:   "match group name",
+			query:  "weekly manga",
+			limit:  10,
+			expect: []string{noted.UUID},
+		},
+		{
+			name:   "match url",
+			query:  cjk.URL[len(cjk.URL)-36:],
+			limit:  10,
+			expect: []string{cjk.UUID},
+		},
+		{
+			name:   "match cjk by substring",
+			query:  "贼王",
+			limit:  10,
+			expect: []string{cjk.UUID},
+		},
+		{
+			name:   "match cjk by n-grams",
+			query:  "海贼 航",
+			limit:  10,
+			expect: []string{cjk.UUID},
+		},
+		{
+			name:   "not match",
+			query:  "zzz",
+			limit:  10,
+			expect: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			webs, err := r.SearchUserWebsites(context.Background(), userUUID, test.query, test.limit)
+			assert.NoError(t, err)
+			assert.NotNil(t, webs)
+			assert.Equal(t, test.expect, userWebsiteUUIDs(webs))
+		})
+	}
+}
+
+func testFindWebsiteUserUUIDs(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	web := createWebsite(t, r, "Website User UUIDs")
+	userA := uniqueID("website-user-uuids-a")
+	userB := uniqueID("website-user-uuids-b")
+
+	createUserWebsite(t, r, web, userB)
+	createUserWebsite(t, r, web, userA)
+	createUserWebsite(t, r, createWebsite(t, r, "Other Website"), uniqueID("website-user-uuids-other"))
+
+	tests := []struct {
+		name        string
+		websiteUUID string
+		expect      []string
+	}{
+		{
+			name:        "list subscribers in order",
+			websiteUUID: web.UUID,
+			expect:      []string{userA, userB},
+		},
+		{
+			name:        "not exist website",
+			websiteUUID: uniqueID("website-user-uuids-not-exist"),
+			expect:      []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userUUIDs, err := r.FindWebsiteUserUUIDs(context.Background(), test.websiteUUID)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expect, userUUIDs)
+		})
+	}
+}
+
+func addTag(t *testing.T, r repository.Repository, userUUID, websiteUUID, tag string) {
+	t.Helper()
+
+	if err := r.AddUserWebsiteTag(context.Background(), userUUID, websiteUUID, tag); err != nil {
+		t.Fatalf("add user website tag fail: %v", err)
+	}
+}
+
+func websiteTags(t *testing.T, r repository.Repository, userUUID, websiteUUID string) []string {
+	t.Helper()
+
+	tags, err := r.FindUserWebsiteTags(context.Background(), userUUID, websiteUUID)
+	if err != nil {
+		t.Fatalf("find user website tags fail: %v", err)
+	}
+
+	return tags
+}
+
+func testUserWebsiteTags(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("user-website-tags-user")
+	otherUserUUID := uniqueID("user-website-tags-other-user")
+
+	first := createWebsite(t, r, "user website tags first")
+	second := createWebsite(t, r, "user website tags second")
+	for _, web := range []model.Website{first, second} {
+		createUserWebsite(t, r, web, userUUID)
+	}
+	createUserWebsite(t, r, first, otherUserUUID)
+
+	addTag(t, r, userUUID, first.UUID, "novel")
+	addTag(t, r, userUUID, first.UUID, "daily")
+	addTag(t, r, userUUID, first.UUID, "novel")
+	addTag(t, r, userUUID, second.UUID, "novel")
+	addTag(t, r, otherUserUUID, first.UUID, "other")
+
+	assert.Equal(t, []string{"daily", "novel"}, websiteTags(t, r, userUUID, first.UUID))
+	assert.Equal(t, []string{"other"}, websiteTags(t, r, otherUserUUID, first.UUID))
+	assert.Equal(t, []string{}, websiteTags(t, r, uniqueID("not-exist-user"), first.UUID))
+
+	tags, err := r.FindUserTags(context.Background(), userUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Tag{{Name: "daily", WebsiteCount: 1}, {Name: "novel", WebsiteCount: 2}}, tags)
+
+	err = r.RemoveUserWebsiteTag(context.Background(), userUUID, first.UUID, "novel")
+	assert.NoError(t, err)
+	err = r.RemoveUserWebsiteTag(context.Background(), userUUID, first.UUID, "not exist")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"daily"}, websiteTags(t, r, userUUID, first.UUID))
+
+	tags, err = r.FindUserTags(context.Background(), uniqueID("not-exist-user"))
+	assert.NoError(t, err)
+	assert.Empty(t, tags)
+}
+
+func testRenameUserTag(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("rename-user-tag-user")
+	otherUserUUID := uniqueID("rename-user-tag-other-user")
+
+	first := createWebsite(t, r, "rename user tag first")
+	second := createWebsite(t, r, "rename user tag second")
+	for _, web := range []model.Website{first, second} {
+		createUserWebsite(t, r, web, userUUID)
+	}
+	createUserWebsite(t, r, first, otherUserUUID)
+
+	addTag(t, r, userUUID, first.UUID, "from")
+	addTag(t, r, userUUID, first.UUID, "to")
+	addTag(t, r, userUUID, second.UUID, "from")
+	addTag(t, r, otherUserUUID, first.UUID, "from")
+
+	err := r.RenameUserTag(context.Background(), userUUID, "from", "to")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"to"}, websiteTags(t, r, userUUID, first.UUID))
+	assert.Equal(t, []string{"to"}, websiteTags(t, r, userUUID, second.UUID))
+	assert.Equal(t, []string{"from"}, websiteTags(t, r, otherUserUUID, first.UUID))
+
+	err = r.RenameUserTag(context.Background(), userUUID, "to", "to")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"to"}, websiteTags(t, r, userUUID, first.UUID))
+}
+
+func testDeleteUserTag(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("delete-user-tag-user")
+	otherUserUUID := uniqueID("delete-user-tag-other-user")
+
+	web := createWebsite(t, r, "delete user tag")
+	createUserWebsite(t, r, web, userUUID)
+	createUserWebsite(t, r, web, otherUserUUID)
+
+	addTag(t, r, userUUID, web.UUID, "deleted")
+	addTag(t, r, userUUID, web.UUID, "kept")
+	addTag(t, r, otherUserUUID, web.UUID, "deleted")
+
+	err := r.DeleteUserTag(context.Background(), userUUID, "deleted")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"kept"}, websiteTags(t, r, userUUID, web.UUID))
+	assert.Equal(t, []string{"deleted"}, websiteTags(t, r, otherUserUUID, web.UUID))
+}
+
+func createAuditEvent(t *testing.T, r repository.Repository, userUUID, websiteUUID string, createdAt time.Time) model.AuditEvent {
+	t.Helper()
+
+	event := model.AuditEvent{
+		UUID:        uniqueID("audit-event"),
+		UserUUID:    userUUID,
+		WebsiteUUID: websiteUUID,
+		Action:      model.AuditActionCreate,
+		After:       `{"website_uuid":"` + websiteUUID + `"}`,
+		RequestID:   uniqueID("request"),
+		CreatedAt:   createdAt,
+	}
+	if err := r.CreateAuditEvent(context.Background(), &event); err != nil {
+		t.Fatalf("create audit event fail: %v", err)
+	}
+
+	return event
+}
+
+func auditEventUUIDs(events []model.AuditEvent) []string {
+	uuids := make([]string, len(events))
+	for i, event := range events {
+		uuids[i] = event.UUID
+	}
+
+	return uuids
+}
+
+func testCreateAuditEvent(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+
+	t.Run("create audit event", func(t *testing.T) {
+		userUUID := uniqueID("create-audit-event-user")
+		event := createAuditEvent(t, r, userUUID, "website uuid", updateTime)
+
+		events, err := r.FindAuditEvents(context.Background(), repository.AuditEventsQuery{UserUUID: userUUID, Limit: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, []model.AuditEvent{event}, events)
+	})
+
+	t.Run("duplicate uuid", func(t *testing.T) {
+		event := createAuditEvent(t, r, uniqueID("create-audit-event-user"), "website uuid", updateTime)
+
+		err := r.CreateAuditEvent(context.Background(), &event)
+		assert.Error(t, err)
+	})
+}
+
+func testFindAuditEvents(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("find-audit-events-user")
+
+	oldest := createAuditEvent(t, r, userUUID, "website-1", updateTime)
+	latest := createAuditEvent(t, r, userUUID, "website-2", updateTime.Add(2*time.Hour))
+	middle := createAuditEvent(t, r, userUUID, "website-1", updateTime.Add(time.Hour))
+	other := createAuditEvent(t, r, uniqueID("find-audit-events-other-user"), "website-1", updateTime.Add(3*time.Hour))
+
+	tests := []struct {
+		name      string
+		query     repository.AuditEventsQuery
+		expect    []string
+		expectErr error
+	}{
+		{
+			name:   "list events of user from the latest",
+			query:  repository.AuditEventsQuery{UserUUID: userUUID, Limit: 10},
+			expect: []string{latest.UUID, middle.UUID, oldest.UUID},
+		},
+		{
+			name:   "list events of user website",
+			query:  repository.AuditEventsQuery{UserUUID: userUUID, WebsiteUUID: "website-1", Limit: 10},
+			expect: []string{middle.UUID, oldest.UUID},
+		},
+		{
+			name:   "limit number of events",
+			query:  repository.AuditEventsQuery{UserUUID: userUUID, Limit: 1},
+			expect: []string{latest.UUID},
+		},
+		{
+			name:      "invalid limit",
+			query:     repository.AuditEventsQuery{UserUUID: userUUID},
+			expectErr: repository.ErrInvalidLimit,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events, err := r.FindAuditEvents(context.Background(), test.query)
+			assert.ErrorIs(t, err, test.expectErr)
+			if test.expectErr == nil {
+				assert.Equal(t, test.expect, auditEventUUIDs(events))
+			}
+		})
+	}
+
+	t.Run("list events of all users", func(t *testing.T) {
+		events, err := r.FindAuditEvents(context.Background(), repository.AuditEventsQuery{Limit: 1000000})
+		assert.NoError(t, err)
+		assert.Subset(t, auditEventUUIDs(events), []string{other.UUID, latest.UUID, middle.UUID, oldest.UUID})
+	})
+}
+
+func testJob(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("job-user")
+
+	job := model.Job{
+		UUID:      uniqueID("job"),
+		UserUUID:  userUUID,
+		Kind:      model.JobKindImport,
+		Status:    model.JobStatusQueued,
+		CreatedAt: updateTime,
+		UpdatedAt: updateTime,
+	}
+	err := r.CreateJob(context.Background(), &job)
+	assert.NoError(t, err)
+
+	found, err := r.FindJob(context.Background(), userUUID, job.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, &job, found)
+
+	_, err = r.FindJob(context.Background(), uniqueID("other-user"), job.UUID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	job.Status, job.Result, job.UpdatedAt = model.JobStatusDone, `{"total":1}`, accessTime
+	err = r.UpdateJob(context.Background(), &job)
+	assert.NoError(t, err)
+
+	found, err = r.FindJob(context.Background(), userUUID, job.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Job{
+		UUID:      job.UUID,
+		UserUUID:  userUUID,
+		Kind:      model.JobKindImport,
+		Status:    model.JobStatusDone,
+		Result:    `{"total":1}`,
+		CreatedAt: updateTime,
+		UpdatedAt: accessTime,
+	}, *found)
+
+	err = r.UpdateJob(context.Background(), &model.Job{UUID: uniqueID("not-exist-job"), UpdatedAt: accessTime})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testCountUserJobs(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("count-jobs-user")
+
+	createJob := func(userUUID, kind string, createdAt time.Time) {
+		t.Helper()
+
+		job := model.Job{
+			UUID:      uniqueID("count-jobs-job"),
+			UserUUID:  userUUID,
+			Kind:      kind,
+			Status:    model.JobStatusQueued,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}
+		if err := r.CreateJob(context.Background(), &job); err != nil {
+			t.Fatalf("create job fail: %v", err)
+		}
+	}
+
+	createJob(userUUID, model.JobKindCheck, updateTime)
+	createJob(userUUID, model.JobKindCheck, accessTime)
+	createJob(userUUID, model.JobKindCheck, accessTime.Add(time.Hour))
+	createJob(userUUID, model.JobKindImport, accessTime)
+	createJob(uniqueID("count-jobs-other-user"), model.JobKindCheck, accessTime)
+
+	count, err := r.CountUserJobs(context.Background(), userUUID, model.JobKindCheck, accessTime)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = r.CountUserJobs(context.Background(), userUUID, model.JobKindCheck, updateTime)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	count, err = r.CountUserJobs(context.Background(), userUUID, model.JobKindImport, accessTime.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func testLockUserJobs(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("lock-jobs-user")
+
+	err := r.WithTx(context.Background(), func(txRepo repository.Repository) error {
+		if err := txRepo.LockUserJobs(context.Background(), userUUID); err != nil {
+			return err
+		}
+
+		job := model.NewJob(userUUID, model.JobKindCheck)
+
+		return txRepo.CreateJob(context.Background(), &job)
+	})
+	assert.NoError(t, err)
+
+	count, err := r.CountUserJobs(context.Background(), userUUID, model.JobKindCheck, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func testFeedToken(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("feed-token-user")
+
+	_, err := r.FindFeedToken(context.Background(), userUUID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	token := model.FeedToken{UserUUID: userUUID, Token: uniqueID("token"), CreatedAt: updateTime}
+	err = r.SaveFeedToken(context.Background(), &token)
+	assert.NoError(t, err)
+
+	found, err := r.FindFeedToken(context.Background(), userUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, &token, found)
+
+	found, err = r.FindFeedTokenByToken(context.Background(), token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, &token, found)
+
+	// saving again replaces the old token
+	newToken := model.FeedToken{UserUUID: userUUID, Token: uniqueID("new-token"), CreatedAt: accessTime}
+	err = r.SaveFeedToken(context.Background(), &newToken)
+	assert.NoError(t, err)
+
+	found, err = r.FindFeedToken(context.Background(), userUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, &newToken, found)
+
+	_, err = r.FindFeedTokenByToken(context.Background(), token.Token)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// token is unique among users
+	err = r.SaveFeedToken(context.Background(), &model.FeedToken{
+		UserUUID: uniqueID("other-feed-token-user"), Token: newToken.Token, CreatedAt: accessTime,
+	})
+	assert.Error(t, err)
+}
+
+func createWebhook(t *testing.T, r repository.Repository, uuid, userUUID, groupName string, createdAt time.Time) model.Webhook {
+	t.Helper()
+
+	webhook := model.Webhook{
+		UUID:      uuid,
+		UserUUID:  userUUID,
+		URL:       "http://example.com/hooks/" + uuid,
+		GroupName: groupName,
+		Secret:    "secret",
+		Enabled:   true,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+
+	if err := r.CreateWebhook(context.Background(), &webhook); err != nil {
+		t.Fatalf("create webhook fail: %v", err)
+	}
+
+	return webhook
+}
+
+func webhookUUIDs(webhooks []model.Webhook) []string {
+	uuids := make([]string, len(webhooks))
+	for i, webhook := range webhooks {
+		uuids[i] = webhook.UUID
+	}
+
+	return uuids
+}
+
+func testWebhook(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	userUUID := uniqueID("webhook-user")
+
+	latest := createWebhook(t, r, uniqueID("webhook-a"), userUUID, "", accessTime)
+	oldest := createWebhook(t, r, uniqueID("webhook-b"), userUUID, "group", updateTime)
+	createWebhook(t, r, uniqueID("webhook-c"), uniqueID("other-webhook-user"), "", updateTime)
+
+	found, err := r.FindWebhook(context.Background(), userUUID, oldest.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, &oldest, found)
+
+	_, err = r.FindWebhook(context.Background(), uniqueID("other-webhook-user"), oldest.UUID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	webhooks, err := r.FindWebhooks(context.Background(), userUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Webhook{oldest, latest}, webhooks)
+
+	oldest.URL, oldest.GroupName, oldest.Enabled = "http://example.com/new-hook", "", false
+	oldest.FailureCount, oldest.UpdatedAt = 3, accessTime
+	err = r.UpdateWebhook(context.Background(), &oldest)
+	assert.NoError(t, err)
+
+	found, err = r.FindWebhook(context.Background(), userUUID, oldest.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, &oldest, found)
+
+	err = r.UpdateWebhook(context.Background(), &model.Webhook{UUID: uniqueID("not-exist-webhook"), UpdatedAt: accessTime})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	delivery := model.NewWebhookDelivery(latest.UUID, uniqueID("website"), "{}")
+	err = r.CreateWebhookDelivery(context.Background(), &delivery)
+	assert.NoError(t, err)
+
+	// deleting webhook removes its deliveries as well
+	err = r.DeleteWebhook(context.Background(), &latest)
+	assert.NoError(t, err)
+
+	_, err = r.FindWebhook(context.Background(), userUUID, latest.UUID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = r.FindWebhookDelivery(context.Background(), delivery.UUID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	webhooks, err = r.FindWebhooks(context.Background(), userUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Webhook{oldest}, webhooks)
+}
+
+func testFindWebsiteWebhooks(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	web := createWebsite(t, r, "Website Webhooks")
+	userA := uniqueID("website-webhooks-a")
+	userB := uniqueID("website-webhooks-b")
+
+	createUserWebsite(t, r, web, userA)
+	createUserWebsite(t, r, web, userB)
+
+	all := createWebhook(t, r, uniqueID("website-webhook-a"), userA, "", updateTime)
+	group := createWebhook(t, r, uniqueID("website-webhook-b"), userB, web.Title, updateTime)
+	createWebhook(t, r, uniqueID("website-webhook-c"), userB, "other group", updateTime)
+	createWebhook(t, r, uniqueID("website-webhook-d"), uniqueID("website-webhooks-other"), "", updateTime)
+
+	disabled := createWebhook(t, r, uniqueID("website-webhook-e"), userA, "", updateTime)
+	disabled.Enabled = false
+	if err := r.UpdateWebhook(context.Background(), &disabled); err != nil {
+		t.Fatalf("update webhook fail: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		websiteUUID string
+		expect      []string
+	}{
+		{
+			name:        "list enabled webhooks of subscribers matching group",
+			websiteUUID: web.UUID,
+			expect:      []string{all.UUID, group.UUID},
+		},
+		{
+			name:        "not exist website",
+			websiteUUID: uniqueID("website-webhooks-not-exist"),
+			expect:      []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			webhooks, err := r.FindWebsiteWebhooks(context.Background(), test.websiteUUID)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expect, webhookUUIDs(webhooks))
+		})
+	}
+}
+
+func testWebhookDelivery(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	webhookUUID := uniqueID("delivery-webhook")
+
+	deliveries := make([]model.WebhookDelivery, 3)
+	for i := range deliveries {
+		deliveries[i] = model.WebhookDelivery{
+			UUID:        uniqueID("delivery"),
+			WebhookUUID: webhookUUID,
+			WebsiteUUID: uniqueID("delivery-website"),
+			Status:      model.WebhookDeliveryStatusPending,
+			Payload:     `{"event":"website_updated"}`,
+			CreatedAt:   updateTime.Add(time.Duration(i) * time.Hour),
+			UpdatedAt:   updateTime.Add(time.Duration(i) * time.Hour),
+		}
+
+		err := r.CreateWebhookDelivery(context.Background(), &deliveries[i])
+		assert.NoError(t, err)
+	}
+
+	found, err := r.FindWebhookDelivery(context.Background(), deliveries[0].UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, &deliveries[0], found)
+
+	deliveries[0].Status, deliveries[0].Attempts = model.WebhookDeliveryStatusFailed, 2
+	deliveries[0].ResponseStatus, deliveries[0].Error = 500, "unexpected status 500"
+	deliveries[0].UpdatedAt = accessTime
+	err = r.UpdateWebhookDelivery(context.Background(), &deliveries[0])
+	assert.NoError(t, err)
+
+	found, err = r.FindWebhookDelivery(context.Background(), deliveries[0].UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, &deliveries[0], found)
+
+	err = r.UpdateWebhookDelivery(context.Background(), &model.WebhookDelivery{UUID: uniqueID("not-exist-delivery"), UpdatedAt: accessTime})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	t.Run("list deliveries from the latest", func(t *testing.T) {
+		found, err := r.FindWebhookDeliveries(context.Background(), webhookUUID, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.WebhookDelivery{deliveries[2], deliveries[1]}, found)
+	})
+
+	t.Run("not exist webhook", func(t *testing.T) {
+		found, err := r.FindWebhookDeliveries(context.Background(), uniqueID("not-exist-webhook"), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.WebhookDelivery{}, found)
+	})
+}
+
+func testWithTx(t *testing.T, newRepo NewRepoFunc) {
+	r := newRepo(t, &config.WebsiteConfig{})
+	errRollback := errors.New("rollback")
+
+	t.Run("commit writes if fn succeeds", func(t *testing.T) {
+		userUUID := uniqueID("with-tx-commit-user")
+
+		var web model.Website
+		err := r.WithTx(context.Background(), func(txRepo repository.Repository) error {
+			web = createWebsite(t, txRepo, "with tx commit")
+			createUserWebsite(t, txRepo, web, userUUID)
+
+			// nested transaction joins the outer one
+			return txRepo.WithTx(context.Background(), func(txRepo repository.Repository) error {
+				_, err := txRepo.FindUserWebsite(context.Background(), userUUID, web.UUID)
+				return err
+			})
+		})
+		assert.NoError(t, err)
+
+		_, err = r.FindUserWebsite(context.Background(), userUUID, web.UUID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rollback writes if fn fails", func(t *testing.T) {
+		existing := createWebsite(t, r, "with tx rollback existing")
+		userUUID := uniqueID("with-tx-rollback-user")
+
+		var web model.Website
+		err := r.WithTx(context.Background(), func(txRepo repository.Repository) error {
+			web = createWebsite(t, txRepo, "with tx rollback")
+			createUserWebsite(t, txRepo, web, userUUID)
+
+			updated := existing
+			updated.Title = "updated in tx"
+			if err := txRepo.UpdateWebsite(context.Background(), &updated); err != nil {
+				return err
+			}
+
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+
+		_, err = r.FindWebsite(context.Background(), web.UUID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		webs, err := r.FindUserWebsites(context.Background(), userUUID)
+		assert.NoError(t, err)
+		assert.Empty(t, webs)
+
+		stored, err := r.FindWebsite(context.Background(), existing.UUID)
+		assert.NoError(t, err)
+		assert.Equal(t, existing.Title, stored.Title)
+	})
+
+	t.Run("rollback merge if fn fails", func(t *testing.T) {
+		from := createWebsite(t, r, "with tx merge from")
+		to := createWebsite(t, r, "with tx merge to")
+
+		err := r.WithTx(context.Background(), func(txRepo repository.Repository) error {
+			if err := txRepo.MergeWebsite(context.Background(), &from, &to); err != nil {
+				return err
+			}
+
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+
+		_, err = r.FindWebsite(context.Background(), from.UUID)
+		assert.NoError(t, err)
+	})
+}
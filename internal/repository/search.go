@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/htchan/WebHistory/internal/model"
+)
+
+// SimilarityThreshold matches the default pg_trgm.similarity_threshold used by % operator
+const SimilarityThreshold = 0.3
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// SearchTerms splits the query into lower case words. CJK text has no word
+// separator, so it is split into overlapping character bigrams instead.
+func SearchTerms(query string) []string {
+	var terms []string
+	appendCJK := func(run []rune) {
+		if len(run) == 1 {
+			terms = append(terms, string(run))
+		}
+
+		for i := 0; i+1 < len(run); i++ {
+			terms = append(terms, string(run[i:i+2]))
+		}
+	}
+
+	var word, cjk []rune
+	for _, r := range strings.ToLower(query) + " " {
+		switch {
+		case isCJK(r):
+			if len(word) > 0 {
+				terms, word = append(terms, string(word)), nil
+			}
+
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if len(cjk) > 0 {
+				appendCJK(cjk)
+				cjk = nil
+			}
+
+			word = append(word, r)
+		default:
+			if len(word) > 0 {
+				terms, word = append(terms, string(word)), nil
+			}
+
+			if len(cjk) > 0 {
+				appendCJK(cjk)
+				cjk = nil
+			}
+		}
+	}
+
+	return terms
+}
+
+// LikePattern returns a pattern matching the query as substring with the
+// LIKE wildcards escaped
+func LikePattern(query string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+	return "%" + replacer.Replace(query) + "%"
+}
+
+func trigrams(text string) map[string]bool {
+	grams := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			grams[string(runes[i:i+3])] = true
+		}
+	}
+
+	return grams
+}
+
+// Similarity follows pg_trgm similarity, the shared trigrams over all trigrams
+func Similarity(a, b string) float64 {
+	gramsA, gramsB := trigrams(a), trigrams(b)
+	if len(gramsA) == 0 || len(gramsB) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for gram := range gramsA {
+		if gramsB[gram] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(gramsA)+len(gramsB)-shared)
+}
+
+// SearchRank scores the user website against the query in the same way as
+// the postgres search query, except the tsvector rank which only postgres
+// supports. The website does not match if ok is false.
+func SearchRank(web model.UserWebsite, query string, terms []string) (rank float64, ok bool) {
+	query = strings.ToLower(query)
+	title := strings.ToLower(web.Title())
+	fields := []string{
+		strings.ToLower(web.Website.Title),
+		strings.ToLower(web.DisplayTitle),
+		strings.ToLower(web.GroupName),
+		strings.ToLower(web.Website.URL),
+		strings.ToLower(web.Note),
+	}
+	doc := strings.Join(fields, " ")
+
+	substring := false
+	for _, field := range fields {
+		if strings.Contains(field, query) {
+			substring = true
+		}
+	}
+
+	matchedTerms := 0
+	for _, term := range terms {
+		if strings.Contains(doc, term) {
+			matchedTerms++
+		}
+	}
+
+	similarity := Similarity(title, query)
+
+	if strings.Contains(title, query) {
+		rank += 1
+	}
+
+	if substring {
+		rank += 0.5
+	}
+
+	rank += similarity
+	if len(terms) > 0 {
+		rank += float64(matchedTerms) / float64(len(terms))
+	}
+
+	ok = substring || similarity >= SimilarityThreshold ||
+		(len(terms) > 0 && matchedTerms == len(terms))
+
+	return rank, ok
+}
+
+// RankUserWebsites ranks the user websites for backends without full text
+// search support, ordered by rank, update time and website uuid
+func RankUserWebsites(webs model.UserWebsites, query string, limit int) model.UserWebsites {
+	terms := SearchTerms(query)
+
+	type rankedWebsite struct {
+		web  model.UserWebsite
+		rank float64
+	}
+
+	var ranked []rankedWebsite
+	for _, web := range webs {
+		if rank, ok := SearchRank(web, query, terms); ok {
+			ranked = append(ranked, rankedWebsite{web: web, rank: rank})
+		}
+	}
+
+	slices.SortStableFunc(ranked, func(a, b rankedWebsite) int {
+		if c := cmp.Compare(b.rank, a.rank); c != 0 {
+			return c
+		}
+
+		if c := b.web.Website.UpdateTime.Compare(a.web.Website.UpdateTime); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.web.WebsiteUUID, b.web.WebsiteUUID)
+	})
+
+	result := model.UserWebsites{}
+	for _, web := range ranked[:min(limit, len(ranked))] {
+		result = append(result, web.web)
+	}
+
+	return result
+}
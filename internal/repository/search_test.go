@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTerms(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		query  string
+		expect []string
+	}{
+		{
+			name:   "split words in lower case",
+			query:  "One  Piece-1000",
+			expect: []string{"one", "piece", "1000"},
+		},
+		{
+			name:   "split cjk into bigrams",
+			query:  "海贼王",
+			expect: []string{"海贼", "贼王"},
+		},
+		{
+			name:   "keep single cjk character",
+			query:  "王 one",
+			expect: []string{"王", "one"},
+		},
+		{
+			name:   "mixed cjk and words",
+			query:  "海贼王one piece",
+			expect: []string{"海贼", "贼王", "one", "piece"},
+		},
+		{
+			name:   "punctuation only",
+			query:  "%_!",
+			expect: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, SearchTerms(test.query))
+		})
+	}
+}
+
+func TestLikePattern(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `%100\%\_a\\b%`, LikePattern(`100%_a\b`))
+}
+
+func TestSimilarity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		a, b   string
+		expect float64
+	}{
+		{name: "same text", a: "one piece", b: "One Piece", expect: 1},
+		{name: "partial match", a: "one", b: "one piece", expect: 0.4},
+		{name: "no match", a: "abc", b: "xyz", expect: 0},
+		{name: "empty text", a: "", b: "xyz", expect: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.InDelta(t, test.expect, Similarity(test.a, test.b), 0.001)
+		})
+	}
+}
@@ -9,6 +9,8 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/htchan/WebHistory/internal/config"
 	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/htchan/WebHistory/internal/repository/repotest"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
@@ -419,6 +421,125 @@ func TestSqlcRepo_FindWebsite(t *testing.T) {
 	}
 }
 
+func TestSqlcRepo_MergeWebsite(t *testing.T) {
+	t.Parallel()
+
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		t.Fatalf("open database fail: %v", err)
+	}
+
+	r := NewRepo(db, &config.WebsiteConfig{})
+
+	fromUUID := "merge-website-from-uuid"
+	toUUID := "merge-website-to-uuid"
+	userUUID := "merge-website-user-uuid"
+	sharedUserUUID := "merge-website-shared-user-uuid"
+	title := "merge website"
+	populateData(db, fromUUID, title+"-from", userUUID, "active")
+	populateData(db, toUUID, title+"-to", sharedUserUUID, "active")
+	db.Exec("insert into user_websites (website_uuid, user_uuid, group_name, access_time) values ($1, $2, $3, $4)", fromUUID, sharedUserUUID, title, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	db.Exec("update websites set content='new content', update_time=$2 where uuid=$1", fromUUID, time.Date(2020, 1, 3, 3, 4, 5, 0, time.UTC))
+	t.Cleanup(func() {
+		db.Exec("delete from websites where uuid=$1 or uuid=$2", fromUUID, toUUID)
+		db.Exec("delete from user_websites where website_uuid=$1 or website_uuid=$2", fromUUID, toUUID)
+		db.Close()
+	})
+
+	from, err := r.FindWebsite(context.Background(), fromUUID)
+	assert.NoError(t, err)
+	to, err := r.FindWebsite(context.Background(), toUUID)
+	assert.NoError(t, err)
+
+	err = r.MergeWebsite(context.Background(), from, to)
+	assert.NoError(t, err)
+
+	expectTo := &model.Website{
+		UUID:       toUUID,
+		URL:        "http://example.com/" + title + "-to",
+		Title:      title + "-to",
+		RawContent: "new content",
+		UpdateTime: time.Date(2020, 1, 3, 3, 4, 5, 0, time.UTC),
+		Status:     "active",
+		Conf:       &config.WebsiteConfig{},
+	}
+	assert.Equal(t, expectTo, to)
+	assert.Equal(t, model.WebsiteStatusInactive, from.Status)
+	assert.Equal(t, toUUID, from.RedirectUUID)
+
+	// merged website is no longer accessible
+	_, err = r.FindWebsite(context.Background(), fromUUID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// subscriptions are moved to target website without duplication
+	for _, user := range []string{userUUID, sharedUserUUID} {
+		webs, err := r.FindUserWebsites(context.Background(), user)
+		assert.NoError(t, err)
+		if assert.Len(t, webs, 1) {
+			assert.Equal(t, toUUID, webs[0].WebsiteUUID)
+		}
+	}
+
+	// creating merged website follows redirect
+	web := model.Website{
+		UUID: "merge-website-new-uuid",
+		URL:  "http://example.com/" + title + "-from",
+	}
+	err = r.CreateWebsite(context.Background(), &web)
+	assert.NoError(t, err)
+	assert.Equal(t, toUUID, web.UUID)
+	assert.Equal(t, expectTo.URL, web.URL)
+}
+
+func TestSqlcRepo_RecordWebsiteMissing(t *testing.T) {
+	t.Parallel()
+
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		t.Fatalf("open database fail: %v", err)
+	}
+
+	r := NewRepo(db, &config.WebsiteConfig{})
+
+	uuid := "record-website-missing-uuid"
+	userUUID := "record-website-missing-user-uuid"
+	title := "record website missing"
+	populateData(db, uuid, title, userUUID, "active")
+	t.Cleanup(func() {
+		db.Exec("delete from websites where uuid=$1", uuid)
+		db.Exec("delete from user_websites where website_uuid=$1", uuid)
+		db.Close()
+	})
+
+	web := &model.Website{UUID: uuid}
+
+	err = r.RecordWebsiteMissing(context.Background(), web, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebsiteStatusActive, web.Status)
+	assert.Equal(t, 1, web.MissingCount)
+
+	err = r.RecordWebsiteMissing(context.Background(), web, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebsiteStatusGone, web.Status)
+	assert.Equal(t, 2, web.MissingCount)
+
+	// gone website is excluded from batch update but visible to subscribers
+	webs, err := r.FindWebsites(context.Background())
+	assert.NoError(t, err)
+	for _, w := range webs {
+		assert.NotEqual(t, uuid, w.UUID)
+	}
+
+	userWeb, err := r.FindUserWebsite(context.Background(), userUUID, uuid)
+	assert.NoError(t, err)
+	assert.True(t, userWeb.Website.Gone())
+
+	err = r.ResetWebsiteMissing(context.Background(), web)
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebsiteStatusActive, web.Status)
+	assert.Equal(t, 0, web.MissingCount)
+}
+
 func TestSqlcRepo_CreateUserWebsite(t *testing.T) {
 	t.Parallel()
 
@@ -565,7 +686,40 @@ func TestSqlcRepo_UpdateUserWebsite(t *testing.T) {
 					UUID:       uuid,
 					URL:        "http://example.com/" + title,
 					Title:      title,
+					RawContent: "content",
 					UpdateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+					Status:     "active",
+					Conf:       &config.WebsiteConfig{},
+				},
+			},
+			expectError: nil,
+		},
+		{
+			name: "update display title, note and read progress",
+			web: model.UserWebsite{
+				WebsiteUUID:     uuid,
+				UserUUID:        userUUID,
+				GroupName:       "custom title",
+				DisplayTitle:    "custom title",
+				Note:            "some note",
+				LastReadChapter: "content",
+				AccessTime:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			expect: &model.UserWebsite{
+				WebsiteUUID:     uuid,
+				UserUUID:        userUUID,
+				GroupName:       "custom title",
+				DisplayTitle:    "custom title",
+				Note:            "some note",
+				LastReadChapter: "content",
+				AccessTime:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				Website: model.Website{
+					UUID:       uuid,
+					URL:        "http://example.com/" + title,
+					Title:      title,
+					RawContent: "content",
+					UpdateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+					Status:     "active",
 					Conf:       &config.WebsiteConfig{},
 				},
 			},
@@ -693,7 +847,9 @@ func TestSqlcRepo_FindUserWebsites(t *testing.T) {
 						UUID:       uuidReadOnly,
 						URL:        "http://example.com/" + title + "-readonly",
 						Title:      title + "-readonly",
+						RawContent: "content",
 						UpdateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+						Status:     "read_only",
 						Conf:       &config.WebsiteConfig{},
 					},
 				},
@@ -706,7 +862,9 @@ func TestSqlcRepo_FindUserWebsites(t *testing.T) {
 						UUID:       uuid,
 						URL:        "http://example.com/" + title,
 						Title:      title,
+						RawContent: "content",
 						UpdateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+						Status:     "active",
 						Conf:       &config.WebsiteConfig{},
 					},
 				},
@@ -777,7 +935,9 @@ func TestSqlcRepo_FindUserWebsitesByGroup(t *testing.T) {
 						UUID:       uuid,
 						URL:        "http://example.com/" + title,
 						Title:      title,
+						RawContent: "content",
 						UpdateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+						Status:     "active",
 						Conf:       &config.WebsiteConfig{},
 					},
 				},
@@ -798,7 +958,9 @@ func TestSqlcRepo_FindUserWebsitesByGroup(t *testing.T) {
 						UUID:       uuidReadOnly,
 						URL:        "http://example.com/" + title + "-readonly",
 						Title:      title + "-readonly",
+						RawContent: "content",
 						UpdateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+						Status:     "read_only",
 						Conf:       &config.WebsiteConfig{},
 					},
 				},
@@ -883,7 +1045,9 @@ func TestSqlcRepo_FindUserWebsite(t *testing.T) {
 					UUID:       uuid,
 					URL:        "http://example.com/" + title,
 					Title:      title,
+					RawContent: "content",
 					UpdateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+					Status:     "active",
 					Conf:       &config.WebsiteConfig{},
 				},
 			},
@@ -902,7 +1066,9 @@ func TestSqlcRepo_FindUserWebsite(t *testing.T) {
 					UUID:       uuidReadOnly,
 					URL:        "http://example.com/" + title + "-readonly",
 					Title:      title + "-readonly",
+					RawContent: "content",
 					UpdateTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+					Status:     "read_only",
 					Conf:       &config.WebsiteConfig{},
 				},
 			},
@@ -939,3 +1105,19 @@ func TestSqlcRepo_FindUserWebsite(t *testing.T) {
 		})
 	}
 }
+
+func TestSqlcRepo_Contract(t *testing.T) {
+	t.Parallel()
+
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		t.Fatalf("open database fail: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	repotest.Run(t, func(t *testing.T, conf *config.WebsiteConfig) repository.Repository {
+		return NewRepo(db, conf)
+	})
+}
@@ -0,0 +1,1754 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	sqlc "github.com/htchan/WebHistory/internal/sqlc/sqlite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const MinTimeUnit = 5 * time.Second
+
+type SqliteRepo struct {
+	conn  *sql.DB
+	db    *sqlc.Queries
+	tx    *sql.Tx
+	stats func() sql.DBStats
+	conf  *config.WebsiteConfig
+}
+
+var _ repository.Repository = &SqliteRepo{}
+
+func NewRepo(db *sql.DB, conf *config.WebsiteConfig) *SqliteRepo {
+	return &SqliteRepo{
+		conn:  db,
+		db:    sqlc.New(db),
+		stats: db.Stats,
+		conf:  conf,
+	}
+}
+
+func toSqlString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
+
+// toSqlTags encodes tags as json array, which is expanded by json_each in query
+func toSqlTags(tags []string) string {
+	data, _ := json.Marshal(tags)
+
+	return string(data)
+}
+
+// toSqlTime keeps time in UTC, sqlite stores time as text and compares the
+// text of times in different time zones in wrong order
+func toSqlTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func fromSqlcWebsite(webModel sqlc.Website) model.Website {
+	return model.Website{
+		UUID:         webModel.Uuid.String,
+		URL:          webModel.Url.String,
+		Title:        webModel.Title.String,
+		RawContent:   webModel.Content.String,
+		UpdateTime:   webModel.UpdateTime.Time.UTC().Truncate(MinTimeUnit),
+		Status:       webModel.Status,
+		RedirectUUID: webModel.RedirectUuid.String,
+		MissingCount: int(webModel.MissingCount),
+	}
+}
+
+func fromSqlcListUserWebsitesRow(userWebModel sqlc.ListUserWebsitesRow) model.UserWebsite {
+	return model.UserWebsite{
+		WebsiteUUID:     userWebModel.WebsiteUuid.String,
+		UserUUID:        userWebModel.UserUuid.String,
+		GroupName:       userWebModel.GroupName.String,
+		DisplayTitle:    userWebModel.DisplayTitle,
+		Note:            userWebModel.Note,
+		LastReadChapter: userWebModel.LastReadChapter,
+		AccessTime:      userWebModel.AccessTime.Time.UTC().Truncate(MinTimeUnit),
+		Website: model.Website{
+			UUID:       userWebModel.WebsiteUuid.String,
+			URL:        userWebModel.Url.String,
+			Title:      userWebModel.Title.String,
+			RawContent: userWebModel.Content.String,
+			UpdateTime: userWebModel.UpdateTime.Time.UTC().Truncate(MinTimeUnit),
+			Status:     userWebModel.Status,
+		},
+	}
+}
+
+// cursorUserWebsite keeps the sort keys of row untruncated for building the
+// page cursor
+func cursorUserWebsite(row sqlc.ListUserWebsitesRow) model.UserWebsite {
+	return model.UserWebsite{
+		WebsiteUUID:  row.WebsiteUuid.String,
+		DisplayTitle: row.DisplayTitle,
+		AccessTime:   row.AccessTime.Time.UTC(),
+		Website: model.Website{
+			Title:      row.Title.String,
+			UpdateTime: row.UpdateTime.Time.UTC(),
+		},
+	}
+}
+
+func fromSqlcListUserWebsitesByGroupRow(userWebModel sqlc.ListUserWebsitesByGroupRow) model.UserWebsite {
+	return model.UserWebsite{
+		WebsiteUUID:     userWebModel.WebsiteUuid.String,
+		UserUUID:        userWebModel.UserUuid.String,
+		GroupName:       userWebModel.GroupName.String,
+		DisplayTitle:    userWebModel.DisplayTitle,
+		Note:            userWebModel.Note,
+		LastReadChapter: userWebModel.LastReadChapter,
+		AccessTime:      userWebModel.AccessTime.Time.UTC().Truncate(MinTimeUnit),
+		Website: model.Website{
+			UUID:       userWebModel.WebsiteUuid.String,
+			URL:        userWebModel.Url.String,
+			Title:      userWebModel.Title.String,
+			RawContent: userWebModel.Content.String,
+			UpdateTime: userWebModel.UpdateTime.Time.UTC().Truncate(MinTimeUnit),
+			Status:     userWebModel.Status,
+		},
+	}
+}
+
+func fromSqlcGetUserWebsiteRow(userWebModel sqlc.GetUserWebsiteRow) model.UserWebsite {
+	return model.UserWebsite{
+		WebsiteUUID:     userWebModel.WebsiteUuid.String,
+		UserUUID:        userWebModel.UserUuid.String,
+		GroupName:       userWebModel.GroupName.String,
+		DisplayTitle:    userWebModel.DisplayTitle,
+		Note:            userWebModel.Note,
+		LastReadChapter: userWebModel.LastReadChapter,
+		AccessTime:      userWebModel.AccessTime.Time.UTC().Truncate(MinTimeUnit),
+		Website: model.Website{
+			UUID:       userWebModel.WebsiteUuid.String,
+			URL:        userWebModel.Url.String,
+			Title:      userWebModel.Title.String,
+			RawContent: userWebModel.Content.String,
+			UpdateTime: userWebModel.UpdateTime.Time.UTC().Truncate(MinTimeUnit),
+			Status:     userWebModel.Status,
+		},
+	}
+}
+
+func toSqlcListUserWebsitesByGroupParams(userUUID, groupName string) sqlc.ListUserWebsitesByGroupParams {
+	return sqlc.ListUserWebsitesByGroupParams{
+		UserUuid:  toSqlString(userUUID),
+		GroupName: toSqlString(groupName),
+	}
+}
+
+func toSqlcGetUserWebsitesParams(userUUID, websiteUUID string) sqlc.GetUserWebsiteParams {
+	return sqlc.GetUserWebsiteParams{
+		UserUuid:    toSqlString(userUUID),
+		WebsiteUuid: toSqlString(websiteUUID),
+	}
+}
+
+func toSqlcCreateWebsiteParams(web *model.Website) sqlc.CreateWebsiteParams {
+	return sqlc.CreateWebsiteParams{
+		Uuid:       toSqlString(web.UUID),
+		Url:        toSqlString(web.URL),
+		Title:      toSqlString(web.Title),
+		Content:    toSqlString(web.RawContent),
+		UpdateTime: toSqlTime(web.UpdateTime),
+	}
+}
+
+func toSqlcCreateUserWebsiteParams(userWeb *model.UserWebsite) sqlc.CreateUserWebsiteParams {
+	return sqlc.CreateUserWebsiteParams{
+		UserUuid:        toSqlString(userWeb.UserUUID),
+		WebsiteUuid:     toSqlString(userWeb.WebsiteUUID),
+		AccessTime:      toSqlTime(userWeb.AccessTime),
+		GroupName:       toSqlString(userWeb.GroupName),
+		DisplayTitle:    userWeb.DisplayTitle,
+		Note:            userWeb.Note,
+		LastReadChapter: userWeb.LastReadChapter,
+	}
+}
+
+func toSqlcUpdateWebsiteParams(web *model.Website) sqlc.UpdateWebsiteParams {
+	return sqlc.UpdateWebsiteParams{
+		Url:        toSqlString(web.URL),
+		Title:      toSqlString(web.Title),
+		Content:    toSqlString(web.RawContent),
+		UpdateTime: toSqlTime(web.UpdateTime),
+		Uuid:       toSqlString(web.UUID),
+	}
+}
+
+func toSqlcUpdateUserWebsiteParams(userWeb *model.UserWebsite) sqlc.UpdateUserWebsiteParams {
+	return sqlc.UpdateUserWebsiteParams{
+		UserUuid:        toSqlString(userWeb.UserUUID),
+		WebsiteUuid:     toSqlString(userWeb.WebsiteUUID),
+		AccessTime:      toSqlTime(userWeb.AccessTime),
+		GroupName:       toSqlString(userWeb.GroupName),
+		DisplayTitle:    userWeb.DisplayTitle,
+		Note:            userWeb.Note,
+		LastReadChapter: userWeb.LastReadChapter,
+	}
+}
+
+func toSqlcDeleteUserWebsiteParams(userWeb *model.UserWebsite) sqlc.DeleteUserWebsiteParams {
+	return sqlc.DeleteUserWebsiteParams{
+		UserUuid:    toSqlString(userWeb.UserUUID),
+		WebsiteUuid: toSqlString(userWeb.WebsiteUUID),
+	}
+}
+
+func (r *SqliteRepo) CreateWebsite(ctx context.Context, web *model.Website) error {
+	_, createWebsiteSpan := repository.GetTracer().Start(ctx, "create website")
+	defer createWebsiteSpan.End()
+
+	params := toSqlcCreateWebsiteParams(web)
+	jsonByte, jsonErr := json.Marshal(params)
+	if jsonErr == nil {
+		createWebsiteSpan.SetAttributes(attribute.String("params", string(jsonByte)))
+	}
+
+	// return web if url exist
+	webModel, err := r.db.CreateWebsite(ctx, params)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			createWebsiteSpan.SetStatus(codes.Error, err.Error())
+			createWebsiteSpan.RecordError(err)
+		}
+
+		return err
+	}
+
+	// follow redirect of merged website
+	if webModel.RedirectUuid.Valid {
+		createWebsiteSpan.SetAttributes(attribute.String("redirect_uuid", webModel.RedirectUuid.String))
+
+		webModel, err = r.db.GetWebsite(ctx, webModel.RedirectUuid)
+		if err != nil {
+			createWebsiteSpan.SetStatus(codes.Error, err.Error())
+			createWebsiteSpan.RecordError(err)
+
+			return fmt.Errorf("follow website redirect fail: %w", err)
+		}
+
+		web.URL = webModel.Url.String
+	}
+
+	web.UUID = webModel.Uuid.String
+	web.Title, web.RawContent = webModel.Title.String, webModel.Content.String
+	web.UpdateTime = webModel.UpdateTime.Time.UTC().Truncate(MinTimeUnit)
+	web.Conf = r.conf
+
+	return nil
+}
+
+func (r *SqliteRepo) UpdateWebsite(ctx context.Context, web *model.Website) error {
+	_, updateWebsiteSpan := repository.GetTracer().Start(ctx, "update website")
+	defer updateWebsiteSpan.End()
+
+	params := toSqlcUpdateWebsiteParams(web)
+	jsonByte, jsonErr := json.Marshal(params)
+	if jsonErr == nil {
+		updateWebsiteSpan.SetAttributes(attribute.String("params", string(jsonByte)))
+	}
+
+	_, err := r.db.UpdateWebsite(ctx, params)
+	if err != nil {
+		updateWebsiteSpan.SetStatus(codes.Error, err.Error())
+		updateWebsiteSpan.RecordError(err)
+
+		return fmt.Errorf("update website fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) DeleteWebsite(ctx context.Context, web *model.Website) error {
+	_, deleteWebsiteSpan := repository.GetTracer().Start(ctx, "delete website")
+	defer deleteWebsiteSpan.End()
+
+	deleteWebsiteSpan.SetAttributes(attribute.String("params.website_uuid", web.UUID))
+
+	err := r.db.DeleteWebsite(ctx, toSqlString(web.UUID))
+	if err != nil {
+		deleteWebsiteSpan.SetStatus(codes.Error, err.Error())
+		deleteWebsiteSpan.RecordError(err)
+
+		return fmt.Errorf("fail to delete website: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) FindWebsites(ctx context.Context) ([]model.Website, error) {
+	_, listWebsitesSpan := repository.GetTracer().Start(ctx, "find websites")
+	defer listWebsitesSpan.End()
+
+	webModels, err := r.db.ListActiveWebsites(ctx)
+	if err != nil {
+		listWebsitesSpan.SetStatus(codes.Error, err.Error())
+		listWebsitesSpan.RecordError(err)
+
+		return nil, fmt.Errorf("list websites fail: %w", err)
+	}
+
+	webs := make([]model.Website, len(webModels))
+	for i, webModel := range webModels {
+		webs[i] = fromSqlcWebsite(webModel)
+		webs[i].Conf = r.conf
+	}
+
+	return webs, nil
+}
+
+func (r *SqliteRepo) FindWebsitesPage(ctx context.Context, query repository.WebsitesQuery) ([]model.Website, error) {
+	_, listWebsitesPageSpan := repository.GetTracer().Start(ctx, "find websites page")
+	defer listWebsitesPageSpan.End()
+
+	listWebsitesPageSpan.SetAttributes(
+		attribute.String("params.host", query.Host),
+		attribute.String("params.after_uuid", query.AfterUUID),
+		attribute.Int("params.limit", query.Limit),
+	)
+
+	if query.Limit <= 0 {
+		listWebsitesPageSpan.SetStatus(codes.Error, repository.ErrInvalidLimit.Error())
+		listWebsitesPageSpan.RecordError(repository.ErrInvalidLimit)
+
+		return nil, fmt.Errorf("list websites page fail: %w", repository.ErrInvalidLimit)
+	}
+
+	webModels, err := r.db.ListActiveWebsitesPage(ctx, sqlc.ListActiveWebsitesPageParams{
+		AfterUuid: query.AfterUUID,
+		Host:      query.Host,
+		PageLimit: int64(query.Limit),
+	})
+	if err != nil {
+		listWebsitesPageSpan.SetStatus(codes.Error, err.Error())
+		listWebsitesPageSpan.RecordError(err)
+
+		return nil, fmt.Errorf("list websites page fail: %w", err)
+	}
+
+	webs := make([]model.Website, len(webModels))
+	for i, webModel := range webModels {
+		webs[i] = fromSqlcWebsite(webModel)
+		webs[i].Conf = r.conf
+	}
+
+	return webs, nil
+}
+
+func (r *SqliteRepo) FindWebsite(ctx context.Context, uuid string) (*model.Website, error) {
+	_, findWebsiteSpan := repository.GetTracer().Start(ctx, "find website")
+	defer findWebsiteSpan.End()
+
+	findWebsiteSpan.SetAttributes(attribute.String("params.website_uuid", uuid))
+
+	webModel, err := r.db.GetWebsite(ctx, toSqlString(uuid))
+	if err != nil {
+		return nil, fmt.Errorf("get website fail: %w", err)
+	}
+
+	web := fromSqlcWebsite(webModel)
+	web.Conf = r.conf
+
+	return &web, nil
+}
+
+func (r *SqliteRepo) RecordWebsiteMissing(ctx context.Context, web *model.Website, threshold int) error {
+	_, recordMissingSpan := repository.GetTracer().Start(ctx, "record website missing")
+	defer recordMissingSpan.End()
+
+	recordMissingSpan.SetAttributes(
+		attribute.String("params.uuid", web.UUID),
+		attribute.Int("params.threshold", threshold),
+	)
+
+	webModel, err := r.db.RecordWebsiteMissing(ctx, sqlc.RecordWebsiteMissingParams{
+		Uuid:      toSqlString(web.UUID),
+		Threshold: int64(threshold),
+	})
+	if err != nil {
+		recordMissingSpan.SetStatus(codes.Error, err.Error())
+		recordMissingSpan.RecordError(err)
+
+		return fmt.Errorf("record website missing fail: %w", err)
+	}
+
+	web.Status, web.MissingCount = webModel.Status, int(webModel.MissingCount)
+
+	return nil
+}
+
+func (r *SqliteRepo) ResetWebsiteMissing(ctx context.Context, web *model.Website) error {
+	_, resetMissingSpan := repository.GetTracer().Start(ctx, "reset website missing")
+	defer resetMissingSpan.End()
+
+	resetMissingSpan.SetAttributes(attribute.String("params.uuid", web.UUID))
+
+	webModel, err := r.db.ResetWebsiteMissing(ctx, toSqlString(web.UUID))
+	if err != nil {
+		resetMissingSpan.SetStatus(codes.Error, err.Error())
+		resetMissingSpan.RecordError(err)
+
+		return fmt.Errorf("reset website missing fail: %w", err)
+	}
+
+	web.Status, web.MissingCount = webModel.Status, int(webModel.MissingCount)
+
+	return nil
+}
+
+func (r *SqliteRepo) CleanupOrphanedWebsites(ctx context.Context, now time.Time, gracePeriod time.Duration) (repository.OrphanCleanupResult, error) {
+	_, cleanupSpan := repository.GetTracer().Start(ctx, "cleanup orphaned websites")
+	defer cleanupSpan.End()
+
+	cleanupSpan.SetAttributes(
+		attribute.String("params.now", now.String()),
+		attribute.String("params.grace_period", gracePeriod.String()),
+	)
+
+	var result repository.OrphanCleanupResult
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		q := txRepo.(*SqliteRepo).db
+
+		if _, err := q.ClearOrphanedWebsites(ctx); err != nil {
+			return err
+		}
+
+		marked, err := q.MarkOrphanedWebsites(ctx, toSqlTime(now))
+		if err != nil {
+			return err
+		}
+
+		deactivated, err := q.DeactivateOrphanedWebsites(ctx, toSqlTime(now.Add(-gracePeriod)))
+		if err != nil {
+			return err
+		}
+
+		fetchesSaved, err := q.CountDeactivatedOrphanedWebsites(ctx)
+		if err != nil {
+			return err
+		}
+
+		result = repository.OrphanCleanupResult{
+			Marked:       int(marked),
+			Deactivated:  int(deactivated),
+			FetchesSaved: int(fetchesSaved),
+		}
+
+		return nil
+	})
+	if err != nil {
+		cleanupSpan.SetStatus(codes.Error, err.Error())
+		cleanupSpan.RecordError(err)
+
+		return result, fmt.Errorf("cleanup orphaned websites fail: %w", err)
+	}
+
+	return result, nil
+}
+
+// WithTx runs fn with a repository bound to a single transaction, which is
+// committed if fn succeeds and rolled back otherwise. Nested calls join the
+// outer transaction.
+func (r *SqliteRepo) WithTx(ctx context.Context, fn func(repository.Repository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	_, withTxSpan := repository.GetTracer().Start(ctx, "with tx")
+	defer withTxSpan.End()
+
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		withTxSpan.SetStatus(codes.Error, err.Error())
+		withTxSpan.RecordError(err)
+
+		return fmt.Errorf("begin tx fail: %w", err)
+	}
+	defer tx.Rollback()
+
+	txRepo := &SqliteRepo{
+		conn:  r.conn,
+		db:    r.db.WithTx(tx),
+		tx:    tx,
+		stats: r.stats,
+		conf:  r.conf,
+	}
+
+	if err := fn(txRepo); err != nil {
+		withTxSpan.SetStatus(codes.Error, err.Error())
+		withTxSpan.RecordError(err)
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		withTxSpan.SetStatus(codes.Error, err.Error())
+		withTxSpan.RecordError(err)
+
+		return fmt.Errorf("commit tx fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) MergeWebsite(ctx context.Context, from, to *model.Website) error {
+	_, mergeWebsiteSpan := repository.GetTracer().Start(ctx, "merge website")
+	defer mergeWebsiteSpan.End()
+
+	mergeWebsiteSpan.SetAttributes(
+		attribute.String("params.from_uuid", from.UUID),
+		attribute.String("params.to_uuid", to.UUID),
+	)
+
+	merged := *to
+	merged.Merge(*from)
+
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		q := txRepo.(*SqliteRepo).db
+
+		err := repository.MergeUserWebsites(ctx, txRepo, from.UUID, to.UUID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.UpdateWebsite(ctx, toSqlcUpdateWebsiteParams(&merged))
+		if err != nil {
+			return err
+		}
+
+		err = q.MoveUserWebsites(ctx, sqlc.MoveUserWebsitesParams{
+			FromUuid: toSqlString(from.UUID),
+			ToUuid:   toSqlString(to.UUID),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteUserWebsitesByWebsite(ctx, toSqlString(from.UUID))
+		if err != nil {
+			return err
+		}
+
+		err = q.MoveUserWebsiteTags(ctx, sqlc.MoveUserWebsiteTagsParams{FromUuid: from.UUID, ToUuid: to.UUID})
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteUserWebsiteTagsByWebsite(ctx, from.UUID)
+		if err != nil {
+			return err
+		}
+
+		return q.RedirectWebsite(ctx, sqlc.RedirectWebsiteParams{
+			FromUuid: toSqlString(from.UUID),
+			ToUuid:   toSqlString(to.UUID),
+		})
+	})
+	if err != nil {
+		mergeWebsiteSpan.SetStatus(codes.Error, err.Error())
+		mergeWebsiteSpan.RecordError(err)
+
+		return fmt.Errorf("merge website fail: %w", err)
+	}
+
+	*to = merged
+	from.Status, from.RedirectUUID = model.WebsiteStatusInactive, to.UUID
+
+	return nil
+}
+
+func (r *SqliteRepo) CreateUserWebsite(ctx context.Context, web *model.UserWebsite) error {
+	_, createUserWebsiteSpan := repository.GetTracer().Start(ctx, "create user website")
+	defer createUserWebsiteSpan.End()
+
+	params := toSqlcCreateUserWebsiteParams(web)
+
+	jsonByte, jsonErr := json.Marshal(params)
+	if jsonErr == nil {
+		createUserWebsiteSpan.SetAttributes(attribute.String("params", string(jsonByte)))
+	}
+
+	// subscribing again brings back website deactivated for having no subscriber
+	err := r.db.ReactivateOrphanedWebsite(ctx, toSqlString(web.WebsiteUUID))
+	if err != nil {
+		createUserWebsiteSpan.SetStatus(codes.Error, fmt.Errorf("reactivate website fail:%w", err).Error())
+		createUserWebsiteSpan.RecordError(err)
+
+		return fmt.Errorf("reactivate website fail: %w", err)
+	}
+
+	userWebModel, err := r.db.CreateUserWebsite(ctx, params)
+	if err != nil {
+		createUserWebsiteSpan.SetStatus(codes.Error, fmt.Errorf("create user website fail:%w", err).Error())
+		createUserWebsiteSpan.RecordError(err)
+
+		return fmt.Errorf("create user website fail: %w", err)
+	}
+
+	web.GroupName = userWebModel.GroupName.String
+	web.DisplayTitle, web.Note = userWebModel.DisplayTitle, userWebModel.Note
+	web.LastReadChapter = userWebModel.LastReadChapter
+	web.AccessTime = userWebModel.AccessTime.Time.UTC().Truncate(MinTimeUnit)
+	tempWeb, err := r.FindWebsite(ctx, web.WebsiteUUID)
+	if err != nil {
+		createUserWebsiteSpan.SetStatus(codes.Error, fmt.Errorf("assign website fail:%w", err).Error())
+		createUserWebsiteSpan.RecordError(err)
+
+		return fmt.Errorf("assign website fail: %w", err)
+	}
+
+	web.Website = *tempWeb
+
+	return nil
+}
+
+func (r *SqliteRepo) UpdateUserWebsite(ctx context.Context, web *model.UserWebsite) error {
+	_, updateUserWebsiteSpan := repository.GetTracer().Start(ctx, "update user website")
+	defer updateUserWebsiteSpan.End()
+
+	params := toSqlcUpdateUserWebsiteParams(web)
+	jsonByte, jsonErr := json.Marshal(params)
+	if jsonErr == nil {
+		updateUserWebsiteSpan.SetAttributes(attribute.String("params", string(jsonByte)))
+	}
+
+	_, err := r.db.UpdateUserWebsite(ctx, params)
+	if err != nil {
+		updateUserWebsiteSpan.SetStatus(codes.Error, err.Error())
+		updateUserWebsiteSpan.RecordError(err)
+
+		return fmt.Errorf("fail to update user website: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) DeleteUserWebsite(ctx context.Context, web *model.UserWebsite) error {
+	_, deleteUserWebsiteSpan := repository.GetTracer().Start(ctx, "delete user website")
+	defer deleteUserWebsiteSpan.End()
+
+	params := toSqlcDeleteUserWebsiteParams(web)
+	jsonByte, jsonErr := json.Marshal(params)
+	if jsonErr == nil {
+		deleteUserWebsiteSpan.SetAttributes(attribute.String("params", string(jsonByte)))
+	}
+
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		q := txRepo.(*SqliteRepo).db
+
+		err := q.DeleteUserWebsiteTags(ctx, sqlc.DeleteUserWebsiteTagsParams{
+			UserUuid: web.UserUUID, WebsiteUuid: web.WebsiteUUID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteUserWebsite(ctx, params)
+	})
+	if err != nil {
+		deleteUserWebsiteSpan.SetStatus(codes.Error, err.Error())
+		deleteUserWebsiteSpan.RecordError(err)
+
+		return fmt.Errorf("delete user website fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) RenameUserGroup(ctx context.Context, userUUID, from, to string) error {
+	_, renameUserGroupSpan := repository.GetTracer().Start(ctx, "rename user group")
+	defer renameUserGroupSpan.End()
+
+	renameUserGroupSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.from", from),
+		attribute.String("params.to", to),
+	)
+
+	err := r.db.RenameUserGroup(ctx, sqlc.RenameUserGroupParams{
+		UserUuid:  toSqlString(userUUID),
+		FromGroup: toSqlString(from),
+		ToGroup:   toSqlString(to),
+	})
+	if err != nil {
+		renameUserGroupSpan.SetStatus(codes.Error, err.Error())
+		renameUserGroupSpan.RecordError(err)
+
+		return fmt.Errorf("rename user group fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) UngroupUserGroup(ctx context.Context, userUUID, group string) error {
+	_, ungroupUserGroupSpan := repository.GetTracer().Start(ctx, "ungroup user group")
+	defer ungroupUserGroupSpan.End()
+
+	ungroupUserGroupSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.group", group),
+	)
+
+	err := r.db.UngroupUserGroup(ctx, sqlc.UngroupUserGroupParams{
+		UserUuid:  toSqlString(userUUID),
+		GroupName: toSqlString(group),
+	})
+	if err != nil {
+		ungroupUserGroupSpan.SetStatus(codes.Error, err.Error())
+		ungroupUserGroupSpan.RecordError(err)
+
+		return fmt.Errorf("ungroup user group fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) DeleteUserGroup(ctx context.Context, userUUID, group string) error {
+	_, deleteUserGroupSpan := repository.GetTracer().Start(ctx, "delete user group")
+	defer deleteUserGroupSpan.End()
+
+	deleteUserGroupSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.group", group),
+	)
+
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		q := txRepo.(*SqliteRepo).db
+
+		err := q.DeleteUserGroupTags(ctx, sqlc.DeleteUserGroupTagsParams{
+			UserUuid:  userUUID,
+			GroupName: toSqlString(group),
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteUserGroup(ctx, sqlc.DeleteUserGroupParams{
+			UserUuid:  toSqlString(userUUID),
+			GroupName: toSqlString(group),
+		})
+	})
+	if err != nil {
+		deleteUserGroupSpan.SetStatus(codes.Error, err.Error())
+		deleteUserGroupSpan.RecordError(err)
+
+		return fmt.Errorf("delete user group fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) RefreshUserWebsites(ctx context.Context, userUUID, group string, accessTime time.Time) (int, error) {
+	_, refreshUserWebsitesSpan := repository.GetTracer().Start(ctx, "refresh user websites")
+	defer refreshUserWebsitesSpan.End()
+
+	refreshUserWebsitesSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.group", group),
+		attribute.String("params.access_time", accessTime.String()),
+	)
+
+	updated, err := r.db.RefreshUserWebsites(ctx, sqlc.RefreshUserWebsitesParams{
+		AccessTime: toSqlTime(accessTime),
+		UserUuid:   toSqlString(userUUID),
+		GroupName:  group,
+	})
+	if err != nil {
+		refreshUserWebsitesSpan.SetStatus(codes.Error, err.Error())
+		refreshUserWebsitesSpan.RecordError(err)
+
+		return 0, fmt.Errorf("refresh user websites fail: %w", err)
+	}
+
+	return int(updated), nil
+}
+
+func (r *SqliteRepo) FindUserWebsites(ctx context.Context, userUUID string) (model.UserWebsites, error) {
+	_, listUserWebsitesSpan := repository.GetTracer().Start(ctx, "find user websites")
+	defer listUserWebsitesSpan.End()
+
+	listUserWebsitesSpan.SetAttributes(attribute.String("params.user_uuid", userUUID))
+
+	userWebModels, err := r.db.ListUserWebsites(ctx, toSqlString(userUUID))
+	if err != nil {
+		listUserWebsitesSpan.SetStatus(codes.Error, err.Error())
+		listUserWebsitesSpan.RecordError(err)
+
+		return nil, fmt.Errorf("list user websites fail: %w", err)
+	}
+
+	webs := make(model.UserWebsites, len(userWebModels))
+	for i, userWebModel := range userWebModels {
+		webs[i] = fromSqlcListUserWebsitesRow(userWebModel)
+		webs[i].Website.Conf = r.conf
+	}
+
+	return webs, nil
+}
+
+func (r *SqliteRepo) FindUserWebsitesByGroup(ctx context.Context, userUUID, groupName string) (model.WebsiteGroup, error) {
+	_, listUserWebsitesByGroupSpan := repository.GetTracer().Start(ctx, "find user websites by group")
+	defer listUserWebsitesByGroupSpan.End()
+
+	params := toSqlcListUserWebsitesByGroupParams(userUUID, groupName)
+	jsonByte, jsonErr := json.Marshal(params)
+	if jsonErr == nil {
+		listUserWebsitesByGroupSpan.SetAttributes(attribute.String("params", string(jsonByte)))
+	}
+
+	userWebModels, err := r.db.ListUserWebsitesByGroup(ctx, params)
+	if err != nil {
+		listUserWebsitesByGroupSpan.SetStatus(codes.Error, err.Error())
+		listUserWebsitesByGroupSpan.RecordError(err)
+
+		return nil, fmt.Errorf("find user websites by group fail: %w", err)
+	}
+
+	group := make(model.WebsiteGroup, len(userWebModels))
+	for i, userWebModel := range userWebModels {
+		group[i] = fromSqlcListUserWebsitesByGroupRow(userWebModel)
+		group[i].Website.Conf = r.conf
+	}
+
+	return group, nil
+}
+
+func (r *SqliteRepo) listUserWebsitesPage(ctx context.Context, query repository.UserWebsitesQuery, cursor repository.UserWebsitesCursor, hasCursor bool) ([]sqlc.ListUserWebsitesRow, error) {
+	userUUID, limit := toSqlString(query.UserUUID), int64(query.Limit+1)
+	tags := toSqlTags(query.FilterTags())
+
+	var webs []sqlc.ListUserWebsitesRow
+	switch query.SortBy {
+	case repository.SortByUpdateTime:
+		rows, err := r.db.ListUserWebsitesPageByUpdateTime(ctx, sqlc.ListUserWebsitesPageByUpdateTimeParams{
+			UserUuid: userUUID, GroupName: query.GroupName, Tags: tags, HasCursor: hasCursor,
+			CursorTime: toSqlTime(cursor.Time), CursorUuid: toSqlString(cursor.WebsiteUUID), PageLimit: limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			webs = append(webs, sqlc.ListUserWebsitesRow(row))
+		}
+	case repository.SortByAccessTime:
+		rows, err := r.db.ListUserWebsitesPageByAccessTime(ctx, sqlc.ListUserWebsitesPageByAccessTimeParams{
+			UserUuid: userUUID, GroupName: query.GroupName, Tags: tags, HasCursor: hasCursor,
+			CursorTime: toSqlTime(cursor.Time), CursorUuid: toSqlString(cursor.WebsiteUUID), PageLimit: limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			webs = append(webs, sqlc.ListUserWebsitesRow(row))
+		}
+	case repository.SortByTitle:
+		rows, err := r.db.ListUserWebsitesPageByTitle(ctx, sqlc.ListUserWebsitesPageByTitleParams{
+			UserUuid: userUUID, GroupName: query.GroupName, Tags: tags, HasCursor: hasCursor,
+			CursorTitle: cursor.Title, CursorUuid: toSqlString(cursor.WebsiteUUID), PageLimit: limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			webs = append(webs, sqlc.ListUserWebsitesRow(row))
+		}
+	default:
+		rows, err := r.db.ListUserWebsitesPageByUnread(ctx, sqlc.ListUserWebsitesPageByUnreadParams{
+			UserUuid: userUUID, GroupName: query.GroupName, Tags: tags, HasCursor: hasCursor,
+			CursorUnread: cursor.Unread, CursorTime: toSqlTime(cursor.Time), CursorUuid: toSqlString(cursor.WebsiteUUID), PageLimit: limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			webs = append(webs, sqlc.ListUserWebsitesRow(row))
+		}
+	}
+
+	return webs, nil
+}
+
+func (r *SqliteRepo) FindUserWebsitesPage(ctx context.Context, query repository.UserWebsitesQuery) (model.UserWebsites, string, error) {
+	_, listUserWebsitesPageSpan := repository.GetTracer().Start(ctx, "find user websites page")
+	defer listUserWebsitesPageSpan.End()
+
+	listUserWebsitesPageSpan.SetAttributes(
+		attribute.String("params.user_uuid", query.UserUUID),
+		attribute.String("params.group_name", query.GroupName),
+		attribute.StringSlice("params.tags", query.Tags),
+		attribute.String("params.sort_by", query.SortBy),
+		attribute.String("params.cursor", query.Cursor),
+		attribute.Int("params.limit", query.Limit),
+	)
+
+	cursor, err := query.Validate()
+	if err != nil {
+		listUserWebsitesPageSpan.SetStatus(codes.Error, err.Error())
+		listUserWebsitesPageSpan.RecordError(err)
+
+		return nil, "", fmt.Errorf("list user websites page fail: %w", err)
+	}
+
+	hasCursor := cursor != nil
+	if !hasCursor {
+		cursor = &repository.UserWebsitesCursor{}
+	}
+
+	rows, err := r.listUserWebsitesPage(ctx, query, *cursor, hasCursor)
+	if err != nil {
+		listUserWebsitesPageSpan.SetStatus(codes.Error, err.Error())
+		listUserWebsitesPageSpan.RecordError(err)
+
+		return nil, "", fmt.Errorf("list user websites page fail: %w", err)
+	}
+
+	rows, nextCursor := repository.NextPageOf(query, rows, cursorUserWebsite)
+	webs := make(model.UserWebsites, 0, len(rows))
+	for _, row := range rows {
+		web := fromSqlcListUserWebsitesRow(row)
+		web.Website.Conf = r.conf
+		webs = append(webs, web)
+	}
+
+	return webs, nextCursor, nil
+}
+
+// SearchUserWebsites ranks websites in go as sqlite has no trigram support
+func (r *SqliteRepo) SearchUserWebsites(ctx context.Context, userUUID, query string, limit int) (model.UserWebsites, error) {
+	_, searchUserWebsitesSpan := repository.GetTracer().Start(ctx, "search user websites")
+	defer searchUserWebsitesSpan.End()
+
+	searchUserWebsitesSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.query", query),
+		attribute.Int("params.limit", limit),
+	)
+
+	userWebModels, err := r.db.ListUserWebsites(ctx, toSqlString(userUUID))
+	if err != nil {
+		searchUserWebsitesSpan.SetStatus(codes.Error, err.Error())
+		searchUserWebsitesSpan.RecordError(err)
+
+		return nil, fmt.Errorf("search user websites fail: %w", err)
+	}
+
+	webs := make(model.UserWebsites, len(userWebModels))
+	for i, userWebModel := range userWebModels {
+		webs[i] = fromSqlcListUserWebsitesRow(userWebModel)
+		webs[i].Website.Conf = r.conf
+	}
+
+	return repository.RankUserWebsites(webs, query, limit), nil
+}
+
+func (r *SqliteRepo) FindUserWebsite(ctx context.Context, userUUID, websiteUUID string) (*model.UserWebsite, error) {
+	_, findUserWebsiteSpan := repository.GetTracer().Start(ctx, "find user website")
+	defer findUserWebsiteSpan.End()
+
+	findUserWebsiteSpan.SetAttributes(attribute.String("params.user_uuid", userUUID), attribute.String("params.website_uuid", websiteUUID))
+
+	userWebModel, err := r.db.GetUserWebsite(ctx, toSqlcGetUserWebsitesParams(userUUID, websiteUUID))
+	if err != nil {
+		findUserWebsiteSpan.SetStatus(codes.Error, err.Error())
+		findUserWebsiteSpan.RecordError(err)
+
+		return nil, fmt.Errorf("get user website fail: %w", err)
+	}
+
+	web := fromSqlcGetUserWebsiteRow(userWebModel)
+	web.Website.Conf = r.conf
+
+	return &web, nil
+}
+
+func (r *SqliteRepo) FindUserUnreadSummary(ctx context.Context, userUUID string, limit int) (model.UnreadSummary, error) {
+	_, findUserUnreadSummarySpan := repository.GetTracer().Start(ctx, "find user unread summary")
+	defer findUserUnreadSummarySpan.End()
+
+	findUserUnreadSummarySpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.Int("params.limit", limit),
+	)
+
+	groupRows, err := r.db.CountUserUnreadWebsitesByGroup(ctx, toSqlString(userUUID))
+	if err != nil {
+		findUserUnreadSummarySpan.SetStatus(codes.Error, err.Error())
+		findUserUnreadSummarySpan.RecordError(err)
+
+		return model.UnreadSummary{}, fmt.Errorf("count user unread websites fail: %w", err)
+	}
+
+	webRows, err := r.db.ListUserUnreadWebsites(ctx, sqlc.ListUserUnreadWebsitesParams{
+		UserUuid:  toSqlString(userUUID),
+		PageLimit: int64(limit),
+	})
+	if err != nil {
+		findUserUnreadSummarySpan.SetStatus(codes.Error, err.Error())
+		findUserUnreadSummarySpan.RecordError(err)
+
+		return model.UnreadSummary{}, fmt.Errorf("list user unread websites fail: %w", err)
+	}
+
+	groups := make([]model.GroupUnreadCount, len(groupRows))
+	for i, row := range groupRows {
+		groups[i] = model.GroupUnreadCount{GroupName: row.GroupName.String, UnreadCount: int(row.UnreadCount)}
+	}
+
+	webs := make([]model.UnreadWebsite, len(webRows))
+	for i, row := range webRows {
+		title := row.DisplayTitle
+		if title == "" {
+			title = row.Title.String
+		}
+
+		webs[i] = model.UnreadWebsite{
+			WebsiteUUID: row.WebsiteUuid.String,
+			GroupName:   row.GroupName.String,
+			Title:       title,
+			UpdateTime:  row.UpdateTime.Time.UTC().Truncate(MinTimeUnit),
+		}
+	}
+
+	return model.NewUnreadSummary(groups, webs), nil
+}
+
+func (r *SqliteRepo) FindWebsiteUserUUIDs(ctx context.Context, websiteUUID string) ([]string, error) {
+	_, listWebsiteUserUUIDsSpan := repository.GetTracer().Start(ctx, "find website user uuids")
+	defer listWebsiteUserUUIDsSpan.End()
+
+	listWebsiteUserUUIDsSpan.SetAttributes(attribute.String("params.website_uuid", websiteUUID))
+
+	userUUIDModels, err := r.db.ListWebsiteUserUUIDs(ctx, toSqlString(websiteUUID))
+	if err != nil {
+		listWebsiteUserUUIDsSpan.SetStatus(codes.Error, err.Error())
+		listWebsiteUserUUIDsSpan.RecordError(err)
+
+		return nil, fmt.Errorf("find website user uuids fail: %w", err)
+	}
+
+	userUUIDs := make([]string, 0, len(userUUIDModels))
+	for _, userUUID := range userUUIDModels {
+		userUUIDs = append(userUUIDs, userUUID.String)
+	}
+
+	return userUUIDs, nil
+}
+
+func fromSqlcAuditEvent(eventModel sqlc.AuditEvent) model.AuditEvent {
+	return model.AuditEvent{
+		UUID:        eventModel.Uuid,
+		UserUUID:    eventModel.UserUuid,
+		WebsiteUUID: eventModel.WebsiteUuid,
+		Action:      eventModel.Action,
+		Before:      eventModel.Before,
+		After:       eventModel.After,
+		RequestID:   eventModel.RequestID,
+		CreatedAt:   eventModel.CreatedAt.UTC(),
+	}
+}
+
+func (r *SqliteRepo) FindUserTags(ctx context.Context, userUUID string) ([]model.Tag, error) {
+	_, listUserTagsSpan := repository.GetTracer().Start(ctx, "find user tags")
+	defer listUserTagsSpan.End()
+
+	listUserTagsSpan.SetAttributes(attribute.String("params.user_uuid", userUUID))
+
+	rows, err := r.db.ListUserTags(ctx, userUUID)
+	if err != nil {
+		listUserTagsSpan.SetStatus(codes.Error, err.Error())
+		listUserTagsSpan.RecordError(err)
+
+		return nil, fmt.Errorf("find user tags fail: %w", err)
+	}
+
+	tags := make([]model.Tag, len(rows))
+	for i, row := range rows {
+		tags[i] = model.Tag{Name: row.Tag, WebsiteCount: int(row.WebsiteCount)}
+	}
+
+	return tags, nil
+}
+
+func (r *SqliteRepo) FindUserWebsiteTags(ctx context.Context, userUUID, websiteUUID string) ([]string, error) {
+	_, listUserWebsiteTagsSpan := repository.GetTracer().Start(ctx, "find user website tags")
+	defer listUserWebsiteTagsSpan.End()
+
+	listUserWebsiteTagsSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.website_uuid", websiteUUID),
+	)
+
+	tags, err := r.db.ListUserWebsiteTags(ctx, sqlc.ListUserWebsiteTagsParams{UserUuid: userUUID, WebsiteUuid: websiteUUID})
+	if err != nil {
+		listUserWebsiteTagsSpan.SetStatus(codes.Error, err.Error())
+		listUserWebsiteTagsSpan.RecordError(err)
+
+		return nil, fmt.Errorf("find user website tags fail: %w", err)
+	}
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return tags, nil
+}
+
+func (r *SqliteRepo) AddUserWebsiteTag(ctx context.Context, userUUID, websiteUUID, tag string) error {
+	_, addUserWebsiteTagSpan := repository.GetTracer().Start(ctx, "add user website tag")
+	defer addUserWebsiteTagSpan.End()
+
+	addUserWebsiteTagSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.website_uuid", websiteUUID),
+		attribute.String("params.tag", tag),
+	)
+
+	err := r.db.CreateUserWebsiteTag(ctx, sqlc.CreateUserWebsiteTagParams{UserUuid: userUUID, WebsiteUuid: websiteUUID, Tag: tag})
+	if err != nil {
+		addUserWebsiteTagSpan.SetStatus(codes.Error, err.Error())
+		addUserWebsiteTagSpan.RecordError(err)
+
+		return fmt.Errorf("add user website tag fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) RemoveUserWebsiteTag(ctx context.Context, userUUID, websiteUUID, tag string) error {
+	_, removeUserWebsiteTagSpan := repository.GetTracer().Start(ctx, "remove user website tag")
+	defer removeUserWebsiteTagSpan.End()
+
+	removeUserWebsiteTagSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.website_uuid", websiteUUID),
+		attribute.String("params.tag", tag),
+	)
+
+	err := r.db.DeleteUserWebsiteTag(ctx, sqlc.DeleteUserWebsiteTagParams{UserUuid: userUUID, WebsiteUuid: websiteUUID, Tag: tag})
+	if err != nil {
+		removeUserWebsiteTagSpan.SetStatus(codes.Error, err.Error())
+		removeUserWebsiteTagSpan.RecordError(err)
+
+		return fmt.Errorf("remove user website tag fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) RenameUserTag(ctx context.Context, userUUID, from, to string) error {
+	_, renameUserTagSpan := repository.GetTracer().Start(ctx, "rename user tag")
+	defer renameUserTagSpan.End()
+
+	renameUserTagSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.from", from),
+		attribute.String("params.to", to),
+	)
+
+	if from == to {
+		return nil
+	}
+
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		q := txRepo.(*SqliteRepo).db
+
+		err := q.RenameUserTag(ctx, sqlc.RenameUserTagParams{UserUuid: userUUID, FromTag: from, ToTag: to})
+		if err != nil {
+			return err
+		}
+
+		// websites already having tag to keep the existing one
+		return q.DeleteUserTag(ctx, sqlc.DeleteUserTagParams{UserUuid: userUUID, Tag: from})
+	})
+	if err != nil {
+		renameUserTagSpan.SetStatus(codes.Error, err.Error())
+		renameUserTagSpan.RecordError(err)
+
+		return fmt.Errorf("rename user tag fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) DeleteUserTag(ctx context.Context, userUUID, tag string) error {
+	_, deleteUserTagSpan := repository.GetTracer().Start(ctx, "delete user tag")
+	defer deleteUserTagSpan.End()
+
+	deleteUserTagSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.tag", tag),
+	)
+
+	err := r.db.DeleteUserTag(ctx, sqlc.DeleteUserTagParams{UserUuid: userUUID, Tag: tag})
+	if err != nil {
+		deleteUserTagSpan.SetStatus(codes.Error, err.Error())
+		deleteUserTagSpan.RecordError(err)
+
+		return fmt.Errorf("delete user tag fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) CreateAuditEvent(ctx context.Context, event *model.AuditEvent) error {
+	_, createAuditEventSpan := repository.GetTracer().Start(ctx, "create audit event")
+	defer createAuditEventSpan.End()
+
+	createAuditEventSpan.SetAttributes(
+		attribute.String("params.user_uuid", event.UserUUID),
+		attribute.String("params.website_uuid", event.WebsiteUUID),
+		attribute.String("params.action", event.Action),
+	)
+
+	eventModel, err := r.db.CreateAuditEvent(ctx, sqlc.CreateAuditEventParams{
+		Uuid:        event.UUID,
+		UserUuid:    event.UserUUID,
+		WebsiteUuid: event.WebsiteUUID,
+		Action:      event.Action,
+		Before:      event.Before,
+		After:       event.After,
+		RequestID:   event.RequestID,
+		CreatedAt:   event.CreatedAt.UTC(),
+	})
+	if err != nil {
+		createAuditEventSpan.SetStatus(codes.Error, err.Error())
+		createAuditEventSpan.RecordError(err)
+
+		return fmt.Errorf("create audit event fail: %w", err)
+	}
+
+	*event = fromSqlcAuditEvent(eventModel)
+
+	return nil
+}
+
+func (r *SqliteRepo) FindAuditEvents(ctx context.Context, query repository.AuditEventsQuery) ([]model.AuditEvent, error) {
+	_, listAuditEventsSpan := repository.GetTracer().Start(ctx, "find audit events")
+	defer listAuditEventsSpan.End()
+
+	listAuditEventsSpan.SetAttributes(
+		attribute.String("params.user_uuid", query.UserUUID),
+		attribute.String("params.website_uuid", query.WebsiteUUID),
+		attribute.Int("params.limit", query.Limit),
+	)
+
+	if query.Limit <= 0 {
+		listAuditEventsSpan.SetStatus(codes.Error, repository.ErrInvalidLimit.Error())
+		listAuditEventsSpan.RecordError(repository.ErrInvalidLimit)
+
+		return nil, fmt.Errorf("list audit events fail: %w", repository.ErrInvalidLimit)
+	}
+
+	eventModels, err := r.db.ListAuditEvents(ctx, sqlc.ListAuditEventsParams{
+		UserUuid:    query.UserUUID,
+		WebsiteUuid: query.WebsiteUUID,
+		PageLimit:   int64(query.Limit),
+	})
+	if err != nil {
+		listAuditEventsSpan.SetStatus(codes.Error, err.Error())
+		listAuditEventsSpan.RecordError(err)
+
+		return nil, fmt.Errorf("list audit events fail: %w", err)
+	}
+
+	events := make([]model.AuditEvent, len(eventModels))
+	for i, eventModel := range eventModels {
+		events[i] = fromSqlcAuditEvent(eventModel)
+	}
+
+	return events, nil
+}
+
+func fromSqlcJob(jobModel sqlc.Job) model.Job {
+	return model.Job{
+		UUID:      jobModel.Uuid,
+		UserUUID:  jobModel.UserUuid,
+		Kind:      jobModel.Kind,
+		Status:    jobModel.Status,
+		Result:    jobModel.Result,
+		CreatedAt: jobModel.CreatedAt.UTC(),
+		UpdatedAt: jobModel.UpdatedAt.UTC(),
+	}
+}
+
+func (r *SqliteRepo) CreateJob(ctx context.Context, job *model.Job) error {
+	_, createJobSpan := repository.GetTracer().Start(ctx, "create job")
+	defer createJobSpan.End()
+
+	createJobSpan.SetAttributes(
+		attribute.String("params.user_uuid", job.UserUUID),
+		attribute.String("params.kind", job.Kind),
+	)
+
+	jobModel, err := r.db.CreateJob(ctx, sqlc.CreateJobParams{
+		Uuid:      job.UUID,
+		UserUuid:  job.UserUUID,
+		Kind:      job.Kind,
+		Status:    job.Status,
+		Result:    job.Result,
+		CreatedAt: job.CreatedAt.UTC(),
+		UpdatedAt: job.UpdatedAt.UTC(),
+	})
+	if err != nil {
+		createJobSpan.SetStatus(codes.Error, err.Error())
+		createJobSpan.RecordError(err)
+
+		return fmt.Errorf("create job fail: %w", err)
+	}
+
+	*job = fromSqlcJob(jobModel)
+
+	return nil
+}
+
+func (r *SqliteRepo) UpdateJob(ctx context.Context, job *model.Job) error {
+	_, updateJobSpan := repository.GetTracer().Start(ctx, "update job")
+	defer updateJobSpan.End()
+
+	updateJobSpan.SetAttributes(
+		attribute.String("params.uuid", job.UUID),
+		attribute.String("params.status", job.Status),
+	)
+
+	jobModel, err := r.db.UpdateJob(ctx, sqlc.UpdateJobParams{
+		Uuid:      job.UUID,
+		Status:    job.Status,
+		Result:    job.Result,
+		UpdatedAt: job.UpdatedAt.UTC(),
+	})
+	if err != nil {
+		updateJobSpan.SetStatus(codes.Error, err.Error())
+		updateJobSpan.RecordError(err)
+
+		return fmt.Errorf("update job fail: %w", err)
+	}
+
+	*job = fromSqlcJob(jobModel)
+
+	return nil
+}
+
+func (r *SqliteRepo) FindJob(ctx context.Context, userUUID, uuid string) (*model.Job, error) {
+	_, getJobSpan := repository.GetTracer().Start(ctx, "find job")
+	defer getJobSpan.End()
+
+	getJobSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.uuid", uuid),
+	)
+
+	jobModel, err := r.db.GetJob(ctx, sqlc.GetJobParams{Uuid: uuid, UserUuid: userUUID})
+	if err != nil {
+		getJobSpan.SetStatus(codes.Error, err.Error())
+		getJobSpan.RecordError(err)
+
+		return nil, fmt.Errorf("find job fail: %w", err)
+	}
+
+	job := fromSqlcJob(jobModel)
+
+	return &job, nil
+}
+
+func (r *SqliteRepo) CountUserJobs(ctx context.Context, userUUID, kind string, since time.Time) (int, error) {
+	_, countUserJobsSpan := repository.GetTracer().Start(ctx, "count user jobs")
+	defer countUserJobsSpan.End()
+
+	countUserJobsSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.kind", kind),
+		attribute.String("params.since", since.String()),
+	)
+
+	count, err := r.db.CountUserJobs(ctx, sqlc.CountUserJobsParams{UserUuid: userUUID, Kind: kind, Since: since.UTC()})
+	if err != nil {
+		countUserJobsSpan.SetStatus(codes.Error, err.Error())
+		countUserJobsSpan.RecordError(err)
+
+		return 0, fmt.Errorf("count user jobs fail: %w", err)
+	}
+
+	return int(count), nil
+}
+
+// LockUserJobs does nothing as transaction of sqlite is started with
+// immediate lock, which blocks the other writers already
+func (r *SqliteRepo) LockUserJobs(ctx context.Context, userUUID string) error {
+	return nil
+}
+
+func fromSqlcFeedToken(tokenModel sqlc.UserFeedToken) model.FeedToken {
+	return model.FeedToken{
+		UserUUID:  tokenModel.UserUuid,
+		Token:     tokenModel.Token,
+		CreatedAt: tokenModel.CreatedAt.UTC(),
+	}
+}
+
+func (r *SqliteRepo) SaveFeedToken(ctx context.Context, token *model.FeedToken) error {
+	_, saveFeedTokenSpan := repository.GetTracer().Start(ctx, "save feed token")
+	defer saveFeedTokenSpan.End()
+
+	saveFeedTokenSpan.SetAttributes(attribute.String("params.user_uuid", token.UserUUID))
+
+	tokenModel, err := r.db.SaveUserFeedToken(ctx, sqlc.SaveUserFeedTokenParams{
+		UserUuid:  token.UserUUID,
+		Token:     token.Token,
+		CreatedAt: token.CreatedAt.UTC(),
+	})
+	if err != nil {
+		saveFeedTokenSpan.SetStatus(codes.Error, err.Error())
+		saveFeedTokenSpan.RecordError(err)
+
+		return fmt.Errorf("save feed token fail: %w", err)
+	}
+
+	*token = fromSqlcFeedToken(tokenModel)
+
+	return nil
+}
+
+func (r *SqliteRepo) FindFeedToken(ctx context.Context, userUUID string) (*model.FeedToken, error) {
+	_, findFeedTokenSpan := repository.GetTracer().Start(ctx, "find feed token")
+	defer findFeedTokenSpan.End()
+
+	findFeedTokenSpan.SetAttributes(attribute.String("params.user_uuid", userUUID))
+
+	tokenModel, err := r.db.GetUserFeedToken(ctx, userUUID)
+	if err != nil {
+		findFeedTokenSpan.SetStatus(codes.Error, err.Error())
+		findFeedTokenSpan.RecordError(err)
+
+		return nil, fmt.Errorf("find feed token fail: %w", err)
+	}
+
+	token := fromSqlcFeedToken(tokenModel)
+
+	return &token, nil
+}
+
+func (r *SqliteRepo) FindFeedTokenByToken(ctx context.Context, token string) (*model.FeedToken, error) {
+	_, findFeedTokenSpan := repository.GetTracer().Start(ctx, "find feed token by token")
+	defer findFeedTokenSpan.End()
+
+	tokenModel, err := r.db.GetUserFeedTokenByToken(ctx, token)
+	if err != nil {
+		findFeedTokenSpan.SetStatus(codes.Error, err.Error())
+		findFeedTokenSpan.RecordError(err)
+
+		return nil, fmt.Errorf("find feed token fail: %w", err)
+	}
+
+	feedToken := fromSqlcFeedToken(tokenModel)
+
+	return &feedToken, nil
+}
+
+func fromSqlcWebhook(webhookModel sqlc.Webhook) model.Webhook {
+	return model.Webhook{
+		UUID:         webhookModel.Uuid,
+		UserUUID:     webhookModel.UserUuid,
+		URL:          webhookModel.Url,
+		GroupName:    webhookModel.GroupName,
+		Secret:       webhookModel.Secret,
+		Enabled:      webhookModel.Enabled,
+		FailureCount: int(webhookModel.FailureCount),
+		CreatedAt:    webhookModel.CreatedAt.UTC(),
+		UpdatedAt:    webhookModel.UpdatedAt.UTC(),
+	}
+}
+
+func fromSqlcWebhooks(webhookModels []sqlc.Webhook) []model.Webhook {
+	webhooks := make([]model.Webhook, 0, len(webhookModels))
+	for _, webhookModel := range webhookModels {
+		webhooks = append(webhooks, fromSqlcWebhook(webhookModel))
+	}
+
+	return webhooks
+}
+
+func (r *SqliteRepo) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	_, createWebhookSpan := repository.GetTracer().Start(ctx, "create webhook")
+	defer createWebhookSpan.End()
+
+	createWebhookSpan.SetAttributes(
+		attribute.String("params.user_uuid", webhook.UserUUID),
+		attribute.String("params.group_name", webhook.GroupName),
+	)
+
+	webhookModel, err := r.db.CreateWebhook(ctx, sqlc.CreateWebhookParams{
+		Uuid:         webhook.UUID,
+		UserUuid:     webhook.UserUUID,
+		Url:          webhook.URL,
+		GroupName:    webhook.GroupName,
+		Secret:       webhook.Secret,
+		Enabled:      webhook.Enabled,
+		FailureCount: int64(webhook.FailureCount),
+		CreatedAt:    webhook.CreatedAt.UTC(),
+		UpdatedAt:    webhook.UpdatedAt.UTC(),
+	})
+	if err != nil {
+		createWebhookSpan.SetStatus(codes.Error, err.Error())
+		createWebhookSpan.RecordError(err)
+
+		return fmt.Errorf("create webhook fail: %w", err)
+	}
+
+	*webhook = fromSqlcWebhook(webhookModel)
+
+	return nil
+}
+
+func (r *SqliteRepo) UpdateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	_, updateWebhookSpan := repository.GetTracer().Start(ctx, "update webhook")
+	defer updateWebhookSpan.End()
+
+	updateWebhookSpan.SetAttributes(
+		attribute.String("params.uuid", webhook.UUID),
+		attribute.Bool("params.enabled", webhook.Enabled),
+		attribute.Int("params.failure_count", webhook.FailureCount),
+	)
+
+	webhookModel, err := r.db.UpdateWebhook(ctx, sqlc.UpdateWebhookParams{
+		Url:          webhook.URL,
+		GroupName:    webhook.GroupName,
+		Enabled:      webhook.Enabled,
+		FailureCount: int64(webhook.FailureCount),
+		UpdatedAt:    webhook.UpdatedAt.UTC(),
+		Uuid:         webhook.UUID,
+	})
+	if err != nil {
+		updateWebhookSpan.SetStatus(codes.Error, err.Error())
+		updateWebhookSpan.RecordError(err)
+
+		return fmt.Errorf("update webhook fail: %w", err)
+	}
+
+	*webhook = fromSqlcWebhook(webhookModel)
+
+	return nil
+}
+
+func (r *SqliteRepo) DeleteWebhook(ctx context.Context, webhook *model.Webhook) error {
+	_, deleteWebhookSpan := repository.GetTracer().Start(ctx, "delete webhook")
+	defer deleteWebhookSpan.End()
+
+	deleteWebhookSpan.SetAttributes(attribute.String("params.uuid", webhook.UUID))
+
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		q := txRepo.(*SqliteRepo).db
+
+		if err := q.DeleteWebhookDeliveries(ctx, webhook.UUID); err != nil {
+			return err
+		}
+
+		return q.DeleteWebhook(ctx, webhook.UUID)
+	})
+	if err != nil {
+		deleteWebhookSpan.SetStatus(codes.Error, err.Error())
+		deleteWebhookSpan.RecordError(err)
+
+		return fmt.Errorf("delete webhook fail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqliteRepo) FindWebhook(ctx context.Context, userUUID, uuid string) (*model.Webhook, error) {
+	_, getWebhookSpan := repository.GetTracer().Start(ctx, "find webhook")
+	defer getWebhookSpan.End()
+
+	getWebhookSpan.SetAttributes(
+		attribute.String("params.user_uuid", userUUID),
+		attribute.String("params.uuid", uuid),
+	)
+
+	webhookModel, err := r.db.GetWebhook(ctx, sqlc.GetWebhookParams{Uuid: uuid, UserUuid: userUUID})
+	if err != nil {
+		getWebhookSpan.SetStatus(codes.Error, err.Error())
+		getWebhookSpan.RecordError(err)
+
+		return nil, fmt.Errorf("find webhook fail: %w", err)
+	}
+
+	webhook := fromSqlcWebhook(webhookModel)
+
+	return &webhook, nil
+}
+
+func (r *SqliteRepo) FindWebhooks(ctx context.Context, userUUID string) ([]model.Webhook, error) {
+	_, listWebhooksSpan := repository.GetTracer().Start(ctx, "find webhooks")
+	defer listWebhooksSpan.End()
+
+	listWebhooksSpan.SetAttributes(attribute.String("params.user_uuid", userUUID))
+
+	webhookModels, err := r.db.ListUserWebhooks(ctx, userUUID)
+	if err != nil {
+		listWebhooksSpan.SetStatus(codes.Error, err.Error())
+		listWebhooksSpan.RecordError(err)
+
+		return nil, fmt.Errorf("find webhooks fail: %w", err)
+	}
+
+	return fromSqlcWebhooks(webhookModels), nil
+}
+
+func (r *SqliteRepo) FindWebsiteWebhooks(ctx context.Context, websiteUUID string) ([]model.Webhook, error) {
+	_, listWebsiteWebhooksSpan := repository.GetTracer().Start(ctx, "find website webhooks")
+	defer listWebsiteWebhooksSpan.End()
+
+	listWebsiteWebhooksSpan.SetAttributes(attribute.String("params.website_uuid", websiteUUID))
+
+	webhookModels, err := r.db.ListWebsiteWebhooks(ctx, toSqlString(websiteUUID))
+	if err != nil {
+		listWebsiteWebhooksSpan.SetStatus(codes.Error, err.Error())
+		listWebsiteWebhooksSpan.RecordError(err)
+
+		return nil, fmt.Errorf("find website webhooks fail: %w", err)
+	}
+
+	return fromSqlcWebhooks(webhookModels), nil
+}
+
+func fromSqlcWebhookDelivery(deliveryModel sqlc.WebhookDelivery) model.WebhookDelivery {
+	return model.WebhookDelivery{
+		UUID:           deliveryModel.Uuid,
+		WebhookUUID:    deliveryModel.WebhookUuid,
+		WebsiteUUID:    deliveryModel.WebsiteUuid,
+		Status:         deliveryModel.Status,
+		Payload:        deliveryModel.Payload,
+		Attempts:       int(deliveryModel.Attempts),
+		ResponseStatus: int(deliveryModel.ResponseStatus),
+		Error:          deliveryModel.Error,
+		CreatedAt:      deliveryModel.CreatedAt.UTC(),
+		UpdatedAt:      deliveryModel.UpdatedAt.UTC(),
+	}
+}
+
+func (r *SqliteRepo) CreateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	_, createWebhookDeliverySpan := repository.GetTracer().Start(ctx, "create webhook delivery")
+	defer createWebhookDeliverySpan.End()
+
+	createWebhookDeliverySpan.SetAttributes(
+		attribute.String("params.webhook_uuid", delivery.WebhookUUID),
+		attribute.String("params.website_uuid", delivery.WebsiteUUID),
+	)
+
+	deliveryModel, err := r.db.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+		Uuid:           delivery.UUID,
+		WebhookUuid:    delivery.WebhookUUID,
+		WebsiteUuid:    delivery.WebsiteUUID,
+		Status:         delivery.Status,
+		Payload:        delivery.Payload,
+		Attempts:       int64(delivery.Attempts),
+		ResponseStatus: int64(delivery.ResponseStatus),
+		Error:          delivery.Error,
+		CreatedAt:      delivery.CreatedAt.UTC(),
+		UpdatedAt:      delivery.UpdatedAt.UTC(),
+	})
+	if err != nil {
+		createWebhookDeliverySpan.SetStatus(codes.Error, err.Error())
+		createWebhookDeliverySpan.RecordError(err)
+
+		return fmt.Errorf("create webhook delivery fail: %w", err)
+	}
+
+	*delivery = fromSqlcWebhookDelivery(deliveryModel)
+
+	return nil
+}
+
+func (r *SqliteRepo) UpdateWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	_, updateWebhookDeliverySpan := repository.GetTracer().Start(ctx, "update webhook delivery")
+	defer updateWebhookDeliverySpan.End()
+
+	updateWebhookDeliverySpan.SetAttributes(
+		attribute.String("params.uuid", delivery.UUID),
+		attribute.String("params.status", delivery.Status),
+	)
+
+	deliveryModel, err := r.db.UpdateWebhookDelivery(ctx, sqlc.UpdateWebhookDeliveryParams{
+		Status:         delivery.Status,
+		Attempts:       int64(delivery.Attempts),
+		ResponseStatus: int64(delivery.ResponseStatus),
+		Error:          delivery.Error,
+		UpdatedAt:      delivery.UpdatedAt.UTC(),
+		Uuid:           delivery.UUID,
+	})
+	if err != nil {
+		updateWebhookDeliverySpan.SetStatus(codes.Error, err.Error())
+		updateWebhookDeliverySpan.RecordError(err)
+
+		return fmt.Errorf("update webhook delivery fail: %w", err)
+	}
+
+	*delivery = fromSqlcWebhookDelivery(deliveryModel)
+
+	return nil
+}
+
+func (r *SqliteRepo) FindWebhookDelivery(ctx context.Context, uuid string) (*model.WebhookDelivery, error) {
+	_, getWebhookDeliverySpan := repository.GetTracer().Start(ctx, "find webhook delivery")
+	defer getWebhookDeliverySpan.End()
+
+	getWebhookDeliverySpan.SetAttributes(attribute.String("params.uuid", uuid))
+
+	deliveryModel, err := r.db.GetWebhookDelivery(ctx, uuid)
+	if err != nil {
+		getWebhookDeliverySpan.SetStatus(codes.Error, err.Error())
+		getWebhookDeliverySpan.RecordError(err)
+
+		return nil, fmt.Errorf("find webhook delivery fail: %w", err)
+	}
+
+	delivery := fromSqlcWebhookDelivery(deliveryModel)
+
+	return &delivery, nil
+}
+
+func (r *SqliteRepo) FindWebhookDeliveries(ctx context.Context, webhookUUID string, limit int) ([]model.WebhookDelivery, error) {
+	_, listWebhookDeliveriesSpan := repository.GetTracer().Start(ctx, "find webhook deliveries")
+	defer listWebhookDeliveriesSpan.End()
+
+	listWebhookDeliveriesSpan.SetAttributes(
+		attribute.String("params.webhook_uuid", webhookUUID),
+		attribute.Int("params.limit", limit),
+	)
+
+	deliveryModels, err := r.db.ListWebhookDeliveries(ctx, sqlc.ListWebhookDeliveriesParams{
+		WebhookUuid: webhookUUID,
+		PageLimit:   int64(limit),
+	})
+	if err != nil {
+		listWebhookDeliveriesSpan.SetStatus(codes.Error, err.Error())
+		listWebhookDeliveriesSpan.RecordError(err)
+
+		return nil, fmt.Errorf("find webhook deliveries fail: %w", err)
+	}
+
+	deliveries := make([]model.WebhookDelivery, 0, len(deliveryModels))
+	for _, deliveryModel := range deliveryModels {
+		deliveries = append(deliveries, fromSqlcWebhookDelivery(deliveryModel))
+	}
+
+	return deliveries, nil
+}
+
+func (r *SqliteRepo) Stats() sql.DBStats {
+	return r.stats()
+}
@@ -0,0 +1,191 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/htchan/WebHistory/internal/repository/repotest"
+	"github.com/htchan/WebHistory/internal/utils"
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", connString)
+	if err != nil {
+		t.Fatalf("open database fail: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+func TestNewRepo(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+
+	tests := []struct {
+		name string
+		db   *sql.DB
+	}{
+		{
+			name: "providing a sqlc database",
+			db:   db,
+		},
+		{
+			name: "providing nil database",
+			db:   nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := NewRepo(test.db, &config.WebsiteConfig{})
+			if test.db != nil {
+				assert.Equal(t, test.db.Stats(), repo.stats())
+			}
+		})
+	}
+}
+
+// deferred transaction fails with SQLITE_BUSY when it upgrades its read lock
+// to write, immediate transaction waits for the others instead
+func TestSqliteRepo_WithTx_ImmediateLock(t *testing.T) {
+	t.Parallel()
+
+	r := NewRepo(openTestDB(t), &config.WebsiteConfig{})
+	userUUID := uuid.New().String()
+	since := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			errs[i] = r.WithTx(context.Background(), func(txRepo repository.Repository) error {
+				if _, err := txRepo.CountUserJobs(context.Background(), userUUID, model.JobKindCheck, since); err != nil {
+					return err
+				}
+
+				// keep the others reading before this transaction writes
+				time.Sleep(10 * time.Millisecond)
+
+				job := model.NewJob(userUUID, model.JobKindCheck)
+
+				return txRepo.CreateJob(context.Background(), &job)
+			})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	count, err := r.CountUserJobs(context.Background(), userUUID, model.JobKindCheck, since)
+	assert.NoError(t, err)
+	assert.Equal(t, len(errs), count)
+}
+
+// sqlite stores time as text, time in other zone is stored in UTC to keep
+// the order of the text same as the time
+func TestSqliteRepo_TextTimestamp(t *testing.T) {
+	t.Parallel()
+
+	r := NewRepo(openTestDB(t), &config.WebsiteConfig{})
+	userUUID, suffix := uuid.New().String(), uuid.New().String()
+	hongKong := time.FixedZone("HKT", 8*60*60)
+
+	webs := []model.Website{
+		{
+			UUID:       "text-timestamp-1-" + suffix,
+			URL:        "http://example.com/text-timestamp-1-" + suffix,
+			Title:      "text timestamp 1",
+			UpdateTime: time.Date(2020, 1, 2, 10, 0, 0, 0, hongKong),
+		},
+		{
+			UUID:       "text-timestamp-2-" + suffix,
+			URL:        "http://example.com/text-timestamp-2-" + suffix,
+			Title:      "text timestamp 2",
+			UpdateTime: time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC),
+		},
+	}
+	for i := range webs {
+		if err := r.CreateWebsite(context.Background(), &webs[i]); err != nil {
+			t.Fatalf("create website fail: %v", err)
+		}
+
+		userWeb := model.UserWebsite{WebsiteUUID: webs[i].UUID, UserUUID: userUUID, AccessTime: webs[i].UpdateTime}
+		if err := r.CreateUserWebsite(context.Background(), &userWeb); err != nil {
+			t.Fatalf("create user website fail: %v", err)
+		}
+	}
+
+	web, err := r.FindWebsite(context.Background(), webs[0].UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 1, 2, 2, 0, 0, 0, time.UTC), web.UpdateTime)
+
+	page, _, err := r.FindUserWebsitesPage(context.Background(), repository.UserWebsitesQuery{
+		UserUUID: userUUID, SortBy: repository.SortByUpdateTime, Limit: 2,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, page, 2) {
+		assert.Equal(t, webs[1].UUID, page[0].WebsiteUUID)
+		assert.Equal(t, webs[0].UUID, page[1].WebsiteUUID)
+	}
+}
+
+func TestSqliteMigration(t *testing.T) {
+	t.Parallel()
+
+	db, err := sql.Open("sqlite", utils.SqliteConnString(filepath.Join(t.TempDir(), "migration.db")))
+	if err != nil {
+		t.Fatalf("open database fail: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		t.Fatalf("create migrate driver fail: %v", err)
+	}
+
+	m, err := migrate.NewWithDatabaseInstance("file://../../../database/migrations/sqlite", "sqlite", driver)
+	if err != nil {
+		t.Fatalf("create migrate fail: %v", err)
+	}
+
+	assert.NoError(t, m.Up(), "migrate up")
+	assert.NoError(t, m.Down(), "migrate down")
+	assert.NoError(t, m.Up(), "migrate up again")
+}
+
+func TestSqliteRepo_Contract(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t)
+
+	repotest.Run(t, func(t *testing.T, conf *config.WebsiteConfig) repository.Repository {
+		return NewRepo(db, conf)
+	})
+}
@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/htchan/WebHistory/internal/utils"
+	"go.uber.org/goleak"
+)
+
+var connString string
+
+func TestMain(m *testing.M) {
+	leak := flag.Bool("leak", false, "check for memory leaks")
+	flag.Parse()
+
+	dir, err := os.MkdirTemp("", "webhistory_test_sqlite")
+	if err != nil {
+		log.Fatalf("fail to create temp dir: %v", err)
+	}
+
+	connString = utils.SqliteConnString(filepath.Join(dir, "test.db"))
+
+	err = setupMigrate(connString)
+	if err != nil {
+		os.RemoveAll(dir)
+		log.Fatalf("fail to migrate: %v", err)
+	}
+
+	if *leak {
+		goleak.VerifyTestMain(m)
+		os.RemoveAll(dir)
+	} else {
+		code := m.Run()
+
+		os.RemoveAll(dir)
+		os.Exit(code)
+	}
+}
+
+func setupMigrate(connString string) error {
+	db, err := sql.Open("sqlite", connString)
+	if err != nil {
+		return fmt.Errorf("migrate fail: %w", err)
+	}
+	defer db.Close()
+
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		return err
+	}
+
+	m, err := migrate.NewWithDatabaseInstance("file://../../../database/migrations/sqlite", "sqlite", driver)
+	if err != nil {
+		return err
+	}
+
+	err = m.Up()
+	if err != nil {
+		return err
+	}
+
+	defer m.Close()
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package website
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+// @Summary		Merge website
+// @description	merge all subscriptions of website into the website of given url
+// @Tags			web-history-admin
+// @Accept			json
+// @Produce		json
+// @Param			X-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param			websiteUUID	path		string	true	"website uuid to be merged"
+// @Param			url	formData		string	true	"url of target website"
+// @Success		200			{object}	mergeWebsiteResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/admin/websites/{websiteUUID}/merge [post]
+func mergeWebsiteHandler(r repository.Repository, conf *config.WebsiteConfig) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		webUUID := chi.URLParam(req, "webUUID")
+		url := req.Context().Value(ContextKeyWebURL).(string)
+
+		from, err := r.FindWebsite(req.Context(), webUUID)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("find website failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		to := model.NewWebsite(url, conf)
+		statusCode := http.StatusBadRequest
+		err = r.WithTx(req.Context(), func(txRepo repository.Repository) error {
+			err := txRepo.CreateWebsite(req.Context(), &to)
+			if err != nil {
+				zerolog.Ctx(req.Context()).Error().Err(err).Msg("create website failed")
+
+				return err
+			}
+
+			if from.UUID == to.UUID {
+				return ErrInvalidParams
+			}
+
+			err = txRepo.MergeWebsite(req.Context(), from, &to)
+			if err != nil {
+				zerolog.Ctx(req.Context()).Error().Err(err).Msg("merge website failed")
+				statusCode = http.StatusInternalServerError
+
+				return err
+			}
+
+			return nil
+		})
+		if err != nil {
+			writeError(res, statusCode, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, mergeWebsiteResp{
+			From: fromModelWebsite(*from),
+			To:   fromModelWebsite(to),
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package website
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+)
+
+// @Summary		Merge website
+// @description	merge all subscriptions of website into the website of given url
+// @Tags			web-history-admin-v2
+// @Accept			json
+// @Produce		json
+// @Param			X-ADMIN-TOKEN	header		string			true	"admin token"
+// @Param			websiteUUID		path		string			true	"website uuid to be merged"
+// @Param			body			body		mergeWebsiteReq	true	"url of target website"
+// @Success		200				{object}	mergeWebsiteResp
+// @Failure		400				{object}	apiErrResp
+// @Failure		404				{object}	apiErrResp
+// @Router			/api/web-watcher/v2/admin/websites/{websiteUUID}/merge [post]
+func mergeWebsiteHandlerV2(r repository.Repository, conf *config.WebsiteConfig) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		webUUID := chi.URLParam(req, "webUUID")
+		body := req.Context().Value(ContextKeyBody).(mergeWebsiteReq)
+
+		from, err := r.FindWebsite(req.Context(), webUUID)
+		if err != nil {
+			return err
+		}
+
+		to := model.NewWebsite(body.Url, conf)
+		err = r.WithTx(req.Context(), func(txRepo repository.Repository) error {
+			if err := txRepo.CreateWebsite(req.Context(), &to); err != nil {
+				return err
+			}
+
+			if from.UUID == to.UUID {
+				return invalidParamsError(map[string]string{"url": "must not be the url of merged website"})
+			}
+
+			return txRepo.MergeWebsite(req.Context(), from, &to)
+		})
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, mergeWebsiteResp{
+			From: fromModelWebsite(*from),
+			To:   fromModelWebsite(to),
+		})
+
+		return nil
+	})
+}
+
+// @Summary		List audit events
+// @description	list audit events of all users from the latest
+// @Tags			web-history-admin-v2
+// @Produce		json
+// @Param			X-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param			user_uuid		query		string	false	"only list events of the user"
+// @Param			website_uuid	query		string	false	"only list events of the website"
+// @Param			limit			query		int		false	"max number of result"
+// @Success		200				{object}	listAuditEventsResp
+// @Failure		400				{object}	apiErrResp
+// @Router			/api/web-watcher/v2/admin/audit-events [get]
+func listAdminAuditEventsHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		query := req.Context().Value(ContextKeyAudit).(repository.AuditEventsQuery)
+
+		events, err := r.FindAuditEvents(req.Context(), query)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, listAuditEventsResp{fromModelAuditEvents(events)})
+
+		return nil
+	})
+}
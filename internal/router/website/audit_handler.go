@@ -0,0 +1,95 @@
+package website
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+func requestID(ctx context.Context) string {
+	if id, ok := ctx.Value(ContextKeyReqID).(uuid.UUID); ok {
+		return id.String()
+	}
+
+	return ""
+}
+
+// recordAuditEvent stores the audit event of user action changing the user
+// website from before to after. It should be called with the repository of
+// the transaction making the change, so the event is kept only if the change
+// is committed.
+func recordAuditEvent(ctx context.Context, r repository.Repository, action string, before, after *model.UserWebsite) error {
+	web := after
+	if web == nil {
+		web = before
+	}
+
+	event := model.NewAuditEvent(web.UserUUID, web.WebsiteUUID, action, requestID(ctx), before, after)
+
+	err := r.CreateAuditEvent(ctx, &event)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("action", action).Msg("create audit event failed")
+	}
+
+	return err
+}
+
+// @Summary		List audit events
+// @description	list audit events of user from the latest
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			website_uuid	query		string	false	"only list events of the website"
+// @Param			limit			query		int		false	"max number of result"
+// @Success		200				{object}	listAuditEventsResp
+// @Failure		400				{object}	errResp
+// @Router			/api/web-watcher/websites/audit-events [get]
+func listAuditEventsHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		query := req.Context().Value(ContextKeyAudit).(repository.AuditEventsQuery)
+		query.UserUUID = req.Context().Value(ContextKeyUserUUID).(string)
+
+		events, err := r.FindAuditEvents(req.Context(), query)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("find audit events failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, listAuditEventsResp{fromModelAuditEvents(events)})
+	}
+}
+
+// @Summary		List audit events
+// @description	list audit events of all users from the latest
+// @Tags			web-history-admin
+// @Accept			json
+// @Produce		json
+// @Param			X-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param			user_uuid		query		string	false	"only list events of the user"
+// @Param			website_uuid	query		string	false	"only list events of the website"
+// @Param			limit			query		int		false	"max number of result"
+// @Success		200				{object}	listAuditEventsResp
+// @Failure		400				{object}	errResp
+// @Router			/api/web-watcher/admin/audit-events [get]
+func listAdminAuditEventsHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		query := req.Context().Value(ContextKeyAudit).(repository.AuditEventsQuery)
+
+		events, err := r.FindAuditEvents(req.Context(), query)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("find audit events failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, listAuditEventsResp{fromModelAuditEvents(events)})
+	}
+}
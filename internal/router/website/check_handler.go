@@ -0,0 +1,117 @@
+package website
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	websiteupdate "github.com/htchan/WebHistory/internal/tasks/nats/website_update"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// startCheckJob creates the job tracking the update of web and publishes the
+// update immediately. User can request at most conf.Limit checks in
+// conf.Window. The job is marked failed if it cannot be published.
+func startCheckJob(
+	ctx context.Context,
+	r repository.Repository,
+	tasks websiteupdate.WebsiteUpdateTasks,
+	conf *config.WebsiteCheckConfig,
+	web model.UserWebsite,
+) (model.Job, error) {
+	if !tasks.Supported(&web.Website) {
+		return model.Job{}, ErrUnsupportedWebsite
+	}
+
+	job := model.NewJob(web.UserUUID, model.JobKindCheck)
+
+	// count and create within the lock, so parallel checks cannot exceed limit
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.LockUserJobs(ctx, web.UserUUID); err != nil {
+			return err
+		}
+
+		count, err := txRepo.CountUserJobs(ctx, web.UserUUID, model.JobKindCheck, time.Now().UTC().Add(-conf.Window))
+		if err != nil {
+			return err
+		} else if count >= conf.Limit {
+			return ErrTooManyChecks
+		}
+
+		return txRepo.CreateJob(ctx, &job)
+	})
+	if errors.Is(err, ErrTooManyChecks) {
+		return model.Job{}, err
+	} else if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("create check job failed")
+
+		return model.Job{}, err
+	}
+
+	jobCtx, jobSpan := getTracer().Start(ctx, "Website Check Job Creation")
+	defer jobSpan.End()
+
+	_, err = tasks.PublishJob(jobCtx, &web.Website, &job)
+	if err != nil {
+		jobSpan.SetStatus(codes.Error, err.Error())
+		jobSpan.RecordError(err)
+		zerolog.Ctx(ctx).Error().Err(err).Str("job_uuid", job.UUID).Msg("publish check job failed")
+
+		job.Status = model.JobStatusFailed
+		job.UpdatedAt = time.Now().UTC()
+		if updateErr := r.UpdateJob(ctx, &job); updateErr != nil {
+			zerolog.Ctx(ctx).Error().Err(updateErr).Str("job_uuid", job.UUID).Msg("update check job failed")
+		}
+
+		return job, fmt.Errorf("%w: %w", ErrJobNotPublished, err)
+	}
+
+	return job, nil
+}
+
+// @Summary		Check website
+// @description	update website in background immediately, the result is kept in the returned job
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			webUUID		path		string	true	"website uuid"
+// @Success		202			{object}	checkWebsiteResp
+// @Failure		400			{object}	errResp
+// @Failure		429			{object}	errResp
+// @Failure		500			{object}	errResp
+// @Failure		503			{object}	errResp
+// @Router			/api/web-watcher/websites/{webUUID}/check [post]
+func checkWebsiteHandler(r repository.Repository, tasks websiteupdate.WebsiteUpdateTasks, conf *config.WebsiteCheckConfig) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+
+		job, err := startCheckJob(req.Context(), r, tasks, conf, web)
+		if errors.Is(err, ErrUnsupportedWebsite) {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		} else if errors.Is(err, ErrTooManyChecks) {
+			writeError(res, http.StatusTooManyRequests, err)
+
+			return
+		} else if errors.Is(err, ErrJobNotPublished) {
+			writeError(res, http.StatusServiceUnavailable, ErrJobNotPublished)
+
+			return
+		} else if err != nil {
+			writeError(res, http.StatusInternalServerError, err)
+
+			return
+		}
+
+		res.WriteHeader(http.StatusAccepted)
+		encodeJsonResp(req.Context(), res, checkWebsiteResp{fromModelJob(job)})
+	}
+}
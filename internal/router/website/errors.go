@@ -0,0 +1,125 @@
+package website
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/htchan/WebHistory/internal/repository"
+	websiteupdate "github.com/htchan/WebHistory/internal/tasks/nats/website_update"
+	"github.com/htchan/WebHistory/internal/userservice"
+	"github.com/rs/zerolog"
+)
+
+// error codes of v2 api
+const (
+	ErrCodeInvalidParams      = "invalid_params"
+	ErrCodeUnauthorized       = "unauthorized"
+	ErrCodeNotFound           = "not_found"
+	ErrCodeConflict           = "conflict"
+	ErrCodeUnsupportedWebsite = "unsupported_website"
+	ErrCodeTooManyRequests    = "too_many_requests"
+	ErrCodeUnavailable        = "service_unavailable"
+	ErrCodeInternal           = "internal_error"
+)
+
+// APIError is the structured error returned by v2 api. Details lists the
+// invalid fields with the reasons.
+type APIError struct {
+	Status    int               `json:"-"`
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	Details   map[string]string `json:"details,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+func (err *APIError) Error() string {
+	return err.Message
+}
+
+func invalidParamsError(details map[string]string) *APIError {
+	return &APIError{
+		Status:  http.StatusBadRequest,
+		Code:    ErrCodeInvalidParams,
+		Message: ErrInvalidParams.Error(),
+		Details: details,
+	}
+}
+
+var apiErrors = []struct {
+	targets []error
+	status  int
+	code    string
+}{
+	{
+		targets: []error{
+			ErrInvalidParams, ErrInvalidChapter, ErrInvalidGroupName,
+			repository.ErrInvalidCursor, repository.ErrInvalidSortKey, repository.ErrInvalidLimit,
+		},
+		status: http.StatusBadRequest,
+		code:   ErrCodeInvalidParams,
+	},
+	{targets: []error{ErrUnauthorized}, status: http.StatusUnauthorized, code: ErrCodeUnauthorized},
+	{targets: []error{ErrRecordNotFound, sql.ErrNoRows}, status: http.StatusNotFound, code: ErrCodeNotFound},
+	{targets: []error{ErrGroupExists}, status: http.StatusConflict, code: ErrCodeConflict},
+	{targets: []error{ErrUnsupportedWebsite, websiteupdate.ErrNotSupportedWebsite}, status: http.StatusUnprocessableEntity, code: ErrCodeUnsupportedWebsite},
+	{targets: []error{ErrTooManyChecks}, status: http.StatusTooManyRequests, code: ErrCodeTooManyRequests},
+	{targets: []error{userservice.ErrUnavailable, ErrJobNotPublished}, status: http.StatusServiceUnavailable, code: ErrCodeUnavailable},
+}
+
+// toAPIError classifies err by the known errors it wraps. Message of unknown
+// error is hidden from client as it may expose internal detail.
+func toAPIError(err error) APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return *apiErr
+	}
+
+	for _, apiError := range apiErrors {
+		for _, target := range apiError.targets {
+			if errors.Is(err, target) {
+				return APIError{Status: apiError.status, Code: apiError.code, Message: target.Error()}
+			}
+		}
+	}
+
+	return APIError{Status: http.StatusInternalServerError, Code: ErrCodeInternal, Message: "internal error"}
+}
+
+type apiErrResp struct {
+	Error APIError `json:"error"`
+}
+
+func writeAPIError(res http.ResponseWriter, req *http.Request, err error) {
+	apiErr := toAPIError(err)
+	apiErr.RequestID = requestID(req.Context())
+
+	if apiErr.Status >= http.StatusInternalServerError {
+		zerolog.Ctx(req.Context()).Error().Err(err).Msg("handle request failed")
+	}
+
+	res.WriteHeader(apiErr.Status)
+	json.NewEncoder(res).Encode(apiErrResp{apiErr})
+}
+
+// errorWriter reports error of middleware. v1 writes err with status, while
+// v2 derives the status from err.
+type errorWriter func(res http.ResponseWriter, req *http.Request, status int, err error)
+
+func writeErrorV1(res http.ResponseWriter, _ *http.Request, status int, err error) {
+	writeError(res, status, err)
+}
+
+func writeErrorV2(res http.ResponseWriter, req *http.Request, _ int, err error) {
+	writeAPIError(res, req, err)
+}
+
+// apiHandler adapts v2 handler returning error to http.HandlerFunc
+func apiHandler(handle func(res http.ResponseWriter, req *http.Request) error) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		if err := handle(res, req); err != nil {
+			writeAPIError(res, req, err)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package website
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	websiteevents "github.com/htchan/WebHistory/internal/tasks/nats/website_events"
+	"github.com/rs/zerolog"
+)
+
+const (
+	eventTypeWebsiteUpdated = "website_updated"
+
+	// eventRetry is the reconnect delay in milliseconds suggested to client
+	eventRetry = 5000
+)
+
+// sseHeartbeatInterval keeps idle stream from being closed by proxies
+var sseHeartbeatInterval = 30 * time.Second
+
+type websiteEventResp struct {
+	Website WebsiteResp `json:"website"`
+}
+
+func writeWebsiteEvent(w io.Writer, event websiteevents.Event) error {
+	data, err := json.Marshal(websiteEventResp{
+		Website: WebsiteResp{
+			UUID:       event.Change.WebsiteUUID,
+			URL:        event.Change.URL,
+			Title:      event.Change.Title,
+			UpdateTime: event.Change.UpdateTime,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, eventTypeWebsiteUpdated, data)
+
+	return err
+}
+
+// streamWebsiteEvents replays the events after Last-Event-ID, then streams
+// live events until client disconnects. Error is only returned before the
+// stream starts.
+func streamWebsiteEvents(res http.ResponseWriter, req *http.Request, broker *websiteevents.Broker) error {
+	ctx := req.Context()
+	userUUID := ctx.Value(ContextKeyUserUUID).(string)
+	lastEventID := ctx.Value(ContextKeyEventID).(uint64)
+
+	// subscribe before replay, so events published during replay are not missed
+	sub := broker.Subscribe(userUUID)
+	defer broker.Unsubscribe(sub)
+
+	var replayed []websiteevents.Event
+	if lastEventID > 0 {
+		var err error
+		replayed, err = broker.Replay(ctx, userUUID, lastEventID)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Uint64("last_event_id", lastEventID).Msg("replay website events failed")
+
+			return err
+		}
+	}
+
+	rc := http.NewResponseController(res)
+	// stream lives longer than write timeout of server
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("clear write deadline failed")
+	}
+
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	for _, event := range replayed {
+		if err := writeWebsiteEvent(res, event); err != nil {
+			zerolog.Ctx(ctx).Debug().Err(err).Msg("write website event failed")
+
+			return nil
+		}
+
+		lastEventID = event.ID
+	}
+
+	fmt.Fprintf(res, "retry: %d\n\n", eventRetry)
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		if err := rc.Flush(); err != nil {
+			zerolog.Ctx(ctx).Debug().Err(err).Msg("flush website events failed")
+
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.Events():
+			// closed by broker, client reconnects and resumes from last event
+			if !ok {
+				return nil
+			}
+
+			if event.ID <= lastEventID {
+				continue
+			}
+
+			if err := writeWebsiteEvent(res, event); err != nil {
+				zerolog.Ctx(ctx).Debug().Err(err).Msg("write website event failed")
+
+				return nil
+			}
+
+			lastEventID = event.ID
+		case <-heartbeat.C:
+			io.WriteString(res, ": heartbeat\n\n")
+		}
+	}
+}
+
+// @Summary		Stream website events
+// @description	stream server-sent events of the changes of user websites, the
+// @description	events after Last-Event-ID header are replayed on reconnect
+// @Tags			web-history
+// @Produce		text/event-stream
+// @Security		UserToken
+// @Param			Last-Event-ID	header		string	false	"id of last received event"
+// @Success		200				{object}	websiteEventResp
+// @Failure		400				{object}	errResp
+// @Router			/api/web-watcher/websites/events [get]
+func websiteEventsHandler(broker *websiteevents.Broker) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		err := streamWebsiteEvents(res, req, broker)
+		if err != nil {
+			writeError(res, http.StatusInternalServerError, err)
+		}
+	}
+}
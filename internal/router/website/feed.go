@@ -0,0 +1,124 @@
+package website
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/model"
+)
+
+const (
+	MaxFeedEntries = 50
+
+	atomContentType = "application/atom+xml; charset=utf-8"
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// feedContent returns the unread chapters of web, or the latest chapter if
+// user has not recorded read progress
+func feedContent(web model.UserWebsite) string {
+	if chapters := web.UnreadChapters(); len(chapters) > 0 {
+		return strings.Join(chapters, "\n")
+	}
+
+	if web.Website.RawContent == "" || web.Website.Conf == nil {
+		return ""
+	}
+
+	return web.Website.Content()[0]
+}
+
+// newAtomFeed lists the latest updated websites of user from the newest.
+// Entry id changes with update time, so feed reader shows each update as new
+// entry.
+func newAtomFeed(userUUID, groupName, selfURL string, webs []model.UserWebsite) atomFeed {
+	webs = slices.Clone(webs)
+	slices.SortFunc(webs, func(a, b model.UserWebsite) int {
+		return cmp.Or(
+			b.Website.UpdateTime.Compare(a.Website.UpdateTime),
+			strings.Compare(a.WebsiteUUID, b.WebsiteUUID),
+		)
+	})
+
+	if len(webs) > MaxFeedEntries {
+		webs = webs[:MaxFeedEntries]
+	}
+
+	feed := atomFeed{
+		ID:      "urn:web-history:feed:" + userUUID,
+		Title:   "Web History updates",
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: "Web History"},
+		Link:    atomLink{Href: selfURL, Rel: "self"},
+		Entries: make([]atomEntry, 0, len(webs)),
+	}
+
+	if groupName != "" {
+		feed.ID += ":" + url.PathEscape(groupName)
+		feed.Title += " - " + groupName
+	}
+
+	if len(webs) > 0 {
+		feed.Updated = webs[0].Website.UpdateTime.UTC().Format(time.RFC3339)
+	}
+
+	for _, web := range webs {
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      fmt.Sprintf("urn:web-history:%s:%d", web.WebsiteUUID, web.Website.UpdateTime.Unix()),
+			Title:   web.Title(),
+			Updated: web.Website.UpdateTime.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: web.Website.URL},
+			Content: atomContent{Type: "text", Body: feedContent(web)},
+		})
+	}
+
+	return feed
+}
+
+func encodeAtomFeed(w io.Writer, feed atomFeed) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	return encoder.Encode(feed)
+}
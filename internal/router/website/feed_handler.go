@@ -0,0 +1,127 @@
+package website
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+func feedPath(routePrefix, token string) string {
+	return routePrefix + "/feeds/" + token
+}
+
+// userFeedToken returns the feed token of user, the token is created on
+// first access. rotate replaces the token so the old feed url stops working.
+func userFeedToken(ctx context.Context, r repository.Repository, userUUID string, rotate bool) (model.FeedToken, error) {
+	if !rotate {
+		token, err := r.FindFeedToken(ctx, userUUID)
+		if err == nil {
+			return *token, nil
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("find feed token failed")
+
+			return model.FeedToken{}, err
+		}
+	}
+
+	token := model.NewFeedToken(userUUID)
+	err := r.SaveFeedToken(ctx, &token)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Bool("rotate", rotate).Msg("save feed token failed")
+	}
+
+	return token, err
+}
+
+// @Summary		Get feed token
+// @description	get the secret token of user atom feed, the token is created on first access
+// @Tags			web-history
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	feedTokenResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/feed-token [get]
+func getFeedTokenHandler(r repository.Repository, routePrefix string) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		token, err := userFeedToken(req.Context(), r, userUUID, false)
+		if err != nil {
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, feedTokenResp{fromModelFeedToken(token, routePrefix)})
+	}
+}
+
+// @Summary		Rotate feed token
+// @description	replace the secret token of user atom feed, the old feed url stops working
+// @Tags			web-history
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	feedTokenResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/feed-token [put]
+func rotateFeedTokenHandler(r repository.Repository, routePrefix string) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		token, err := userFeedToken(req.Context(), r, userUUID, true)
+		if err != nil {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, feedTokenResp{fromModelFeedToken(token, routePrefix)})
+	}
+}
+
+// @Summary		Atom feed
+// @description	atom feed of the latest website updates of the user owning the token,
+// @description	the token in url authenticates feed readers
+// @Tags			web-history
+// @Produce		application/atom+xml
+// @Param			feedToken	path		string	true	"feed token"
+// @Param			group		query		string	false	"only list websites of the group"
+// @Success		200			{string}	string
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/feeds/{feedToken} [get]
+func feedHandler(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		token, err := r.FindFeedTokenByToken(req.Context(), chi.URLParam(req, "feedToken"))
+		if err != nil {
+			return err
+		}
+
+		groupName := strings.TrimSpace(req.URL.Query().Get("group"))
+
+		var webs []model.UserWebsite
+		if groupName != "" {
+			webs, err = r.FindUserWebsitesByGroup(req.Context(), token.UserUUID, groupName)
+		} else {
+			webs, err = r.FindUserWebsites(req.Context(), token.UserUUID)
+		}
+		if err != nil {
+			return err
+		}
+
+		res.Header().Set("Content-Type", atomContentType)
+
+		feed := newAtomFeed(token.UserUUID, groupName, req.URL.String(), webs)
+		if err := encodeAtomFeed(res, feed); err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("encode atom feed failed")
+		}
+
+		return nil
+	})
+}
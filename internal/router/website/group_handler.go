@@ -0,0 +1,314 @@
+package website
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+// modes of deleting group
+const (
+	GroupDeleteModeUnsubscribe = "unsubscribe" // unsubscribe all websites of group
+	GroupDeleteModeUngroup     = "ungroup"     // move websites back to the group named by website title
+)
+
+// moveUserGroup moves all websites of group from into group to. Group to must
+// exist if merge is true, and must not exist otherwise.
+func moveUserGroup(ctx context.Context, r repository.Repository, conf *config.WebsiteConfig, userUUID, from, to string, merge bool) (model.WebsiteGroup, error) {
+	var group model.WebsiteGroup
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		webs, err := txRepo.FindUserWebsitesByGroup(ctx, userUUID, from)
+		if err != nil {
+			return err
+		} else if len(webs) == 0 {
+			return ErrRecordNotFound
+		}
+
+		if from == to {
+			return ErrInvalidGroupName
+		}
+
+		for _, web := range webs {
+			if !validGroupName(conf, web, to) {
+				return ErrInvalidGroupName
+			}
+		}
+
+		group, err = txRepo.FindUserWebsitesByGroup(ctx, userUUID, to)
+		if err != nil {
+			return err
+		} else if merge && len(group) == 0 {
+			return ErrRecordNotFound
+		} else if !merge && len(group) > 0 {
+			return ErrGroupExists
+		}
+
+		err = txRepo.RenameUserGroup(ctx, userUUID, from, to)
+		if err != nil {
+			return err
+		}
+
+		for _, before := range webs {
+			web := before
+			web.GroupName = to
+
+			err := recordAuditEvent(ctx, txRepo, model.AuditActionChangeGroup, &before, &web)
+			if err != nil {
+				return err
+			}
+
+			group = append(group, web)
+		}
+
+		return nil
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("from", from).Str("to", to).Msg("move user group failed")
+	}
+
+	return group, err
+}
+
+// deleteUserGroup removes group by the given mode
+func deleteUserGroup(ctx context.Context, r repository.Repository, userUUID, group, mode string) error {
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		webs, err := txRepo.FindUserWebsitesByGroup(ctx, userUUID, group)
+		if err != nil {
+			return err
+		} else if len(webs) == 0 {
+			return ErrRecordNotFound
+		}
+
+		if mode == GroupDeleteModeUngroup {
+			err = txRepo.UngroupUserGroup(ctx, userUUID, group)
+		} else {
+			err = txRepo.DeleteUserGroup(ctx, userUUID, group)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, before := range webs {
+			if mode == GroupDeleteModeUngroup {
+				web := before
+				web.GroupName = web.Website.Title
+				err = recordAuditEvent(ctx, txRepo, model.AuditActionChangeGroup, &before, &web)
+			} else {
+				err = recordAuditEvent(ctx, txRepo, model.AuditActionDelete, &before, nil)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("group", group).Str("mode", mode).Msg("delete user group failed")
+	}
+
+	return err
+}
+
+// refreshUserWebsites marks all websites of group, or all websites of user if
+// group is empty, as read. Group must have websites.
+func refreshUserWebsites(ctx context.Context, r repository.Repository, userUUID, group string) (int, error) {
+	updated, err := r.RefreshUserWebsites(ctx, userUUID, group, time.Now().UTC().Truncate(5*time.Second))
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("group", group).Msg("refresh user websites failed")
+
+		return 0, err
+	} else if group != "" && updated == 0 {
+		return 0, ErrRecordNotFound
+	}
+
+	return updated, nil
+}
+
+// @Summary		List groups
+// @description	list groups of user with the number of websites and unread chapters
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	listGroupsResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/group-counts [get]
+func listGroupsHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		webs, err := r.FindUserWebsites(req.Context(), userUUID)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("find user websites failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, listGroupsResp{fromModelWebsiteGroupSummaries(webs.WebsiteGroups())})
+	}
+}
+
+// @Summary		Summarize unread websites
+// @description	count websites updated after last access by group, and list the latest updated ones
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	websiteSummaryResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/summary [get]
+func websiteSummaryHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		summary, err := r.FindUserUnreadSummary(req.Context(), userUUID, SummaryLatestUnread)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("find user unread summary failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, fromModelUnreadSummary(summary))
+	}
+}
+
+// @Summary		Rename group
+// @description	rename group of all its websites, new group name must not be used
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			groupName	path		string	true	"group name"
+// @Param			group_name	formData	string	true	"new group name"
+// @Success		200			{object}	renameGroupResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/groups/{groupName}/rename [put]
+func renameGroupHandler(r repository.Repository, conf *config.WebsiteConfig) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		groupName := req.Context().Value(ContextKeyGroup).(string)
+
+		group, err := moveUserGroup(req.Context(), r, conf, userUUID, chi.URLParam(req, "groupName"), groupName, false)
+		if err != nil {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, renameGroupResp{fromModelWebsiteGroup(group)})
+	}
+}
+
+// @Summary		Merge group
+// @description	move all websites of group into another existing group
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			groupName	path		string	true	"group name"
+// @Param			group_name	formData	string	true	"name of group merged into"
+// @Success		200			{object}	mergeGroupResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/groups/{groupName}/merge [put]
+func mergeGroupHandler(r repository.Repository, conf *config.WebsiteConfig) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		groupName := req.Context().Value(ContextKeyGroup).(string)
+
+		group, err := moveUserGroup(req.Context(), r, conf, userUUID, chi.URLParam(req, "groupName"), groupName, true)
+		if err != nil {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, mergeGroupResp{fromModelWebsiteGroup(group)})
+	}
+}
+
+// @Summary		Delete group
+// @description	delete group by unsubscribing its websites or moving them back to the group named by website title
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			groupName	path		string	true	"group name"
+// @Param			mode		query		string	true	"delete mode"	Enums(unsubscribe, ungroup)
+// @Success		200			{object}	deleteGroupResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/groups/{groupName} [delete]
+func deleteGroupHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		mode := req.Context().Value(ContextKeyMode).(string)
+		groupName := chi.URLParam(req, "groupName")
+
+		err := deleteUserGroup(req.Context(), r, userUUID, groupName, mode)
+		if err != nil {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, deleteGroupResp{fmt.Sprintf("group <%v> deleted", groupName)})
+	}
+}
+
+// @Summary		Refresh group
+// @description	mark all websites of group as read
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			groupName	path		string	true	"group name"
+// @Success		200			{object}	refreshWebsitesResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/groups/{groupName}/refresh [put]
+func refreshGroupHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		updated, err := refreshUserWebsites(req.Context(), r, userUUID, chi.URLParam(req, "groupName"))
+		if err != nil {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, refreshWebsitesResp{updated})
+	}
+}
+
+// @Summary		Refresh all websites
+// @description	mark all websites of user as read
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	refreshWebsitesResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/refresh [put]
+func refreshAllWebsitesHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		updated, err := refreshUserWebsites(req.Context(), r, userUUID, "")
+		if err != nil {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, refreshWebsitesResp{updated})
+	}
+}
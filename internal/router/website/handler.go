@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -26,6 +27,19 @@ func getTracer() trace.Tracer {
 	return tracer
 }
 
+// writePageError reports invalid pagination params separately from lookup failure
+func writePageError(res http.ResponseWriter, err error) {
+	if errors.Is(err, repository.ErrInvalidCursor) ||
+		errors.Is(err, repository.ErrInvalidSortKey) ||
+		errors.Is(err, repository.ErrInvalidLimit) {
+		writeError(res, http.StatusBadRequest, ErrInvalidParams)
+
+		return
+	}
+
+	writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+}
+
 func encodeJsonResp(ctx context.Context, res http.ResponseWriter, body any) {
 	_, encodeSpan := getTracer().Start(ctx, "Encode response")
 	defer encodeSpan.End()
@@ -38,13 +52,36 @@ func encodeJsonResp(ctx context.Context, res http.ResponseWriter, body any) {
 // @Tags			web-history
 // @Accept			json
 // @Produce		json
-// @Param			X-USER-UUID	header		string	true	"user uuid"
+// @Security		UserToken
+// @Param			limit		query		int		false	"page size, enables pagination"
+// @Param			cursor		query		string	false	"next_cursor of previous page"
+// @Param			sort		query		string	false	"sort key"	Enums(unread, update_time, access_time, title)
+// @Param			tag			query		[]string	false	"only list websites having all the tags"	collectionFormat(multi)
 // @Success		200			{object}	listAllWebsiteGroupsResp
 // @Failure		400			{object}	errResp
 // @Router			/api/web-watcher/websites/groups [get]
 func getAllWebsiteGroupsHandler(r repository.Repository) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
 		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		if query, ok := req.Context().Value(ContextKeyPage).(repository.UserWebsitesQuery); ok {
+			query.UserUUID = userUUID
+			webs, nextCursor, err := r.FindUserWebsitesPage(req.Context(), query)
+			if err != nil {
+				zerolog.Ctx(req.Context()).Error().Err(err).Msg("find user websites page failed")
+				writePageError(res, err)
+
+				return
+			}
+
+			encodeJsonResp(req.Context(), res, listAllWebsiteGroupsResp{
+				WebsiteGroups: fromModelWebsiteGroups(webs.WebsiteGroups()),
+				NextCursor:    nextCursor,
+			})
+
+			return
+		}
+
 		webs, err := r.FindUserWebsites(req.Context(), userUUID)
 
 		if err != nil {
@@ -54,7 +91,10 @@ func getAllWebsiteGroupsHandler(r repository.Repository) http.HandlerFunc {
 			return
 		}
 
-		encodeJsonResp(req.Context(), res, listAllWebsiteGroupsResp{fromModelWebsiteGroups(webs.WebsiteGroups())})
+		groups := webs.WebsiteGroups()
+		groups.SortByUnread()
+
+		encodeJsonResp(req.Context(), res, listAllWebsiteGroupsResp{WebsiteGroups: fromModelWebsiteGroups(groups)})
 	}
 }
 
@@ -63,8 +103,12 @@ func getAllWebsiteGroupsHandler(r repository.Repository) http.HandlerFunc {
 // @Tags			web-history
 // @Accept			json
 // @Produce		json
-// @Param			X-USER-UUID	header		string	true	"user uuid"
+// @Security		UserToken
 // @Param			groupName	path		string	true	"group name"
+// @Param			limit		query		int		false	"page size, enables pagination"
+// @Param			cursor		query		string	false	"next_cursor of previous page"
+// @Param			sort		query		string	false	"sort key"	Enums(unread, update_time, access_time, title)
+// @Param			tag			query		[]string	false	"only list websites having all the tags"	collectionFormat(multi)
 // @Success		200			{object}	getWebsiteGroupResp
 // @Failure		400			{object}	errResp
 // @Router			/api/web-watcher/websites/groups/{groupName} [get]
@@ -73,6 +117,30 @@ func getWebsiteGroupHandler(r repository.Repository) http.HandlerFunc {
 		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
 		groupName := chi.URLParam(req, "groupName")
 
+		if query, ok := req.Context().Value(ContextKeyPage).(repository.UserWebsitesQuery); ok {
+			query.UserUUID, query.GroupName = userUUID, groupName
+			webs, nextCursor, err := r.FindUserWebsitesPage(req.Context(), query)
+			if err != nil {
+				zerolog.Ctx(req.Context()).Error().Err(err).Msg("find user websites page by group failed")
+				writePageError(res, err)
+
+				return
+			}
+
+			if len(webs) == 0 && query.Cursor == "" {
+				writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+				return
+			}
+
+			encodeJsonResp(req.Context(), res, getWebsiteGroupResp{
+				WebsiteGroup: fromModelWebsiteGroup(model.WebsiteGroup(webs)),
+				NextCursor:   nextCursor,
+			})
+
+			return
+		}
+
 		webs, err := r.FindUserWebsitesByGroup(req.Context(), userUUID, groupName)
 		if err != nil || len(webs) == 0 {
 
@@ -82,8 +150,124 @@ func getWebsiteGroupHandler(r repository.Repository) http.HandlerFunc {
 			return
 		}
 
-		encodeJsonResp(req.Context(), res, getWebsiteGroupResp{fromModelWebsiteGroup(webs)})
+		webs.SortByUnread()
+
+		encodeJsonResp(req.Context(), res, getWebsiteGroupResp{WebsiteGroup: fromModelWebsiteGroup(webs)})
+	}
+}
+
+// subscribeWebsite creates the website of url if not exist and subscribes
+// user to it, together with the audit event
+func subscribeWebsite(ctx context.Context, r repository.Repository, conf *config.WebsiteConfig, userUUID, url string) (model.UserWebsite, error) {
+	web := model.NewWebsite(url, conf)
+
+	var userWeb model.UserWebsite
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		err := txRepo.CreateWebsite(ctx, &web)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("create website failed")
+
+			return err
+		}
+
+		userWeb = model.NewUserWebsite(web, userUUID)
+		err = txRepo.CreateUserWebsite(ctx, &userWeb)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("create user website failed")
+
+			return err
+		}
+
+		return recordAuditEvent(ctx, txRepo, model.AuditActionCreate, nil, &userWeb)
+	})
+
+	return userWeb, err
+}
+
+// publishWebsiteUpdate publishes update job only if website is updated more
+// than 24 hr ago
+func publishWebsiteUpdate(ctx context.Context, tasks websiteupdate.WebsiteUpdateTasks, web *model.Website) error {
+	if time.Since(web.UpdateTime) <= 24*time.Hour {
+		return nil
+	}
+
+	jobCtx, jobSpan := getTracer().Start(ctx, "Website Update Job Creation")
+	defer jobSpan.End()
+
+	supportedList, err := tasks.Publish(jobCtx, web)
+	if err != nil {
+		jobSpan.SetStatus(codes.Error, err.Error())
+		jobSpan.RecordError(err)
+
+		zerolog.Ctx(jobCtx).Error().Err(err).
+			Msg("publish website update task failed")
+
+		return err
+	} else if len(supportedList) == 0 {
+		jobSpan.SetStatus(codes.Error, ErrUnsupportedWebsite.Error())
+		jobSpan.RecordError(ErrUnsupportedWebsite)
+
+		zerolog.Ctx(jobCtx).Error().Err(ErrUnsupportedWebsite).
+			Msg("unsupported website")
+
+		return ErrUnsupportedWebsite
+	}
+
+	return nil
+}
+
+// saveUserWebsite updates user website from before to web, together with the
+// audit event of action
+func saveUserWebsite(ctx context.Context, r repository.Repository, action string, before, web *model.UserWebsite) error {
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.UpdateUserWebsite(ctx, web); err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, txRepo, action, before, web)
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("action", action).Msg("update user website failed")
+	}
+
+	return err
+}
+
+// unsubscribeWebsite deletes user website, together with the audit event
+func unsubscribeWebsite(ctx context.Context, r repository.Repository, web *model.UserWebsite) error {
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.DeleteUserWebsite(ctx, web); err != nil {
+			return err
+		}
+
+		return recordAuditEvent(ctx, txRepo, model.AuditActionDelete, web, nil)
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("delete user website failed")
+	}
+
+	return err
+}
+
+// applyUserWebsiteInfo returns web with the given display title and note
+func applyUserWebsiteInfo(web model.UserWebsite, params updateUserWebsiteReq) model.UserWebsite {
+	if params.Title != nil {
+		// websites still in their default group follow the title change
+		if web.GroupName == web.Title() {
+			web.GroupName = *params.Title
+		}
+
+		web.DisplayTitle = *params.Title
+		if web.GroupName == "" {
+			web.GroupName = web.Title()
+		}
+	}
+
+	if params.Note != nil {
+		web.Note = *params.Note
 	}
+
+	return web
 }
 
 // @Summary		Create website
@@ -91,7 +275,7 @@ func getWebsiteGroupHandler(r repository.Repository) http.HandlerFunc {
 // @Tags			web-history
 // @Accept			json
 // @Produce		json
-// @Param			X-USER-UUID	header		string	true	"user uuid"
+// @Security		UserToken
 // @Param			url	formData		string	true	"url"
 // @Success		200			{object}	createWebsiteResp
 // @Failure		400			{object}	errResp
@@ -102,55 +286,50 @@ func createWebsiteHandler(r repository.Repository, conf *config.WebsiteConfig, t
 		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
 		url := req.Context().Value(ContextKeyWebURL).(string)
 
-		web := model.NewWebsite(url, conf)
-
-		err := r.CreateWebsite(req.Context(), &web)
+		userWeb, err := subscribeWebsite(req.Context(), r, conf, userUUID, url)
 		if err != nil {
-			zerolog.Ctx(req.Context()).Error().Err(err).Msg("create website failed")
 			writeError(res, http.StatusBadRequest, err)
 
 			return
 		}
 
-		userWeb := model.NewUserWebsite(web, userUUID)
-		err = r.CreateUserWebsite(req.Context(), &userWeb)
+		web := userWeb.Website
+		err = publishWebsiteUpdate(req.Context(), tasks, &web)
 		if err != nil {
-			zerolog.Ctx(req.Context()).Error().Err(err).Msg("create user website failed")
 			writeError(res, http.StatusBadRequest, err)
 
 			return
 		}
 
-		// only publish job it website is updated more than 24 hr ago
-		if time.Since(web.UpdateTime) > 24*time.Hour {
-			jobCtx, jobSpan := getTracer().Start(req.Context(), "Website Update Job Creation")
-			defer jobSpan.End()
-
-			supportedList, err := tasks.Publish(jobCtx, &web)
-			if err != nil {
-				jobSpan.SetStatus(codes.Error, err.Error())
-				jobSpan.RecordError(err)
-
-				zerolog.Ctx(jobCtx).Error().Err(err).
-					Msg("publish website update task failed")
-				writeError(res, http.StatusBadRequest, err)
-
-				return
-			} else if len(supportedList) == 0 {
-				jobSpan.SetStatus(codes.Error, "unsupported website")
-				jobSpan.RecordError(errors.New("unsupported website"))
+		encodeJsonResp(req.Context(), res, createWebsiteResp{fmt.Sprintf("website <%v> inserted", web.Title)})
+	}
+}
 
-				zerolog.Ctx(jobCtx).Error().Err(err).
-					Msg("unsupported website")
-				writeError(res, http.StatusBadRequest, errors.New("unsupported website"))
+// @Summary		Search user websites
+// @description	search user websites by title, group name, url and note
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			q			query		string	true	"search query"
+// @Param			limit		query		int		false	"max number of result"
+// @Success		200			{object}	searchWebsitesResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/search [get]
+func searchWebsitesHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		params := req.Context().Value(ContextKeySearch).(searchReq)
 
-				return
-			}
+		webs, err := r.SearchUserWebsites(req.Context(), userUUID, params.Query, params.Limit)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("search user websites failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
 
-			jobSpan.End()
+			return
 		}
 
-		encodeJsonResp(req.Context(), res, createWebsiteResp{fmt.Sprintf("website <%v> inserted", web.Title)})
+		encodeJsonResp(req.Context(), res, searchWebsitesResp{fromModelWebsiteGroup(model.WebsiteGroup(webs))})
 	}
 }
 
@@ -159,7 +338,7 @@ func createWebsiteHandler(r repository.Repository, conf *config.WebsiteConfig, t
 // @Tags			web-history
 // @Accept			json
 // @Produce		json
-// @Param			X-USER-UUID	header		string	true	"user uuid"
+// @Security		UserToken
 // @Param			websiteUUID	path		string	true	"website uuid"
 // @Success		200			{object}	getUserWebsiteResp
 // @Failure		400			{object}	errResp
@@ -177,19 +356,19 @@ func getUserWebsiteHandler() http.HandlerFunc {
 // @Tags			web-history
 // @Accept			json
 // @Produce		json
-// @Param			X-USER-UUID	header		string	true	"user uuid"
+// @Security		UserToken
 // @Param			websiteUUID	path		string	true	"website uuid"
 // @Success		200			{object}	refreshWebsiteResp
 // @Failure		400			{object}	errResp
 // @Router			/api/web-watcher/websites/{websiteUUID}/refresh [put]
 func refreshWebsiteHandler(r repository.Repository) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
-		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		before := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		web := before
 		web.AccessTime = time.Now().UTC().Truncate(5 * time.Second)
 
-		err := r.UpdateUserWebsite(req.Context(), &web)
+		err := saveUserWebsite(req.Context(), r, model.AuditActionRefresh, &before, &web)
 		if err != nil {
-			zerolog.Ctx(req.Context()).Error().Err(err).Msg("refresh user website failed")
 			writeError(res, http.StatusInternalServerError, err)
 
 			return
@@ -206,7 +385,7 @@ func refreshWebsiteHandler(r repository.Repository) http.HandlerFunc {
 //	@Tags			web-history
 //	@Accept			json
 //	@Produce		json
-//	@Param			X-USER-UUID	header		string	true	"user uuid"
+//	@Security		UserToken
 //	@Param			websiteUUID	path		string	true	"website uuid"
 //	@Success		200			{object}	deleteWebsiteResp
 //	@Failure		400			{object}	errResp
@@ -215,9 +394,8 @@ func deleteWebsiteHandler(r repository.Repository) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
 		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
 
-		err := r.DeleteUserWebsite(req.Context(), &web)
+		err := unsubscribeWebsite(req.Context(), r, &web)
 		if err != nil {
-			zerolog.Ctx(req.Context()).Error().Err(err).Msg("delete user website failed")
 			writeError(res, http.StatusInternalServerError, err)
 
 			return
@@ -227,9 +405,17 @@ func deleteWebsiteHandler(r repository.Repository) http.HandlerFunc {
 	}
 }
 
-func validGroupName(web model.UserWebsite, groupName string) bool {
+// validGroupName reports if web can be put into group named groupName. Group
+// name has to share a character with website title in series grouping mode.
+func validGroupName(conf *config.WebsiteConfig, web model.UserWebsite, groupName string) bool {
+	if groupName == "" {
+		return false
+	} else if !conf.SeriesGrouping {
+		return true
+	}
+
 	for char := range strings.SplitSeq(groupName, "") {
-		if strings.Contains(web.Website.Title, char) {
+		if strings.Contains(web.Title(), char) {
 			return true
 		}
 	}
@@ -243,26 +429,26 @@ func validGroupName(web model.UserWebsite, groupName string) bool {
 //	@Tags			web-history
 //	@Accept			json
 //	@Produce		json
-//	@Param			X-USER-UUID	header		string	true	"user uuid"
+//	@Security		UserToken
 //	@Param			websiteUUID	path		string	true	"website uuid"
 //	@Param			group_name	formData		string	true	"group name"
 //	@Success		200			{object}	changeWebsiteGroupResp
 //	@Failure		400			{object}	errResp
 //	@Router			/api/web-watcher/websites/{websiteUUID}/change-group [put]
-func changeWebsiteGroupHandler(r repository.Repository) http.HandlerFunc {
+func changeWebsiteGroupHandler(r repository.Repository, conf *config.WebsiteConfig) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
-		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		before := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
 		groupName := req.Context().Value(ContextKeyGroup).(string)
-		if !validGroupName(web, groupName) {
-			writeError(res, http.StatusBadRequest, errors.New("invalid group name"))
+		if !validGroupName(conf, before, groupName) {
+			writeError(res, http.StatusBadRequest, ErrInvalidGroupName)
 			return
 		}
 
+		web := before
 		web.GroupName = groupName
 
-		err := r.UpdateUserWebsite(req.Context(), &web)
+		err := saveUserWebsite(req.Context(), r, model.AuditActionChangeGroup, &before, &web)
 		if err != nil {
-			zerolog.Ctx(req.Context()).Error().Err(err).Str("group_name", groupName).Msg("update user website group failed")
 			writeError(res, http.StatusBadRequest, err)
 
 			return
@@ -272,6 +458,75 @@ func changeWebsiteGroupHandler(r repository.Repository) http.HandlerFunc {
 	}
 }
 
+// write swagger docs
+//
+//	@Summary		Update user website
+//	@description	update display title or note of user website
+//	@Tags			web-history
+//	@Accept			json
+//	@Produce		json
+//	@Security		UserToken
+//	@Param			websiteUUID	path		string	true	"website uuid"
+//	@Param			title	formData		string	false	"display title, empty to use website title"
+//	@Param			note	formData		string	false	"note"
+//	@Success		200			{object}	updateUserWebsiteResp
+//	@Failure		400			{object}	errResp
+//	@Router			/api/web-watcher/websites/{websiteUUID} [patch]
+func updateUserWebsiteHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		before := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		params := req.Context().Value(ContextKeyWebInfo).(updateUserWebsiteReq)
+
+		web := applyUserWebsiteInfo(before, params)
+
+		err := saveUserWebsite(req.Context(), r, model.AuditActionUpdate, &before, &web)
+		if err != nil {
+			writeError(res, http.StatusInternalServerError, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, updateUserWebsiteResp{fromModelUserWebsite(web)})
+	}
+}
+
+// write swagger docs
+//
+//	@Summary		Update read progress
+//	@description	record the last read chapter of user website, empty chapter to clear the progress
+//	@Tags			web-history
+//	@Accept			json
+//	@Produce		json
+//	@Security		UserToken
+//	@Param			websiteUUID	path		string	true	"website uuid"
+//	@Param			chapter	formData		string	true	"last read chapter"
+//	@Success		200			{object}	updateReadProgressResp
+//	@Failure		400			{object}	errResp
+//	@Router			/api/web-watcher/websites/{websiteUUID}/read-progress [put]
+func updateReadProgressHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		before := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		chapter := req.Context().Value(ContextKeyChapter).(string)
+
+		if chapter != "" && !slices.Contains(before.Website.Content(), chapter) {
+			writeError(res, http.StatusBadRequest, ErrInvalidChapter)
+			return
+		}
+
+		web := before
+		web.LastReadChapter = chapter
+
+		err := saveUserWebsite(req.Context(), r, model.AuditActionReadProgress, &before, &web)
+		if err != nil {
+			writeError(res, http.StatusInternalServerError, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, updateReadProgressResp{fromModelUserWebsite(web)})
+	}
+}
+
 func dbStatsHandler(r repository.Repository) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
 		json.NewEncoder(res).Encode(r.Stats())
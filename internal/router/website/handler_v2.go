@@ -0,0 +1,985 @@
+package website
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	websiteevents "github.com/htchan/WebHistory/internal/tasks/nats/website_events"
+	websiteimport "github.com/htchan/WebHistory/internal/tasks/nats/website_import"
+	websiteupdate "github.com/htchan/WebHistory/internal/tasks/nats/website_update"
+)
+
+// @Summary		List website groups
+// @description	list website groups of user
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			limit		query		int		false	"page size, enables pagination"
+// @Param			cursor		query		string	false	"next_cursor of previous page"
+// @Param			sort		query		string	false	"sort key"	Enums(unread, update_time, access_time, title)
+// @Param			tag			query		[]string	false	"only list websites having all the tags"	collectionFormat(multi)
+// @Success		200			{object}	listAllWebsiteGroupsResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		500			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/groups [get]
+func getAllWebsiteGroupsHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		if query, ok := req.Context().Value(ContextKeyPage).(repository.UserWebsitesQuery); ok {
+			query.UserUUID = userUUID
+			webs, nextCursor, err := r.FindUserWebsitesPage(req.Context(), query)
+			if err != nil {
+				return err
+			}
+
+			encodeJsonResp(req.Context(), res, listAllWebsiteGroupsResp{
+				WebsiteGroups: fromModelWebsiteGroups(webs.WebsiteGroups()),
+				NextCursor:    nextCursor,
+			})
+
+			return nil
+		}
+
+		webs, err := r.FindUserWebsites(req.Context(), userUUID)
+		if err != nil {
+			return err
+		}
+
+		groups := webs.WebsiteGroups()
+		groups.SortByUnread()
+
+		encodeJsonResp(req.Context(), res, listAllWebsiteGroupsResp{WebsiteGroups: fromModelWebsiteGroups(groups)})
+
+		return nil
+	})
+}
+
+// @Summary		Get website group
+// @description	get website group of user
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			groupName	path		string	true	"group name"
+// @Param			limit		query		int		false	"page size, enables pagination"
+// @Param			cursor		query		string	false	"next_cursor of previous page"
+// @Param			sort		query		string	false	"sort key"	Enums(unread, update_time, access_time, title)
+// @Param			tag			query		[]string	false	"only list websites having all the tags"	collectionFormat(multi)
+// @Success		200			{object}	getWebsiteGroupResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/groups/{groupName} [get]
+func getWebsiteGroupHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		groupName := chi.URLParam(req, "groupName")
+
+		if query, ok := req.Context().Value(ContextKeyPage).(repository.UserWebsitesQuery); ok {
+			query.UserUUID, query.GroupName = userUUID, groupName
+			webs, nextCursor, err := r.FindUserWebsitesPage(req.Context(), query)
+			if err != nil {
+				return err
+			}
+
+			if len(webs) == 0 && query.Cursor == "" {
+				return ErrRecordNotFound
+			}
+
+			encodeJsonResp(req.Context(), res, getWebsiteGroupResp{
+				WebsiteGroup: fromModelWebsiteGroup(model.WebsiteGroup(webs)),
+				NextCursor:   nextCursor,
+			})
+
+			return nil
+		}
+
+		webs, err := r.FindUserWebsitesByGroup(req.Context(), userUUID, groupName)
+		if err != nil {
+			return err
+		}
+
+		if len(webs) == 0 {
+			return ErrRecordNotFound
+		}
+
+		webs.SortByUnread()
+
+		encodeJsonResp(req.Context(), res, getWebsiteGroupResp{WebsiteGroup: fromModelWebsiteGroup(webs)})
+
+		return nil
+	})
+}
+
+// @Summary		Search user websites
+// @description	search user websites by title, group name, url and note
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			q			query		string	true	"search query"
+// @Param			limit		query		int		false	"max number of result"
+// @Success		200			{object}	searchWebsitesResp
+// @Failure		400			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/search [get]
+func searchWebsitesHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		params := req.Context().Value(ContextKeySearch).(searchReq)
+
+		webs, err := r.SearchUserWebsites(req.Context(), userUUID, params.Query, params.Limit)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, searchWebsitesResp{fromModelWebsiteGroup(model.WebsiteGroup(webs))})
+
+		return nil
+	})
+}
+
+// @Summary		Create website
+// @description	subscribe user to website of url
+// @Tags			web-history-v2
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			body		body		createWebsiteReq	true	"website url"
+// @Success		201			{object}	getUserWebsiteResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		422			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites [post]
+func createWebsiteHandlerV2(r repository.Repository, conf *config.WebsiteConfig, tasks websiteupdate.WebsiteUpdateTasks) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		body := req.Context().Value(ContextKeyBody).(createWebsiteReq)
+
+		userWeb, err := subscribeWebsite(req.Context(), r, conf, userUUID, body.Url)
+		if err != nil {
+			return err
+		}
+
+		err = publishWebsiteUpdate(req.Context(), tasks, &userWeb.Website)
+		if err != nil {
+			return err
+		}
+
+		res.WriteHeader(http.StatusCreated)
+		encodeJsonResp(req.Context(), res, getUserWebsiteResp{fromModelUserWebsite(userWeb)})
+
+		return nil
+	})
+}
+
+// @Summary		Refresh user website
+// @description	mark user website as read now
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			websiteUUID	path		string	true	"website uuid"
+// @Success		200			{object}	refreshWebsiteResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/{websiteUUID}/refresh [put]
+func refreshWebsiteHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		before := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		web := before
+		web.AccessTime = time.Now().UTC().Truncate(5 * time.Second)
+
+		err := saveUserWebsite(req.Context(), r, model.AuditActionRefresh, &before, &web)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, refreshWebsiteResp{fromModelUserWebsite(web)})
+
+		return nil
+	})
+}
+
+// @Summary		Delete user website
+// @description	unsubscribe user from website
+// @Tags			web-history-v2
+// @Security		UserToken
+// @Param			websiteUUID	path		string	true	"website uuid"
+// @Success		204
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/{websiteUUID} [delete]
+func deleteWebsiteHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+
+		err := unsubscribeWebsite(req.Context(), r, &web)
+		if err != nil {
+			return err
+		}
+
+		res.WriteHeader(http.StatusNoContent)
+
+		return nil
+	})
+}
+
+// @Summary		Change website group
+// @description	move user website to another group
+// @Tags			web-history-v2
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			websiteUUID	path		string			true	"website uuid"
+// @Param			body		body		groupNameReq	true	"group name"
+// @Success		200			{object}	changeWebsiteGroupResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/{websiteUUID}/change-group [put]
+func changeWebsiteGroupHandlerV2(r repository.Repository, conf *config.WebsiteConfig) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		before := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		body := req.Context().Value(ContextKeyBody).(groupNameReq)
+		if !validGroupName(conf, before, body.GroupName) {
+			return invalidParamsError(map[string]string{
+				"group_name": "must share a character with website title",
+			})
+		}
+
+		web := before
+		web.GroupName = body.GroupName
+
+		err := saveUserWebsite(req.Context(), r, model.AuditActionChangeGroup, &before, &web)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, changeWebsiteGroupResp{fromModelUserWebsite(web)})
+
+		return nil
+	})
+}
+
+// @Summary		Update user website
+// @description	update display title or note of user website
+// @Tags			web-history-v2
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			websiteUUID	path		string					true	"website uuid"
+// @Param			body		body		updateUserWebsiteReq	true	"title and note, empty title to use website title"
+// @Success		200			{object}	updateUserWebsiteResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/{websiteUUID} [patch]
+func updateUserWebsiteHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		before := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		body := req.Context().Value(ContextKeyBody).(updateUserWebsiteReq)
+
+		web := applyUserWebsiteInfo(before, body)
+
+		err := saveUserWebsite(req.Context(), r, model.AuditActionUpdate, &before, &web)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, updateUserWebsiteResp{fromModelUserWebsite(web)})
+
+		return nil
+	})
+}
+
+// @Summary		Update read progress
+// @description	record the last read chapter of user website, empty chapter to clear the progress
+// @Tags			web-history-v2
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			websiteUUID	path		string					true	"website uuid"
+// @Param			body		body		updateReadProgressReq	true	"last read chapter"
+// @Success		200			{object}	updateReadProgressResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/{websiteUUID}/read-progress [put]
+func updateReadProgressHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		before := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		body := req.Context().Value(ContextKeyBody).(updateReadProgressReq)
+
+		chapter := *body.Chapter
+		if chapter != "" && !slices.Contains(before.Website.Content(), chapter) {
+			return invalidParamsError(map[string]string{"chapter": "must be a chapter of website"})
+		}
+
+		web := before
+		web.LastReadChapter = chapter
+
+		err := saveUserWebsite(req.Context(), r, model.AuditActionReadProgress, &before, &web)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, updateReadProgressResp{fromModelUserWebsite(web)})
+
+		return nil
+	})
+}
+
+// @Summary		List audit events
+// @description	list audit events of user from the latest
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			website_uuid	query		string	false	"only list events of the website"
+// @Param			limit			query		int		false	"max number of result"
+// @Success		200				{object}	listAuditEventsResp
+// @Failure		400				{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/audit-events [get]
+func listAuditEventsHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		query := req.Context().Value(ContextKeyAudit).(repository.AuditEventsQuery)
+		query.UserUUID = req.Context().Value(ContextKeyUserUUID).(string)
+
+		events, err := r.FindAuditEvents(req.Context(), query)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, listAuditEventsResp{fromModelAuditEvents(events)})
+
+		return nil
+	})
+}
+
+// @Summary		List groups
+// @description	list groups of user with the number of websites and unread chapters
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	listGroupsResp
+// @Failure		401			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/group-counts [get]
+func listGroupsHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		webs, err := r.FindUserWebsites(req.Context(), userUUID)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, listGroupsResp{fromModelWebsiteGroupSummaries(webs.WebsiteGroups())})
+
+		return nil
+	})
+}
+
+// @Summary		Summarize unread websites
+// @description	count websites updated after last access by group, and list the latest updated ones
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	websiteSummaryResp
+// @Failure		401			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/summary [get]
+func websiteSummaryHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		summary, err := r.FindUserUnreadSummary(req.Context(), userUUID, SummaryLatestUnread)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, fromModelUnreadSummary(summary))
+
+		return nil
+	})
+}
+
+// @Summary		Rename group
+// @description	rename group of all its websites, new group name must not be used
+// @Tags			web-history-v2
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			groupName	path		string			true	"group name"
+// @Param			body		body		groupNameReq	true	"new group name"
+// @Success		200			{object}	renameGroupResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		404			{object}	apiErrResp
+// @Failure		409			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/groups/{groupName}/rename [put]
+func renameGroupHandlerV2(r repository.Repository, conf *config.WebsiteConfig) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		body := req.Context().Value(ContextKeyBody).(groupNameReq)
+
+		group, err := moveUserGroup(req.Context(), r, conf, userUUID, chi.URLParam(req, "groupName"), body.GroupName, false)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, renameGroupResp{fromModelWebsiteGroup(group)})
+
+		return nil
+	})
+}
+
+// @Summary		Merge group
+// @description	move all websites of group into another existing group
+// @Tags			web-history-v2
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			groupName	path		string			true	"group name"
+// @Param			body		body		groupNameReq	true	"name of group merged into"
+// @Success		200			{object}	mergeGroupResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/groups/{groupName}/merge [put]
+func mergeGroupHandlerV2(r repository.Repository, conf *config.WebsiteConfig) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		body := req.Context().Value(ContextKeyBody).(groupNameReq)
+
+		group, err := moveUserGroup(req.Context(), r, conf, userUUID, chi.URLParam(req, "groupName"), body.GroupName, true)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, mergeGroupResp{fromModelWebsiteGroup(group)})
+
+		return nil
+	})
+}
+
+// @Summary		Delete group
+// @description	delete group by unsubscribing its websites or moving them back to the group named by website title
+// @Tags			web-history-v2
+// @Security		UserToken
+// @Param			groupName	path	string	true	"group name"
+// @Param			mode		query	string	true	"delete mode"	Enums(unsubscribe, ungroup)
+// @Success		204
+// @Failure		400	{object}	apiErrResp
+// @Failure		404	{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/groups/{groupName} [delete]
+func deleteGroupHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		mode := req.Context().Value(ContextKeyMode).(string)
+
+		err := deleteUserGroup(req.Context(), r, userUUID, chi.URLParam(req, "groupName"), mode)
+		if err != nil {
+			return err
+		}
+
+		res.WriteHeader(http.StatusNoContent)
+
+		return nil
+	})
+}
+
+// @Summary		Refresh group
+// @description	mark all websites of group as read
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			groupName	path		string	true	"group name"
+// @Success		200			{object}	refreshWebsitesResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/groups/{groupName}/refresh [put]
+func refreshGroupHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		updated, err := refreshUserWebsites(req.Context(), r, userUUID, chi.URLParam(req, "groupName"))
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, refreshWebsitesResp{updated})
+
+		return nil
+	})
+}
+
+// @Summary		Refresh all websites
+// @description	mark all websites of user as read
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	refreshWebsitesResp
+// @Failure		500			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/refresh [put]
+func refreshAllWebsitesHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		updated, err := refreshUserWebsites(req.Context(), r, userUUID, "")
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, refreshWebsitesResp{updated})
+
+		return nil
+	})
+}
+
+// @Summary		List tags
+// @description	list tags of user with the number of tagged websites
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	listTagsResp
+// @Failure		500			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/tags [get]
+func listTagsHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		tags, err := r.FindUserTags(req.Context(), userUUID)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, listTagsResp{fromModelTags(tags)})
+
+		return nil
+	})
+}
+
+// @Summary		Rename tag
+// @description	rename tag of all websites, the tags are merged if new tag already exists
+// @Tags			web-history-v2
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			tag			path		string	true	"tag"
+// @Param			body		body		tagReq	true	"new tag"
+// @Success		200			{object}	listTagsResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/tags/{tag}/rename [put]
+func renameTagHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		tag := req.Context().Value(ContextKeyTag).(string)
+		body := req.Context().Value(ContextKeyBody).(tagReq)
+
+		tags, err := renameUserTag(req.Context(), r, userUUID, tag, body.Tag)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, listTagsResp{fromModelTags(tags)})
+
+		return nil
+	})
+}
+
+// @Summary		Delete tag
+// @description	remove tag from all websites
+// @Tags			web-history-v2
+// @Security		UserToken
+// @Param			tag			path	string	true	"tag"
+// @Success		204
+// @Failure		400	{object}	apiErrResp
+// @Failure		404	{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/tags/{tag} [delete]
+func deleteTagHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		tag := req.Context().Value(ContextKeyTag).(string)
+
+		err := deleteUserTag(req.Context(), r, userUUID, tag)
+		if err != nil {
+			return err
+		}
+
+		res.WriteHeader(http.StatusNoContent)
+
+		return nil
+	})
+}
+
+// @Summary		List website tags
+// @description	list tags of user website
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			websiteUUID	path		string	true	"website uuid"
+// @Success		200			{object}	websiteTagsResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/{websiteUUID}/tags [get]
+func getWebsiteTagsHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+
+		tags, err := r.FindUserWebsiteTags(req.Context(), web.UserUUID, web.WebsiteUUID)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, websiteTagsResp{tags})
+
+		return nil
+	})
+}
+
+// @Summary		Add website tag
+// @description	add tag to user website
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			websiteUUID	path		string	true	"website uuid"
+// @Param			tag			path		string	true	"tag"
+// @Success		200			{object}	websiteTagsResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/{websiteUUID}/tags/{tag} [put]
+func addWebsiteTagHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		tag := req.Context().Value(ContextKeyTag).(string)
+
+		tags, err := updateWebsiteTag(req.Context(), r, web, tag, true)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, websiteTagsResp{tags})
+
+		return nil
+	})
+}
+
+// @Summary		Remove website tag
+// @description	remove tag from user website
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			websiteUUID	path		string	true	"website uuid"
+// @Param			tag			path		string	true	"tag"
+// @Success		200			{object}	websiteTagsResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/{websiteUUID}/tags/{tag} [delete]
+func removeWebsiteTagHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		tag := req.Context().Value(ContextKeyTag).(string)
+
+		tags, err := updateWebsiteTag(req.Context(), r, web, tag, false)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, websiteTagsResp{tags})
+
+		return nil
+	})
+}
+
+// @Summary		Export websites
+// @description	export all websites of user as json, csv or opml file
+// @Tags			web-history-v2
+// @Produce		json,text/csv,text/x-opml
+// @Security		UserToken
+// @Param			format		query		string	false	"file format"	Enums(json, csv, opml)
+// @Success		200			{file}		file
+// @Failure		400			{object}	apiErrResp
+// @Failure		500			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/export [get]
+func exportWebsitesHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		format := req.Context().Value(ContextKeyFormat).(string)
+
+		webs, err := r.FindUserWebsites(req.Context(), userUUID)
+		if err != nil {
+			return err
+		}
+
+		writeExportFile(req.Context(), res, format, webs)
+
+		return nil
+	})
+}
+
+// @Summary		Import websites
+// @description	import websites from json, csv or opml file in background, the
+// @description	report of each website is kept in the returned job
+// @Tags			web-history-v2
+// @Accept			json,text/csv,text/x-opml
+// @Produce		json
+// @Security		UserToken
+// @Param			format		query		string	false	"file format"	Enums(json, csv, opml)
+// @Param			file		body		string	true	"imported file"
+// @Success		202			{object}	importWebsitesResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		500			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/import [post]
+func importWebsitesHandlerV2(r repository.Repository, task *websiteimport.WebsiteImportTask) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		rows := req.Context().Value(ContextKeyImport).([]websiteimport.ImportRow)
+
+		job, err := startImportJob(req.Context(), r, task, userUUID, rows)
+		if err != nil {
+			return err
+		}
+
+		res.WriteHeader(http.StatusAccepted)
+		encodeJsonResp(req.Context(), res, importWebsitesResp{fromModelJob(job)})
+
+		return nil
+	})
+}
+
+// @Summary		Check website
+// @description	update website in background immediately, the result is kept in the returned job
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			webUUID		path		string	true	"website uuid"
+// @Success		202			{object}	checkWebsiteResp
+// @Failure		404			{object}	apiErrResp
+// @Failure		422			{object}	apiErrResp
+// @Failure		429			{object}	apiErrResp
+// @Failure		503			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/{webUUID}/check [post]
+func checkWebsiteHandlerV2(r repository.Repository, tasks websiteupdate.WebsiteUpdateTasks, conf *config.WebsiteCheckConfig) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+
+		job, err := startCheckJob(req.Context(), r, tasks, conf, web)
+		if err != nil {
+			return err
+		}
+
+		res.WriteHeader(http.StatusAccepted)
+		encodeJsonResp(req.Context(), res, checkWebsiteResp{fromModelJob(job)})
+
+		return nil
+	})
+}
+
+// @Summary		Get job
+// @description	get status of background job, with the report once it is done
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			jobUUID		path		string	true	"job uuid"
+// @Success		200			{object}	getJobResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/jobs/{jobUUID} [get]
+func getJobHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		job, err := r.FindJob(req.Context(), userUUID, chi.URLParam(req, "jobUUID"))
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, getJobResp{fromModelJob(*job)})
+
+		return nil
+	})
+}
+
+// @Summary		Get feed token
+// @description	get the secret token of user atom feed, the token is created on first access
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	feedTokenResp
+// @Failure		500			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/feed-token [get]
+func getFeedTokenHandlerV2(r repository.Repository, routePrefix string) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		token, err := userFeedToken(req.Context(), r, userUUID, false)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, feedTokenResp{fromModelFeedToken(token, routePrefix)})
+
+		return nil
+	})
+}
+
+// @Summary		Rotate feed token
+// @description	replace the secret token of user atom feed, the old feed url stops working
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	feedTokenResp
+// @Failure		500			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/feed-token [put]
+func rotateFeedTokenHandlerV2(r repository.Repository, routePrefix string) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		token, err := userFeedToken(req.Context(), r, userUUID, true)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, feedTokenResp{fromModelFeedToken(token, routePrefix)})
+
+		return nil
+	})
+}
+
+// @Summary		Stream website events
+// @description	stream server-sent events of the changes of user websites, the
+// @description	events after Last-Event-ID header are replayed on reconnect
+// @Tags			web-history-v2
+// @Produce		text/event-stream
+// @Security		UserToken
+// @Param			Last-Event-ID	header		string	false	"id of last received event"
+// @Success		200				{object}	websiteEventResp
+// @Failure		400				{object}	apiErrResp
+// @Failure		500				{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/events [get]
+func websiteEventsHandlerV2(broker *websiteevents.Broker) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		return streamWebsiteEvents(res, req, broker)
+	})
+}
+
+// @Summary		List webhooks
+// @description	list webhooks of user, the secret of webhook is not listed
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	listWebhooksResp
+// @Failure		500			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/webhooks [get]
+func listWebhooksHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		webhooks, err := r.FindWebhooks(req.Context(), userUUID)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, listWebhooksResp{fromModelWebhooks(webhooks)})
+
+		return nil
+	})
+}
+
+// @Summary		Create webhook
+// @description	create webhook receiving the updates of user websites, or only
+// @description	the websites of group_name if it is given. The secret signing the
+// @description	payloads is only returned in this response.
+// @Tags			web-history-v2
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			body		body		webhookReq	true	"webhook"
+// @Success		201			{object}	webhookResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		500			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/webhooks [post]
+func createWebhookHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		body := req.Context().Value(ContextKeyBody).(webhookReq)
+
+		webhook, err := createUserWebhook(req.Context(), r, userUUID, body)
+		if err != nil {
+			return err
+		}
+
+		resp := fromModelWebhook(webhook)
+		resp.Secret = webhook.Secret
+
+		res.WriteHeader(http.StatusCreated)
+		encodeJsonResp(req.Context(), res, webhookResp{resp})
+
+		return nil
+	})
+}
+
+// @Summary		Update webhook
+// @description	update the given fields of webhook, enabling a disabled webhook resets its failure count
+// @Tags			web-history-v2
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			webhookUUID	path		string		true	"webhook uuid"
+// @Param			body		body		webhookReq	true	"updated fields"
+// @Success		200			{object}	webhookResp
+// @Failure		400			{object}	apiErrResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/webhooks/{webhookUUID} [patch]
+func updateWebhookHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		webhook := req.Context().Value(ContextKeyWebhook).(model.Webhook)
+		body := req.Context().Value(ContextKeyBody).(webhookReq)
+
+		webhook, err := updateUserWebhook(req.Context(), r, webhook, body)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, webhookResp{fromModelWebhook(webhook)})
+
+		return nil
+	})
+}
+
+// @Summary		Delete webhook
+// @description	delete webhook with its delivery logs
+// @Tags			web-history-v2
+// @Security		UserToken
+// @Param			webhookUUID	path	string	true	"webhook uuid"
+// @Success		204
+// @Failure		404	{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/webhooks/{webhookUUID} [delete]
+func deleteWebhookHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		webhook := req.Context().Value(ContextKeyWebhook).(model.Webhook)
+
+		err := r.DeleteWebhook(req.Context(), &webhook)
+		if err != nil {
+			return err
+		}
+
+		res.WriteHeader(http.StatusNoContent)
+
+		return nil
+	})
+}
+
+// @Summary		List webhook deliveries
+// @description	list the latest deliveries of webhook with their status
+// @Tags			web-history-v2
+// @Produce		json
+// @Security		UserToken
+// @Param			webhookUUID	path		string	true	"webhook uuid"
+// @Success		200			{object}	listWebhookDeliveriesResp
+// @Failure		404			{object}	apiErrResp
+// @Router			/api/web-watcher/v2/websites/webhooks/{webhookUUID}/deliveries [get]
+func listWebhookDeliveriesHandlerV2(r repository.Repository) http.HandlerFunc {
+	return apiHandler(func(res http.ResponseWriter, req *http.Request) error {
+		webhook := req.Context().Value(ContextKeyWebhook).(model.Webhook)
+
+		deliveries, err := r.FindWebhookDeliveries(req.Context(), webhook.UUID, MaxWebhookDeliveries)
+		if err != nil {
+			return err
+		}
+
+		encodeJsonResp(req.Context(), res, listWebhookDeliveriesResp{fromModelWebhookDeliveries(deliveries)})
+
+		return nil
+	})
+}
@@ -2,15 +2,23 @@ package website
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/htchan/WebHistory/internal/config"
 	"github.com/htchan/WebHistory/internal/repository"
+	webhookdelivery "github.com/htchan/WebHistory/internal/tasks/nats/webhook_delivery"
+	"github.com/htchan/WebHistory/internal/userservice"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel/codes"
@@ -25,8 +33,39 @@ const (
 	ContextKeyWebURL   ContextKey = "web_url"
 	ContextKeyWebsite  ContextKey = "website"
 	ContextKeyGroup    ContextKey = "group"
+	ContextKeyWebInfo  ContextKey = "web_info"
+	ContextKeyChapter  ContextKey = "chapter"
+	ContextKeyPage     ContextKey = "page"
+	ContextKeySearch   ContextKey = "search"
+	ContextKeyAudit    ContextKey = "audit"
+	ContextKeyBody     ContextKey = "body"
+	ContextKeyMode     ContextKey = "mode"
+	ContextKeyTag      ContextKey = "tag"
+	ContextKeyNewTag   ContextKey = "new_tag"
+	ContextKeyFormat   ContextKey = "format"
+	ContextKeyImport   ContextKey = "import"
+	ContextKeyEventID  ContextKey = "event_id"
+	ContextKeyWebhook  ContextKey = "webhook"
+	ContextKeyHookInfo ContextKey = "hook_info"
 
-	HeaderKeyUserUUID string = "X-USER-UUID"
+	HeaderKeyAuthorization string = "Authorization"
+	HeaderKeyAdminToken    string = "X-ADMIN-TOKEN"
+
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+
+	MaxBodySize = 1 << 20
+
+	MaxTagLength = 32
+
+	MaxImportRows = 1000
+
+	MaxWebhookDeliveries = 50
+
+	SummaryLatestUnread = 10
 )
 
 func logRequest() func(next http.Handler) http.Handler {
@@ -63,7 +102,14 @@ func TraceMiddleware(next http.Handler) http.Handler {
 	)
 }
 
-func AuthenticateMiddleware(conf *config.UserServiceConfig) func(next http.Handler) http.Handler {
+func AuthenticateMiddleware(auth userservice.Authenticator, conf *config.UserServiceConfig) func(next http.Handler) http.Handler {
+	return authenticate(auth, conf, writeErrorV1)
+}
+
+// authenticate verifies the bearer token or session cookie with user service
+// and sets the uuid of verified user to context. Browser without valid token
+// is redirected to login page.
+func authenticate(auth userservice.Authenticator, conf *config.UserServiceConfig, onError errorWriter) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
 			func(res http.ResponseWriter, req *http.Request) {
@@ -72,16 +118,22 @@ func AuthenticateMiddleware(conf *config.UserServiceConfig) func(next http.Handl
 					return
 				}
 
-				_, authSpan := getTracer().Start(req.Context(), "authentication")
+				ctx, authSpan := getTracer().Start(req.Context(), "authentication")
 				defer authSpan.End()
 
-				userUUID := req.Header.Get(HeaderKeyUserUUID)
-
-				if _, err := uuid.Parse(userUUID); err != nil {
-					authSpan.SetStatus(codes.Error, ErrUnauthorized.Error())
-					authSpan.RecordError(ErrUnauthorized)
+				userUUID, err := auth.Authenticate(ctx, userToken(req, conf))
+				if err != nil {
+					authSpan.SetStatus(codes.Error, err.Error())
+					authSpan.RecordError(err)
 
-					writeError(res, http.StatusUnauthorized, ErrUnauthorized)
+					if errors.Is(err, userservice.ErrUnavailable) {
+						zerolog.Ctx(req.Context()).Error().Err(err).Msg("authenticate failed")
+						onError(res, req, http.StatusServiceUnavailable, userservice.ErrUnavailable)
+					} else if conf.LoginURL != "" && browserRequest(req) {
+						redirectLogin(res, req, conf)
+					} else {
+						onError(res, req, http.StatusUnauthorized, ErrUnauthorized)
+					}
 
 					return
 				}
@@ -89,7 +141,7 @@ func AuthenticateMiddleware(conf *config.UserServiceConfig) func(next http.Handl
 				zerolog.Ctx(req.Context()).Debug().
 					Str("user_uuid", userUUID).
 					Msg("set params")
-				ctx := context.WithValue(req.Context(), ContextKeyUserUUID, userUUID)
+				ctx = context.WithValue(req.Context(), ContextKeyUserUUID, userUUID)
 				authSpan.End()
 
 				next.ServeHTTP(res, req.WithContext(ctx))
@@ -97,6 +149,65 @@ func AuthenticateMiddleware(conf *config.UserServiceConfig) func(next http.Handl
 		)
 	}
 }
+
+// userToken reads the bearer token in header, or the session cookie if the
+// header is not given
+func userToken(req *http.Request, conf *config.UserServiceConfig) string {
+	if header := req.Header.Get(HeaderKeyAuthorization); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return ""
+		}
+
+		return strings.TrimSpace(token)
+	}
+
+	if cookie, err := req.Cookie(conf.CookieName); err == nil {
+		return cookie.Value
+	}
+
+	return ""
+}
+
+// browserRequest reports if req is navigated by browser instead of called by
+// api client
+func browserRequest(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		req.Header.Get(HeaderKeyAuthorization) == "" &&
+		strings.Contains(req.Header.Get("Accept"), "text/html")
+}
+
+// AdminAuthenticateMiddleware rejects all requests if admin token is not configured
+func AdminAuthenticateMiddleware(conf *config.AdminConfig) func(next http.Handler) http.Handler {
+	return adminAuthenticate(conf, writeErrorV1)
+}
+
+func adminAuthenticate(conf *config.AdminConfig, onError errorWriter) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				_, authSpan := getTracer().Start(req.Context(), "admin authentication")
+				defer authSpan.End()
+
+				token := req.Header.Get(HeaderKeyAdminToken)
+
+				if conf.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(conf.Token)) != 1 {
+					authSpan.SetStatus(codes.Error, ErrUnauthorized.Error())
+					authSpan.RecordError(ErrUnauthorized)
+
+					onError(res, req, http.StatusUnauthorized, ErrUnauthorized)
+
+					return
+				}
+
+				authSpan.End()
+
+				next.ServeHTTP(res, req)
+			},
+		)
+	}
+}
+
 func SetContentType(next http.Handler) http.Handler {
 	return http.HandlerFunc(
 		func(res http.ResponseWriter, req *http.Request) {
@@ -106,6 +217,22 @@ func SetContentType(next http.Handler) http.Handler {
 	)
 }
 
+func validURL(url string) bool {
+	return url != "" && strings.HasPrefix(url, "http")
+}
+
+// normalizeTag trims tag and reports whether it is a valid tag
+func normalizeTag(tag string) (string, bool) {
+	tag = strings.TrimSpace(tag)
+	length := utf8.RuneCountInString(tag)
+
+	return tag, length > 0 && length <= MaxTagLength
+}
+
+func invalidTagError(field string) *APIError {
+	return invalidParamsError(map[string]string{field: fmt.Sprintf("must be 1 to %d characters", MaxTagLength)})
+}
+
 func WebsiteParams(next http.Handler) http.Handler {
 	return http.HandlerFunc(
 		func(res http.ResponseWriter, req *http.Request) {
@@ -123,7 +250,7 @@ func WebsiteParams(next http.Handler) http.Handler {
 			}
 
 			url := req.Form.Get("url")
-			if url == "" || !strings.HasPrefix(url, "http") {
+			if !validURL(url) {
 				paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
 				paramsSpan.RecordError(ErrInvalidParams)
 
@@ -144,6 +271,10 @@ func WebsiteParams(next http.Handler) http.Handler {
 }
 
 func QueryUserWebsite(r repository.Repository) func(http.Handler) http.Handler {
+	return queryUserWebsite(r, writeErrorV1)
+}
+
+func queryUserWebsite(r repository.Repository, onError errorWriter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
 			func(res http.ResponseWriter, req *http.Request) {
@@ -158,7 +289,7 @@ func QueryUserWebsite(r repository.Repository) func(http.Handler) http.Handler {
 					dbSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
 					dbSpan.RecordError(ErrInvalidParams)
 
-					writeError(res, http.StatusBadRequest, err)
+					onError(res, req, http.StatusBadRequest, err)
 					return
 				}
 
@@ -202,3 +333,781 @@ func GroupNameParams(next http.Handler) http.Handler {
 		},
 	)
 }
+
+func UserWebsiteInfoParams(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			_, paramsSpan := getTracer().Start(req.Context(), "parse user website info params")
+			defer paramsSpan.End()
+
+			err := req.ParseForm()
+			if err != nil {
+				paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+				paramsSpan.RecordError(ErrInvalidParams)
+
+				writeError(res, http.StatusBadRequest, ErrInvalidParams)
+
+				return
+			}
+
+			var params updateUserWebsiteReq
+			if req.Form.Has("title") {
+				title := strings.TrimSpace(req.Form.Get("title"))
+				params.Title = &title
+			}
+
+			if req.Form.Has("note") {
+				note := req.Form.Get("note")
+				params.Note = &note
+			}
+
+			if params.Title == nil && params.Note == nil {
+				paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+				paramsSpan.RecordError(ErrInvalidParams)
+
+				writeError(res, http.StatusBadRequest, ErrInvalidParams)
+
+				return
+			}
+
+			zerolog.Ctx(req.Context()).Debug().
+				Bool("update title", params.Title != nil).
+				Bool("update note", params.Note != nil).
+				Msg("set params")
+			ctx := context.WithValue(req.Context(), ContextKeyWebInfo, params)
+			paramsSpan.End()
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+
+func ChapterParams(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			_, paramsSpan := getTracer().Start(req.Context(), "parse chapter params")
+			defer paramsSpan.End()
+
+			err := req.ParseForm()
+			if err != nil || !req.Form.Has("chapter") {
+				paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+				paramsSpan.RecordError(ErrInvalidParams)
+
+				writeError(res, http.StatusBadRequest, ErrInvalidParams)
+
+				return
+			}
+
+			chapter := strings.TrimSpace(req.Form.Get("chapter"))
+			zerolog.Ctx(req.Context()).Debug().
+				Str("chapter", chapter).
+				Msg("set params")
+			ctx := context.WithValue(req.Context(), ContextKeyChapter, chapter)
+			paramsSpan.End()
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+
+// PaginationParams enables pagination only if any of limit, cursor, sort or tag
+// is given, so clients without pagination still receive the full listing.
+// Repeated tag only lists websites having all the tags.
+func PaginationParams(next http.Handler) http.Handler {
+	return paginationParams(writeErrorV1)(next)
+}
+
+func paginationParams(onError errorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				_, paramsSpan := getTracer().Start(req.Context(), "parse pagination params")
+				defer paramsSpan.End()
+
+				form := req.URL.Query()
+				if !form.Has("limit") && !form.Has("cursor") && !form.Has("sort") && !form.Has("tag") {
+					paramsSpan.End()
+					next.ServeHTTP(res, req)
+
+					return
+				}
+
+				query := repository.UserWebsitesQuery{
+					SortBy: form.Get("sort"),
+					Cursor: form.Get("cursor"),
+					Limit:  DefaultPageLimit,
+				}
+
+				if form.Has("limit") {
+					limit, err := strconv.Atoi(form.Get("limit"))
+					if err != nil || limit <= 0 || limit > MaxPageLimit {
+						paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+						paramsSpan.RecordError(ErrInvalidParams)
+
+						onError(res, req, http.StatusBadRequest, invalidParamsError(map[string]string{
+							"limit": fmt.Sprintf("must be between 1 and %d", MaxPageLimit),
+						}))
+
+						return
+					}
+
+					query.Limit = limit
+				}
+
+				if query.SortBy != "" && !repository.ValidSortKey(query.SortBy) {
+					paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+					paramsSpan.RecordError(ErrInvalidParams)
+
+					onError(res, req, http.StatusBadRequest, invalidParamsError(map[string]string{
+						"sort": "must be one of " + strings.Join(repository.SortKeys, ", "),
+					}))
+
+					return
+				}
+
+				for _, tag := range form["tag"] {
+					tag, ok := normalizeTag(tag)
+					if !ok {
+						paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+						paramsSpan.RecordError(ErrInvalidParams)
+
+						onError(res, req, http.StatusBadRequest, invalidTagError("tag"))
+
+						return
+					}
+
+					query.Tags = append(query.Tags, tag)
+				}
+
+				zerolog.Ctx(req.Context()).Debug().
+					Str("sort", query.SortBy).
+					Str("cursor", query.Cursor).
+					Int("limit", query.Limit).
+					Strs("tags", query.Tags).
+					Msg("set params")
+				ctx := context.WithValue(req.Context(), ContextKeyPage, query)
+				paramsSpan.End()
+
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+func SearchParams(next http.Handler) http.Handler {
+	return searchParams(writeErrorV1)(next)
+}
+
+func searchParams(onError errorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				_, paramsSpan := getTracer().Start(req.Context(), "parse search params")
+				defer paramsSpan.End()
+
+				form := req.URL.Query()
+				params := searchReq{
+					Query: strings.TrimSpace(form.Get("q")),
+					Limit: DefaultSearchLimit,
+				}
+
+				if form.Has("limit") {
+					limit, err := strconv.Atoi(form.Get("limit"))
+					if err != nil || limit <= 0 || limit > MaxSearchLimit {
+						params.Limit = 0
+					} else {
+						params.Limit = limit
+					}
+				}
+
+				details := make(map[string]string)
+				if params.Query == "" {
+					details["q"] = "must not be empty"
+				}
+
+				if params.Limit == 0 {
+					details["limit"] = fmt.Sprintf("must be between 1 and %d", MaxSearchLimit)
+				}
+
+				if len(details) > 0 {
+					paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+					paramsSpan.RecordError(ErrInvalidParams)
+
+					onError(res, req, http.StatusBadRequest, invalidParamsError(details))
+
+					return
+				}
+
+				zerolog.Ctx(req.Context()).Debug().
+					Str("query", params.Query).
+					Int("limit", params.Limit).
+					Msg("set params")
+				ctx := context.WithValue(req.Context(), ContextKeySearch, params)
+				paramsSpan.End()
+
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// AuditEventsParams parses the filter of audit events, user_uuid is only
+// respected by admin endpoint
+func AuditEventsParams(next http.Handler) http.Handler {
+	return auditEventsParams(writeErrorV1)(next)
+}
+
+func auditEventsParams(onError errorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				_, paramsSpan := getTracer().Start(req.Context(), "parse audit events params")
+				defer paramsSpan.End()
+
+				form := req.URL.Query()
+				query := repository.AuditEventsQuery{
+					UserUUID:    form.Get("user_uuid"),
+					WebsiteUUID: form.Get("website_uuid"),
+					Limit:       DefaultPageLimit,
+				}
+
+				if form.Has("limit") {
+					limit, err := strconv.Atoi(form.Get("limit"))
+					if err != nil || limit <= 0 || limit > MaxPageLimit {
+						paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+						paramsSpan.RecordError(ErrInvalidParams)
+
+						onError(res, req, http.StatusBadRequest, invalidParamsError(map[string]string{
+							"limit": fmt.Sprintf("must be between 1 and %d", MaxPageLimit),
+						}))
+
+						return
+					}
+
+					query.Limit = limit
+				}
+
+				zerolog.Ctx(req.Context()).Debug().
+					Str("user_uuid", query.UserUUID).
+					Str("website_uuid", query.WebsiteUUID).
+					Int("limit", query.Limit).
+					Msg("set params")
+				ctx := context.WithValue(req.Context(), ContextKeyAudit, query)
+				paramsSpan.End()
+
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+func GroupDeleteModeParams(next http.Handler) http.Handler {
+	return groupDeleteModeParams(writeErrorV1)(next)
+}
+
+func groupDeleteModeParams(onError errorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				_, paramsSpan := getTracer().Start(req.Context(), "parse group delete mode params")
+				defer paramsSpan.End()
+
+				mode := req.URL.Query().Get("mode")
+				if mode != GroupDeleteModeUnsubscribe && mode != GroupDeleteModeUngroup {
+					paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+					paramsSpan.RecordError(ErrInvalidParams)
+
+					onError(res, req, http.StatusBadRequest, invalidParamsError(map[string]string{
+						"mode": fmt.Sprintf("must be %s or %s", GroupDeleteModeUnsubscribe, GroupDeleteModeUngroup),
+					}))
+
+					return
+				}
+
+				zerolog.Ctx(req.Context()).Debug().
+					Str("mode", mode).
+					Msg("set params")
+				ctx := context.WithValue(req.Context(), ContextKeyMode, mode)
+				paramsSpan.End()
+
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// TagParams validates the tag in url path
+func TagParams(next http.Handler) http.Handler {
+	return tagParams(writeErrorV1)(next)
+}
+
+func tagParams(onError errorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				_, paramsSpan := getTracer().Start(req.Context(), "parse tag params")
+				defer paramsSpan.End()
+
+				tag, ok := normalizeTag(chi.URLParam(req, "tag"))
+				if !ok {
+					paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+					paramsSpan.RecordError(ErrInvalidParams)
+
+					onError(res, req, http.StatusBadRequest, invalidTagError("tag"))
+
+					return
+				}
+
+				zerolog.Ctx(req.Context()).Debug().
+					Str("tag", tag).
+					Msg("set params")
+				ctx := context.WithValue(req.Context(), ContextKeyTag, tag)
+				paramsSpan.End()
+
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// NewTagParams validates the tag form value of v1 tag renaming
+func NewTagParams(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			_, paramsSpan := getTracer().Start(req.Context(), "parse new tag params")
+			defer paramsSpan.End()
+
+			err := req.ParseForm()
+			if err != nil {
+				paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+				paramsSpan.RecordError(ErrInvalidParams)
+
+				writeError(res, http.StatusBadRequest, ErrInvalidParams)
+
+				return
+			}
+
+			tag, ok := normalizeTag(req.Form.Get("tag"))
+			if !ok {
+				paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+				paramsSpan.RecordError(ErrInvalidParams)
+
+				writeError(res, http.StatusBadRequest, ErrInvalidParams)
+
+				return
+			}
+
+			zerolog.Ctx(req.Context()).Debug().
+				Str("new tag", tag).
+				Msg("set params")
+			ctx := context.WithValue(req.Context(), ContextKeyNewTag, tag)
+			paramsSpan.End()
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+
+// TransferFormatParams validates the file format of website export and
+// import, json is used if format is not given
+func TransferFormatParams(next http.Handler) http.Handler {
+	return transferFormatParams(writeErrorV1)(next)
+}
+
+func transferFormatParams(onError errorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				_, paramsSpan := getTracer().Start(req.Context(), "parse transfer format params")
+				defer paramsSpan.End()
+
+				format := strings.ToLower(req.URL.Query().Get("format"))
+				if format == "" {
+					format = FormatJSON
+				}
+
+				if !validTransferFormat(format) {
+					paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+					paramsSpan.RecordError(ErrInvalidParams)
+
+					onError(res, req, http.StatusBadRequest, invalidParamsError(map[string]string{
+						"format": fmt.Sprintf("must be %s, %s or %s", FormatJSON, FormatCSV, FormatOPML),
+					}))
+
+					return
+				}
+
+				zerolog.Ctx(req.Context()).Debug().
+					Str("format", format).
+					Msg("set params")
+				ctx := context.WithValue(req.Context(), ContextKeyFormat, format)
+				paramsSpan.End()
+
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// ImportFileBody decodes the imported file in request body by the format
+// parsed by TransferFormatParams
+func ImportFileBody(next http.Handler) http.Handler {
+	return importFileBody(writeErrorV1)(next)
+}
+
+func importFileBody(onError errorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				_, paramsSpan := getTracer().Start(req.Context(), "parse import file body")
+				defer paramsSpan.End()
+
+				format := req.Context().Value(ContextKeyFormat).(string)
+
+				rows, err := decodeWebsites(http.MaxBytesReader(res, req.Body, MaxBodySize), format)
+				if err != nil {
+					paramsSpan.SetStatus(codes.Error, err.Error())
+					paramsSpan.RecordError(err)
+
+					onError(res, req, http.StatusBadRequest, invalidParamsError(map[string]string{
+						"file": fmt.Sprintf("must be a valid %s file: %s", format, err),
+					}))
+
+					return
+				}
+
+				if len(rows) == 0 || len(rows) > MaxImportRows {
+					paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+					paramsSpan.RecordError(ErrInvalidParams)
+
+					onError(res, req, http.StatusBadRequest, invalidParamsError(map[string]string{
+						"file": fmt.Sprintf("must contain 1 to %d websites", MaxImportRows),
+					}))
+
+					return
+				}
+
+				for i := range rows {
+					rows[i].URL = strings.TrimSpace(rows[i].URL)
+					rows[i].Title = strings.TrimSpace(rows[i].Title)
+					rows[i].GroupName = strings.TrimSpace(rows[i].GroupName)
+				}
+
+				zerolog.Ctx(req.Context()).Debug().
+					Int("rows", len(rows)).
+					Msg("set params")
+				ctx := context.WithValue(req.Context(), ContextKeyImport, rows)
+				paramsSpan.End()
+
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// JSONBody decodes the json request body of v2 api into T and stores it in
+// context. validate trims the fields and returns the invalid fields with the
+// reasons.
+func JSONBody[T any](validate func(*T) map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				_, paramsSpan := getTracer().Start(req.Context(), "parse json body")
+				defer paramsSpan.End()
+
+				var body T
+				err := json.NewDecoder(http.MaxBytesReader(res, req.Body, MaxBodySize)).Decode(&body)
+				if err != nil {
+					paramsSpan.SetStatus(codes.Error, err.Error())
+					paramsSpan.RecordError(err)
+
+					writeAPIError(res, req, invalidParamsError(map[string]string{"body": "must be a json object"}))
+
+					return
+				}
+
+				if details := validate(&body); len(details) > 0 {
+					paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+					paramsSpan.RecordError(ErrInvalidParams)
+
+					writeAPIError(res, req, invalidParamsError(details))
+
+					return
+				}
+
+				ctx := context.WithValue(req.Context(), ContextKeyBody, body)
+				paramsSpan.End()
+
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+func validateCreateWebsiteReq(body *createWebsiteReq) map[string]string {
+	body.Url = strings.TrimSpace(body.Url)
+	if !validURL(body.Url) {
+		return map[string]string{"url": "must be a http url"}
+	}
+
+	return nil
+}
+
+func validateGroupNameReq(body *groupNameReq) map[string]string {
+	body.GroupName = strings.TrimSpace(body.GroupName)
+	if body.GroupName == "" {
+		return map[string]string{"group_name": "must not be empty"}
+	}
+
+	return nil
+}
+
+func validateTagReq(body *tagReq) map[string]string {
+	tag, ok := normalizeTag(body.Tag)
+	if !ok {
+		return invalidTagError("tag").Details
+	}
+
+	body.Tag = tag
+
+	return nil
+}
+
+func validateUpdateUserWebsiteReq(body *updateUserWebsiteReq) map[string]string {
+	if body.Title == nil && body.Note == nil {
+		return map[string]string{"title": "title or note is required", "note": "title or note is required"}
+	}
+
+	if body.Title != nil {
+		title := strings.TrimSpace(*body.Title)
+		body.Title = &title
+	}
+
+	return nil
+}
+
+func validateUpdateReadProgressReq(body *updateReadProgressReq) map[string]string {
+	if body.Chapter == nil {
+		return map[string]string{"chapter": "is required"}
+	}
+
+	chapter := strings.TrimSpace(*body.Chapter)
+	body.Chapter = &chapter
+
+	return nil
+}
+
+func validateMergeWebsiteReq(body *mergeWebsiteReq) map[string]string {
+	body.Url = strings.TrimSpace(body.Url)
+	if !validURL(body.Url) {
+		return map[string]string{"url": "must be a http url"}
+	}
+
+	return nil
+}
+
+func validateWebhookReq(body *webhookReq) map[string]string {
+	if body.URL != nil {
+		url := strings.TrimSpace(*body.URL)
+		if !validURL(url) {
+			return map[string]string{"url": "must be a http url"}
+		}
+
+		if !validWebhookURL(url) {
+			return map[string]string{"url": "must not be a local or private address"}
+		}
+
+		body.URL = &url
+	}
+
+	if body.GroupName != nil {
+		groupName := strings.TrimSpace(*body.GroupName)
+		body.GroupName = &groupName
+	}
+
+	return nil
+}
+
+// validWebhookURL rejects url known to be internal, worker checks the
+// resolved address again before sending
+func validWebhookURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && webhookdelivery.AllowedHost(u.Hostname())
+}
+
+func validateCreateWebhookReq(body *webhookReq) map[string]string {
+	if body.URL == nil {
+		return map[string]string{"url": "is required"}
+	}
+
+	return validateWebhookReq(body)
+}
+
+func validateUpdateWebhookReq(body *webhookReq) map[string]string {
+	if body.URL == nil && body.GroupName == nil && body.Enabled == nil {
+		return map[string]string{
+			"url":        "url, group_name or enabled is required",
+			"group_name": "url, group_name or enabled is required",
+			"enabled":    "url, group_name or enabled is required",
+		}
+	}
+
+	return validateWebhookReq(body)
+}
+
+// CreateWebhookParams parses the form of v1 webhook creation
+func CreateWebhookParams(next http.Handler) http.Handler {
+	return webhookParams(validateCreateWebhookReq)(next)
+}
+
+// WebhookInfoParams parses the form of v1 webhook update, only the given
+// fields are updated
+func WebhookInfoParams(next http.Handler) http.Handler {
+	return webhookParams(validateUpdateWebhookReq)(next)
+}
+
+func webhookParams(validate func(*webhookReq) map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				_, paramsSpan := getTracer().Start(req.Context(), "parse webhook params")
+				defer paramsSpan.End()
+
+				err := req.ParseForm()
+				if err != nil {
+					paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+					paramsSpan.RecordError(ErrInvalidParams)
+
+					writeError(res, http.StatusBadRequest, ErrInvalidParams)
+
+					return
+				}
+
+				var params webhookReq
+				if req.Form.Has("url") {
+					url := req.Form.Get("url")
+					params.URL = &url
+				}
+
+				if req.Form.Has("group_name") {
+					groupName := req.Form.Get("group_name")
+					params.GroupName = &groupName
+				}
+
+				if req.Form.Has("enabled") {
+					enabled, parseErr := strconv.ParseBool(req.Form.Get("enabled"))
+					if parseErr != nil {
+						paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+						paramsSpan.RecordError(ErrInvalidParams)
+
+						writeError(res, http.StatusBadRequest, ErrInvalidParams)
+
+						return
+					}
+
+					params.Enabled = &enabled
+				}
+
+				if details := validate(&params); len(details) > 0 {
+					paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+					paramsSpan.RecordError(ErrInvalidParams)
+
+					writeError(res, http.StatusBadRequest, ErrInvalidParams)
+
+					return
+				}
+
+				zerolog.Ctx(req.Context()).Debug().
+					Bool("update url", params.URL != nil).
+					Bool("update group name", params.GroupName != nil).
+					Bool("update enabled", params.Enabled != nil).
+					Msg("set params")
+				ctx := context.WithValue(req.Context(), ContextKeyHookInfo, params)
+				paramsSpan.End()
+
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+func QueryWebhook(r repository.Repository) func(http.Handler) http.Handler {
+	return queryWebhook(r, writeErrorV1)
+}
+
+func queryWebhook(r repository.Repository, onError errorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+				webhookUUID := chi.URLParam(req, "webhookUUID")
+
+				_, dbSpan := getTracer().Start(req.Context(), "query webhook")
+				defer dbSpan.End()
+
+				webhook, err := r.FindWebhook(req.Context(), userUUID, webhookUUID)
+				if err != nil {
+					dbSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+					dbSpan.RecordError(ErrInvalidParams)
+
+					onError(res, req, http.StatusBadRequest, err)
+					return
+				}
+
+				dbSpan.End()
+
+				zerolog.Ctx(req.Context()).Debug().
+					Str("webhook uuid", webhook.UUID).
+					Msg("set params")
+				ctx := context.WithValue(req.Context(), ContextKeyWebhook, *webhook)
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// LastEventIDParams parses the Last-Event-ID header sent by reconnecting
+// event source, 0 means client has not received any event
+func LastEventIDParams(next http.Handler) http.Handler {
+	return lastEventIDParams(writeErrorV1)(next)
+}
+
+func lastEventIDParams(onError errorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				_, paramsSpan := getTracer().Start(req.Context(), "parse last event id params")
+				defer paramsSpan.End()
+
+				var lastEventID uint64
+				if value := strings.TrimSpace(req.Header.Get("Last-Event-ID")); value != "" {
+					id, err := strconv.ParseUint(value, 10, 64)
+					if err != nil {
+						paramsSpan.SetStatus(codes.Error, ErrInvalidParams.Error())
+						paramsSpan.RecordError(ErrInvalidParams)
+
+						onError(res, req, http.StatusBadRequest, invalidParamsError(map[string]string{
+							"Last-Event-ID": "must be id of received event",
+						}))
+
+						return
+					}
+
+					lastEventID = id
+				}
+
+				zerolog.Ctx(req.Context()).Debug().
+					Uint64("last_event_id", lastEventID).
+					Msg("set params")
+				ctx := context.WithValue(req.Context(), ContextKeyEventID, lastEventID)
+				paramsSpan.End()
+
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
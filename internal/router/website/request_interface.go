@@ -1,9 +1,37 @@
 package website
 
 type groupNameReq struct {
-	GroupName string
+	GroupName string `json:"group_name"`
+}
+
+type tagReq struct {
+	Tag string `json:"tag"`
+}
+
+type searchReq struct {
+	Query string
+	Limit int
 }
 
 type createWebsiteReq struct {
-	Url string
+	Url string `json:"url"`
+}
+
+type updateUserWebsiteReq struct {
+	Title *string `json:"title"`
+	Note  *string `json:"note"`
+}
+
+type updateReadProgressReq struct {
+	Chapter *string `json:"chapter"`
+}
+
+type mergeWebsiteReq struct {
+	Url string `json:"url"`
+}
+
+type webhookReq struct {
+	URL       *string `json:"url"`
+	GroupName *string `json:"group_name"`
+	Enabled   *bool   `json:"enabled"`
 }
@@ -1,6 +1,9 @@
 package website
 
 import (
+	"encoding/json"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/htchan/WebHistory/internal/model"
@@ -17,13 +20,34 @@ type WebsiteResp struct {
 }
 
 type UserWebsiteResp struct {
-	UUID       string    `json:"uuid"`
-	UserUUID   string    `json:"user_uuid"`
-	URL        string    `json:"url"`
-	Title      string    `json:"title"`
-	GroupName  string    `json:"group_name"`
-	UpdateTime time.Time `json:"update_time"`
-	AccessTime time.Time `json:"access_time"`
+	UUID            string    `json:"uuid"`
+	UserUUID        string    `json:"user_uuid"`
+	URL             string    `json:"url"`
+	Title           string    `json:"title"`
+	GroupName       string    `json:"group_name"`
+	Note            string    `json:"note"`
+	LastReadChapter string    `json:"last_read_chapter"`
+	UnreadCount     int       `json:"unread_count"`
+	Gone            bool      `json:"gone"`
+	UpdateTime      time.Time `json:"update_time"`
+	AccessTime      time.Time `json:"access_time"`
+}
+
+type AuditEventResp struct {
+	UUID        string          `json:"uuid"`
+	UserUUID    string          `json:"user_uuid"`
+	WebsiteUUID string          `json:"website_uuid"`
+	Action      string          `json:"action"`
+	Before      json.RawMessage `json:"before" swaggertype:"object"`
+	After       json.RawMessage `json:"after" swaggertype:"object"`
+	RequestID   string          `json:"request_id"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type WebsiteGroupSummaryResp struct {
+	GroupName    string `json:"group_name"`
+	WebsiteCount int    `json:"website_count"`
+	UnreadCount  int    `json:"unread_count"`
 }
 
 type WebsiteGroupResp []UserWebsiteResp
@@ -40,14 +64,45 @@ func fromModelWebsite(web model.Website) WebsiteResp {
 
 func fromModelUserWebsite(web model.UserWebsite) UserWebsiteResp {
 	return UserWebsiteResp{
-		UUID:       web.WebsiteUUID,
-		UserUUID:   web.UserUUID,
-		URL:        web.Website.URL,
-		Title:      web.Website.Title,
-		GroupName:  web.GroupName,
-		UpdateTime: web.Website.UpdateTime,
-		AccessTime: web.AccessTime,
+		UUID:            web.WebsiteUUID,
+		UserUUID:        web.UserUUID,
+		URL:             web.Website.URL,
+		Title:           web.Title(),
+		GroupName:       web.GroupName,
+		Note:            web.Note,
+		LastReadChapter: web.LastReadChapter,
+		UnreadCount:     web.UnreadCount(),
+		Gone:            web.Website.Gone(),
+		UpdateTime:      web.Website.UpdateTime,
+		AccessTime:      web.AccessTime,
+	}
+}
+
+// auditPayload returns null for empty payload so json stays valid
+func auditPayload(payload string) json.RawMessage {
+	if payload == "" {
+		return json.RawMessage("null")
 	}
+
+	return json.RawMessage(payload)
+}
+
+func fromModelAuditEvents(events []model.AuditEvent) []AuditEventResp {
+	resp := []AuditEventResp{}
+	for _, event := range events {
+		resp = append(resp, AuditEventResp{
+			UUID:        event.UUID,
+			UserUUID:    event.UserUUID,
+			WebsiteUUID: event.WebsiteUUID,
+			Action:      event.Action,
+			Before:      auditPayload(event.Before),
+			After:       auditPayload(event.After),
+			RequestID:   event.RequestID,
+			CreatedAt:   event.CreatedAt,
+		})
+	}
+
+	return resp
 }
 
 func fromModelWebsiteGroup(group model.WebsiteGroup) WebsiteGroupResp {
@@ -68,12 +123,125 @@ func fromModelWebsiteGroups(groups model.WebsiteGroups) WebsiteGroupsResp {
 	return webs
 }
 
+// fromModelWebsiteGroupSummaries summarizes groups in the order of group name
+func fromModelWebsiteGroupSummaries(groups model.WebsiteGroups) []WebsiteGroupSummaryResp {
+	summaries := []WebsiteGroupSummaryResp{}
+	for _, group := range groups {
+		summaries = append(summaries, WebsiteGroupSummaryResp{
+			GroupName:    group[0].GroupName,
+			WebsiteCount: len(group),
+			UnreadCount:  group.UnreadCount(),
+		})
+	}
+
+	slices.SortFunc(summaries, func(a, b WebsiteGroupSummaryResp) int {
+		return strings.Compare(a.GroupName, b.GroupName)
+	})
+
+	return summaries
+}
+
+type listGroupsResp struct {
+	Groups []WebsiteGroupSummaryResp `json:"groups"`
+}
+
+type renameGroupResp struct {
+	WebsiteGroup WebsiteGroupResp `json:"website_group"`
+}
+
+type mergeGroupResp struct {
+	WebsiteGroup WebsiteGroupResp `json:"website_group"`
+}
+
+type deleteGroupResp struct {
+	Msg string `json:"message"`
+}
+
+type refreshWebsitesResp struct {
+	Updated int `json:"updated"`
+}
+
+type GroupUnreadCountResp struct {
+	GroupName   string `json:"group_name"`
+	UnreadCount int    `json:"unread_count"`
+}
+
+type UnreadWebsiteResp struct {
+	UUID       string    `json:"uuid"`
+	GroupName  string    `json:"group_name"`
+	Title      string    `json:"title"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+// websiteSummaryResp counts websites updated after user last accessed them,
+// unlike unread_count of website which counts chapters
+type websiteSummaryResp struct {
+	UnreadCount  int                    `json:"unread_count"`
+	Groups       []GroupUnreadCountResp `json:"groups"`
+	LatestUnread []UnreadWebsiteResp    `json:"latest_unread"`
+}
+
+func fromModelUnreadSummary(summary model.UnreadSummary) websiteSummaryResp {
+	resp := websiteSummaryResp{
+		UnreadCount:  summary.UnreadCount,
+		Groups:       make([]GroupUnreadCountResp, len(summary.Groups)),
+		LatestUnread: make([]UnreadWebsiteResp, len(summary.LatestUnread)),
+	}
+
+	for i, group := range summary.Groups {
+		resp.Groups[i] = GroupUnreadCountResp{GroupName: group.GroupName, UnreadCount: group.UnreadCount}
+	}
+
+	for i, web := range summary.LatestUnread {
+		resp.LatestUnread[i] = UnreadWebsiteResp{
+			UUID:       web.WebsiteUUID,
+			GroupName:  web.GroupName,
+			Title:      web.Title,
+			UpdateTime: web.UpdateTime,
+		}
+	}
+
+	return resp
+}
+
+type TagResp struct {
+	Name         string `json:"name"`
+	WebsiteCount int    `json:"website_count"`
+}
+
+func fromModelTags(tags []model.Tag) []TagResp {
+	resp := []TagResp{}
+	for _, tag := range tags {
+		resp = append(resp, TagResp{Name: tag.Name, WebsiteCount: tag.WebsiteCount})
+	}
+
+	return resp
+}
+
+type listTagsResp struct {
+	Tags []TagResp `json:"tags"`
+}
+
+type deleteTagResp struct {
+	Msg string `json:"message"`
+}
+
+type websiteTagsResp struct {
+	Tags []string `json:"tags"`
+}
+
 type listAllWebsiteGroupsResp struct {
 	WebsiteGroups WebsiteGroupsResp `json:"website_groups"`
+	NextCursor    string            `json:"next_cursor,omitempty"`
 }
 
 type getWebsiteGroupResp struct {
 	WebsiteGroup WebsiteGroupResp `json:"website_group"`
+	NextCursor   string           `json:"next_cursor,omitempty"`
+}
+
+type searchWebsitesResp struct {
+	Websites WebsiteGroupResp `json:"websites"`
 }
 
 type createWebsiteResp struct {
@@ -95,3 +263,156 @@ type deleteWebsiteResp struct {
 type changeWebsiteGroupResp struct {
 	Website UserWebsiteResp `json:"website"`
 }
+
+type updateUserWebsiteResp struct {
+	Website UserWebsiteResp `json:"website"`
+}
+
+type updateReadProgressResp struct {
+	Website UserWebsiteResp `json:"website"`
+}
+
+type listAuditEventsResp struct {
+	AuditEvents []AuditEventResp `json:"audit_events"`
+}
+
+type mergeWebsiteResp struct {
+	From WebsiteResp `json:"from"`
+	To   WebsiteResp `json:"to"`
+}
+
+type JobResp struct {
+	UUID      string          `json:"uuid"`
+	Kind      string          `json:"kind"`
+	Status    string          `json:"status"`
+	Result    json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func fromModelJob(job model.Job) JobResp {
+	resp := JobResp{
+		UUID:      job.UUID,
+		Kind:      job.Kind,
+		Status:    job.Status,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+
+	if job.Result != "" {
+		resp.Result = json.RawMessage(job.Result)
+	}
+
+	return resp
+}
+
+type importWebsitesResp struct {
+	Job JobResp `json:"job"`
+}
+
+type getJobResp struct {
+	Job JobResp `json:"job"`
+}
+
+type checkWebsiteResp struct {
+	Job JobResp `json:"job"`
+}
+
+type FeedTokenResp struct {
+	Token     string    `json:"token"`
+	FeedPath  string    `json:"feed_path"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func fromModelFeedToken(token model.FeedToken, routePrefix string) FeedTokenResp {
+	return FeedTokenResp{
+		Token:     token.Token,
+		FeedPath:  feedPath(routePrefix, token.Token),
+		CreatedAt: token.CreatedAt,
+	}
+}
+
+type feedTokenResp struct {
+	FeedToken FeedTokenResp `json:"feed_token"`
+}
+
+// WebhookResp only has the secret right after the webhook is created, so the
+// secret cannot be read again afterwards
+type WebhookResp struct {
+	UUID         string    `json:"uuid"`
+	URL          string    `json:"url"`
+	GroupName    string    `json:"group_name"`
+	Secret       string    `json:"secret,omitempty"`
+	Enabled      bool      `json:"enabled"`
+	FailureCount int       `json:"failure_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func fromModelWebhook(webhook model.Webhook) WebhookResp {
+	return WebhookResp{
+		UUID:         webhook.UUID,
+		URL:          webhook.URL,
+		GroupName:    webhook.GroupName,
+		Enabled:      webhook.Enabled,
+		FailureCount: webhook.FailureCount,
+		CreatedAt:    webhook.CreatedAt,
+		UpdatedAt:    webhook.UpdatedAt,
+	}
+}
+
+func fromModelWebhooks(webhooks []model.Webhook) []WebhookResp {
+	resp := []WebhookResp{}
+	for _, webhook := range webhooks {
+		resp = append(resp, fromModelWebhook(webhook))
+	}
+
+	return resp
+}
+
+type WebhookDeliveryResp struct {
+	UUID           string          `json:"uuid"`
+	WebsiteUUID    string          `json:"website_uuid"`
+	Status         string          `json:"status"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+func fromModelWebhookDeliveries(deliveries []model.WebhookDelivery) []WebhookDeliveryResp {
+	resp := []WebhookDeliveryResp{}
+	for _, delivery := range deliveries {
+		resp = append(resp, WebhookDeliveryResp{
+			UUID:           delivery.UUID,
+			WebsiteUUID:    delivery.WebsiteUUID,
+			Status:         delivery.Status,
+			Payload:        json.RawMessage(delivery.Payload),
+			Attempts:       delivery.Attempts,
+			ResponseStatus: delivery.ResponseStatus,
+			Error:          delivery.Error,
+			CreatedAt:      delivery.CreatedAt,
+			UpdatedAt:      delivery.UpdatedAt,
+		})
+	}
+
+	return resp
+}
+
+type listWebhooksResp struct {
+	Webhooks []WebhookResp `json:"webhooks"`
+}
+
+type webhookResp struct {
+	Webhook WebhookResp `json:"webhook"`
+}
+
+type deleteWebhookResp struct {
+	Msg string `json:"message"`
+}
+
+type listWebhookDeliveriesResp struct {
+	Deliveries []WebhookDeliveryResp `json:"deliveries"`
+}
@@ -5,33 +5,48 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"net/url"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	_ "github.com/htchan/WebHistory/docs" // docs is generated by Swag CLI, you have to import it.
 	"github.com/htchan/WebHistory/internal/config"
 	"github.com/htchan/WebHistory/internal/repository"
+	websiteevents "github.com/htchan/WebHistory/internal/tasks/nats/website_events"
+	websiteimport "github.com/htchan/WebHistory/internal/tasks/nats/website_import"
 	websiteupdate "github.com/htchan/WebHistory/internal/tasks/nats/website_update"
+	"github.com/htchan/WebHistory/internal/userservice"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
 var ErrUnauthorized = errors.New("unauthorized")
 var ErrInvalidParams = errors.New("invalid params")
 var ErrRecordNotFound = errors.New("record not found")
+var ErrInvalidChapter = errors.New("invalid chapter")
+var ErrInvalidGroupName = errors.New("invalid group name")
+var ErrGroupExists = errors.New("group already exists")
+var ErrUnsupportedWebsite = errors.New("unsupported website")
+var ErrTooManyChecks = errors.New("too many checks")
+var ErrJobNotPublished = errors.New("job not published")
 
 func writeError(res http.ResponseWriter, statusCode int, err error) {
 	res.WriteHeader(statusCode)
 	json.NewEncoder(res).Encode(errResp{err.Error()})
 }
 
-func redirectLogin(res http.ResponseWriter, req *http.Request) {
-	loginURL := os.Getenv("LOGIN_URL")
-	serviceUUID := os.Getenv("SERVICE_UUID")
-	http.Redirect(res, req, fmt.Sprintf("%v?service=%v", loginURL, serviceUUID), 302)
+func redirectLogin(res http.ResponseWriter, req *http.Request, conf *config.UserServiceConfig) {
+	http.Redirect(res, req, fmt.Sprintf("%v?service=%v", conf.LoginURL, url.QueryEscape(conf.ServiceUUID)), http.StatusFound)
 }
 
-func AddRoutes(router chi.Router, r repository.Repository, tasks websiteupdate.WebsiteUpdateTasks, conf *config.APIConfig) {
+func AddRoutes(
+	router chi.Router,
+	r repository.Repository,
+	tasks websiteupdate.WebsiteUpdateTasks,
+	importTask *websiteimport.WebsiteImportTask,
+	events *websiteevents.Broker,
+	auth userservice.Authenticator,
+	conf *config.APIConfig,
+) {
 	router.Use(logRequest())
 	router.Use(TraceMiddleware)
 
@@ -41,31 +56,167 @@ func AddRoutes(router chi.Router, r repository.Repository, tasks websiteupdate.W
 				cors.Handler(
 					cors.Options{
 						AllowedOrigins: []string{"*"},
-						AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+						AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 						AllowedHeaders: []string{"*"},
 						MaxAge:         300, // Maximum value not ignored by any of major browsers
 					},
 				),
 			)
-			router.Use(AuthenticateMiddleware(&conf.UserServiceConfig))
+			router.Use(AuthenticateMiddleware(auth, &conf.UserServiceConfig))
 			router.Use(SetContentType)
 
 			router.Route("/groups", func(router chi.Router) {
-				router.Get("/", getAllWebsiteGroupsHandler(r))
-				router.Get("/{groupName}", getWebsiteGroupHandler(r))
+				router.With(PaginationParams).Get("/", getAllWebsiteGroupsHandler(r))
+				router.With(PaginationParams).Get("/{groupName}", getWebsiteGroupHandler(r))
+				router.With(GroupDeleteModeParams).Delete("/{groupName}", deleteGroupHandler(r))
+				router.With(GroupNameParams).Put("/{groupName}/rename", renameGroupHandler(r, &conf.WebsiteConfig))
+				router.With(GroupNameParams).Put("/{groupName}/merge", mergeGroupHandler(r, &conf.WebsiteConfig))
+				router.Put("/{groupName}/refresh", refreshGroupHandler(r))
 			})
 
+			router.Route("/tags", func(router chi.Router) {
+				router.Get("/", listTagsHandler(r))
+				router.With(TagParams).Delete("/{tag}", deleteTagHandler(r))
+				router.With(TagParams, NewTagParams).Put("/{tag}/rename", renameTagHandler(r))
+			})
+
+			router.Route("/webhooks", func(router chi.Router) {
+				router.Get("/", listWebhooksHandler(r))
+				router.With(CreateWebhookParams).Post("/", createWebhookHandler(r))
+
+				router.With(QueryWebhook(r)).Route("/{webhookUUID}", func(router chi.Router) {
+					router.With(WebhookInfoParams).Patch("/", updateWebhookHandler(r))
+					router.Delete("/", deleteWebhookHandler(r))
+					router.Get("/deliveries", listWebhookDeliveriesHandler(r))
+				})
+			})
+
+			router.Get("/group-counts", listGroupsHandler(r))
+			router.Get("/summary", websiteSummaryHandler(r))
+			router.Put("/refresh", refreshAllWebsitesHandler(r))
+			router.With(SearchParams).Get("/search", searchWebsitesHandler(r))
+			router.With(AuditEventsParams).Get("/audit-events", listAuditEventsHandler(r))
+			router.With(TransferFormatParams).Get("/export", exportWebsitesHandler(r))
+			router.With(TransferFormatParams, ImportFileBody).Post("/import", importWebsitesHandler(r, importTask))
+			router.Get("/jobs/{jobUUID}", getJobHandler(r))
+			router.Get("/feed-token", getFeedTokenHandler(r, conf.BinConfig.APIRoutePrefix))
+			router.Put("/feed-token", rotateFeedTokenHandler(r, conf.BinConfig.APIRoutePrefix))
+			router.With(LastEventIDParams).Get("/events", websiteEventsHandler(events))
 			router.With(WebsiteParams).Post("/", createWebsiteHandler(r, &conf.WebsiteConfig, tasks))
 
 			router.With(QueryUserWebsite(r)).Route("/{webUUID}", func(router chi.Router) {
 				router.Get("/", getUserWebsiteHandler())
 				router.Delete("/", deleteWebsiteHandler(r))
+				router.With(UserWebsiteInfoParams).Patch("/", updateUserWebsiteHandler(r))
 				router.Put("/refresh", refreshWebsiteHandler(r))
-				router.With(GroupNameParams).Put("/change-group", changeWebsiteGroupHandler(r))
+				router.Post("/check", checkWebsiteHandler(r, tasks, &conf.CheckConfig))
+				router.With(GroupNameParams).Put("/change-group", changeWebsiteGroupHandler(r, &conf.WebsiteConfig))
+				router.With(ChapterParams).Put("/read-progress", updateReadProgressHandler(r))
+				router.Get("/tags", getWebsiteTagsHandler(r))
+				router.With(TagParams).Put("/tags/{tag}", addWebsiteTagHandler(r))
+				router.With(TagParams).Delete("/tags/{tag}", removeWebsiteTagHandler(r))
 			})
 		})
+		router.Route("/admin", func(router chi.Router) {
+			router.Use(AdminAuthenticateMiddleware(&conf.AdminConfig))
+			router.Use(SetContentType)
+
+			router.With(WebsiteParams).Post("/websites/{webUUID}/merge", mergeWebsiteHandler(r, &conf.WebsiteConfig))
+			router.With(AuditEventsParams).Get("/audit-events", listAdminAuditEventsHandler(r))
+		})
+		router.Route("/v2", func(router chi.Router) {
+			addV2Routes(router, r, tasks, importTask, events, auth, conf)
+		})
+		// feed readers cannot authenticate, the secret token in url identifies user
+		router.Get("/feeds/{feedToken}", feedHandler(r))
 		router.Get("/db-stats", dbStatsHandler(r))
 	})
 
 	router.Get("/docs/swagger/*", httpSwagger.WrapHandler)
 }
+
+// addV2Routes serves the same resources as v1 with json request body and
+// structured errors of correct status
+func addV2Routes(
+	router chi.Router,
+	r repository.Repository,
+	tasks websiteupdate.WebsiteUpdateTasks,
+	importTask *websiteimport.WebsiteImportTask,
+	events *websiteevents.Broker,
+	auth userservice.Authenticator,
+	conf *config.APIConfig,
+) {
+	router.Route("/websites", func(router chi.Router) {
+		router.Use(
+			cors.Handler(
+				cors.Options{
+					AllowedOrigins: []string{"*"},
+					AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+					AllowedHeaders: []string{"*"},
+					MaxAge:         300, // Maximum value not ignored by any of major browsers
+				},
+			),
+		)
+		router.Use(authenticate(auth, &conf.UserServiceConfig, writeErrorV2))
+		router.Use(SetContentType)
+
+		router.Route("/groups", func(router chi.Router) {
+			router.With(paginationParams(writeErrorV2)).Get("/", getAllWebsiteGroupsHandlerV2(r))
+			router.With(paginationParams(writeErrorV2)).Get("/{groupName}", getWebsiteGroupHandlerV2(r))
+			router.With(groupDeleteModeParams(writeErrorV2)).Delete("/{groupName}", deleteGroupHandlerV2(r))
+			router.With(JSONBody(validateGroupNameReq)).Put("/{groupName}/rename", renameGroupHandlerV2(r, &conf.WebsiteConfig))
+			router.With(JSONBody(validateGroupNameReq)).Put("/{groupName}/merge", mergeGroupHandlerV2(r, &conf.WebsiteConfig))
+			router.Put("/{groupName}/refresh", refreshGroupHandlerV2(r))
+		})
+
+		router.Route("/tags", func(router chi.Router) {
+			router.Get("/", listTagsHandlerV2(r))
+			router.With(tagParams(writeErrorV2)).Delete("/{tag}", deleteTagHandlerV2(r))
+			router.With(tagParams(writeErrorV2), JSONBody(validateTagReq)).Put("/{tag}/rename", renameTagHandlerV2(r))
+		})
+
+		router.Route("/webhooks", func(router chi.Router) {
+			router.Get("/", listWebhooksHandlerV2(r))
+			router.With(JSONBody(validateCreateWebhookReq)).Post("/", createWebhookHandlerV2(r))
+
+			router.With(queryWebhook(r, writeErrorV2)).Route("/{webhookUUID}", func(router chi.Router) {
+				router.With(JSONBody(validateUpdateWebhookReq)).Patch("/", updateWebhookHandlerV2(r))
+				router.Delete("/", deleteWebhookHandlerV2(r))
+				router.Get("/deliveries", listWebhookDeliveriesHandlerV2(r))
+			})
+		})
+
+		router.Get("/group-counts", listGroupsHandlerV2(r))
+		router.Get("/summary", websiteSummaryHandlerV2(r))
+		router.Put("/refresh", refreshAllWebsitesHandlerV2(r))
+		router.With(searchParams(writeErrorV2)).Get("/search", searchWebsitesHandlerV2(r))
+		router.With(auditEventsParams(writeErrorV2)).Get("/audit-events", listAuditEventsHandlerV2(r))
+		router.With(transferFormatParams(writeErrorV2)).Get("/export", exportWebsitesHandlerV2(r))
+		router.With(transferFormatParams(writeErrorV2), importFileBody(writeErrorV2)).Post("/import", importWebsitesHandlerV2(r, importTask))
+		router.Get("/jobs/{jobUUID}", getJobHandlerV2(r))
+		router.Get("/feed-token", getFeedTokenHandlerV2(r, conf.BinConfig.APIRoutePrefix))
+		router.Put("/feed-token", rotateFeedTokenHandlerV2(r, conf.BinConfig.APIRoutePrefix))
+		router.With(lastEventIDParams(writeErrorV2)).Get("/events", websiteEventsHandlerV2(events))
+		router.With(JSONBody(validateCreateWebsiteReq)).Post("/", createWebsiteHandlerV2(r, &conf.WebsiteConfig, tasks))
+
+		router.With(queryUserWebsite(r, writeErrorV2)).Route("/{webUUID}", func(router chi.Router) {
+			router.Get("/", getUserWebsiteHandler())
+			router.Delete("/", deleteWebsiteHandlerV2(r))
+			router.With(JSONBody(validateUpdateUserWebsiteReq)).Patch("/", updateUserWebsiteHandlerV2(r))
+			router.Put("/refresh", refreshWebsiteHandlerV2(r))
+			router.Post("/check", checkWebsiteHandlerV2(r, tasks, &conf.CheckConfig))
+			router.With(JSONBody(validateGroupNameReq)).Put("/change-group", changeWebsiteGroupHandlerV2(r, &conf.WebsiteConfig))
+			router.With(JSONBody(validateUpdateReadProgressReq)).Put("/read-progress", updateReadProgressHandlerV2(r))
+			router.Get("/tags", getWebsiteTagsHandlerV2(r))
+			router.With(tagParams(writeErrorV2)).Put("/tags/{tag}", addWebsiteTagHandlerV2(r))
+			router.With(tagParams(writeErrorV2)).Delete("/tags/{tag}", removeWebsiteTagHandlerV2(r))
+		})
+	})
+	router.Route("/admin", func(router chi.Router) {
+		router.Use(adminAuthenticate(&conf.AdminConfig, writeErrorV2))
+		router.Use(SetContentType)
+
+		router.With(JSONBody(validateMergeWebsiteReq)).Post("/websites/{webUUID}/merge", mergeWebsiteHandlerV2(r, &conf.WebsiteConfig))
+		router.With(auditEventsParams(writeErrorV2)).Get("/audit-events", listAdminAuditEventsHandlerV2(r))
+	})
+}
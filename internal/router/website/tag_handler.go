@@ -0,0 +1,251 @@
+package website
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+func hasTag(tags []model.Tag, name string) bool {
+	return slices.ContainsFunc(tags, func(tag model.Tag) bool { return tag.Name == name })
+}
+
+// renameUserTag renames tag from of all websites to tag to and returns the
+// tags after renaming. The tags are merged if tag to already exists.
+func renameUserTag(ctx context.Context, r repository.Repository, userUUID, from, to string) ([]model.Tag, error) {
+	var tags []model.Tag
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		var err error
+		tags, err = txRepo.FindUserTags(ctx, userUUID)
+		if err != nil {
+			return err
+		} else if !hasTag(tags, from) {
+			return ErrRecordNotFound
+		} else if from == to {
+			return invalidParamsError(map[string]string{"tag": "must not be the renamed tag"})
+		}
+
+		err = txRepo.RenameUserTag(ctx, userUUID, from, to)
+		if err != nil {
+			return err
+		}
+
+		tags, err = txRepo.FindUserTags(ctx, userUUID)
+
+		return err
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("from", from).Str("to", to).Msg("rename user tag failed")
+	}
+
+	return tags, err
+}
+
+// deleteUserTag removes tag from all websites of user
+func deleteUserTag(ctx context.Context, r repository.Repository, userUUID, tag string) error {
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		tags, err := txRepo.FindUserTags(ctx, userUUID)
+		if err != nil {
+			return err
+		} else if !hasTag(tags, tag) {
+			return ErrRecordNotFound
+		}
+
+		return txRepo.DeleteUserTag(ctx, userUUID, tag)
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("tag", tag).Msg("delete user tag failed")
+	}
+
+	return err
+}
+
+// updateWebsiteTag adds or removes tag of web and returns the tags of web
+// after update
+func updateWebsiteTag(ctx context.Context, r repository.Repository, web model.UserWebsite, tag string, add bool) ([]string, error) {
+	var tags []string
+	err := r.WithTx(ctx, func(txRepo repository.Repository) error {
+		var err error
+		if add {
+			err = txRepo.AddUserWebsiteTag(ctx, web.UserUUID, web.WebsiteUUID, tag)
+		} else {
+			err = txRepo.RemoveUserWebsiteTag(ctx, web.UserUUID, web.WebsiteUUID, tag)
+		}
+		if err != nil {
+			return err
+		}
+
+		tags, err = txRepo.FindUserWebsiteTags(ctx, web.UserUUID, web.WebsiteUUID)
+
+		return err
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("tag", tag).Bool("add", add).Msg("update website tag failed")
+	}
+
+	return tags, err
+}
+
+// @Summary		List tags
+// @description	list tags of user with the number of tagged websites
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	listTagsResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/tags [get]
+func listTagsHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		tags, err := r.FindUserTags(req.Context(), userUUID)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("find user tags failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, listTagsResp{fromModelTags(tags)})
+	}
+}
+
+// @Summary		Rename tag
+// @description	rename tag of all websites, the tags are merged if new tag already exists
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			tag			path		string	true	"tag"
+// @Param			tag			formData	string	true	"new tag"
+// @Success		200			{object}	listTagsResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/tags/{tag}/rename [put]
+func renameTagHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		tag := req.Context().Value(ContextKeyTag).(string)
+		newTag := req.Context().Value(ContextKeyNewTag).(string)
+
+		tags, err := renameUserTag(req.Context(), r, userUUID, tag, newTag)
+		if err != nil {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, listTagsResp{fromModelTags(tags)})
+	}
+}
+
+// @Summary		Delete tag
+// @description	remove tag from all websites
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			tag			path		string	true	"tag"
+// @Success		200			{object}	deleteTagResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/tags/{tag} [delete]
+func deleteTagHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		tag := req.Context().Value(ContextKeyTag).(string)
+
+		err := deleteUserTag(req.Context(), r, userUUID, tag)
+		if err != nil {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, deleteTagResp{fmt.Sprintf("tag <%v> deleted", tag)})
+	}
+}
+
+// @Summary		List website tags
+// @description	list tags of user website
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			websiteUUID	path		string	true	"website uuid"
+// @Success		200			{object}	websiteTagsResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/{websiteUUID}/tags [get]
+func getWebsiteTagsHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+
+		tags, err := r.FindUserWebsiteTags(req.Context(), web.UserUUID, web.WebsiteUUID)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("find user website tags failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, websiteTagsResp{tags})
+	}
+}
+
+// @Summary		Add website tag
+// @description	add tag to user website
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			websiteUUID	path		string	true	"website uuid"
+// @Param			tag			path		string	true	"tag"
+// @Success		200			{object}	websiteTagsResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/{websiteUUID}/tags/{tag} [put]
+func addWebsiteTagHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		tag := req.Context().Value(ContextKeyTag).(string)
+
+		tags, err := updateWebsiteTag(req.Context(), r, web, tag, true)
+		if err != nil {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, websiteTagsResp{tags})
+	}
+}
+
+// @Summary		Remove website tag
+// @description	remove tag from user website
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			websiteUUID	path		string	true	"website uuid"
+// @Param			tag			path		string	true	"tag"
+// @Success		200			{object}	websiteTagsResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/{websiteUUID}/tags/{tag} [delete]
+func removeWebsiteTagHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		web := req.Context().Value(ContextKeyWebsite).(model.UserWebsite)
+		tag := req.Context().Value(ContextKeyTag).(string)
+
+		tags, err := updateWebsiteTag(req.Context(), r, web, tag, false)
+		if err != nil {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, websiteTagsResp{tags})
+	}
+}
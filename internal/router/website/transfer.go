@@ -0,0 +1,308 @@
+package website
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/model"
+	websiteimport "github.com/htchan/WebHistory/internal/tasks/nats/website_import"
+)
+
+// file formats of website export and import
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatOPML = "opml"
+)
+
+var (
+	transferContentTypes = map[string]string{
+		FormatJSON: "application/json; charset=utf-8",
+		FormatCSV:  "text/csv; charset=utf-8",
+		FormatOPML: "text/x-opml; charset=utf-8",
+	}
+
+	csvHeader = []string{"url", "title", "group_name", "note", "access_time"}
+
+	errNoURLColumn = errors.New("url column is missing")
+)
+
+type transferFile struct {
+	Websites []websiteimport.ImportRow `json:"websites"`
+}
+
+type opmlFile struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Head    opmlHead      `xml:"head"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+// opmlOutline is either a group holding outlines of websites, or a website
+// of type link. xmlUrl and htmlUrl are read for files exported by feed readers.
+type opmlOutline struct {
+	Text       string        `xml:"text,attr"`
+	Type       string        `xml:"type,attr,omitempty"`
+	Title      string        `xml:"title,attr,omitempty"`
+	URL        string        `xml:"url,attr,omitempty"`
+	XMLURL     string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL    string        `xml:"htmlUrl,attr,omitempty"`
+	Note       string        `xml:"note,attr,omitempty"`
+	AccessTime string        `xml:"accessTime,attr,omitempty"`
+	Outlines   []opmlOutline `xml:"outline"`
+}
+
+func (outline opmlOutline) url() string {
+	for _, u := range []string{outline.URL, outline.HTMLURL, outline.XMLURL} {
+		if u != "" {
+			return u
+		}
+	}
+
+	return ""
+}
+
+func validTransferFormat(format string) bool {
+	_, ok := transferContentTypes[format]
+
+	return ok
+}
+
+// toTransferRows keeps the user defined fields of webs, so importing the
+// exported file restores the subscriptions
+func toTransferRows(webs []model.UserWebsite) []websiteimport.ImportRow {
+	rows := make([]websiteimport.ImportRow, 0, len(webs))
+	for _, web := range webs {
+		rows = append(rows, websiteimport.ImportRow{
+			URL:        web.Website.URL,
+			Title:      web.DisplayTitle,
+			GroupName:  web.GroupName,
+			Note:       web.Note,
+			AccessTime: web.AccessTime.UTC(),
+		})
+	}
+
+	return rows
+}
+
+func encodeWebsites(w io.Writer, format string, webs []model.UserWebsite) error {
+	switch format {
+	case FormatCSV:
+		return encodeCSV(w, toTransferRows(webs))
+	case FormatOPML:
+		return encodeOPML(w, webs)
+	default:
+		return json.NewEncoder(w).Encode(transferFile{toTransferRows(webs)})
+	}
+}
+
+func decodeWebsites(r io.Reader, format string) ([]websiteimport.ImportRow, error) {
+	switch format {
+	case FormatCSV:
+		return decodeCSV(r)
+	case FormatOPML:
+		return decodeOPML(r)
+	default:
+		var file transferFile
+		if err := json.NewDecoder(r).Decode(&file); err != nil {
+			return nil, err
+		}
+
+		return file.Websites, nil
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid access time %q", value)
+	}
+
+	return t.UTC(), nil
+}
+
+func encodeCSV(w io.Writer, rows []websiteimport.ImportRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		err := writer.Write([]string{row.URL, row.Title, row.GroupName, row.Note, formatTime(row.AccessTime)})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// decodeCSV reads the columns by the header, so the columns can be in any
+// order and only url column is required
+func decodeCSV(r io.Reader) ([]websiteimport.ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := columns["url"]; !ok {
+		return nil, errNoURLColumn
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]websiteimport.ImportRow, 0, len(records))
+	for i, record := range records {
+		field := func(name string) string {
+			col, ok := columns[name]
+			if !ok || col >= len(record) {
+				return ""
+			}
+
+			return strings.TrimSpace(record[col])
+		}
+
+		accessTime, err := parseTime(field("access_time"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+
+		rows = append(rows, websiteimport.ImportRow{
+			URL:        field("url"),
+			Title:      field("title"),
+			GroupName:  field("group_name"),
+			Note:       field("note"),
+			AccessTime: accessTime,
+		})
+	}
+
+	return rows, nil
+}
+
+// encodeOPML lists the websites under outline of their groups in the order
+// of first appearance
+func encodeOPML(w io.Writer, webs []model.UserWebsite) error {
+	file := opmlFile{
+		Version: "2.0",
+		Head: opmlHead{
+			Title:       "Web History subscriptions",
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+		Body: []opmlOutline{},
+	}
+
+	groupIndex := make(map[string]int)
+	for _, web := range webs {
+		i, ok := groupIndex[web.GroupName]
+		if !ok {
+			i = len(file.Body)
+			groupIndex[web.GroupName] = i
+			file.Body = append(file.Body, opmlOutline{Text: web.GroupName})
+		}
+
+		text := web.Title()
+		if text == "" {
+			text = web.Website.URL
+		}
+
+		file.Body[i].Outlines = append(file.Body[i].Outlines, opmlOutline{
+			Text:       text,
+			Type:       "link",
+			Title:      web.DisplayTitle,
+			URL:        web.Website.URL,
+			Note:       web.Note,
+			AccessTime: formatTime(web.AccessTime),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	return encoder.Encode(file)
+}
+
+// decodeOPML reads every outline having url as website, and takes the text
+// of its closest parent outline as group name
+func decodeOPML(r io.Reader) ([]websiteimport.ImportRow, error) {
+	var file opmlFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	rows := []websiteimport.ImportRow{}
+
+	var walk func(outlines []opmlOutline, groupName string) error
+	walk = func(outlines []opmlOutline, groupName string) error {
+		for _, outline := range outlines {
+			url := outline.url()
+			if url == "" {
+				if err := walk(outline.Outlines, strings.TrimSpace(outline.Text)); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			accessTime, err := parseTime(outline.AccessTime)
+			if err != nil {
+				return fmt.Errorf("outline %q: %w", outline.Text, err)
+			}
+
+			rows = append(rows, websiteimport.ImportRow{
+				URL:        strings.TrimSpace(url),
+				Title:      strings.TrimSpace(outline.Title),
+				GroupName:  groupName,
+				Note:       outline.Note,
+				AccessTime: accessTime,
+			})
+		}
+
+		return nil
+	}
+
+	if err := walk(file.Body, ""); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,155 @@
+package website
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	websiteimport "github.com/htchan/WebHistory/internal/tasks/nats/website_import"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// writeExportFile writes webs as attachment of format
+func writeExportFile(ctx context.Context, res http.ResponseWriter, format string, webs []model.UserWebsite) {
+	_, encodeSpan := getTracer().Start(ctx, "Encode export file")
+	defer encodeSpan.End()
+
+	res.Header().Set("Content-Type", transferContentTypes[format])
+	res.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="web-history.%s"`, format))
+
+	err := encodeWebsites(res, format, webs)
+	if err != nil {
+		encodeSpan.SetStatus(codes.Error, err.Error())
+		encodeSpan.RecordError(err)
+		zerolog.Ctx(ctx).Error().Err(err).Str("format", format).Msg("encode export file failed")
+	}
+}
+
+// startImportJob creates the import job of rows and publishes it to worker.
+// The job is marked failed if it cannot be published.
+func startImportJob(
+	ctx context.Context,
+	r repository.Repository,
+	task *websiteimport.WebsiteImportTask,
+	userUUID string,
+	rows []websiteimport.ImportRow,
+) (model.Job, error) {
+	job := model.NewJob(userUUID, model.JobKindImport)
+
+	err := r.CreateJob(ctx, &job)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("create import job failed")
+
+		return job, err
+	}
+
+	jobCtx, jobSpan := getTracer().Start(ctx, "Website Import Job Creation")
+	defer jobSpan.End()
+
+	err = task.Publish(jobCtx, &websiteimport.WebsiteImportParams{
+		JobUUID:   job.UUID,
+		UserUUID:  userUUID,
+		RequestID: requestID(ctx),
+		Rows:      rows,
+	})
+	if err != nil {
+		jobSpan.SetStatus(codes.Error, err.Error())
+		jobSpan.RecordError(err)
+		zerolog.Ctx(ctx).Error().Err(err).Str("job_uuid", job.UUID).Msg("publish import job failed")
+
+		job.Status = model.JobStatusFailed
+		job.UpdatedAt = time.Now().UTC()
+		if updateErr := r.UpdateJob(ctx, &job); updateErr != nil {
+			zerolog.Ctx(ctx).Error().Err(updateErr).Str("job_uuid", job.UUID).Msg("update import job failed")
+		}
+
+		return job, err
+	}
+
+	return job, nil
+}
+
+// @Summary		Export websites
+// @description	export all websites of user as json, csv or opml file
+// @Tags			web-history
+// @Produce		json,text/csv,text/x-opml
+// @Security		UserToken
+// @Param			format		query		string	false	"file format"	Enums(json, csv, opml)
+// @Success		200			{file}		file
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/export [get]
+func exportWebsitesHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		format := req.Context().Value(ContextKeyFormat).(string)
+
+		webs, err := r.FindUserWebsites(req.Context(), userUUID)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("find user websites failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		writeExportFile(req.Context(), res, format, webs)
+	}
+}
+
+// @Summary		Import websites
+// @description	import websites from json, csv or opml file in background, the
+// @description	report of each website is kept in the returned job
+// @Tags			web-history
+// @Accept			json,text/csv,text/x-opml
+// @Produce		json
+// @Security		UserToken
+// @Param			format		query		string	false	"file format"	Enums(json, csv, opml)
+// @Param			file		body		string	true	"imported file"
+// @Success		202			{object}	importWebsitesResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/import [post]
+func importWebsitesHandler(r repository.Repository, task *websiteimport.WebsiteImportTask) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		rows := req.Context().Value(ContextKeyImport).([]websiteimport.ImportRow)
+
+		job, err := startImportJob(req.Context(), r, task, userUUID, rows)
+		if err != nil {
+			writeError(res, http.StatusBadRequest, err)
+
+			return
+		}
+
+		res.WriteHeader(http.StatusAccepted)
+		encodeJsonResp(req.Context(), res, importWebsitesResp{fromModelJob(job)})
+	}
+}
+
+// @Summary		Get job
+// @description	get status of background job, with the report once it is done
+// @Tags			web-history
+// @Produce		json
+// @Security		UserToken
+// @Param			jobUUID		path		string	true	"job uuid"
+// @Success		200			{object}	getJobResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/jobs/{jobUUID} [get]
+func getJobHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		job, err := r.FindJob(req.Context(), userUUID, chi.URLParam(req, "jobUUID"))
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("find job failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, getJobResp{fromModelJob(*job)})
+	}
+}
@@ -0,0 +1,195 @@
+package website
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+func createUserWebhook(ctx context.Context, r repository.Repository, userUUID string, params webhookReq) (model.Webhook, error) {
+	var groupName string
+	if params.GroupName != nil {
+		groupName = *params.GroupName
+	}
+
+	webhook := model.NewWebhook(userUUID, *params.URL, groupName)
+	if params.Enabled != nil {
+		webhook.Enabled = *params.Enabled
+	}
+
+	err := r.CreateWebhook(ctx, &webhook)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("create webhook failed")
+	}
+
+	return webhook, err
+}
+
+// updateUserWebhook updates the given fields of webhook. Enabling a disabled
+// webhook clears its failures, so it is not disabled again by the next failure.
+func updateUserWebhook(ctx context.Context, r repository.Repository, webhook model.Webhook, params webhookReq) (model.Webhook, error) {
+	if params.URL != nil {
+		webhook.URL = *params.URL
+	}
+
+	if params.GroupName != nil {
+		webhook.GroupName = *params.GroupName
+	}
+
+	if params.Enabled != nil {
+		if *params.Enabled && !webhook.Enabled {
+			webhook.FailureCount = 0
+		}
+
+		webhook.Enabled = *params.Enabled
+	}
+
+	webhook.UpdatedAt = time.Now().UTC()
+
+	err := r.UpdateWebhook(ctx, &webhook)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("update webhook failed")
+	}
+
+	return webhook, err
+}
+
+// @Summary		List webhooks
+// @description	list webhooks of user, the secret of webhook is not listed
+// @Tags			web-history
+// @Produce		json
+// @Security		UserToken
+// @Success		200			{object}	listWebhooksResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/webhooks [get]
+func listWebhooksHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+
+		webhooks, err := r.FindWebhooks(req.Context(), userUUID)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("find webhooks failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, listWebhooksResp{fromModelWebhooks(webhooks)})
+	}
+}
+
+// @Summary		Create webhook
+// @description	create webhook receiving the updates of user websites, or only
+// @description	the websites of group_name if it is given. The secret signing the
+// @description	payloads is only returned in this response.
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			url			formData	string	true	"webhook url"
+// @Param			group_name	formData	string	false	"only send updates of websites in the group"
+// @Param			enabled		formData	bool	false	"enabled"
+// @Success		200			{object}	webhookResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/webhooks [post]
+func createWebhookHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		userUUID := req.Context().Value(ContextKeyUserUUID).(string)
+		params := req.Context().Value(ContextKeyHookInfo).(webhookReq)
+
+		webhook, err := createUserWebhook(req.Context(), r, userUUID, params)
+		if err != nil {
+			writeError(res, http.StatusInternalServerError, err)
+
+			return
+		}
+
+		resp := fromModelWebhook(webhook)
+		resp.Secret = webhook.Secret
+		encodeJsonResp(req.Context(), res, webhookResp{resp})
+	}
+}
+
+// @Summary		Update webhook
+// @description	update the given fields of webhook, enabling a disabled webhook resets its failure count
+// @Tags			web-history
+// @Accept			json
+// @Produce		json
+// @Security		UserToken
+// @Param			webhookUUID	path		string	true	"webhook uuid"
+// @Param			url			formData	string	false	"webhook url"
+// @Param			group_name	formData	string	false	"only send updates of websites in the group"
+// @Param			enabled		formData	bool	false	"enabled"
+// @Success		200			{object}	webhookResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/webhooks/{webhookUUID} [patch]
+func updateWebhookHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		webhook := req.Context().Value(ContextKeyWebhook).(model.Webhook)
+		params := req.Context().Value(ContextKeyHookInfo).(webhookReq)
+
+		webhook, err := updateUserWebhook(req.Context(), r, webhook, params)
+		if err != nil {
+			writeError(res, http.StatusInternalServerError, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, webhookResp{fromModelWebhook(webhook)})
+	}
+}
+
+// @Summary		Delete webhook
+// @description	delete webhook with its delivery logs
+// @Tags			web-history
+// @Produce		json
+// @Security		UserToken
+// @Param			webhookUUID	path		string	true	"webhook uuid"
+// @Success		200			{object}	deleteWebhookResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/webhooks/{webhookUUID} [delete]
+func deleteWebhookHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		webhook := req.Context().Value(ContextKeyWebhook).(model.Webhook)
+
+		err := r.DeleteWebhook(req.Context(), &webhook)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("delete webhook failed")
+			writeError(res, http.StatusInternalServerError, err)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, deleteWebhookResp{fmt.Sprintf("webhook <%v> deleted", webhook.URL)})
+	}
+}
+
+// @Summary		List webhook deliveries
+// @description	list the latest deliveries of webhook with their status
+// @Tags			web-history
+// @Produce		json
+// @Security		UserToken
+// @Param			webhookUUID	path		string	true	"webhook uuid"
+// @Success		200			{object}	listWebhookDeliveriesResp
+// @Failure		400			{object}	errResp
+// @Router			/api/web-watcher/websites/webhooks/{webhookUUID}/deliveries [get]
+func listWebhookDeliveriesHandler(r repository.Repository) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		webhook := req.Context().Value(ContextKeyWebhook).(model.Webhook)
+
+		deliveries, err := r.FindWebhookDeliveries(req.Context(), webhook.UUID, MaxWebhookDeliveries)
+		if err != nil {
+			zerolog.Ctx(req.Context()).Error().Err(err).Msg("find webhook deliveries failed")
+			writeError(res, http.StatusBadRequest, ErrRecordNotFound)
+
+			return
+		}
+
+		encodeJsonResp(req.Context(), res, listWebhookDeliveriesResp{fromModelWebhookDeliveries(deliveries)})
+	}
+}
@@ -0,0 +1,234 @@
+package website
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/WebHistory/internal/config"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_mergeWebsiteHandler(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.WebsiteConfig{Separator: "\n"}
+	fromWeb := model.Website{
+		UUID:       "from_uuid",
+		URL:        "http://example.com/old",
+		Title:      "title",
+		RawContent: "chapter 2",
+		UpdateTime: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+		Conf:       conf,
+	}
+	toWeb := model.Website{
+		UUID:       "to_uuid",
+		URL:        "http://example.com/new",
+		Title:      "title",
+		RawContent: "chapter 1",
+		UpdateTime: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		Conf:       conf,
+	}
+
+	createWebsite := func(target model.Website) func(context.Context, *model.Website) error {
+		return func(_ context.Context, web *model.Website) error {
+			*web = target
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		webUUID      string
+		url          string
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "merge website into target",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				from := fromWeb
+				rpo.EXPECT().FindWebsite(gomock.Any(), "from_uuid").Return(&from, nil)
+				rpo.EXPECT().CreateWebsite(gomock.Any(), gomock.Any()).DoAndReturn(createWebsite(toWeb))
+				rpo.EXPECT().MergeWebsite(gomock.Any(), &fromWeb, &toWeb).DoAndReturn(
+					func(_ context.Context, from, to *model.Website) error {
+						to.Merge(*from)
+						return nil
+					},
+				)
+
+				return rpo
+			},
+			webUUID:      "from_uuid",
+			url:          "http://example.com/new",
+			expectStatus: 200,
+			expectResp:   `{"from":{"uuid":"from_uuid","url":"http://example.com/old","title":"title","update_time":"2000-01-02T00:00:00Z"},"to":{"uuid":"to_uuid","url":"http://example.com/new","title":"title","update_time":"2000-01-02T00:00:00Z"}}`,
+		},
+		{
+			name: "return error if source website not found",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindWebsite(gomock.Any(), "unknown").Return(nil, errors.New("not found"))
+
+				return rpo
+			},
+			webUUID:      "unknown",
+			url:          "http://example.com/new",
+			expectStatus: 400,
+			expectResp:   `{"error":"record not found"}`,
+		},
+		{
+			name: "return error if create target website failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				from := fromWeb
+				rpo.EXPECT().FindWebsite(gomock.Any(), "from_uuid").Return(&from, nil)
+				rpo.EXPECT().CreateWebsite(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+				return rpo
+			},
+			webUUID:      "from_uuid",
+			url:          "http://example.com/new",
+			expectStatus: 400,
+			expectResp:   `{"error":"some error"}`,
+		},
+		{
+			name: "return error if merging website into itself",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				from := fromWeb
+				rpo.EXPECT().FindWebsite(gomock.Any(), "from_uuid").Return(&from, nil)
+				rpo.EXPECT().CreateWebsite(gomock.Any(), gomock.Any()).DoAndReturn(createWebsite(fromWeb))
+
+				return rpo
+			},
+			webUUID:      "from_uuid",
+			url:          "http://example.com/old",
+			expectStatus: 400,
+			expectResp:   `{"error":"invalid params"}`,
+		},
+		{
+			name: "return error if merge failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				from := fromWeb
+				rpo.EXPECT().FindWebsite(gomock.Any(), "from_uuid").Return(&from, nil)
+				rpo.EXPECT().CreateWebsite(gomock.Any(), gomock.Any()).DoAndReturn(createWebsite(toWeb))
+				rpo.EXPECT().MergeWebsite(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+				return rpo
+			},
+			webUUID:      "from_uuid",
+			url:          "http://example.com/new",
+			expectStatus: 500,
+			expectResp:   `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("POST", "/admin/websites/{webUUID}/merge", nil)
+			assert.NoError(t, err, "create request")
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("webUUID", test.webUUID)
+
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, ContextKeyWebURL, test.url)
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+			mergeWebsiteHandler(test.getRepo(ctrl), conf).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_listAdminAuditEventsHandler(t *testing.T) {
+	t.Parallel()
+
+	event := model.AuditEvent{
+		UUID:        "event_uuid",
+		UserUUID:    "user_uuid",
+		WebsiteUUID: "web_uuid",
+		Action:      model.AuditActionDelete,
+		Before:      `{"group_name":"group"}`,
+		RequestID:   "request_id",
+		CreatedAt:   time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		query        repository.AuditEventsQuery
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "filter audit events by user",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindAuditEvents(gomock.Any(), repository.AuditEventsQuery{
+					UserUUID: "user_uuid", Limit: 10,
+				}).Return([]model.AuditEvent{event}, nil)
+
+				return rpo
+			},
+			query:        repository.AuditEventsQuery{UserUUID: "user_uuid", Limit: 10},
+			expectStatus: 200,
+			expectResp:   `{"audit_events":[{"uuid":"event_uuid","user_uuid":"user_uuid","website_uuid":"web_uuid","action":"delete","before":{"group_name":"group"},"after":null,"request_id":"request_id","created_at":"2000-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name: "return error if find failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindAuditEvents(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error"))
+
+				return rpo
+			},
+			query:        repository.AuditEventsQuery{Limit: 10},
+			expectStatus: 400,
+			expectResp:   `{"error":"record not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", "/admin/audit-events", nil)
+			assert.NoError(t, err, "create request")
+
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyAudit, test.query))
+			rr := httptest.NewRecorder()
+			listAdminAuditEventsHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
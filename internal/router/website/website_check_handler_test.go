@@ -0,0 +1,257 @@
+package website
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
+	mockvendor "github.com/htchan/WebHistory/internal/mock/vendor"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/htchan/WebHistory/internal/repository/memory"
+	websiteupdate "github.com/htchan/WebHistory/internal/tasks/nats/website_update"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_checkWebsiteHandler(t *testing.T) {
+	nc, err := nats.Connect(connString)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		nc.Close()
+	})
+
+	conf := &config.WebsiteCheckConfig{Limit: 2, Window: time.Hour}
+	web := groupWebsite("web_1", "title 1", "group")
+
+	tests := []struct {
+		name         string
+		supported    bool
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   func(t *testing.T, resp string)
+		expectMsg    bool
+	}{
+		{
+			name:      "create and publish check job",
+			supported: true,
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().LockUserJobs(gomock.Any(), "user_uuid").Return(nil)
+				rpo.EXPECT().CountUserJobs(gomock.Any(), "user_uuid", model.JobKindCheck, gomock.Cond(func(since time.Time) bool {
+					return time.Since(since) >= time.Hour && time.Since(since) < time.Hour+time.Minute
+				})).Return(1, nil)
+				rpo.EXPECT().CreateJob(gomock.Any(), gomock.Cond(func(job *model.Job) bool {
+					return job.UserUUID == "user_uuid" && job.Kind == model.JobKindCheck && job.Status == model.JobStatusQueued
+				})).Return(nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusAccepted,
+			expectResp: func(t *testing.T, resp string) {
+				assert.Contains(t, resp, `"kind":"check","status":"queued"`)
+			},
+			expectMsg: true,
+		},
+		{
+			name:      "return error if website is not supported",
+			supported: false,
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				return mockrepo.NewMockRepository(c)
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp: func(t *testing.T, resp string) {
+				assert.Equal(t, `{"error":"unsupported website"}`, resp)
+			},
+		},
+		{
+			name:      "return error if user checked too many times",
+			supported: true,
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().LockUserJobs(gomock.Any(), "user_uuid").Return(nil)
+				rpo.EXPECT().CountUserJobs(gomock.Any(), "user_uuid", model.JobKindCheck, gomock.Any()).Return(2, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusTooManyRequests,
+			expectResp: func(t *testing.T, resp string) {
+				assert.Equal(t, `{"error":"too many checks"}`, resp)
+			},
+		},
+		{
+			name:      "return error if lock jobs failed",
+			supported: true,
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().LockUserJobs(gomock.Any(), "user_uuid").Return(errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusInternalServerError,
+			expectResp: func(t *testing.T, resp string) {
+				assert.Equal(t, `{"error":"some error"}`, resp)
+			},
+		},
+		{
+			name:      "return error if count jobs failed",
+			supported: true,
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().LockUserJobs(gomock.Any(), "user_uuid").Return(nil)
+				rpo.EXPECT().CountUserJobs(gomock.Any(), "user_uuid", model.JobKindCheck, gomock.Any()).Return(0, errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusInternalServerError,
+			expectResp: func(t *testing.T, resp string) {
+				assert.Equal(t, `{"error":"some error"}`, resp)
+			},
+		},
+		{
+			name:      "return error if create job failed",
+			supported: true,
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().LockUserJobs(gomock.Any(), "user_uuid").Return(nil)
+				rpo.EXPECT().CountUserJobs(gomock.Any(), "user_uuid", model.JobKindCheck, gomock.Any()).Return(0, nil)
+				rpo.EXPECT().CreateJob(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusInternalServerError,
+			expectResp: func(t *testing.T, resp string) {
+				assert.Equal(t, `{"error":"some error"}`, resp)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serv := mockvendor.NewMockVendorService(ctrl)
+			serv.EXPECT().Support(gomock.Any()).Return(test.supported).AnyTimes()
+			serv.EXPECT().Name().Return("check_web").AnyTimes()
+
+			received := make(chan *nats.Msg, 1)
+			sub, err := nc.ChanSubscribe("web_history.websites.update.check_web", received)
+			require.NoError(t, err)
+			defer sub.Unsubscribe()
+
+			req := httptest.NewRequest(http.MethodPost, "/websites/web_1/check", nil)
+			ctx := context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid")
+			ctx = context.WithValue(ctx, ContextKeyWebsite, web)
+			rr := httptest.NewRecorder()
+
+			tasks := websiteupdate.WebsiteUpdateTasks{websiteupdate.NewTask(nc, serv, nil, nil)}
+			checkWebsiteHandler(test.getRepo(ctrl), tasks, conf).ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			test.expectResp(t, strings.Trim(rr.Body.String(), "\n"))
+
+			if test.expectMsg {
+				select {
+				case msg := <-received:
+					assert.Contains(t, string(msg.Data), `"uuid":"web_1"`)
+					assert.Contains(t, string(msg.Data), `"user_uuid":"user_uuid"`)
+					assert.Contains(t, string(msg.Data), `"job_uuid":"`)
+				case <-time.After(2 * time.Second):
+					t.Fatal("timed out waiting for published message")
+				}
+			} else {
+				assert.Empty(t, received)
+			}
+		})
+	}
+}
+
+func Test_checkWebsiteHandler_publishFailed(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serv := mockvendor.NewMockVendorService(ctrl)
+	serv.EXPECT().Support(gomock.Any()).Return(true).AnyTimes()
+	serv.EXPECT().Name().Return("check_web_publish_failed").AnyTimes()
+
+	rpo := mockrepo.NewMockRepository(ctrl)
+	expectWithTx(rpo)
+	rpo.EXPECT().LockUserJobs(gomock.Any(), "user_uuid").Return(nil)
+	rpo.EXPECT().CountUserJobs(gomock.Any(), "user_uuid", model.JobKindCheck, gomock.Any()).Return(0, nil)
+	rpo.EXPECT().CreateJob(gomock.Any(), gomock.Any()).Return(nil)
+	rpo.EXPECT().UpdateJob(gomock.Any(), gomock.Cond(func(job *model.Job) bool {
+		return job.Kind == model.JobKindCheck && job.Status == model.JobStatusFailed
+	})).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/websites/web_1/check", nil)
+	ctx := context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid")
+	ctx = context.WithValue(ctx, ContextKeyWebsite, groupWebsite("web_1", "title 1", "group"))
+	rr := httptest.NewRecorder()
+
+	// publishing by nil connection always fails
+	tasks := websiteupdate.WebsiteUpdateTasks{websiteupdate.NewTask(nil, serv, nil, nil)}
+	checkWebsiteHandler(rpo, tasks, &config.WebsiteCheckConfig{Limit: 1, Window: time.Hour}).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, `{"error":"job not published"}`, strings.Trim(rr.Body.String(), "\n"))
+}
+
+func Test_startCheckJob_parallel(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serv := mockvendor.NewMockVendorService(ctrl)
+	serv.EXPECT().Support(gomock.Any()).Return(true).AnyTimes()
+	serv.EXPECT().Name().Return("check_web_parallel").AnyTimes()
+
+	rpo := memory.NewRepo(&config.WebsiteConfig{})
+	conf := &config.WebsiteCheckConfig{Limit: 3, Window: time.Hour}
+	// publishing by nil connection always fails, the failed job is still counted
+	tasks := websiteupdate.WebsiteUpdateTasks{websiteupdate.NewTask(nil, serv, nil, nil)}
+	web := groupWebsite("web_1", "title 1", "group")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Go(func() {
+			_, err := startCheckJob(context.Background(), rpo, tasks, conf, web)
+			errs <- err
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	throttled := 0
+	for err := range errs {
+		if errors.Is(err, ErrTooManyChecks) {
+			throttled++
+		} else {
+			assert.ErrorIs(t, err, ErrJobNotPublished)
+		}
+	}
+
+	assert.Equal(t, 7, throttled)
+
+	count, err := rpo.CountUserJobs(context.Background(), "user_uuid", model.JobKindCheck, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
@@ -0,0 +1,154 @@
+package website
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	websiteevents "github.com/htchan/WebHistory/internal/tasks/nats/website_events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEventsServer serves handler to user_uuid, the events stream needs real
+// connection to flush events before the handler returns
+func newEventsServer(t *testing.T, handler http.Handler, lastEventID uint64) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ctx := context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid")
+		ctx = context.WithValue(ctx, ContextKeyEventID, lastEventID)
+		handler.ServeHTTP(res, req.WithContext(ctx))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// readEvent reads lines of reader until the blank line ending an event
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		if line == "\n" {
+			return strings.Join(lines, "")
+		}
+
+		lines = append(lines, line)
+	}
+}
+
+func Test_websiteEventsHandler(t *testing.T) {
+	t.Parallel()
+
+	updateTime := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	t.Run("stream events of user", func(t *testing.T) {
+		t.Parallel()
+
+		broker := websiteevents.NewBroker(nil)
+		server := newEventsServer(t, websiteEventsHandler(broker), 0)
+
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+		reader := bufio.NewReader(resp.Body)
+		assert.Equal(t, "retry: 5000\n", readEvent(t, reader))
+
+		change := websiteevents.WebsiteChange{
+			WebsiteUUID: "web_uuid",
+			URL:         "http://example.com",
+			Title:       "title",
+			UpdateTime:  updateTime,
+		}
+		broker.Dispatch(websiteevents.Event{ID: 3, Change: withUsers(change, "other_user")})
+		broker.Dispatch(websiteevents.Event{ID: 4, Change: withUsers(change, "user_uuid")})
+		broker.Dispatch(websiteevents.Event{ID: 4, Change: withUsers(change, "user_uuid")})
+		broker.Dispatch(websiteevents.Event{ID: 5, Change: withUsers(change, "other_user", "user_uuid")})
+
+		for _, id := range []string{"4", "5"} {
+			assert.Equal(
+				t,
+				"id: "+id+"\nevent: website_updated\ndata: "+
+					`{"website":{"uuid":"web_uuid","url":"http://example.com","title":"title","update_time":"2000-01-02T00:00:00Z"}}`+"\n",
+				readEvent(t, reader),
+			)
+		}
+	})
+
+	t.Run("end stream once broker closed", func(t *testing.T) {
+		t.Parallel()
+
+		broker := websiteevents.NewBroker(nil)
+		server := newEventsServer(t, websiteEventsHandler(broker), 0)
+
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		reader := bufio.NewReader(resp.Body)
+		readEvent(t, reader)
+
+		broker.Close()
+
+		rest, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Empty(t, rest)
+	})
+
+	t.Run("return error if replay failed", func(t *testing.T) {
+		t.Parallel()
+
+		broker := websiteevents.NewBroker(nil)
+		server := newEventsServer(t, websiteEventsHandler(broker), 3)
+
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, `{"error":"website events broker not started"}`, strings.Trim(string(body), "\n"))
+	})
+}
+
+func Test_websiteEventsHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	t.Run("return error if replay failed", func(t *testing.T) {
+		t.Parallel()
+
+		broker := websiteevents.NewBroker(nil)
+		server := newEventsServer(t, websiteEventsHandlerV2(broker), 3)
+
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, `{"error":{"code":"internal_error","message":"internal error"}}`, strings.Trim(string(body), "\n"))
+	})
+}
+
+func withUsers(change websiteevents.WebsiteChange, userUUIDs ...string) websiteevents.WebsiteChange {
+	change.UserUUIDs = userUUIDs
+
+	return change
+}
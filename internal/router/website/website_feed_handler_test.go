@@ -0,0 +1,316 @@
+package website
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/WebHistory/internal/config"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func feedWebsite(uuid string, updateTime time.Time, lastRead string) model.UserWebsite {
+	web := groupWebsite(uuid, "title "+uuid, "group")
+	web.LastReadChapter = lastRead
+	web.Website.UpdateTime = updateTime
+	web.Website.RawContent = "chapter 3\nchapter 2\nchapter 1"
+	web.Website.Conf = &config.WebsiteConfig{Separator: "\n"}
+
+	return web
+}
+
+func Test_newAtomFeed(t *testing.T) {
+	t.Parallel()
+
+	older := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	t.Run("list websites from the latest update", func(t *testing.T) {
+		t.Parallel()
+
+		feed := newAtomFeed("user_uuid", "", "/feeds/token", []model.UserWebsite{
+			feedWebsite("web_1", older, ""),
+			feedWebsite("web_2", newer, "chapter 1"),
+		})
+
+		assert.Equal(t, "urn:web-history:feed:user_uuid", feed.ID)
+		assert.Equal(t, "Web History updates", feed.Title)
+		assert.Equal(t, "2000-01-02T00:00:00Z", feed.Updated)
+		assert.Equal(t, atomLink{Href: "/feeds/token", Rel: "self"}, feed.Link)
+		assert.Equal(t, []atomEntry{
+			{
+				ID:      fmt.Sprintf("urn:web-history:web_2:%d", newer.Unix()),
+				Title:   "title web_2",
+				Updated: "2000-01-02T00:00:00Z",
+				Link:    atomLink{Href: "http://example.com/web_2"},
+				Content: atomContent{Type: "text", Body: "chapter 3\nchapter 2"},
+			},
+			{
+				ID:      fmt.Sprintf("urn:web-history:web_1:%d", older.Unix()),
+				Title:   "title web_1",
+				Updated: "2000-01-01T00:00:00Z",
+				Link:    atomLink{Href: "http://example.com/web_1"},
+				Content: atomContent{Type: "text", Body: "chapter 3"},
+			},
+		}, feed.Entries)
+	})
+
+	t.Run("name feed by group", func(t *testing.T) {
+		t.Parallel()
+
+		feed := newAtomFeed("user_uuid", "my group", "/feeds/token?group=my+group", nil)
+
+		assert.Equal(t, "urn:web-history:feed:user_uuid:my%20group", feed.ID)
+		assert.Equal(t, "Web History updates - my group", feed.Title)
+		assert.Empty(t, feed.Entries)
+	})
+
+	t.Run("limit number of entries", func(t *testing.T) {
+		t.Parallel()
+
+		webs := make([]model.UserWebsite, 0, MaxFeedEntries+1)
+		for i := range MaxFeedEntries + 1 {
+			webs = append(webs, feedWebsite(fmt.Sprintf("web_%d", i), older.Add(time.Duration(i)*time.Hour), ""))
+		}
+
+		feed := newAtomFeed("user_uuid", "", "/feeds/token", webs)
+
+		assert.Len(t, feed.Entries, MaxFeedEntries)
+		assert.Equal(t, fmt.Sprintf("title web_%d", MaxFeedEntries), feed.Entries[0].Title)
+	})
+}
+
+func feedRequest(token, query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/feeds/"+token+query, nil)
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("feedToken", token)
+
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+}
+
+func Test_feedHandler(t *testing.T) {
+	t.Parallel()
+
+	updateTime := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+	token := &model.FeedToken{UserUUID: "user_uuid", Token: "token"}
+
+	tests := []struct {
+		name              string
+		getRepo           func(*gomock.Controller) repository.Repository
+		query             string
+		expectStatus      int
+		expectContentType string
+		expectResp        string
+	}{
+		{
+			name: "serve feed of all websites",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindFeedTokenByToken(gomock.Any(), "token").Return(token, nil)
+				rpo.EXPECT().FindUserWebsites(gomock.Any(), "user_uuid").Return(
+					model.UserWebsites{feedWebsite("web_1", updateTime, "chapter 2")}, nil,
+				)
+
+				return rpo
+			},
+			expectStatus:      http.StatusOK,
+			expectContentType: atomContentType,
+			expectResp: `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>urn:web-history:feed:user_uuid</id>
+  <title>Web History updates</title>
+  <updated>2000-01-02T00:00:00Z</updated>
+  <author>
+    <name>Web History</name>
+  </author>
+  <link href="/feeds/token" rel="self"></link>
+  <entry>
+    <id>urn:web-history:web_1:946771200</id>
+    <title>title web_1</title>
+    <updated>2000-01-02T00:00:00Z</updated>
+    <link href="http://example.com/web_1"></link>
+    <content type="text">chapter 3</content>
+  </entry>
+</feed>`,
+		},
+		{
+			name: "serve feed of group",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindFeedTokenByToken(gomock.Any(), "token").Return(token, nil)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(nil, nil)
+
+				return rpo
+			},
+			query:             "?group=group",
+			expectStatus:      http.StatusOK,
+			expectContentType: atomContentType,
+		},
+		{
+			name: "return not found for unknown token",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindFeedTokenByToken(gomock.Any(), "token").Return(nil, fmt.Errorf("find feed token fail: %w", sql.ErrNoRows))
+
+				return rpo
+			},
+			expectStatus: http.StatusNotFound,
+			expectResp:   `{"error":{"code":"not_found","message":"sql: no rows in result set"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rr := httptest.NewRecorder()
+			feedHandler(test.getRepo(ctrl)).ServeHTTP(rr, feedRequest("token", test.query))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			if test.expectContentType != "" {
+				assert.Equal(t, test.expectContentType, rr.Header().Get("Content-Type"))
+			}
+			if test.expectResp != "" {
+				assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+			}
+		})
+	}
+}
+
+func Test_getFeedTokenHandler(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   func(t *testing.T, resp string)
+	}{
+		{
+			name: "return existing token",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindFeedToken(gomock.Any(), "user_uuid").Return(
+					&model.FeedToken{UserUUID: "user_uuid", Token: "token", CreatedAt: createdAt}, nil,
+				)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp: func(t *testing.T, resp string) {
+				assert.Equal(t, `{"feed_token":{"token":"token","feed_path":"/api/web-watcher/feeds/token","created_at":"2000-01-01T00:00:00Z"}}`, resp)
+			},
+		},
+		{
+			name: "create token on first access",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindFeedToken(gomock.Any(), "user_uuid").Return(nil, fmt.Errorf("find feed token fail: %w", sql.ErrNoRows))
+				rpo.EXPECT().SaveFeedToken(gomock.Any(), gomock.Cond(func(token *model.FeedToken) bool {
+					return token.UserUUID == "user_uuid" && len(token.Token) == 48
+				})).Return(nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp: func(t *testing.T, resp string) {
+				assert.Contains(t, resp, `"feed_path":"/api/web-watcher/feeds/`)
+			},
+		},
+		{
+			name: "return error if find failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindFeedToken(gomock.Any(), "user_uuid").Return(nil, errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp: func(t *testing.T, resp string) {
+				assert.Equal(t, `{"error":"record not found"}`, resp)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodGet, "/websites/feed-token", nil)
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid"))
+			rr := httptest.NewRecorder()
+			getFeedTokenHandler(test.getRepo(ctrl), "/api/web-watcher").ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			test.expectResp(t, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_rotateFeedTokenHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+	}{
+		{
+			name: "replace token",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().SaveFeedToken(gomock.Any(), gomock.Cond(func(token *model.FeedToken) bool {
+					return token.UserUUID == "user_uuid" && token.Token != ""
+				})).Return(nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name: "return error if save failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().SaveFeedToken(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodPut, "/v2/websites/feed-token", nil)
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid"))
+			rr := httptest.NewRecorder()
+			rotateFeedTokenHandlerV2(test.getRepo(ctrl), "/api/web-watcher").ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,572 @@
+package website
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/WebHistory/internal/config"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func groupWebsite(uuid, title, group string) model.UserWebsite {
+	return model.UserWebsite{
+		WebsiteUUID: uuid,
+		UserUUID:    "user_uuid",
+		GroupName:   group,
+		AccessTime:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		Website: model.Website{
+			UUID:       uuid,
+			Title:      title,
+			URL:        "http://example.com/" + uuid,
+			UpdateTime: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func groupRequest(method, groupName string) *http.Request {
+	req := httptest.NewRequest(method, "/websites/groups/"+groupName, nil)
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("groupName", groupName)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	ctx = context.WithValue(ctx, ContextKeyUserUUID, "user_uuid")
+
+	return req.WithContext(ctx)
+}
+
+func Test_listGroupsHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "list groups in order of name",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserWebsites(gomock.Any(), "user_uuid").Return(model.UserWebsites{
+					groupWebsite("web_1", "title 1", "novel"),
+					groupWebsite("web_2", "title 2", "comic"),
+					groupWebsite("web_3", "title 3", "novel"),
+				}, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"groups":[{"group_name":"comic","website_count":1,"unread_count":0},{"group_name":"novel","website_count":2,"unread_count":0}]}`,
+		},
+		{
+			name: "return empty list for user without website",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserWebsites(gomock.Any(), "user_uuid").Return(model.UserWebsites{}, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"groups":[]}`,
+		},
+		{
+			name: "return error if find failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserWebsites(gomock.Any(), "user_uuid").Return(nil, errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"record not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodGet, "/websites/group-counts", nil)
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid"))
+			rr := httptest.NewRecorder()
+			listGroupsHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_websiteSummaryHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "summarize unread websites",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserUnreadSummary(gomock.Any(), "user_uuid", SummaryLatestUnread).Return(model.NewUnreadSummary(
+					[]model.GroupUnreadCount{{GroupName: "novel", UnreadCount: 2}, {GroupName: "comic", UnreadCount: 1}},
+					[]model.UnreadWebsite{{
+						WebsiteUUID: "web_1",
+						GroupName:   "novel",
+						Title:       "title 1",
+						UpdateTime:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+					}},
+				), nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"unread_count":3,"groups":[{"group_name":"novel","unread_count":2},{"group_name":"comic","unread_count":1}],"latest_unread":[{"uuid":"web_1","group_name":"novel","title":"title 1","update_time":"2000-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name: "return empty summary for user without unread website",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserUnreadSummary(gomock.Any(), "user_uuid", SummaryLatestUnread).Return(model.UnreadSummary{}, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"unread_count":0,"groups":[],"latest_unread":[]}`,
+		},
+		{
+			name: "return error if find failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserUnreadSummary(gomock.Any(), "user_uuid", SummaryLatestUnread).Return(model.UnreadSummary{}, errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"record not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodGet, "/websites/summary", nil)
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid"))
+			rr := httptest.NewRecorder()
+			websiteSummaryHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_renameGroupHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		conf         *config.WebsiteConfig
+		group        string
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "rename group",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{
+					groupWebsite("web_1", "title 1", "group"),
+					groupWebsite("web_2", "title 2", "group"),
+				}, nil)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "new group").Return(model.WebsiteGroup{}, nil)
+				rpo.EXPECT().RenameUserGroup(gomock.Any(), "user_uuid", "group", "new group").Return(nil)
+				expectAuditEvent(rpo, model.AuditActionChangeGroup, "user_uuid", "web_1")
+				expectAuditEvent(rpo, model.AuditActionChangeGroup, "user_uuid", "web_2")
+
+				return rpo
+			},
+			conf:         &config.WebsiteConfig{},
+			group:        "new group",
+			expectStatus: http.StatusOK,
+			expectResp:   `{"website_group":[{"uuid":"web_1","user_uuid":"user_uuid","url":"http://example.com/web_1","title":"title 1","group_name":"new group","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"},{"uuid":"web_2","user_uuid":"user_uuid","url":"http://example.com/web_2","title":"title 2","group_name":"new group","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name: "return error if new group exists",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{
+					groupWebsite("web_1", "title 1", "group"),
+				}, nil)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "other").Return(model.WebsiteGroup{
+					groupWebsite("web_2", "title 2", "other"),
+				}, nil)
+
+				return rpo
+			},
+			conf:         &config.WebsiteConfig{},
+			group:        "other",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"group already exists"}`,
+		},
+		{
+			name: "return error if group not exist",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{}, nil)
+
+				return rpo
+			},
+			conf:         &config.WebsiteConfig{},
+			group:        "new group",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"record not found"}`,
+		},
+		{
+			name: "return error if website title not sharing character in series grouping mode",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{
+					groupWebsite("web_1", "abc", "group"),
+				}, nil)
+
+				return rpo
+			},
+			conf:         &config.WebsiteConfig{SeriesGrouping: true},
+			group:        "xyz",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"invalid group name"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := groupRequest(http.MethodPut, "group")
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyGroup, test.group))
+			rr := httptest.NewRecorder()
+			renameGroupHandler(test.getRepo(ctrl), test.conf).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_mergeGroupHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		group        string
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "merge group into existing group",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{
+					groupWebsite("web_1", "title 1", "group"),
+				}, nil)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "other").Return(model.WebsiteGroup{
+					groupWebsite("web_2", "title 2", "other"),
+				}, nil)
+				rpo.EXPECT().RenameUserGroup(gomock.Any(), "user_uuid", "group", "other").Return(nil)
+				expectAuditEvent(rpo, model.AuditActionChangeGroup, "user_uuid", "web_1")
+
+				return rpo
+			},
+			group:        "other",
+			expectStatus: http.StatusOK,
+			expectResp:   `{"website_group":[{"uuid":"web_2","user_uuid":"user_uuid","url":"http://example.com/web_2","title":"title 2","group_name":"other","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"},{"uuid":"web_1","user_uuid":"user_uuid","url":"http://example.com/web_1","title":"title 1","group_name":"other","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name: "return error if target group not exist",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{
+					groupWebsite("web_1", "title 1", "group"),
+				}, nil)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "other").Return(model.WebsiteGroup{}, nil)
+
+				return rpo
+			},
+			group:        "other",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"record not found"}`,
+		},
+		{
+			name: "return error if merge into itself",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{
+					groupWebsite("web_1", "title 1", "group"),
+				}, nil)
+
+				return rpo
+			},
+			group:        "group",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"invalid group name"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := groupRequest(http.MethodPut, "group")
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyGroup, test.group))
+			rr := httptest.NewRecorder()
+			mergeGroupHandler(test.getRepo(ctrl), &config.WebsiteConfig{}).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_deleteGroupHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		mode         string
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "unsubscribe websites of group",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{
+					groupWebsite("web_1", "title 1", "group"),
+					groupWebsite("web_2", "title 2", "group"),
+				}, nil)
+				rpo.EXPECT().DeleteUserGroup(gomock.Any(), "user_uuid", "group").Return(nil)
+				expectAuditEvent(rpo, model.AuditActionDelete, "user_uuid", "web_1")
+				expectAuditEvent(rpo, model.AuditActionDelete, "user_uuid", "web_2")
+
+				return rpo
+			},
+			mode:         GroupDeleteModeUnsubscribe,
+			expectStatus: http.StatusOK,
+			expectResp:   `{"message":"group \u003cgroup\u003e deleted"}`,
+		},
+		{
+			name: "ungroup websites of group",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{
+					groupWebsite("web_1", "title 1", "group"),
+				}, nil)
+				rpo.EXPECT().UngroupUserGroup(gomock.Any(), "user_uuid", "group").Return(nil)
+				rpo.EXPECT().CreateAuditEvent(gomock.Any(), gomock.Cond(func(event *model.AuditEvent) bool {
+					return event.Action == model.AuditActionChangeGroup && event.WebsiteUUID == "web_1" &&
+						strings.Contains(event.After, `"group_name":"title 1"`)
+				})).Return(nil)
+
+				return rpo
+			},
+			mode:         GroupDeleteModeUngroup,
+			expectStatus: http.StatusOK,
+			expectResp:   `{"message":"group \u003cgroup\u003e deleted"}`,
+		},
+		{
+			name: "return error if delete failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{
+					groupWebsite("web_1", "title 1", "group"),
+				}, nil)
+				rpo.EXPECT().DeleteUserGroup(gomock.Any(), "user_uuid", "group").Return(errors.New("some error"))
+
+				return rpo
+			},
+			mode:         GroupDeleteModeUnsubscribe,
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := groupRequest(http.MethodDelete, "group")
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyMode, test.mode))
+			rr := httptest.NewRecorder()
+			deleteGroupHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_refreshGroupHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "refresh websites of group",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().RefreshUserWebsites(gomock.Any(), "user_uuid", "group", gomock.Cond(func(accessTime time.Time) bool {
+					return accessTime.Equal(accessTime.Truncate(5*time.Second)) && time.Since(accessTime) < time.Minute
+				})).Return(2, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"updated":2}`,
+		},
+		{
+			name: "return error if group not exist",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().RefreshUserWebsites(gomock.Any(), "user_uuid", "group", gomock.Any()).Return(0, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"record not found"}`,
+		},
+		{
+			name: "return error if refresh failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().RefreshUserWebsites(gomock.Any(), "user_uuid", "group", gomock.Any()).Return(0, errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := groupRequest(http.MethodPut, "group")
+			rr := httptest.NewRecorder()
+			refreshGroupHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_refreshAllWebsitesHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "refresh all websites of user",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().RefreshUserWebsites(gomock.Any(), "user_uuid", "", gomock.Any()).Return(3, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"updated":3}`,
+		},
+		{
+			name: "return zero if user has no websites",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().RefreshUserWebsites(gomock.Any(), "user_uuid", "", gomock.Any()).Return(0, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"updated":0}`,
+		},
+		{
+			name: "return error if refresh failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().RefreshUserWebsites(gomock.Any(), "user_uuid", "", gomock.Any()).Return(0, errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodPut, "/websites/refresh", nil)
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid"))
+			rr := httptest.NewRecorder()
+			refreshAllWebsitesHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
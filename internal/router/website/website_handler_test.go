@@ -26,11 +26,28 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
+// expectWithTx runs the transaction callback against the same mock repo
+func expectWithTx(rpo *mockrepo.MockRepository) {
+	rpo.EXPECT().WithTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, fn func(repository.Repository) error) error {
+			return fn(rpo)
+		},
+	)
+}
+
+// expectAuditEvent expects the audit event of action on user website to be recorded
+func expectAuditEvent(rpo *mockrepo.MockRepository, action, userUUID, websiteUUID string) {
+	rpo.EXPECT().CreateAuditEvent(gomock.Any(), gomock.Cond(func(event *model.AuditEvent) bool {
+		return event.Action == action && event.UserUUID == userUUID && event.WebsiteUUID == websiteUUID
+	})).Return(nil)
+}
+
 func Test_getAllWebsiteGroupsHandler(t *testing.T) {
 	tests := []struct {
 		name         string
 		getRepo      func(*gomock.Controller) repository.Repository
 		userUUID     string
+		page         *repository.UserWebsitesQuery
 		expectStatus int
 		expectRes    string
 	}{
@@ -80,7 +97,7 @@ func Test_getAllWebsiteGroupsHandler(t *testing.T) {
 			},
 			userUUID:     "abc",
 			expectStatus: 200,
-			expectRes:    `{"website_groups":[[{"uuid":"1","user_uuid":"abc","url":"","title":"title 1","group_name":"group 1","update_time":"2000-01-01T01:00:00Z","access_time":"2000-01-01T00:00:00Z"},{"uuid":"2","user_uuid":"abc","url":"","title":"title 2","group_name":"group 1","update_time":"2000-01-02T01:00:00Z","access_time":"2000-01-02T00:00:00Z"}],[{"uuid":"3","user_uuid":"abc","url":"","title":"title 3","group_name":"group 3","update_time":"2000-01-03T01:00:00Z","access_time":"2000-01-03T00:00:00Z"}]]}`,
+			expectRes:    `{"website_groups":[[{"uuid":"1","user_uuid":"abc","url":"","title":"title 1","group_name":"group 1","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T01:00:00Z","access_time":"2000-01-01T00:00:00Z"},{"uuid":"2","user_uuid":"abc","url":"","title":"title 2","group_name":"group 1","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-02T01:00:00Z","access_time":"2000-01-02T00:00:00Z"}],[{"uuid":"3","user_uuid":"abc","url":"","title":"title 3","group_name":"group 3","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-03T01:00:00Z","access_time":"2000-01-03T00:00:00Z"}]]}`,
 		},
 		{
 			name: "return error if findUserWebsites return error",
@@ -96,6 +113,65 @@ func Test_getAllWebsiteGroupsHandler(t *testing.T) {
 			expectStatus: 400,
 			expectRes:    `{"error":"record not found"}`,
 		},
+		{
+			name: "get a page of user websites with next cursor",
+			getRepo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindUserWebsitesPage(gomock.Any(), repository.UserWebsitesQuery{
+					UserUUID: "abc", SortBy: "title", Limit: 1,
+				}).Return(
+					model.UserWebsites{
+						{
+							UserUUID:    "abc",
+							WebsiteUUID: "1",
+							GroupName:   "group 1",
+							AccessTime:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+							Website: model.Website{
+								UUID:       "1",
+								Title:      "title 1",
+								UpdateTime: time.Date(2000, 1, 1, 1, 0, 0, 0, time.UTC),
+							},
+						},
+					}, "next-cursor", nil,
+				)
+
+				return rpo
+			},
+			userUUID:     "abc",
+			page:         &repository.UserWebsitesQuery{SortBy: "title", Limit: 1},
+			expectStatus: 200,
+			expectRes:    `{"website_groups":[[{"uuid":"1","user_uuid":"abc","url":"","title":"title 1","group_name":"group 1","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T01:00:00Z","access_time":"2000-01-01T00:00:00Z"}]],"next_cursor":"next-cursor"}`,
+		},
+		{
+			name: "get the last page of user websites",
+			getRepo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindUserWebsitesPage(gomock.Any(), repository.UserWebsitesQuery{
+					UserUUID: "abc", Cursor: "cursor", Limit: 1,
+				}).Return(model.UserWebsites{}, "", nil)
+
+				return rpo
+			},
+			userUUID:     "abc",
+			page:         &repository.UserWebsitesQuery{Cursor: "cursor", Limit: 1},
+			expectStatus: 200,
+			expectRes:    `{"website_groups":[]}`,
+		},
+		{
+			name: "return invalid params if cursor is invalid",
+			getRepo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindUserWebsitesPage(gomock.Any(), repository.UserWebsitesQuery{
+					UserUUID: "abc", Cursor: "invalid", Limit: 1,
+				}).Return(nil, "", fmt.Errorf("list user websites page fail: %w", repository.ErrInvalidCursor))
+
+				return rpo
+			},
+			userUUID:     "abc",
+			page:         &repository.UserWebsitesQuery{Cursor: "invalid", Limit: 1},
+			expectStatus: 400,
+			expectRes:    `{"error":"invalid params"}`,
+		},
 	}
 
 	for _, test := range tests {
@@ -109,6 +185,9 @@ func Test_getAllWebsiteGroupsHandler(t *testing.T) {
 
 			ctx := req.Context()
 			ctx = context.WithValue(ctx, ContextKeyUserUUID, test.userUUID)
+			if test.page != nil {
+				ctx = context.WithValue(ctx, ContextKeyPage, *test.page)
+			}
 			req = req.WithContext(ctx)
 			rr := httptest.NewRecorder()
 			getAllWebsiteGroupsHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
@@ -126,6 +205,7 @@ func Test_getWebsiteGroupHandler(t *testing.T) {
 		r            func(*gomock.Controller) repository.Repository
 		userUUID     string
 		group        string
+		page         *repository.UserWebsitesQuery
 		expectStatus int
 		expectRes    string
 	}{
@@ -165,7 +245,7 @@ func Test_getWebsiteGroupHandler(t *testing.T) {
 			userUUID:     "abc",
 			group:        "group 1",
 			expectStatus: 200,
-			expectRes:    `{"website_group":[{"uuid":"1","user_uuid":"abc","url":"","title":"title 1","group_name":"group 1","update_time":"2000-01-01T01:00:00Z","access_time":"2000-01-01T00:00:00Z"},{"uuid":"2","user_uuid":"abc","url":"","title":"title 2","group_name":"group 1","update_time":"2000-01-02T01:00:00Z","access_time":"2000-01-02T00:00:00Z"}]}`,
+			expectRes:    `{"website_group":[{"uuid":"1","user_uuid":"abc","url":"","title":"title 1","group_name":"group 1","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T01:00:00Z","access_time":"2000-01-01T00:00:00Z"},{"uuid":"2","user_uuid":"abc","url":"","title":"title 2","group_name":"group 1","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-02T01:00:00Z","access_time":"2000-01-02T00:00:00Z"}]}`,
 		},
 		{
 			name: "return error if user not exist",
@@ -197,6 +277,52 @@ func Test_getWebsiteGroupHandler(t *testing.T) {
 			expectStatus: 400,
 			expectRes:    `{"error":"record not found"}`,
 		},
+		{
+			name: "get a page of user websites of group",
+			r: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserWebsitesPage(gomock.Any(), repository.UserWebsitesQuery{
+					UserUUID: "abc", GroupName: "group 1", Limit: 1,
+				}).Return(
+					model.UserWebsites{
+						{
+							UserUUID:    "abc",
+							WebsiteUUID: "1",
+							GroupName:   "group 1",
+							AccessTime:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+							Website: model.Website{
+								UUID:       "1",
+								Title:      "title 1",
+								UpdateTime: time.Date(2000, 1, 1, 1, 0, 0, 0, time.UTC),
+							},
+						},
+					}, "next-cursor", nil,
+				)
+
+				return rpo
+			},
+			userUUID:     "abc",
+			group:        "group 1",
+			page:         &repository.UserWebsitesQuery{Limit: 1},
+			expectStatus: 200,
+			expectRes:    `{"website_group":[{"uuid":"1","user_uuid":"abc","url":"","title":"title 1","group_name":"group 1","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T01:00:00Z","access_time":"2000-01-01T00:00:00Z"}],"next_cursor":"next-cursor"}`,
+		},
+		{
+			name: "return error if first page of group is empty",
+			r: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserWebsitesPage(gomock.Any(), repository.UserWebsitesQuery{
+					UserUUID: "abc", GroupName: "group not exist", Limit: 1,
+				}).Return(model.UserWebsites{}, "", nil)
+
+				return rpo
+			},
+			userUUID:     "abc",
+			group:        "group not exist",
+			page:         &repository.UserWebsitesQuery{Limit: 1},
+			expectStatus: 400,
+			expectRes:    `{"error":"record not found"}`,
+		},
 	}
 
 	for _, test := range tests {
@@ -214,6 +340,9 @@ func Test_getWebsiteGroupHandler(t *testing.T) {
 			rctx.URLParams.Add("groupName", test.group)
 			ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
 			ctx = context.WithValue(ctx, ContextKeyUserUUID, test.userUUID)
+			if test.page != nil {
+				ctx = context.WithValue(ctx, ContextKeyPage, *test.page)
+			}
 			req = req.WithContext(ctx)
 			rr := httptest.NewRecorder()
 			getWebsiteGroupHandler(test.r(ctrl)).ServeHTTP(rr, req)
@@ -224,6 +353,95 @@ func Test_getWebsiteGroupHandler(t *testing.T) {
 	}
 }
 
+func Test_searchWebsitesHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		r            func(*gomock.Controller) repository.Repository
+		userUUID     string
+		params       searchReq
+		expectStatus int
+		expectRes    string
+	}{
+		{
+			name: "search user websites",
+			r: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().SearchUserWebsites(gomock.Any(), "abc", "title", 20).Return(
+					model.UserWebsites{
+						{
+							UserUUID:    "abc",
+							WebsiteUUID: "1",
+							GroupName:   "group 1",
+							AccessTime:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+							Website: model.Website{
+								UUID:       "1",
+								Title:      "title 1",
+								UpdateTime: time.Date(2000, 1, 1, 1, 0, 0, 0, time.UTC),
+							},
+						},
+					}, nil,
+				)
+
+				return rpo
+			},
+			userUUID:     "abc",
+			params:       searchReq{Query: "title", Limit: 20},
+			expectStatus: 200,
+			expectRes:    `{"websites":[{"uuid":"1","user_uuid":"abc","url":"","title":"title 1","group_name":"group 1","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T01:00:00Z","access_time":"2000-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name: "search without result",
+			r: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().SearchUserWebsites(gomock.Any(), "abc", "unknown", 20).Return(model.UserWebsites{}, nil)
+
+				return rpo
+			},
+			userUUID:     "abc",
+			params:       searchReq{Query: "unknown", Limit: 20},
+			expectStatus: 200,
+			expectRes:    `{"websites":[]}`,
+		},
+		{
+			name: "return error if search fail",
+			r: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().SearchUserWebsites(gomock.Any(), "abc", "title", 20).Return(nil, errors.New("some error"))
+
+				return rpo
+			},
+			userUUID:     "abc",
+			params:       searchReq{Query: "title", Limit: 20},
+			expectStatus: 400,
+			expectRes:    `{"error":"record not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", "/websites/search", nil)
+			assert.NoError(t, err, "create request")
+
+			ctx := req.Context()
+			ctx = context.WithValue(ctx, ContextKeyUserUUID, test.userUUID)
+			ctx = context.WithValue(ctx, ContextKeySearch, test.params)
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+			searchWebsitesHandler(test.r(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectRes, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
 func Test_createWebsiteHandler(t *testing.T) {
 	nc, err := nats.Connect(connString)
 	assert.NoError(t, err)
@@ -233,8 +451,11 @@ func Test_createWebsiteHandler(t *testing.T) {
 
 	uuid.SetClockSequence(1)
 	uuid.SetRand(io.NopCloser(bytes.NewReader([]byte(
-		"000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		strings.Repeat("0", 160),
 	))))
+	t.Cleanup(func() {
+		uuid.SetRand(nil)
+	})
 	tests := []struct {
 		name            string
 		conf            *config.WebsiteConfig
@@ -250,6 +471,7 @@ func Test_createWebsiteHandler(t *testing.T) {
 			name: "happy flow/within 24 hrs",
 			mockRepo: func(ctrl *gomock.Controller) repository.Repository {
 				rpo := mockrepo.NewMockRepository(ctrl)
+				expectWithTx(rpo)
 				rpo.EXPECT().CreateWebsite(gomock.Any(),
 					&model.Website{
 						UUID:       "30303030-3030-4030-b030-303030303030",
@@ -272,6 +494,7 @@ func Test_createWebsiteHandler(t *testing.T) {
 						},
 					},
 				).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionCreate, "abc", "30303030-3030-4030-b030-303030303030")
 
 				return rpo
 			},
@@ -293,6 +516,7 @@ func Test_createWebsiteHandler(t *testing.T) {
 			name: "happy flow/more than 24 hrs",
 			mockRepo: func(ctrl *gomock.Controller) repository.Repository {
 				rpo := mockrepo.NewMockRepository(ctrl)
+				expectWithTx(rpo)
 				rpo.EXPECT().CreateWebsite(gomock.Any(),
 					&model.Website{
 						UUID:       "30303030-3030-4030-b030-303030303030",
@@ -318,6 +542,7 @@ func Test_createWebsiteHandler(t *testing.T) {
 						},
 					},
 				).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionCreate, "abc", "30303030-3030-4030-b030-303030303030")
 
 				return rpo
 			},
@@ -357,6 +582,7 @@ func Test_createWebsiteHandler(t *testing.T) {
 			name: "error/not supported web",
 			mockRepo: func(ctrl *gomock.Controller) repository.Repository {
 				rpo := mockrepo.NewMockRepository(ctrl)
+				expectWithTx(rpo)
 				rpo.EXPECT().CreateWebsite(gomock.Any(),
 					&model.Website{
 						UUID:       "30303030-3030-4030-b030-303030303030",
@@ -382,6 +608,7 @@ func Test_createWebsiteHandler(t *testing.T) {
 						},
 					},
 				).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionCreate, "abc", "30303030-3030-4030-b030-303030303030")
 
 				return rpo
 			},
@@ -421,6 +648,7 @@ func Test_createWebsiteHandler(t *testing.T) {
 			name: "error/repo return error",
 			mockRepo: func(ctrl *gomock.Controller) repository.Repository {
 				rpo := mockrepo.NewMockRepository(ctrl)
+				expectWithTx(rpo)
 				rpo.EXPECT().CreateWebsite(gomock.Any(),
 					&model.Website{
 						UUID:       "30303030-3030-4030-b030-303030303030",
@@ -499,7 +727,25 @@ func Test_getWebsiteHandler(t *testing.T) {
 				},
 			},
 			expectStatus: 200,
-			expectRes:    `{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"title","group_name":"name","update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}}`,
+			expectRes:    `{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"title","group_name":"name","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}}`,
+		},
+		{
+			name: "return gone website with flag",
+			web: model.UserWebsite{
+				WebsiteUUID: "web_uuid",
+				UserUUID:    "user_uuid",
+				GroupName:   "name",
+				AccessTime:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+				Website: model.Website{
+					UUID:       "web_uuid",
+					Title:      "title",
+					URL:        "http://example.com/",
+					UpdateTime: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+					Status:     model.WebsiteStatusGone,
+				},
+			},
+			expectStatus: 200,
+			expectRes:    `{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"title","group_name":"name","note":"","last_read_chapter":"","unread_count":0,"gone":true,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}}`,
 		},
 	}
 
@@ -535,6 +781,7 @@ func Test_refreshWebsiteHandler(t *testing.T) {
 			name: "return website with updated AccessTime",
 			mockRepo: func(ctrl *gomock.Controller) repository.Repository {
 				rpo := mockrepo.NewMockRepository(ctrl)
+				expectWithTx(rpo)
 				rpo.EXPECT().UpdateUserWebsite(gomock.Any(),
 					&model.UserWebsite{
 						WebsiteUUID: "web_uuid",
@@ -549,6 +796,7 @@ func Test_refreshWebsiteHandler(t *testing.T) {
 						},
 					},
 				).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionRefresh, "user_uuid", "web_uuid")
 
 				return rpo
 			},
@@ -565,7 +813,7 @@ func Test_refreshWebsiteHandler(t *testing.T) {
 				},
 			},
 			expectStatus: 200,
-			expectResp:   fmt.Sprintf(`{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"title","group_name":"name","update_time":"2000-01-01T00:00:00Z","access_time":"%s"}}`, time.Now().UTC().Truncate(5*time.Second).Format("2006-01-02T15:04:05Z07:00")),
+			expectResp:   fmt.Sprintf(`{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"title","group_name":"name","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"%s"}}`, time.Now().UTC().Truncate(5*time.Second).Format("2006-01-02T15:04:05Z07:00")),
 		},
 	}
 
@@ -603,6 +851,7 @@ func Test_deleteWebsiteHandler(t *testing.T) {
 			name: "return website of deleted content",
 			mockRepo: func(ctrl *gomock.Controller) repository.Repository {
 				rpo := mockrepo.NewMockRepository(ctrl)
+				expectWithTx(rpo)
 				rpo.EXPECT().DeleteUserWebsite(gomock.Any(),
 					&model.UserWebsite{
 						WebsiteUUID: "web_uuid",
@@ -617,6 +866,7 @@ func Test_deleteWebsiteHandler(t *testing.T) {
 						},
 					},
 				).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionDelete, "user_uuid", "web_uuid")
 
 				return rpo
 			},
@@ -664,6 +914,7 @@ func Test_changeWebsiteGroupHandler(t *testing.T) {
 	tests := []struct {
 		name         string
 		getRepo      func(*gomock.Controller) repository.Repository
+		conf         *config.WebsiteConfig
 		web          model.UserWebsite
 		group        string
 		expectStatus int
@@ -673,6 +924,7 @@ func Test_changeWebsiteGroupHandler(t *testing.T) {
 			name: "return website of deleted content",
 			getRepo: func(c *gomock.Controller) repository.Repository {
 				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
 				rpo.EXPECT().UpdateUserWebsite(gomock.Any(),
 					&model.UserWebsite{
 						WebsiteUUID: "web_uuid",
@@ -687,9 +939,11 @@ func Test_changeWebsiteGroupHandler(t *testing.T) {
 						},
 					},
 				).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionChangeGroup, "user_uuid", "web_uuid")
 
 				return rpo
 			},
+			conf: &config.WebsiteConfig{},
 			web: model.UserWebsite{
 				WebsiteUUID: "web_uuid",
 				UserUUID:    "user_uuid",
@@ -704,7 +958,39 @@ func Test_changeWebsiteGroupHandler(t *testing.T) {
 			},
 			group:        "group_name",
 			expectStatus: 200,
-			expectResp:   `{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"title","group_name":"group_name","update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}}`,
+			expectResp:   `{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"title","group_name":"group_name","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}}`,
+		},
+		{
+			name: "return error for group not sharing character with title in series grouping mode",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				return mockrepo.NewMockRepository(c)
+			},
+			conf: &config.WebsiteConfig{SeriesGrouping: true},
+			web: model.UserWebsite{
+				WebsiteUUID: "web_uuid",
+				UserUUID:    "user_uuid",
+				GroupName:   "name",
+				Website:     model.Website{UUID: "web_uuid", Title: "title"},
+			},
+			group:        "xyz",
+			expectStatus: 400,
+			expectResp:   `{"error":"invalid group name"}`,
+		},
+		{
+			name: "return error for empty group",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				return mockrepo.NewMockRepository(c)
+			},
+			conf: &config.WebsiteConfig{},
+			web: model.UserWebsite{
+				WebsiteUUID: "web_uuid",
+				UserUUID:    "user_uuid",
+				GroupName:   "name",
+				Website:     model.Website{UUID: "web_uuid", Title: "title"},
+			},
+			group:        "",
+			expectStatus: 400,
+			expectResp:   `{"error":"invalid group name"}`,
 		},
 	}
 
@@ -723,7 +1009,320 @@ func Test_changeWebsiteGroupHandler(t *testing.T) {
 			ctx = context.WithValue(ctx, ContextKeyGroup, test.group)
 			req = req.WithContext(ctx)
 			rr := httptest.NewRecorder()
-			changeWebsiteGroupHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+			changeWebsiteGroupHandler(test.getRepo(ctrl), test.conf).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_updateUserWebsiteHandler(t *testing.T) {
+	t.Parallel()
+
+	title, emptyTitle, note := "custom title", "", "some note"
+	web := model.UserWebsite{
+		WebsiteUUID: "web_uuid",
+		UserUUID:    "user_uuid",
+		GroupName:   "title",
+		AccessTime:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		Website: model.Website{
+			UUID:       "web_uuid",
+			Title:      "title",
+			URL:        "http://example.com/",
+			UpdateTime: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		web          model.UserWebsite
+		params       updateUserWebsiteReq
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "update title of website in default group",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWeb := web
+				expectWeb.DisplayTitle = title
+				expectWeb.GroupName = title
+				expectWithTx(rpo)
+				rpo.EXPECT().UpdateUserWebsite(gomock.Any(), &expectWeb).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionUpdate, "user_uuid", "web_uuid")
+
+				return rpo
+			},
+			web:          web,
+			params:       updateUserWebsiteReq{Title: &title},
+			expectStatus: 200,
+			expectResp:   `{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"custom title","group_name":"custom title","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}}`,
+		},
+		{
+			name: "update title of website in custom group",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWeb := web
+				expectWeb.DisplayTitle = title
+				expectWeb.GroupName = "group"
+				expectWithTx(rpo)
+				rpo.EXPECT().UpdateUserWebsite(gomock.Any(), &expectWeb).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionUpdate, "user_uuid", "web_uuid")
+
+				return rpo
+			},
+			web: func() model.UserWebsite {
+				w := web
+				w.GroupName = "group"
+				return w
+			}(),
+			params:       updateUserWebsiteReq{Title: &title},
+			expectStatus: 200,
+			expectResp:   `{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"custom title","group_name":"group","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}}`,
+		},
+		{
+			name: "reset title and update note",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWeb := web
+				expectWeb.Note = note
+				expectWithTx(rpo)
+				rpo.EXPECT().UpdateUserWebsite(gomock.Any(), &expectWeb).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionUpdate, "user_uuid", "web_uuid")
+
+				return rpo
+			},
+			web: func() model.UserWebsite {
+				w := web
+				w.DisplayTitle = title
+				w.GroupName = title
+				return w
+			}(),
+			params:       updateUserWebsiteReq{Title: &emptyTitle, Note: &note},
+			expectStatus: 200,
+			expectResp:   `{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"title","group_name":"title","note":"some note","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}}`,
+		},
+		{
+			name: "return error if update failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().UpdateUserWebsite(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+				return rpo
+			},
+			web:          web,
+			params:       updateUserWebsiteReq{Note: &note},
+			expectStatus: 500,
+			expectResp:   `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("PATCH", "/websites/{webUUID}", nil)
+			assert.NoError(t, err, "create request")
+
+			ctx := req.Context()
+			ctx = context.WithValue(ctx, ContextKeyWebsite, test.web)
+			ctx = context.WithValue(ctx, ContextKeyWebInfo, test.params)
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+			updateUserWebsiteHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_updateReadProgressHandler(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.WebsiteConfig{Separator: "\n"}
+	web := model.UserWebsite{
+		WebsiteUUID: "web_uuid",
+		UserUUID:    "user_uuid",
+		GroupName:   "title",
+		AccessTime:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		Website: model.Website{
+			UUID:       "web_uuid",
+			Title:      "title",
+			URL:        "http://example.com/",
+			RawContent: "chapter 3\nchapter 2\nchapter 1",
+			UpdateTime: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			Conf:       conf,
+		},
+	}
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		chapter      string
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "record listed chapter",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWeb := web
+				expectWeb.LastReadChapter = "chapter 2"
+				expectWithTx(rpo)
+				rpo.EXPECT().UpdateUserWebsite(gomock.Any(), &expectWeb).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionReadProgress, "user_uuid", "web_uuid")
+
+				return rpo
+			},
+			chapter:      "chapter 2",
+			expectStatus: 200,
+			expectResp:   `{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"title","group_name":"title","note":"","last_read_chapter":"chapter 2","unread_count":1,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}}`,
+		},
+		{
+			name: "clear read progress",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().UpdateUserWebsite(gomock.Any(), &web).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionReadProgress, "user_uuid", "web_uuid")
+
+				return rpo
+			},
+			chapter:      "",
+			expectStatus: 200,
+			expectResp:   `{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"title","group_name":"title","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}}`,
+		},
+		{
+			name: "return error for not listed chapter",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				return mockrepo.NewMockRepository(c)
+			},
+			chapter:      "chapter 4",
+			expectStatus: 400,
+			expectResp:   `{"error":"invalid chapter"}`,
+		},
+		{
+			name: "return error if update failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().UpdateUserWebsite(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+				return rpo
+			},
+			chapter:      "chapter 1",
+			expectStatus: 500,
+			expectResp:   `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("PUT", "/websites/{webUUID}/read-progress", nil)
+			assert.NoError(t, err, "create request")
+
+			ctx := req.Context()
+			ctx = context.WithValue(ctx, ContextKeyWebsite, web)
+			ctx = context.WithValue(ctx, ContextKeyChapter, test.chapter)
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+			updateReadProgressHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_listAuditEventsHandler(t *testing.T) {
+	t.Parallel()
+
+	event := model.AuditEvent{
+		UUID:        "event_uuid",
+		UserUUID:    "user_uuid",
+		WebsiteUUID: "web_uuid",
+		Action:      model.AuditActionChangeGroup,
+		Before:      `{"group_name":"old"}`,
+		After:       `{"group_name":"new"}`,
+		RequestID:   "request_id",
+		CreatedAt:   time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		query        repository.AuditEventsQuery
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "list audit events of user only",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindAuditEvents(gomock.Any(), repository.AuditEventsQuery{
+					UserUUID: "user_uuid", WebsiteUUID: "web_uuid", Limit: 10,
+				}).Return([]model.AuditEvent{event}, nil)
+
+				return rpo
+			},
+			query:        repository.AuditEventsQuery{UserUUID: "other_user_uuid", WebsiteUUID: "web_uuid", Limit: 10},
+			expectStatus: 200,
+			expectResp:   `{"audit_events":[{"uuid":"event_uuid","user_uuid":"user_uuid","website_uuid":"web_uuid","action":"change_group","before":{"group_name":"old"},"after":{"group_name":"new"},"request_id":"request_id","created_at":"2000-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name: "return empty list",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindAuditEvents(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+				return rpo
+			},
+			query:        repository.AuditEventsQuery{Limit: 10},
+			expectStatus: 200,
+			expectResp:   `{"audit_events":[]}`,
+		},
+		{
+			name: "return error if find failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindAuditEvents(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error"))
+
+				return rpo
+			},
+			query:        repository.AuditEventsQuery{Limit: 10},
+			expectStatus: 400,
+			expectResp:   `{"error":"record not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", "/websites/audit-events", nil)
+			assert.NoError(t, err, "create request")
+
+			ctx := req.Context()
+			ctx = context.WithValue(ctx, ContextKeyUserUUID, "user_uuid")
+			ctx = context.WithValue(ctx, ContextKeyAudit, test.query)
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+			listAuditEventsHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
 
 			assert.Equal(t, test.expectStatus, rr.Code)
 			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
@@ -0,0 +1,535 @@
+package website
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/htchan/WebHistory/internal/config"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
+	mockvendor "github.com/htchan/WebHistory/internal/mock/vendor"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	websiteupdate "github.com/htchan/WebHistory/internal/tasks/nats/website_update"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_toAPIError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		err    error
+		expect APIError
+	}{
+		{
+			name:   "api error",
+			err:    fmt.Errorf("wrapped: %w", invalidParamsError(map[string]string{"url": "required"})),
+			expect: APIError{Status: 400, Code: ErrCodeInvalidParams, Message: "invalid params", Details: map[string]string{"url": "required"}},
+		},
+		{
+			name:   "invalid cursor",
+			err:    fmt.Errorf("list user websites page fail: %w", repository.ErrInvalidCursor),
+			expect: APIError{Status: 400, Code: ErrCodeInvalidParams, Message: "invalid cursor"},
+		},
+		{
+			name:   "unauthorized",
+			err:    ErrUnauthorized,
+			expect: APIError{Status: 401, Code: ErrCodeUnauthorized, Message: "unauthorized"},
+		},
+		{
+			name:   "record not found",
+			err:    fmt.Errorf("get user website fail: %w", sql.ErrNoRows),
+			expect: APIError{Status: 404, Code: ErrCodeNotFound, Message: sql.ErrNoRows.Error()},
+		},
+		{
+			name:   "unsupported website",
+			err:    websiteupdate.ErrNotSupportedWebsite,
+			expect: APIError{Status: 422, Code: ErrCodeUnsupportedWebsite, Message: "website is not supported"},
+		},
+		{
+			name:   "unknown error is hidden",
+			err:    errors.New("connection refused"),
+			expect: APIError{Status: 500, Code: ErrCodeInternal, Message: "internal error"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, toAPIError(test.err))
+		})
+	}
+}
+
+func Test_writeAPIError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/websites/groups", nil)
+	reqID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	req = req.WithContext(context.WithValue(req.Context(), ContextKeyReqID, reqID))
+	rr := httptest.NewRecorder()
+
+	writeAPIError(rr, req, invalidParamsError(map[string]string{"limit": "must be between 1 and 200"}))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t,
+		`{"error":{"code":"invalid_params","message":"invalid params","details":{"limit":"must be between 1 and 200"},"request_id":"00000000-0000-0000-0000-000000000001"}}`,
+		strings.Trim(rr.Body.String(), "\n"),
+	)
+}
+
+func Test_JSONBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		body         string
+		expectBody   *createWebsiteReq
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name:         "decode and trim body",
+			body:         `{"url":" https://example.com/ "}`,
+			expectBody:   &createWebsiteReq{Url: "https://example.com/"},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "invalid json",
+			body:         `url=https://example.com/`,
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":{"code":"invalid_params","message":"invalid params","details":{"body":"must be a json object"}}}`,
+		},
+		{
+			name:         "invalid field",
+			body:         `{"url":"example.com"}`,
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":{"code":"invalid_params","message":"invalid params","details":{"url":"must be a http url"}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/v2/websites", strings.NewReader(test.body))
+			rr := httptest.NewRecorder()
+
+			var body *createWebsiteReq
+			JSONBody(validateCreateWebsiteReq)(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				if params, ok := req.Context().Value(ContextKeyBody).(createWebsiteReq); ok {
+					body = &params
+				}
+			})).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectBody, body)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_createWebsiteHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	// website updated recently is not published for update
+	updateTime := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		getTasks     func(*gomock.Controller) websiteupdate.WebsiteUpdateTasks
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "return created user website",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().CreateWebsite(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, web *model.Website) error {
+						web.UUID, web.Title = "web_uuid", "title"
+						return nil
+					},
+				)
+				rpo.EXPECT().CreateUserWebsite(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, web *model.UserWebsite) error {
+						web.AccessTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+						web.Website.UpdateTime = updateTime
+						return nil
+					},
+				)
+				expectAuditEvent(rpo, model.AuditActionCreate, "user_uuid", "web_uuid")
+
+				return rpo
+			},
+			getTasks:     func(c *gomock.Controller) websiteupdate.WebsiteUpdateTasks { return nil },
+			expectStatus: http.StatusCreated,
+			expectResp: fmt.Sprintf(
+				`{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"https://example.com/","title":"title","group_name":"title","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"%s","access_time":"2000-01-01T00:00:00Z"}}`,
+				updateTime.Format(time.RFC3339),
+			),
+		},
+		{
+			name: "return unprocessable entity for unsupported website",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().CreateWebsite(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, web *model.Website) error {
+						web.UUID = "web_uuid"
+						web.UpdateTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+						return nil
+					},
+				)
+				rpo.EXPECT().CreateUserWebsite(gomock.Any(), gomock.Any()).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionCreate, "user_uuid", "web_uuid")
+
+				return rpo
+			},
+			getTasks: func(c *gomock.Controller) websiteupdate.WebsiteUpdateTasks {
+				serv := mockvendor.NewMockVendorService(c)
+				serv.EXPECT().Support(gomock.Any()).Return(false)
+
+				return websiteupdate.WebsiteUpdateTasks{websiteupdate.NewTask(nil, serv, nil, nil)}
+			},
+			expectStatus: http.StatusUnprocessableEntity,
+			expectResp:   `{"error":{"code":"unsupported_website","message":"website is not supported"}}`,
+		},
+		{
+			name: "hide internal error",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().CreateWebsite(gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+
+				return rpo
+			},
+			getTasks:     func(c *gomock.Controller) websiteupdate.WebsiteUpdateTasks { return nil },
+			expectStatus: http.StatusInternalServerError,
+			expectResp:   `{"error":{"code":"internal_error","message":"internal error"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodPost, "/v2/websites", nil)
+			ctx := context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid")
+			ctx = context.WithValue(ctx, ContextKeyBody, createWebsiteReq{Url: "https://example.com/"})
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+			createWebsiteHandlerV2(test.getRepo(ctrl), &config.WebsiteConfig{}, test.getTasks(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_getWebsiteGroupHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "return not found for empty group",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{}, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusNotFound,
+			expectResp:   `{"error":{"code":"not_found","message":"record not found"}}`,
+		},
+		{
+			name: "return internal error if find failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(nil, errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusInternalServerError,
+			expectResp:   `{"error":{"code":"internal_error","message":"internal error"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodGet, "/v2/websites/groups/group", nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("groupName", "group")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+			ctx = context.WithValue(ctx, ContextKeyUserUUID, "user_uuid")
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+			getWebsiteGroupHandlerV2(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_changeWebsiteGroupHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	web := model.UserWebsite{
+		WebsiteUUID: "web_uuid",
+		UserUUID:    "user_uuid",
+		GroupName:   "title",
+		AccessTime:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		Website: model.Website{
+			UUID:       "web_uuid",
+			Title:      "title",
+			URL:        "http://example.com/",
+			UpdateTime: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		group        string
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "change group",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWeb := web
+				expectWeb.GroupName = "tit"
+				expectWithTx(rpo)
+				rpo.EXPECT().UpdateUserWebsite(gomock.Any(), &expectWeb).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionChangeGroup, "user_uuid", "web_uuid")
+
+				return rpo
+			},
+			group:        "tit",
+			expectStatus: http.StatusOK,
+			expectResp:   `{"website":{"uuid":"web_uuid","user_uuid":"user_uuid","url":"http://example.com/","title":"title","group_name":"tit","note":"","last_read_chapter":"","unread_count":0,"gone":false,"update_time":"2000-01-01T00:00:00Z","access_time":"2000-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:         "return invalid params for invalid group name",
+			getRepo:      func(c *gomock.Controller) repository.Repository { return mockrepo.NewMockRepository(c) },
+			group:        "xyz",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":{"code":"invalid_params","message":"invalid params","details":{"group_name":"must share a character with website title"}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodPut, "/v2/websites/web_uuid/change-group", nil)
+			ctx := context.WithValue(req.Context(), ContextKeyWebsite, web)
+			ctx = context.WithValue(ctx, ContextKeyBody, groupNameReq{GroupName: test.group})
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+			changeWebsiteGroupHandlerV2(test.getRepo(ctrl), &config.WebsiteConfig{SeriesGrouping: true}).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_deleteWebsiteHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	web := model.UserWebsite{WebsiteUUID: "web_uuid", UserUUID: "user_uuid"}
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "return no content",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().DeleteUserWebsite(gomock.Any(), &web).Return(nil)
+				expectAuditEvent(rpo, model.AuditActionDelete, "user_uuid", "web_uuid")
+
+				return rpo
+			},
+			expectStatus: http.StatusNoContent,
+			expectResp:   "",
+		},
+		{
+			name: "return internal error if delete failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().DeleteUserWebsite(gomock.Any(), &web).Return(errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusInternalServerError,
+			expectResp:   `{"error":{"code":"internal_error","message":"internal error"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodDelete, "/v2/websites/web_uuid", nil)
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyWebsite, web))
+			rr := httptest.NewRecorder()
+			deleteWebsiteHandlerV2(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_queryUserWebsiteV2(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpo := mockrepo.NewMockRepository(ctrl)
+	rpo.EXPECT().FindUserWebsite(gomock.Any(), "user_uuid", "web_uuid").Return(nil, fmt.Errorf("get user website fail: %w", sql.ErrNoRows))
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/websites/web_uuid", nil)
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("webUUID", "web_uuid")
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	ctx = context.WithValue(ctx, ContextKeyUserUUID, "user_uuid")
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	queryUserWebsite(rpo, writeErrorV2)(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		t.Error("next handler should not be called")
+	})).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, `{"error":{"code":"not_found","message":"sql: no rows in result set"}}`, strings.Trim(rr.Body.String(), "\n"))
+}
+
+func Test_renameGroupHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpo := mockrepo.NewMockRepository(ctrl)
+	expectWithTx(rpo)
+	rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "group").Return(model.WebsiteGroup{
+		groupWebsite("web_1", "title 1", "group"),
+	}, nil)
+	rpo.EXPECT().FindUserWebsitesByGroup(gomock.Any(), "user_uuid", "other").Return(model.WebsiteGroup{
+		groupWebsite("web_2", "title 2", "other"),
+	}, nil)
+
+	req := groupRequest(http.MethodPut, "group")
+	req = req.WithContext(context.WithValue(req.Context(), ContextKeyBody, groupNameReq{GroupName: "other"}))
+	rr := httptest.NewRecorder()
+	renameGroupHandlerV2(rpo, &config.WebsiteConfig{}).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, `{"error":{"code":"conflict","message":"group already exists"}}`, strings.Trim(rr.Body.String(), "\n"))
+}
+
+func Test_refreshGroupHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpo := mockrepo.NewMockRepository(ctrl)
+	rpo.EXPECT().RefreshUserWebsites(gomock.Any(), "user_uuid", "group", gomock.Any()).Return(0, nil)
+
+	req := groupRequest(http.MethodPut, "group")
+	rr := httptest.NewRecorder()
+	refreshGroupHandlerV2(rpo).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, `{"error":{"code":"not_found","message":"record not found"}}`, strings.Trim(rr.Body.String(), "\n"))
+}
+
+func Test_checkWebsiteHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serv := mockvendor.NewMockVendorService(ctrl)
+	serv.EXPECT().Support(gomock.Any()).Return(true).AnyTimes()
+	serv.EXPECT().Name().Return("check_web_v2").AnyTimes()
+
+	rpo := mockrepo.NewMockRepository(ctrl)
+	expectWithTx(rpo)
+	rpo.EXPECT().LockUserJobs(gomock.Any(), "user_uuid").Return(nil)
+	rpo.EXPECT().CountUserJobs(gomock.Any(), "user_uuid", model.JobKindCheck, gomock.Any()).Return(1, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/websites/web_1/check", nil)
+	ctx := context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid")
+	ctx = context.WithValue(ctx, ContextKeyWebsite, groupWebsite("web_1", "title 1", "group"))
+	rr := httptest.NewRecorder()
+
+	tasks := websiteupdate.WebsiteUpdateTasks{websiteupdate.NewTask(nil, serv, nil, nil)}
+	checkWebsiteHandlerV2(rpo, tasks, &config.WebsiteCheckConfig{Limit: 1, Window: time.Hour}).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, `{"error":{"code":"too_many_requests","message":"too many checks"}}`, strings.Trim(rr.Body.String(), "\n"))
+}
+
+func Test_renameTagHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpo := mockrepo.NewMockRepository(ctrl)
+	expectWithTx(rpo)
+	rpo.EXPECT().FindUserTags(gomock.Any(), "user_uuid").Return([]model.Tag{{Name: "other", WebsiteCount: 1}}, nil)
+
+	req := tagRequest(http.MethodPut, "/websites/tags/tag/rename", "tag")
+	req = req.WithContext(context.WithValue(req.Context(), ContextKeyBody, tagReq{Tag: "new tag"}))
+	rr := httptest.NewRecorder()
+	renameTagHandlerV2(rpo).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, `{"error":{"code":"not_found","message":"record not found"}}`, strings.Trim(rr.Body.String(), "\n"))
+}
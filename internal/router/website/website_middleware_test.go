@@ -1,16 +1,188 @@
 package website
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/WebHistory/internal/config"
+	mockuserservice "github.com/htchan/WebHistory/internal/mock/userservice"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/htchan/WebHistory/internal/userservice"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
 
 func Test_writeError(t *testing.T) {
 
 }
 
 func Test_redirectLogin(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/websites", nil)
+	res := httptest.NewRecorder()
 
+	redirectLogin(res, req, &config.UserServiceConfig{LoginURL: "http://login/", ServiceUUID: "service uuid"})
+
+	assert.Equal(t, http.StatusFound, res.Code)
+	assert.Equal(t, "http://login/?service=service+uuid", res.Header().Get("Location"))
 }
 
 func Test_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	userUUID := "e3fb5a59-9b06-4c0b-9b2b-4b1f6e4bd6f0"
+	conf := &config.UserServiceConfig{CookieName: "token", LoginURL: "http://login/", ServiceUUID: "service"}
+
+	tests := []struct {
+		name           string
+		conf           *config.UserServiceConfig
+		method         string
+		headers        map[string]string
+		cookie         *http.Cookie
+		getAuth        func(*gomock.Controller) userservice.Authenticator
+		expectStatus   int
+		expectUserUUID string
+		expectLocation string
+	}{
+		{
+			name:    "bearer token",
+			conf:    conf,
+			method:  http.MethodGet,
+			headers: map[string]string{"Authorization": "Bearer user-token"},
+			getAuth: func(c *gomock.Controller) userservice.Authenticator {
+				auth := mockuserservice.NewMockAuthenticator(c)
+				auth.EXPECT().Authenticate(gomock.Any(), "user-token").Return(userUUID, nil)
+				return auth
+			},
+			expectStatus:   http.StatusOK,
+			expectUserUUID: userUUID,
+		},
+		{
+			name:   "session cookie",
+			conf:   conf,
+			method: http.MethodGet,
+			cookie: &http.Cookie{Name: "token", Value: "cookie-token"},
+			getAuth: func(c *gomock.Controller) userservice.Authenticator {
+				auth := mockuserservice.NewMockAuthenticator(c)
+				auth.EXPECT().Authenticate(gomock.Any(), "cookie-token").Return(userUUID, nil)
+				return auth
+			},
+			expectStatus:   http.StatusOK,
+			expectUserUUID: userUUID,
+		},
+		{
+			name:    "user uuid header is not trusted",
+			conf:    conf,
+			method:  http.MethodGet,
+			headers: map[string]string{"X-USER-UUID": userUUID},
+			getAuth: func(c *gomock.Controller) userservice.Authenticator {
+				auth := mockuserservice.NewMockAuthenticator(c)
+				auth.EXPECT().Authenticate(gomock.Any(), "").Return("", userservice.ErrUnauthorized)
+				return auth
+			},
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "non bearer authorization",
+			conf:    conf,
+			method:  http.MethodGet,
+			headers: map[string]string{"Authorization": "Basic user-token"},
+			cookie:  &http.Cookie{Name: "token", Value: "cookie-token"},
+			getAuth: func(c *gomock.Controller) userservice.Authenticator {
+				auth := mockuserservice.NewMockAuthenticator(c)
+				auth.EXPECT().Authenticate(gomock.Any(), "").Return("", userservice.ErrUnauthorized)
+				return auth
+			},
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "redirect browser to login",
+			conf:    conf,
+			method:  http.MethodGet,
+			headers: map[string]string{"Accept": "text/html,application/xhtml+xml"},
+			getAuth: func(c *gomock.Controller) userservice.Authenticator {
+				auth := mockuserservice.NewMockAuthenticator(c)
+				auth.EXPECT().Authenticate(gomock.Any(), "").Return("", userservice.ErrUnauthorized)
+				return auth
+			},
+			expectStatus:   http.StatusFound,
+			expectLocation: "http://login/?service=service",
+		},
+		{
+			name:    "not redirect browser if login url is not configured",
+			conf:    &config.UserServiceConfig{CookieName: "token"},
+			method:  http.MethodGet,
+			headers: map[string]string{"Accept": "text/html"},
+			getAuth: func(c *gomock.Controller) userservice.Authenticator {
+				auth := mockuserservice.NewMockAuthenticator(c)
+				auth.EXPECT().Authenticate(gomock.Any(), "").Return("", userservice.ErrUnauthorized)
+				return auth
+			},
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "user service unavailable",
+			conf:    conf,
+			method:  http.MethodGet,
+			headers: map[string]string{"Authorization": "Bearer user-token", "Accept": "text/html"},
+			getAuth: func(c *gomock.Controller) userservice.Authenticator {
+				auth := mockuserservice.NewMockAuthenticator(c)
+				auth.EXPECT().Authenticate(gomock.Any(), "user-token").Return("", fmt.Errorf("%w: timeout", userservice.ErrUnavailable))
+				return auth
+			},
+			expectStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:   "skip options request",
+			conf:   conf,
+			method: http.MethodOptions,
+			getAuth: func(c *gomock.Controller) userservice.Authenticator {
+				return mockuserservice.NewMockAuthenticator(c)
+			},
+			expectStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			req := httptest.NewRequest(test.method, "/websites", nil)
+			for key, value := range test.headers {
+				req.Header.Set(key, value)
+			}
+
+			if test.cookie != nil {
+				req.AddCookie(test.cookie)
+			}
+
+			res := httptest.NewRecorder()
+
+			var gotUserUUID string
+			handler := AuthenticateMiddleware(test.getAuth(ctrl), test.conf)(
+				http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+					gotUserUUID, _ = req.Context().Value(ContextKeyUserUUID).(string)
+				}),
+			)
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, test.expectStatus, res.Code)
+			assert.Equal(t, test.expectUserUUID, gotUserUUID)
+			assert.Equal(t, test.expectLocation, res.Header().Get("Location"))
+		})
+	}
+}
+
+func Test_AdminAuthenticate(t *testing.T) {
 
 }
 
@@ -31,5 +203,365 @@ func Test_GroupNameParams(t *testing.T) {
 }
 
 func Test_validGroupName(t *testing.T) {
+	t.Parallel()
+
+	web := model.UserWebsite{Website: model.Website{Title: "title"}}
+
+	tests := []struct {
+		name      string
+		conf      *config.WebsiteConfig
+		groupName string
+		expect    bool
+	}{
+		{name: "any group name", conf: &config.WebsiteConfig{}, groupName: "xyz", expect: true},
+		{name: "empty group name", conf: &config.WebsiteConfig{}, groupName: "", expect: false},
+		{name: "series grouping with shared character", conf: &config.WebsiteConfig{SeriesGrouping: true}, groupName: "tx", expect: true},
+		{name: "series grouping without shared character", conf: &config.WebsiteConfig{SeriesGrouping: true}, groupName: "xyz", expect: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, validGroupName(test.conf, web, test.groupName))
+		})
+	}
+}
+
+func Test_PaginationParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		query        string
+		expectPage   *repository.UserWebsitesQuery
+		expectStatus int
+	}{
+		{
+			name:         "no pagination params",
+			query:        "",
+			expectPage:   nil,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "default limit",
+			query:        "?sort=title",
+			expectPage:   &repository.UserWebsitesQuery{SortBy: "title", Limit: DefaultPageLimit},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "all params",
+			query:        "?sort=access_time&cursor=abc&limit=10",
+			expectPage:   &repository.UserWebsitesQuery{SortBy: "access_time", Cursor: "abc", Limit: 10},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "invalid limit",
+			query:        "?limit=abc",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "limit exceed maximum",
+			query:        fmt.Sprintf("?limit=%d", MaxPageLimit+1),
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid sort key",
+			query:        "?sort=unknown",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "filter by tags",
+			query:        "?tag=novel&tag=%20weekly%20",
+			expectPage:   &repository.UserWebsitesQuery{Tags: []string{"novel", "weekly"}, Limit: DefaultPageLimit},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "empty tag",
+			query:        "?tag=%20",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "tag exceed maximum length",
+			query:        "?tag=" + strings.Repeat("a", MaxTagLength+1),
+			expectStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/websites/groups/"+test.query, nil)
+			rr := httptest.NewRecorder()
+
+			var page *repository.UserWebsitesQuery
+			PaginationParams(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				if query, ok := req.Context().Value(ContextKeyPage).(repository.UserWebsitesQuery); ok {
+					page = &query
+				}
+			})).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectPage, page)
+		})
+	}
+}
+
+func Test_SearchParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		query        string
+		expectParams *searchReq
+		expectStatus int
+	}{
+		{
+			name:         "query with default limit",
+			query:        "?q=+one+piece+",
+			expectParams: &searchReq{Query: "one piece", Limit: DefaultSearchLimit},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "query with limit",
+			query:        "?q=one&limit=5",
+			expectParams: &searchReq{Query: "one", Limit: 5},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "missing query",
+			query:        "?q=+",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid limit",
+			query:        fmt.Sprintf("?q=one&limit=%d", MaxSearchLimit+1),
+			expectStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/websites/search"+test.query, nil)
+			rr := httptest.NewRecorder()
+
+			var params *searchReq
+			SearchParams(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				if search, ok := req.Context().Value(ContextKeySearch).(searchReq); ok {
+					params = &search
+				}
+			})).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectParams, params)
+		})
+	}
+}
+
+func Test_AuditEventsParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		query        string
+		expectParams *repository.AuditEventsQuery
+		expectStatus int
+	}{
+		{
+			name:         "default limit",
+			query:        "",
+			expectParams: &repository.AuditEventsQuery{Limit: DefaultPageLimit},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:  "filter by user and website",
+			query: "?user_uuid=user_uuid&website_uuid=web_uuid&limit=5",
+			expectParams: &repository.AuditEventsQuery{
+				UserUUID:    "user_uuid",
+				WebsiteUUID: "web_uuid",
+				Limit:       5,
+			},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "invalid limit",
+			query:        fmt.Sprintf("?limit=%d", MaxPageLimit+1),
+			expectStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/websites/audit-events"+test.query, nil)
+			rr := httptest.NewRecorder()
+
+			var params *repository.AuditEventsQuery
+			AuditEventsParams(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				if query, ok := req.Context().Value(ContextKeyAudit).(repository.AuditEventsQuery); ok {
+					params = &query
+				}
+			})).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectParams, params)
+		})
+	}
+}
+
+func Test_GroupDeleteModeParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		query        string
+		expectMode   string
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name:         "unsubscribe",
+			query:        "?mode=unsubscribe",
+			expectMode:   GroupDeleteModeUnsubscribe,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "ungroup",
+			query:        "?mode=ungroup",
+			expectMode:   GroupDeleteModeUngroup,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "missing mode",
+			query:        "",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"invalid params"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodDelete, "/websites/groups/group"+test.query, nil)
+			rr := httptest.NewRecorder()
+
+			var mode string
+			GroupDeleteModeParams(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				mode, _ = req.Context().Value(ContextKeyMode).(string)
+			})).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectMode, mode)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_TagParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		tag          string
+		expectTag    string
+		expectStatus int
+	}{
+		{
+			name:         "trim tag",
+			tag:          " novel ",
+			expectTag:    "novel",
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "tag of maximum length",
+			tag:          strings.Repeat("字", MaxTagLength),
+			expectTag:    strings.Repeat("字", MaxTagLength),
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "empty tag",
+			tag:          " ",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "tag exceed maximum length",
+			tag:          strings.Repeat("a", MaxTagLength+1),
+			expectStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodDelete, "/websites/tags/tag", nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("tag", test.tag)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+			rr := httptest.NewRecorder()
+
+			var tag string
+			TagParams(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				tag = req.Context().Value(ContextKeyTag).(string)
+			})).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectTag, tag)
+		})
+	}
+}
+
+func Test_LastEventIDParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		lastEventID  string
+		expectID     uint64
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name:         "parse event id",
+			lastEventID:  "12",
+			expectID:     12,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "no event received",
+			lastEventID:  "",
+			expectID:     0,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "invalid event id",
+			lastEventID:  "abc",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"invalid params"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/websites/events", nil)
+			req.Header.Set("Last-Event-ID", test.lastEventID)
+			rr := httptest.NewRecorder()
+
+			var id uint64
+			LastEventIDParams(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				id = req.Context().Value(ContextKeyEventID).(uint64)
+			})).ServeHTTP(rr, req)
 
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectID, id)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
 }
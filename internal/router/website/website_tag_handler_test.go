@@ -0,0 +1,307 @@
+package website
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func tagRequest(method, path, tag string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("tag", tag)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	ctx = context.WithValue(ctx, ContextKeyUserUUID, "user_uuid")
+	ctx = context.WithValue(ctx, ContextKeyTag, tag)
+	ctx = context.WithValue(ctx, ContextKeyWebsite, groupWebsite("web_1", "title 1", "group"))
+
+	return req.WithContext(ctx)
+}
+
+func Test_listTagsHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "list tags",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserTags(gomock.Any(), "user_uuid").Return([]model.Tag{
+					{Name: "favourite", WebsiteCount: 1},
+					{Name: "weekly", WebsiteCount: 2},
+				}, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"tags":[{"name":"favourite","website_count":1},{"name":"weekly","website_count":2}]}`,
+		},
+		{
+			name: "return empty list for user without tag",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserTags(gomock.Any(), "user_uuid").Return(nil, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"tags":[]}`,
+		},
+		{
+			name: "return error if find failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserTags(gomock.Any(), "user_uuid").Return(nil, errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"record not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodGet, "/websites/tags", nil)
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid"))
+			rr := httptest.NewRecorder()
+			listTagsHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_renameTagHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		newTag       string
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "rename tag",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserTags(gomock.Any(), "user_uuid").Return([]model.Tag{{Name: "tag", WebsiteCount: 2}}, nil)
+				rpo.EXPECT().RenameUserTag(gomock.Any(), "user_uuid", "tag", "new tag").Return(nil)
+				rpo.EXPECT().FindUserTags(gomock.Any(), "user_uuid").Return([]model.Tag{{Name: "new tag", WebsiteCount: 2}}, nil)
+
+				return rpo
+			},
+			newTag:       "new tag",
+			expectStatus: http.StatusOK,
+			expectResp:   `{"tags":[{"name":"new tag","website_count":2}]}`,
+		},
+		{
+			name: "return error if tag not exist",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserTags(gomock.Any(), "user_uuid").Return([]model.Tag{{Name: "other", WebsiteCount: 1}}, nil)
+
+				return rpo
+			},
+			newTag:       "new tag",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"record not found"}`,
+		},
+		{
+			name: "return error if new tag is the renamed tag",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserTags(gomock.Any(), "user_uuid").Return([]model.Tag{{Name: "tag", WebsiteCount: 1}}, nil)
+
+				return rpo
+			},
+			newTag:       "tag",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"invalid params"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := tagRequest(http.MethodPut, "/websites/tags/tag/rename", "tag")
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyNewTag, test.newTag))
+			rr := httptest.NewRecorder()
+			renameTagHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_deleteTagHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "delete tag",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserTags(gomock.Any(), "user_uuid").Return([]model.Tag{{Name: "tag", WebsiteCount: 1}}, nil)
+				rpo.EXPECT().DeleteUserTag(gomock.Any(), "user_uuid", "tag").Return(nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"message":"tag \u003ctag\u003e deleted"}`,
+		},
+		{
+			name: "return error if tag not exist",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().FindUserTags(gomock.Any(), "user_uuid").Return([]model.Tag{}, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"record not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := tagRequest(http.MethodDelete, "/websites/tags/tag", "tag")
+			rr := httptest.NewRecorder()
+			deleteTagHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_getWebsiteTagsHandler(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpo := mockrepo.NewMockRepository(ctrl)
+	rpo.EXPECT().FindUserWebsiteTags(gomock.Any(), "user_uuid", "web_1").Return([]string{"favourite", "weekly"}, nil)
+
+	req := tagRequest(http.MethodGet, "/websites/web_1/tags", "")
+	rr := httptest.NewRecorder()
+	getWebsiteTagsHandler(rpo).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"tags":["favourite","weekly"]}`, strings.Trim(rr.Body.String(), "\n"))
+}
+
+func Test_addWebsiteTagHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "add tag",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().AddUserWebsiteTag(gomock.Any(), "user_uuid", "web_1", "weekly").Return(nil)
+				rpo.EXPECT().FindUserWebsiteTags(gomock.Any(), "user_uuid", "web_1").Return([]string{"favourite", "weekly"}, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"tags":["favourite","weekly"]}`,
+		},
+		{
+			name: "return error if add failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				expectWithTx(rpo)
+				rpo.EXPECT().AddUserWebsiteTag(gomock.Any(), "user_uuid", "web_1", "weekly").Return(errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := tagRequest(http.MethodPut, "/websites/web_1/tags/weekly", "weekly")
+			rr := httptest.NewRecorder()
+			addWebsiteTagHandler(test.getRepo(ctrl)).ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_removeWebsiteTagHandler(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpo := mockrepo.NewMockRepository(ctrl)
+	expectWithTx(rpo)
+	rpo.EXPECT().RemoveUserWebsiteTag(gomock.Any(), "user_uuid", "web_1", "weekly").Return(nil)
+	rpo.EXPECT().FindUserWebsiteTags(gomock.Any(), "user_uuid", "web_1").Return([]string{}, nil)
+
+	req := tagRequest(http.MethodDelete, "/websites/web_1/tags/weekly", "weekly")
+	rr := httptest.NewRecorder()
+	removeWebsiteTagHandler(rpo).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"tags":[]}`, strings.Trim(rr.Body.String(), "\n"))
+}
@@ -0,0 +1,530 @@
+package website
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	websiteimport "github.com/htchan/WebHistory/internal/tasks/nats/website_import"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func transferWebsites() []model.UserWebsite {
+	web1 := groupWebsite("web_1", "title 1", "group")
+	web1.DisplayTitle = "display, title"
+	web1.Note = "some note"
+
+	web2 := groupWebsite("web_2", "title 2", "other group")
+	web3 := groupWebsite("web_3", "title 3", "group")
+
+	return []model.UserWebsite{web1, web2, web3}
+}
+
+func transferRows() []websiteimport.ImportRow {
+	accessTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return []websiteimport.ImportRow{
+		{URL: "http://example.com/web_1", Title: "display, title", GroupName: "group", Note: "some note", AccessTime: accessTime},
+		{URL: "http://example.com/web_2", GroupName: "other group", AccessTime: accessTime},
+		{URL: "http://example.com/web_3", GroupName: "group", AccessTime: accessTime},
+	}
+}
+
+func Test_encodeWebsites(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format string
+		expect string
+	}{
+		{
+			name:   "json",
+			format: FormatJSON,
+			expect: `{"websites":[` +
+				`{"url":"http://example.com/web_1","title":"display, title","group_name":"group","note":"some note","access_time":"2000-01-01T00:00:00Z"},` +
+				`{"url":"http://example.com/web_2","group_name":"other group","access_time":"2000-01-01T00:00:00Z"},` +
+				`{"url":"http://example.com/web_3","group_name":"group","access_time":"2000-01-01T00:00:00Z"}]}` + "\n",
+		},
+		{
+			name:   "csv",
+			format: FormatCSV,
+			expect: "url,title,group_name,note,access_time\n" +
+				"http://example.com/web_1,\"display, title\",group,some note,2000-01-01T00:00:00Z\n" +
+				"http://example.com/web_2,,other group,,2000-01-01T00:00:00Z\n" +
+				"http://example.com/web_3,,group,,2000-01-01T00:00:00Z\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var builder strings.Builder
+			err := encodeWebsites(&builder, test.format, transferWebsites())
+			assert.NoError(t, err)
+			assert.Equal(t, test.expect, builder.String())
+		})
+	}
+}
+
+func Test_encodeOPML(t *testing.T) {
+	t.Parallel()
+
+	var builder strings.Builder
+	err := encodeWebsites(&builder, FormatOPML, transferWebsites())
+	require.NoError(t, err)
+
+	got := builder.String()
+	assert.True(t, strings.HasPrefix(got, `<?xml version="1.0" encoding="UTF-8"?>`))
+	assert.Contains(t, got, `<opml version="2.0">`)
+	assert.Contains(t, got, `<outline text="group">`+"\n"+
+		`      <outline text="display, title" type="link" title="display, title" url="http://example.com/web_1" note="some note" accessTime="2000-01-01T00:00:00Z"></outline>`+"\n"+
+		`      <outline text="title 3" type="link" url="http://example.com/web_3" accessTime="2000-01-01T00:00:00Z"></outline>`+"\n"+
+		`    </outline>`)
+	assert.Contains(t, got, `<outline text="other group">`)
+}
+
+func Test_decodeWebsites(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		format    string
+		file      string
+		expect    []websiteimport.ImportRow
+		expectErr bool
+	}{
+		{
+			name:   "json",
+			format: FormatJSON,
+			file:   `{"websites":[{"url":"http://example.com/web_1","title":"title","group_name":"group","note":"note","access_time":"2000-01-01T00:00:00Z"},{"url":"http://example.com/web_2"}]}`,
+			expect: []websiteimport.ImportRow{
+				{URL: "http://example.com/web_1", Title: "title", GroupName: "group", Note: "note", AccessTime: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+				{URL: "http://example.com/web_2"},
+			},
+		},
+		{
+			name:   "csv with columns in any order",
+			format: FormatCSV,
+			file:   "Group_Name,URL\ngroup,http://example.com/web_1\n,http://example.com/web_2\n",
+			expect: []websiteimport.ImportRow{
+				{URL: "http://example.com/web_1", GroupName: "group"},
+				{URL: "http://example.com/web_2"},
+			},
+		},
+		{
+			name:   "opml exported by feed reader",
+			format: FormatOPML,
+			file: `<?xml version="1.0"?><opml version="1.0"><head><title>feeds</title></head><body>` +
+				`<outline text="novels"><outline text="novel" type="rss" xmlUrl="http://example.com/feed" htmlUrl="http://example.com/web_1"/></outline>` +
+				`<outline text="ungrouped" type="rss" xmlUrl="http://example.com/web_2"/>` +
+				`</body></opml>`,
+			expect: []websiteimport.ImportRow{
+				{URL: "http://example.com/web_1", GroupName: "novels"},
+				{URL: "http://example.com/web_2"},
+			},
+		},
+		{
+			name:      "invalid json",
+			format:    FormatJSON,
+			file:      `[]`,
+			expectErr: true,
+		},
+		{
+			name:      "csv without url column",
+			format:    FormatCSV,
+			file:      "title\ntitle\n",
+			expectErr: true,
+		},
+		{
+			name:      "csv with invalid access time",
+			format:    FormatCSV,
+			file:      "url,access_time\nhttp://example.com/web_1,yesterday\n",
+			expectErr: true,
+		},
+		{
+			name:      "invalid opml",
+			format:    FormatOPML,
+			file:      `<opml><body>`,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			rows, err := decodeWebsites(strings.NewReader(test.file), test.format)
+			if test.expectErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expect, rows)
+		})
+	}
+}
+
+func Test_transferRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{FormatJSON, FormatCSV, FormatOPML} {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			var builder strings.Builder
+			require.NoError(t, encodeWebsites(&builder, format, transferWebsites()))
+
+			rows, err := decodeWebsites(strings.NewReader(builder.String()), format)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, transferRows(), rows)
+		})
+	}
+}
+
+func Test_TransferFormatParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		query        string
+		expectFormat string
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name:         "default to json",
+			query:        "",
+			expectFormat: FormatJSON,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "accept format in any case",
+			query:        "?format=OPML",
+			expectFormat: FormatOPML,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "reject unknown format",
+			query:        "?format=xml",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":{"code":"invalid_params","message":"invalid params","details":{"format":"must be json, csv or opml"}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var format string
+			handler := transferFormatParams(writeErrorV2)(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				format = req.Context().Value(ContextKeyFormat).(string)
+			}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/websites/export"+test.query, nil))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectFormat, format)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_ImportFileBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		format       string
+		body         string
+		expectRows   []websiteimport.ImportRow
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name:         "trim rows",
+			format:       FormatCSV,
+			body:         "url,title,group_name\n http://example.com/web_1 , title , group \n",
+			expectRows:   []websiteimport.ImportRow{{URL: "http://example.com/web_1", Title: "title", GroupName: "group"}},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "reject invalid file",
+			format:       FormatJSON,
+			body:         `not json`,
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":{"code":"invalid_params","message":"invalid params","details":{"file":"must be a valid json file: invalid character 'o' in literal null (expecting 'u')"}}}`,
+		},
+		{
+			name:         "reject empty file",
+			format:       FormatCSV,
+			body:         "url\n",
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":{"code":"invalid_params","message":"invalid params","details":{"file":"must contain 1 to 1000 websites"}}}`,
+		},
+		{
+			name:         "reject too many websites",
+			format:       FormatCSV,
+			body:         "url\n" + strings.Repeat("http://example.com\n", MaxImportRows+1),
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":{"code":"invalid_params","message":"invalid params","details":{"file":"must contain 1 to 1000 websites"}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rows []websiteimport.ImportRow
+			handler := importFileBody(writeErrorV2)(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				rows = req.Context().Value(ContextKeyImport).([]websiteimport.ImportRow)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/websites/import", strings.NewReader(test.body))
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyFormat, test.format))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectRows, rows)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_exportWebsitesHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		getRepo           func(*gomock.Controller) repository.Repository
+		expectStatus      int
+		expectContentType string
+		expectDisposition string
+		expectResp        string
+	}{
+		{
+			name: "export websites",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserWebsites(gomock.Any(), "user_uuid").Return(model.UserWebsites(transferWebsites()[1:2]), nil)
+
+				return rpo
+			},
+			expectStatus:      http.StatusOK,
+			expectContentType: "text/csv; charset=utf-8",
+			expectDisposition: `attachment; filename="web-history.csv"`,
+			expectResp:        "url,title,group_name,note,access_time\nhttp://example.com/web_2,,other group,,2000-01-01T00:00:00Z",
+		},
+		{
+			name: "return error if find failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindUserWebsites(gomock.Any(), "user_uuid").Return(nil, errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"record not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodGet, "/websites/export", nil)
+			ctx := context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid")
+			ctx = context.WithValue(ctx, ContextKeyFormat, FormatCSV)
+			rr := httptest.NewRecorder()
+			exportWebsitesHandler(test.getRepo(ctrl)).ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			if test.expectContentType != "" {
+				assert.Equal(t, test.expectContentType, rr.Header().Get("Content-Type"))
+				assert.Equal(t, test.expectDisposition, rr.Header().Get("Content-Disposition"))
+			}
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_importWebsitesHandler(t *testing.T) {
+	nc, err := nats.Connect(connString)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		nc.Close()
+	})
+
+	rows := []websiteimport.ImportRow{{URL: "http://example.com/web_1"}}
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   func(t *testing.T, resp string)
+		expectMsg    bool
+	}{
+		{
+			name: "create and publish import job",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().CreateJob(gomock.Any(), gomock.Cond(func(job *model.Job) bool {
+					return job.UserUUID == "user_uuid" && job.Kind == model.JobKindImport && job.Status == model.JobStatusQueued
+				})).Return(nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusAccepted,
+			expectResp: func(t *testing.T, resp string) {
+				assert.Contains(t, resp, `"kind":"import","status":"queued"`)
+			},
+			expectMsg: true,
+		},
+		{
+			name: "return error if create job failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().CreateJob(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp: func(t *testing.T, resp string) {
+				assert.Equal(t, `{"error":"some error"}`, resp)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			received := make(chan *nats.Msg, 1)
+			sub, err := nc.ChanSubscribe("web_history.websites.import", received)
+			require.NoError(t, err)
+			defer sub.Unsubscribe()
+
+			req := httptest.NewRequest(http.MethodPost, "/websites/import", nil)
+			ctx := context.WithValue(req.Context(), ContextKeyUserUUID, "user_uuid")
+			ctx = context.WithValue(ctx, ContextKeyImport, rows)
+			rr := httptest.NewRecorder()
+
+			task := websiteimport.NewTask(nc, nil, nil, nil)
+			importWebsitesHandler(test.getRepo(ctrl), task).ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			test.expectResp(t, strings.Trim(rr.Body.String(), "\n"))
+
+			if test.expectMsg {
+				select {
+				case msg := <-received:
+					assert.Contains(t, string(msg.Data), `"user_uuid":"user_uuid","request_id":"","rows":[{"url":"http://example.com/web_1"}]`)
+				case <-time.After(2 * time.Second):
+					t.Fatal("timed out waiting for published message")
+				}
+			}
+		})
+	}
+}
+
+func Test_getJobHandlerV2(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "get done job",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindJob(gomock.Any(), "user_uuid", "job_uuid").Return(&model.Job{
+					UUID:      "job_uuid",
+					UserUUID:  "user_uuid",
+					Kind:      model.JobKindImport,
+					Status:    model.JobStatusDone,
+					Result:    `{"total":1}`,
+					CreatedAt: createdAt,
+					UpdatedAt: createdAt,
+				}, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"job":{"uuid":"job_uuid","kind":"import","status":"done","result":{"total":1},"created_at":"2000-01-01T00:00:00Z","updated_at":"2000-01-01T00:00:00Z"}}`,
+		},
+		{
+			name: "get queued job",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindJob(gomock.Any(), "user_uuid", "job_uuid").Return(&model.Job{
+					UUID:      "job_uuid",
+					UserUUID:  "user_uuid",
+					Kind:      model.JobKindImport,
+					Status:    model.JobStatusQueued,
+					CreatedAt: createdAt,
+					UpdatedAt: createdAt,
+				}, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"job":{"uuid":"job_uuid","kind":"import","status":"queued","created_at":"2000-01-01T00:00:00Z","updated_at":"2000-01-01T00:00:00Z"}}`,
+		},
+		{
+			name: "return not found for job of other user",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindJob(gomock.Any(), "user_uuid", "job_uuid").Return(nil, errors.Join(errors.New("find job fail"), ErrRecordNotFound))
+
+				return rpo
+			},
+			expectStatus: http.StatusNotFound,
+			expectResp:   `{"error":{"code":"not_found","message":"record not found"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req := httptest.NewRequest(http.MethodGet, "/v2/websites/jobs/job_uuid", nil)
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("jobUUID", "job_uuid")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+			ctx = context.WithValue(ctx, ContextKeyUserUUID, "user_uuid")
+			rr := httptest.NewRecorder()
+			getJobHandlerV2(test.getRepo(ctrl)).ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
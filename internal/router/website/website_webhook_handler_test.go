@@ -0,0 +1,554 @@
+package website
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var webhookTime = time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+
+func testWebhook(enabled bool, failureCount int) model.Webhook {
+	return model.Webhook{
+		UUID:         "hook_1",
+		UserUUID:     "user_uuid",
+		URL:          "http://example.com/hook",
+		GroupName:    "group",
+		Secret:       "secret",
+		Enabled:      enabled,
+		FailureCount: failureCount,
+		CreatedAt:    webhookTime,
+		UpdatedAt:    webhookTime,
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func webhookRequest(method, body string, webhook *model.Webhook, params *webhookReq) *http.Request {
+	req := httptest.NewRequest(method, "/websites/webhooks", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("webhookUUID", "hook_1")
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	ctx = context.WithValue(ctx, ContextKeyUserUUID, "user_uuid")
+
+	if webhook != nil {
+		ctx = context.WithValue(ctx, ContextKeyWebhook, *webhook)
+	}
+
+	if params != nil {
+		ctx = context.WithValue(ctx, ContextKeyHookInfo, *params)
+		ctx = context.WithValue(ctx, ContextKeyBody, *params)
+	}
+
+	return req.WithContext(ctx)
+}
+
+func Test_webhookParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		middleware   func(http.Handler) http.Handler
+		body         string
+		expectStatus int
+		expectParams webhookReq
+	}{
+		{
+			name:         "parse create params",
+			middleware:   CreateWebhookParams,
+			body:         "url=+http://example.com/hook+&group_name=+group+&enabled=false",
+			expectStatus: http.StatusOK,
+			expectParams: webhookReq{
+				URL:       ptr("http://example.com/hook"),
+				GroupName: ptr("group"),
+				Enabled:   ptr(false),
+			},
+		},
+		{
+			name:         "parse update params",
+			middleware:   WebhookInfoParams,
+			body:         "enabled=true",
+			expectStatus: http.StatusOK,
+			expectParams: webhookReq{Enabled: ptr(true)},
+		},
+		{
+			name:         "return error if url missing on create",
+			middleware:   CreateWebhookParams,
+			body:         "group_name=group",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "return error if url is not http",
+			middleware:   WebhookInfoParams,
+			body:         "url=ftp://example.com",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "return error if url is loopback address",
+			middleware:   CreateWebhookParams,
+			body:         "url=http://127.0.0.1:4222/hook",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "return error if url is localhost",
+			middleware:   WebhookInfoParams,
+			body:         "url=http://localhost/hook",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "return error if url is metadata address",
+			middleware:   WebhookInfoParams,
+			body:         "url=http://169.254.169.254/latest/meta-data",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "return error if url is private address",
+			middleware:   WebhookInfoParams,
+			body:         "url=https://192.168.1.10/hook",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "return error if enabled is not bool",
+			middleware:   WebhookInfoParams,
+			body:         "enabled=maybe",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "return error if nothing to update",
+			middleware:   WebhookInfoParams,
+			body:         "",
+			expectStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var params webhookReq
+			handler := test.middleware(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				params = req.Context().Value(ContextKeyHookInfo).(webhookReq)
+			}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, webhookRequest(http.MethodPost, test.body, nil, nil))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectParams, params)
+		})
+	}
+}
+
+func Test_queryWebhook(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		getRepo       func(*gomock.Controller) repository.Repository
+		onError       errorWriter
+		expectStatus  int
+		expectWebhook model.Webhook
+		expectResp    string
+	}{
+		{
+			name: "set webhook of user",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				webhook := testWebhook(true, 0)
+				rpo.EXPECT().FindWebhook(gomock.Any(), "user_uuid", "hook_1").Return(&webhook, nil)
+
+				return rpo
+			},
+			onError:       writeErrorV1,
+			expectStatus:  http.StatusOK,
+			expectWebhook: testWebhook(true, 0),
+		},
+		{
+			name: "v1 returns bad request if webhook not found",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindWebhook(gomock.Any(), "user_uuid", "hook_1").Return(nil, sql.ErrNoRows)
+
+				return rpo
+			},
+			onError:      writeErrorV1,
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"sql: no rows in result set"}`,
+		},
+		{
+			name: "v2 returns not found if webhook not found",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindWebhook(gomock.Any(), "user_uuid", "hook_1").Return(nil, sql.ErrNoRows)
+
+				return rpo
+			},
+			onError:      writeErrorV2,
+			expectStatus: http.StatusNotFound,
+			expectResp:   `{"error":{"code":"not_found","message":"sql: no rows in result set"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var webhook model.Webhook
+			handler := queryWebhook(test.getRepo(ctrl), test.onError)(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				webhook = req.Context().Value(ContextKeyWebhook).(model.Webhook)
+			}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, webhookRequest(http.MethodGet, "", nil, nil))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectWebhook, webhook)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_listWebhooksHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "list webhooks without secret",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindWebhooks(gomock.Any(), "user_uuid").Return([]model.Webhook{testWebhook(true, 1)}, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp: `{"webhooks":[{"uuid":"hook_1","url":"http://example.com/hook","group_name":"group","enabled":true,` +
+				`"failure_count":1,"created_at":"2000-01-02T00:00:00Z","updated_at":"2000-01-02T00:00:00Z"}]}`,
+		},
+		{
+			name: "return empty list for user without webhook",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindWebhooks(gomock.Any(), "user_uuid").Return(nil, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp:   `{"webhooks":[]}`,
+		},
+		{
+			name: "return error if find failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindWebhooks(gomock.Any(), "user_uuid").Return(nil, errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"record not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rr := httptest.NewRecorder()
+			listWebhooksHandler(test.getRepo(ctrl)).ServeHTTP(rr, webhookRequest(http.MethodGet, "", nil, nil))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_createWebhookHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		handler      func(repository.Repository) http.HandlerFunc
+		params       webhookReq
+		expectStatus int
+		expectSecret bool
+	}{
+		{
+			name: "v1 returns webhook with secret",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, webhook *model.Webhook) error {
+						assert.Equal(t, "user_uuid", webhook.UserUUID)
+						assert.Equal(t, "http://example.com/hook", webhook.URL)
+						assert.Equal(t, "group", webhook.GroupName)
+						assert.False(t, webhook.Enabled)
+
+						return nil
+					},
+				)
+
+				return rpo
+			},
+			handler:      createWebhookHandler,
+			params:       webhookReq{URL: ptr("http://example.com/hook"), GroupName: ptr("group"), Enabled: ptr(false)},
+			expectStatus: http.StatusOK,
+			expectSecret: true,
+		},
+		{
+			name: "v2 returns created webhook with secret",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(nil)
+
+				return rpo
+			},
+			handler:      createWebhookHandlerV2,
+			params:       webhookReq{URL: ptr("http://example.com/hook")},
+			expectStatus: http.StatusCreated,
+			expectSecret: true,
+		},
+		{
+			name: "v2 returns error if create failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+				return rpo
+			},
+			handler:      createWebhookHandlerV2,
+			params:       webhookReq{URL: ptr("http://example.com/hook")},
+			expectStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rr := httptest.NewRecorder()
+			test.handler(test.getRepo(ctrl)).ServeHTTP(rr, webhookRequest(http.MethodPost, "", nil, &test.params))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			if test.expectSecret {
+				assert.Regexp(t, `"secret":"[0-9a-f]{64}"`, rr.Body.String())
+			} else {
+				assert.NotContains(t, rr.Body.String(), `"secret"`)
+			}
+		})
+	}
+}
+
+func Test_updateWebhookHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		webhook            model.Webhook
+		params             webhookReq
+		updateErr          error
+		expectStatus       int
+		expectEnabled      bool
+		expectFailureCount int
+		expectURL          string
+	}{
+		{
+			name:               "update url",
+			webhook:            testWebhook(true, 2),
+			params:             webhookReq{URL: ptr("http://example.com/new-hook")},
+			expectStatus:       http.StatusOK,
+			expectEnabled:      true,
+			expectFailureCount: 2,
+			expectURL:          "http://example.com/new-hook",
+		},
+		{
+			name:               "reset failure count on re-enable",
+			webhook:            testWebhook(false, 10),
+			params:             webhookReq{Enabled: ptr(true)},
+			expectStatus:       http.StatusOK,
+			expectEnabled:      true,
+			expectFailureCount: 0,
+			expectURL:          "http://example.com/hook",
+		},
+		{
+			name:               "return error if update failed",
+			webhook:            testWebhook(true, 0),
+			params:             webhookReq{Enabled: ptr(false)},
+			updateErr:          errors.New("some error"),
+			expectStatus:       http.StatusInternalServerError,
+			expectEnabled:      false,
+			expectFailureCount: 0,
+			expectURL:          "http://example.com/hook",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rpo := mockrepo.NewMockRepository(ctrl)
+			rpo.EXPECT().UpdateWebhook(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, webhook *model.Webhook) error {
+					assert.Equal(t, test.expectEnabled, webhook.Enabled)
+					assert.Equal(t, test.expectFailureCount, webhook.FailureCount)
+					assert.Equal(t, test.expectURL, webhook.URL)
+					assert.True(t, webhook.UpdatedAt.After(webhookTime))
+
+					return test.updateErr
+				},
+			)
+
+			rr := httptest.NewRecorder()
+			updateWebhookHandler(rpo).ServeHTTP(rr, webhookRequest(http.MethodPatch, "", &test.webhook, &test.params))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.NotContains(t, rr.Body.String(), `"secret"`)
+		})
+	}
+}
+
+func Test_deleteWebhookHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		handler      func(repository.Repository) http.HandlerFunc
+		deleteErr    error
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name:         "v1 delete webhook",
+			handler:      deleteWebhookHandler,
+			expectStatus: http.StatusOK,
+			expectResp:   `{"message":"webhook \u003chttp://example.com/hook\u003e deleted"}`,
+		},
+		{
+			name:         "v2 delete webhook",
+			handler:      deleteWebhookHandlerV2,
+			expectStatus: http.StatusNoContent,
+		},
+		{
+			name:         "v2 return error if delete failed",
+			handler:      deleteWebhookHandlerV2,
+			deleteErr:    errors.New("some error"),
+			expectStatus: http.StatusInternalServerError,
+			expectResp:   `{"error":{"code":"internal_error","message":"internal error"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			webhook := testWebhook(true, 0)
+			rpo := mockrepo.NewMockRepository(ctrl)
+			rpo.EXPECT().DeleteWebhook(gomock.Any(), &webhook).Return(test.deleteErr)
+
+			rr := httptest.NewRecorder()
+			test.handler(rpo).ServeHTTP(rr, webhookRequest(http.MethodDelete, "", &webhook, nil))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_listWebhookDeliveriesHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getRepo      func(*gomock.Controller) repository.Repository
+		expectStatus int
+		expectResp   string
+	}{
+		{
+			name: "list latest deliveries",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindWebhookDeliveries(gomock.Any(), "hook_1", MaxWebhookDeliveries).Return([]model.WebhookDelivery{
+					{
+						UUID:           "delivery_1",
+						WebhookUUID:    "hook_1",
+						WebsiteUUID:    "web_1",
+						Status:         model.WebhookDeliveryStatusRetrying,
+						Payload:        `{"event":"website_updated"}`,
+						Attempts:       1,
+						ResponseStatus: 500,
+						Error:          "unexpected response status: 500",
+						CreatedAt:      webhookTime,
+						UpdatedAt:      webhookTime,
+					},
+				}, nil)
+
+				return rpo
+			},
+			expectStatus: http.StatusOK,
+			expectResp: `{"deliveries":[{"uuid":"delivery_1","website_uuid":"web_1","status":"retrying",` +
+				`"payload":{"event":"website_updated"},"attempts":1,"response_status":500,` +
+				`"error":"unexpected response status: 500","created_at":"2000-01-02T00:00:00Z","updated_at":"2000-01-02T00:00:00Z"}]}`,
+		},
+		{
+			name: "return error if find failed",
+			getRepo: func(c *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(c)
+				rpo.EXPECT().FindWebhookDeliveries(gomock.Any(), "hook_1", MaxWebhookDeliveries).Return(nil, errors.New("some error"))
+
+				return rpo
+			},
+			expectStatus: http.StatusBadRequest,
+			expectResp:   `{"error":"record not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			webhook := testWebhook(true, 0)
+			rr := httptest.NewRecorder()
+			listWebhookDeliveriesHandler(test.getRepo(ctrl)).ServeHTTP(rr, webhookRequest(http.MethodGet, "", &webhook, nil))
+
+			assert.Equal(t, test.expectStatus, rr.Code)
+			assert.Equal(t, test.expectResp, strings.Trim(rr.Body.String(), "\n"))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+type AuditEvent struct {
+	Uuid        string
+	UserUuid    string
+	WebsiteUuid string
+	Action      string
+	Before      string
+	After       string
+	RequestID   string
+	CreatedAt   time.Time
+}
+
+type Job struct {
+	Uuid      string
+	UserUuid  string
+	Kind      string
+	Status    string
+	Result    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type UserFeedToken struct {
+	UserUuid  string
+	Token     string
+	CreatedAt time.Time
+}
+
+type UserWebsite struct {
+	WebsiteUuid     sql.NullString
+	UserUuid        sql.NullString
+	AccessTime      sql.NullTime
+	GroupName       sql.NullString
+	DisplayTitle    string
+	Note            string
+	LastReadChapter string
+}
+
+type UserWebsiteTag struct {
+	UserUuid    string
+	WebsiteUuid string
+	Tag         string
+}
+
+type Webhook struct {
+	Uuid         string
+	UserUuid     string
+	Url          string
+	GroupName    string
+	Secret       string
+	Enabled      bool
+	FailureCount int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type WebhookDelivery struct {
+	Uuid           string
+	WebhookUuid    string
+	WebsiteUuid    string
+	Status         string
+	Payload        string
+	Attempts       int64
+	ResponseStatus int64
+	Error          string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type Website struct {
+	Uuid         sql.NullString
+	Url          sql.NullString
+	Title        sql.NullString
+	Content      sql.NullString
+	UpdateTime   sql.NullTime
+	Status       string
+	RedirectUuid sql.NullString
+	MissingCount int64
+	OrphanedAt   sql.NullTime
+}
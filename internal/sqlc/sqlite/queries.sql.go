@@ -0,0 +1,2012 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: queries.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const clearOrphanedWebsites = `-- name: ClearOrphanedWebsites :execrows
+UPDATE websites SET orphaned_at=NULL
+WHERE status='active' and orphaned_at IS NOT NULL and EXISTS (
+  SELECT 1 FROM user_websites WHERE user_websites.website_uuid=websites.uuid
+)
+`
+
+func (q *Queries) ClearOrphanedWebsites(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, clearOrphanedWebsites)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countDeactivatedOrphanedWebsites = `-- name: CountDeactivatedOrphanedWebsites :one
+SELECT COUNT(*) FROM websites WHERE status='inactive' and orphaned_at IS NOT NULL
+`
+
+func (q *Queries) CountDeactivatedOrphanedWebsites(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDeactivatedOrphanedWebsites)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserJobs = `-- name: CountUserJobs :one
+SELECT count(*) FROM jobs
+WHERE user_uuid=?1 and kind=?2 and created_at >= ?3
+`
+
+type CountUserJobsParams struct {
+	UserUuid string
+	Kind     string
+	Since    time.Time
+}
+
+func (q *Queries) CountUserJobs(ctx context.Context, arg CountUserJobsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserJobs, arg.UserUuid, arg.Kind, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserUnreadWebsitesByGroup = `-- name: CountUserUnreadWebsitesByGroup :many
+SELECT group_name, count(*) AS unread_count
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=? and websites.status != 'inactive' and update_time > access_time
+GROUP BY group_name
+ORDER BY unread_count DESC, group_name
+`
+
+type CountUserUnreadWebsitesByGroupRow struct {
+	GroupName   sql.NullString
+	UnreadCount int64
+}
+
+func (q *Queries) CountUserUnreadWebsitesByGroup(ctx context.Context, userUuid sql.NullString) ([]CountUserUnreadWebsitesByGroupRow, error) {
+	rows, err := q.db.QueryContext(ctx, countUserUnreadWebsitesByGroup, userUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountUserUnreadWebsitesByGroupRow
+	for rows.Next() {
+		var i CountUserUnreadWebsitesByGroupRow
+		if err := rows.Scan(&i.GroupName, &i.UnreadCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createAuditEvent = `-- name: CreateAuditEvent :one
+INSERT INTO audit_events
+(uuid, user_uuid, website_uuid, action, before, after, request_id, created_at)
+VALUES
+(?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING uuid, user_uuid, website_uuid, "action", "before", "after", request_id, created_at
+`
+
+type CreateAuditEventParams struct {
+	Uuid        string
+	UserUuid    string
+	WebsiteUuid string
+	Action      string
+	Before      string
+	After       string
+	RequestID   string
+	CreatedAt   time.Time
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	row := q.db.QueryRowContext(ctx, createAuditEvent,
+		arg.Uuid,
+		arg.UserUuid,
+		arg.WebsiteUuid,
+		arg.Action,
+		arg.Before,
+		arg.After,
+		arg.RequestID,
+		arg.CreatedAt,
+	)
+	var i AuditEvent
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.WebsiteUuid,
+		&i.Action,
+		&i.Before,
+		&i.After,
+		&i.RequestID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createJob = `-- name: CreateJob :one
+INSERT INTO jobs
+(uuid, user_uuid, kind, status, result, created_at, updated_at)
+VALUES
+(?, ?, ?, ?, ?, ?, ?)
+RETURNING uuid, user_uuid, kind, status, result, created_at, updated_at
+`
+
+type CreateJobParams struct {
+	Uuid      string
+	UserUuid  string
+	Kind      string
+	Status    string
+	Result    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, error) {
+	row := q.db.QueryRowContext(ctx, createJob,
+		arg.Uuid,
+		arg.UserUuid,
+		arg.Kind,
+		arg.Status,
+		arg.Result,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i Job
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Kind,
+		&i.Status,
+		&i.Result,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createUserWebsite = `-- name: CreateUserWebsite :one
+INSERT INTO user_websites
+(user_uuid, website_uuid, access_time, group_name, display_title, note, last_read_chapter)
+VALUES
+(?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(user_uuid, website_uuid) DO
+UPDATE SET user_uuid=excluded.user_uuid, website_uuid=excluded.website_uuid
+RETURNING website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter
+`
+
+type CreateUserWebsiteParams struct {
+	UserUuid        sql.NullString
+	WebsiteUuid     sql.NullString
+	AccessTime      sql.NullTime
+	GroupName       sql.NullString
+	DisplayTitle    string
+	Note            string
+	LastReadChapter string
+}
+
+func (q *Queries) CreateUserWebsite(ctx context.Context, arg CreateUserWebsiteParams) (UserWebsite, error) {
+	row := q.db.QueryRowContext(ctx, createUserWebsite,
+		arg.UserUuid,
+		arg.WebsiteUuid,
+		arg.AccessTime,
+		arg.GroupName,
+		arg.DisplayTitle,
+		arg.Note,
+		arg.LastReadChapter,
+	)
+	var i UserWebsite
+	err := row.Scan(
+		&i.WebsiteUuid,
+		&i.UserUuid,
+		&i.AccessTime,
+		&i.GroupName,
+		&i.DisplayTitle,
+		&i.Note,
+		&i.LastReadChapter,
+	)
+	return i, err
+}
+
+const createUserWebsiteTag = `-- name: CreateUserWebsiteTag :exec
+INSERT INTO user_website_tags
+(user_uuid, website_uuid, tag)
+VALUES
+(?, ?, ?)
+ON CONFLICT(user_uuid, website_uuid, tag) DO NOTHING
+`
+
+type CreateUserWebsiteTagParams struct {
+	UserUuid    string
+	WebsiteUuid string
+	Tag         string
+}
+
+func (q *Queries) CreateUserWebsiteTag(ctx context.Context, arg CreateUserWebsiteTagParams) error {
+	_, err := q.db.ExecContext(ctx, createUserWebsiteTag, arg.UserUuid, arg.WebsiteUuid, arg.Tag)
+	return err
+}
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks
+(uuid, user_uuid, url, group_name, secret, enabled, failure_count, created_at, updated_at)
+VALUES
+(?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING uuid, user_uuid, url, group_name, secret, enabled, failure_count, created_at, updated_at
+`
+
+type CreateWebhookParams struct {
+	Uuid         string
+	UserUuid     string
+	Url          string
+	GroupName    string
+	Secret       string
+	Enabled      bool
+	FailureCount int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, createWebhook,
+		arg.Uuid,
+		arg.UserUuid,
+		arg.Url,
+		arg.GroupName,
+		arg.Secret,
+		arg.Enabled,
+		arg.FailureCount,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Url,
+		&i.GroupName,
+		&i.Secret,
+		&i.Enabled,
+		&i.FailureCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries
+(uuid, webhook_uuid, website_uuid, status, payload, attempts, response_status, error, created_at, updated_at)
+VALUES
+(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING uuid, webhook_uuid, website_uuid, status, payload, attempts, response_status, error, created_at, updated_at
+`
+
+type CreateWebhookDeliveryParams struct {
+	Uuid           string
+	WebhookUuid    string
+	WebsiteUuid    string
+	Status         string
+	Payload        string
+	Attempts       int64
+	ResponseStatus int64
+	Error          string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, createWebhookDelivery,
+		arg.Uuid,
+		arg.WebhookUuid,
+		arg.WebsiteUuid,
+		arg.Status,
+		arg.Payload,
+		arg.Attempts,
+		arg.ResponseStatus,
+		arg.Error,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.Uuid,
+		&i.WebhookUuid,
+		&i.WebsiteUuid,
+		&i.Status,
+		&i.Payload,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createWebsite = `-- name: CreateWebsite :one
+INSERT INTO websites
+(uuid, url, title, content, update_time)
+VALUES
+(?, ?, ?, ?, ?)
+ON CONFLICT (url) DO
+UPDATE SET url=excluded.url
+RETURNING uuid, url, title, content, update_time, status, redirect_uuid, missing_count, orphaned_at
+`
+
+type CreateWebsiteParams struct {
+	Uuid       sql.NullString
+	Url        sql.NullString
+	Title      sql.NullString
+	Content    sql.NullString
+	UpdateTime sql.NullTime
+}
+
+func (q *Queries) CreateWebsite(ctx context.Context, arg CreateWebsiteParams) (Website, error) {
+	row := q.db.QueryRowContext(ctx, createWebsite,
+		arg.Uuid,
+		arg.Url,
+		arg.Title,
+		arg.Content,
+		arg.UpdateTime,
+	)
+	var i Website
+	err := row.Scan(
+		&i.Uuid,
+		&i.Url,
+		&i.Title,
+		&i.Content,
+		&i.UpdateTime,
+		&i.Status,
+		&i.RedirectUuid,
+		&i.MissingCount,
+		&i.OrphanedAt,
+	)
+	return i, err
+}
+
+const deactivateOrphanedWebsites = `-- name: DeactivateOrphanedWebsites :execrows
+UPDATE websites SET status='inactive'
+WHERE status='active' and orphaned_at < ?1 and NOT EXISTS (
+  SELECT 1 FROM user_websites WHERE user_websites.website_uuid=websites.uuid
+)
+`
+
+func (q *Queries) DeactivateOrphanedWebsites(ctx context.Context, orphanedBefore sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deactivateOrphanedWebsites, orphanedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteUserGroup = `-- name: DeleteUserGroup :exec
+DELETE FROM user_websites
+WHERE user_uuid=? and group_name=?
+`
+
+type DeleteUserGroupParams struct {
+	UserUuid  sql.NullString
+	GroupName sql.NullString
+}
+
+func (q *Queries) DeleteUserGroup(ctx context.Context, arg DeleteUserGroupParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserGroup, arg.UserUuid, arg.GroupName)
+	return err
+}
+
+const deleteUserGroupTags = `-- name: DeleteUserGroupTags :exec
+DELETE FROM user_website_tags
+WHERE user_website_tags.user_uuid=?1 and website_uuid IN (
+  SELECT website_uuid FROM user_websites
+  WHERE user_websites.user_uuid=?1 and group_name=?2
+)
+`
+
+type DeleteUserGroupTagsParams struct {
+	UserUuid  string
+	GroupName sql.NullString
+}
+
+func (q *Queries) DeleteUserGroupTags(ctx context.Context, arg DeleteUserGroupTagsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserGroupTags, arg.UserUuid, arg.GroupName)
+	return err
+}
+
+const deleteUserTag = `-- name: DeleteUserTag :exec
+DELETE FROM user_website_tags
+WHERE user_uuid=? and tag=?
+`
+
+type DeleteUserTagParams struct {
+	UserUuid string
+	Tag      string
+}
+
+func (q *Queries) DeleteUserTag(ctx context.Context, arg DeleteUserTagParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTag, arg.UserUuid, arg.Tag)
+	return err
+}
+
+const deleteUserWebsite = `-- name: DeleteUserWebsite :exec
+DELETE FROM user_websites
+where user_uuid=? and website_uuid=?
+`
+
+type DeleteUserWebsiteParams struct {
+	UserUuid    sql.NullString
+	WebsiteUuid sql.NullString
+}
+
+func (q *Queries) DeleteUserWebsite(ctx context.Context, arg DeleteUserWebsiteParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserWebsite, arg.UserUuid, arg.WebsiteUuid)
+	return err
+}
+
+const deleteUserWebsiteTag = `-- name: DeleteUserWebsiteTag :exec
+DELETE FROM user_website_tags
+WHERE user_uuid=? and website_uuid=? and tag=?
+`
+
+type DeleteUserWebsiteTagParams struct {
+	UserUuid    string
+	WebsiteUuid string
+	Tag         string
+}
+
+func (q *Queries) DeleteUserWebsiteTag(ctx context.Context, arg DeleteUserWebsiteTagParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserWebsiteTag, arg.UserUuid, arg.WebsiteUuid, arg.Tag)
+	return err
+}
+
+const deleteUserWebsiteTags = `-- name: DeleteUserWebsiteTags :exec
+DELETE FROM user_website_tags
+WHERE user_uuid=? and website_uuid=?
+`
+
+type DeleteUserWebsiteTagsParams struct {
+	UserUuid    string
+	WebsiteUuid string
+}
+
+func (q *Queries) DeleteUserWebsiteTags(ctx context.Context, arg DeleteUserWebsiteTagsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserWebsiteTags, arg.UserUuid, arg.WebsiteUuid)
+	return err
+}
+
+const deleteUserWebsiteTagsByWebsite = `-- name: DeleteUserWebsiteTagsByWebsite :exec
+DELETE FROM user_website_tags WHERE website_uuid=?
+`
+
+func (q *Queries) DeleteUserWebsiteTagsByWebsite(ctx context.Context, websiteUuid string) error {
+	_, err := q.db.ExecContext(ctx, deleteUserWebsiteTagsByWebsite, websiteUuid)
+	return err
+}
+
+const deleteUserWebsitesByWebsite = `-- name: DeleteUserWebsitesByWebsite :exec
+DELETE FROM user_websites WHERE website_uuid=?
+`
+
+func (q *Queries) DeleteUserWebsitesByWebsite(ctx context.Context, websiteUuid sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, deleteUserWebsitesByWebsite, websiteUuid)
+	return err
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :exec
+DELETE FROM webhooks WHERE uuid=?1
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, uuid string) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhook, uuid)
+	return err
+}
+
+const deleteWebhookDeliveries = `-- name: DeleteWebhookDeliveries :exec
+DELETE FROM webhook_deliveries WHERE webhook_uuid=?1
+`
+
+func (q *Queries) DeleteWebhookDeliveries(ctx context.Context, webhookUuid string) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhookDeliveries, webhookUuid)
+	return err
+}
+
+const deleteWebsite = `-- name: DeleteWebsite :exec
+DELETE FROM websites WHERE uuid=?
+`
+
+func (q *Queries) DeleteWebsite(ctx context.Context, uuid sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, deleteWebsite, uuid)
+	return err
+}
+
+const getJob = `-- name: GetJob :one
+SELECT uuid, user_uuid, kind, status, result, created_at, updated_at FROM jobs
+WHERE uuid=?1 and user_uuid=?2
+`
+
+type GetJobParams struct {
+	Uuid     string
+	UserUuid string
+}
+
+func (q *Queries) GetJob(ctx context.Context, arg GetJobParams) (Job, error) {
+	row := q.db.QueryRowContext(ctx, getJob, arg.Uuid, arg.UserUuid)
+	var i Job
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Kind,
+		&i.Status,
+		&i.Result,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserFeedToken = `-- name: GetUserFeedToken :one
+SELECT user_uuid, token, created_at FROM user_feed_tokens WHERE user_uuid=?1
+`
+
+func (q *Queries) GetUserFeedToken(ctx context.Context, userUuid string) (UserFeedToken, error) {
+	row := q.db.QueryRowContext(ctx, getUserFeedToken, userUuid)
+	var i UserFeedToken
+	err := row.Scan(&i.UserUuid, &i.Token, &i.CreatedAt)
+	return i, err
+}
+
+const getUserFeedTokenByToken = `-- name: GetUserFeedTokenByToken :one
+SELECT user_uuid, token, created_at FROM user_feed_tokens WHERE token=?1
+`
+
+func (q *Queries) GetUserFeedTokenByToken(ctx context.Context, token string) (UserFeedToken, error) {
+	row := q.db.QueryRowContext(ctx, getUserFeedTokenByToken, token)
+	var i UserFeedToken
+	err := row.Scan(&i.UserUuid, &i.Token, &i.CreatedAt)
+	return i, err
+}
+
+const getUserWebsite = `-- name: GetUserWebsite :one
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=? and website_uuid=? and websites.status != 'inactive'
+`
+
+type GetUserWebsiteParams struct {
+	UserUuid    sql.NullString
+	WebsiteUuid sql.NullString
+}
+
+type GetUserWebsiteRow struct {
+	WebsiteUuid     sql.NullString
+	UserUuid        sql.NullString
+	AccessTime      sql.NullTime
+	GroupName       sql.NullString
+	DisplayTitle    string
+	Note            string
+	LastReadChapter string
+	Uuid            sql.NullString
+	Url             sql.NullString
+	Title           sql.NullString
+	Content         sql.NullString
+	UpdateTime      sql.NullTime
+	Status          string
+}
+
+func (q *Queries) GetUserWebsite(ctx context.Context, arg GetUserWebsiteParams) (GetUserWebsiteRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserWebsite, arg.UserUuid, arg.WebsiteUuid)
+	var i GetUserWebsiteRow
+	err := row.Scan(
+		&i.WebsiteUuid,
+		&i.UserUuid,
+		&i.AccessTime,
+		&i.GroupName,
+		&i.DisplayTitle,
+		&i.Note,
+		&i.LastReadChapter,
+		&i.Uuid,
+		&i.Url,
+		&i.Title,
+		&i.Content,
+		&i.UpdateTime,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getWebhook = `-- name: GetWebhook :one
+SELECT uuid, user_uuid, url, group_name, secret, enabled, failure_count, created_at, updated_at FROM webhooks
+WHERE uuid=?1 and user_uuid=?2
+`
+
+type GetWebhookParams struct {
+	Uuid     string
+	UserUuid string
+}
+
+func (q *Queries) GetWebhook(ctx context.Context, arg GetWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, getWebhook, arg.Uuid, arg.UserUuid)
+	var i Webhook
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Url,
+		&i.GroupName,
+		&i.Secret,
+		&i.Enabled,
+		&i.FailureCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT uuid, webhook_uuid, website_uuid, status, payload, attempts, response_status, error, created_at, updated_at FROM webhook_deliveries WHERE uuid=?1
+`
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, uuid string) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookDelivery, uuid)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.Uuid,
+		&i.WebhookUuid,
+		&i.WebsiteUuid,
+		&i.Status,
+		&i.Payload,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWebsite = `-- name: GetWebsite :one
+SELECT uuid, url, title, content, update_time, status, redirect_uuid, missing_count, orphaned_at from websites WHERE uuid=? and status != 'inactive'
+`
+
+func (q *Queries) GetWebsite(ctx context.Context, uuid sql.NullString) (Website, error) {
+	row := q.db.QueryRowContext(ctx, getWebsite, uuid)
+	var i Website
+	err := row.Scan(
+		&i.Uuid,
+		&i.Url,
+		&i.Title,
+		&i.Content,
+		&i.UpdateTime,
+		&i.Status,
+		&i.RedirectUuid,
+		&i.MissingCount,
+		&i.OrphanedAt,
+	)
+	return i, err
+}
+
+const listActiveWebsites = `-- name: ListActiveWebsites :many
+SELECT uuid, url, title, content, update_time, status, redirect_uuid, missing_count, orphaned_at FROM websites WHERE status='active'
+`
+
+func (q *Queries) ListActiveWebsites(ctx context.Context) ([]Website, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveWebsites)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Website
+	for rows.Next() {
+		var i Website
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Url,
+			&i.Title,
+			&i.Content,
+			&i.UpdateTime,
+			&i.Status,
+			&i.RedirectUuid,
+			&i.MissingCount,
+			&i.OrphanedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActiveWebsitesPage = `-- name: ListActiveWebsitesPage :many
+SELECT uuid, url, title, content, update_time, status, redirect_uuid, missing_count, orphaned_at FROM websites
+WHERE status='active' and uuid > CAST(?1 AS TEXT)
+and (CAST(?2 AS TEXT) = ''
+  or substr(
+    '.' || substr(substr(url, instr(url, '://') + 3), 1, instr(substr(url, instr(url, '://') + 3) || '/', '/') - 1),
+    0 - length(CAST(?2 AS TEXT)) - 1
+  ) = '.' || CAST(?2 AS TEXT))
+ORDER BY uuid ASC
+LIMIT ?3
+`
+
+type ListActiveWebsitesPageParams struct {
+	AfterUuid string
+	Host      string
+	PageLimit int64
+}
+
+func (q *Queries) ListActiveWebsitesPage(ctx context.Context, arg ListActiveWebsitesPageParams) ([]Website, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveWebsitesPage, arg.AfterUuid, arg.Host, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Website
+	for rows.Next() {
+		var i Website
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Url,
+			&i.Title,
+			&i.Content,
+			&i.UpdateTime,
+			&i.Status,
+			&i.RedirectUuid,
+			&i.MissingCount,
+			&i.OrphanedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEvents = `-- name: ListAuditEvents :many
+SELECT uuid, user_uuid, website_uuid, "action", "before", "after", request_id, created_at FROM audit_events
+WHERE (CAST(?1 AS TEXT) = '' or user_uuid=CAST(?1 AS TEXT))
+and (CAST(?2 AS TEXT) = '' or website_uuid=CAST(?2 AS TEXT))
+ORDER BY created_at DESC, uuid DESC
+LIMIT ?3
+`
+
+type ListAuditEventsParams struct {
+	UserUuid    string
+	WebsiteUuid string
+	PageLimit   int64
+}
+
+func (q *Queries) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEvents, arg.UserUuid, arg.WebsiteUuid, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditEvent
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.UserUuid,
+			&i.WebsiteUuid,
+			&i.Action,
+			&i.Before,
+			&i.After,
+			&i.RequestID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTags = `-- name: ListUserTags :many
+SELECT tag, count(*) AS website_count
+FROM user_website_tags
+JOIN user_websites ON user_websites.user_uuid=user_website_tags.user_uuid and user_websites.website_uuid=user_website_tags.website_uuid
+JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_website_tags.user_uuid=? and websites.status != 'inactive'
+GROUP BY tag
+ORDER BY tag
+`
+
+type ListUserTagsRow struct {
+	Tag          string
+	WebsiteCount int64
+}
+
+func (q *Queries) ListUserTags(ctx context.Context, userUuid string) ([]ListUserTagsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTags, userUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserTagsRow
+	for rows.Next() {
+		var i ListUserTagsRow
+		if err := rows.Scan(&i.Tag, &i.WebsiteCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserUnreadWebsites = `-- name: ListUserUnreadWebsites :many
+SELECT website_uuid, group_name, display_title, title, update_time
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=?1 and websites.status != 'inactive' and update_time > access_time
+ORDER BY update_time DESC, website_uuid ASC
+LIMIT ?2
+`
+
+type ListUserUnreadWebsitesParams struct {
+	UserUuid  sql.NullString
+	PageLimit int64
+}
+
+type ListUserUnreadWebsitesRow struct {
+	WebsiteUuid  sql.NullString
+	GroupName    sql.NullString
+	DisplayTitle string
+	Title        sql.NullString
+	UpdateTime   sql.NullTime
+}
+
+func (q *Queries) ListUserUnreadWebsites(ctx context.Context, arg ListUserUnreadWebsitesParams) ([]ListUserUnreadWebsitesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserUnreadWebsites, arg.UserUuid, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserUnreadWebsitesRow
+	for rows.Next() {
+		var i ListUserUnreadWebsitesRow
+		if err := rows.Scan(
+			&i.WebsiteUuid,
+			&i.GroupName,
+			&i.DisplayTitle,
+			&i.Title,
+			&i.UpdateTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserWebhooks = `-- name: ListUserWebhooks :many
+SELECT uuid, user_uuid, url, group_name, secret, enabled, failure_count, created_at, updated_at FROM webhooks
+WHERE user_uuid=?1
+ORDER BY created_at, uuid
+`
+
+func (q *Queries) ListUserWebhooks(ctx context.Context, userUuid string) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, listUserWebhooks, userUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.UserUuid,
+			&i.Url,
+			&i.GroupName,
+			&i.Secret,
+			&i.Enabled,
+			&i.FailureCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserWebsiteTags = `-- name: ListUserWebsiteTags :many
+SELECT tag FROM user_website_tags
+WHERE user_uuid=? and website_uuid=?
+ORDER BY tag
+`
+
+type ListUserWebsiteTagsParams struct {
+	UserUuid    string
+	WebsiteUuid string
+}
+
+func (q *Queries) ListUserWebsiteTags(ctx context.Context, arg ListUserWebsiteTagsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listUserWebsiteTags, arg.UserUuid, arg.WebsiteUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		items = append(items, tag)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserWebsites = `-- name: ListUserWebsites :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=? and websites.status != 'inactive'
+ORDER BY (update_time > access_time) DESC, update_time DESC, access_time DESC
+`
+
+type ListUserWebsitesRow struct {
+	WebsiteUuid     sql.NullString
+	UserUuid        sql.NullString
+	AccessTime      sql.NullTime
+	GroupName       sql.NullString
+	DisplayTitle    string
+	Note            string
+	LastReadChapter string
+	Uuid            sql.NullString
+	Url             sql.NullString
+	Title           sql.NullString
+	Content         sql.NullString
+	UpdateTime      sql.NullTime
+	Status          string
+}
+
+func (q *Queries) ListUserWebsites(ctx context.Context, userUuid sql.NullString) ([]ListUserWebsitesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserWebsites, userUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserWebsitesRow
+	for rows.Next() {
+		var i ListUserWebsitesRow
+		if err := rows.Scan(
+			&i.WebsiteUuid,
+			&i.UserUuid,
+			&i.AccessTime,
+			&i.GroupName,
+			&i.DisplayTitle,
+			&i.Note,
+			&i.LastReadChapter,
+			&i.Uuid,
+			&i.Url,
+			&i.Title,
+			&i.Content,
+			&i.UpdateTime,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserWebsitesByGroup = `-- name: ListUserWebsitesByGroup :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_uuid=? and group_name=? and websites.status != 'inactive'
+`
+
+type ListUserWebsitesByGroupParams struct {
+	UserUuid  sql.NullString
+	GroupName sql.NullString
+}
+
+type ListUserWebsitesByGroupRow struct {
+	WebsiteUuid     sql.NullString
+	UserUuid        sql.NullString
+	AccessTime      sql.NullTime
+	GroupName       sql.NullString
+	DisplayTitle    string
+	Note            string
+	LastReadChapter string
+	Uuid            sql.NullString
+	Url             sql.NullString
+	Title           sql.NullString
+	Content         sql.NullString
+	UpdateTime      sql.NullTime
+	Status          string
+}
+
+func (q *Queries) ListUserWebsitesByGroup(ctx context.Context, arg ListUserWebsitesByGroupParams) ([]ListUserWebsitesByGroupRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserWebsitesByGroup, arg.UserUuid, arg.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserWebsitesByGroupRow
+	for rows.Next() {
+		var i ListUserWebsitesByGroupRow
+		if err := rows.Scan(
+			&i.WebsiteUuid,
+			&i.UserUuid,
+			&i.AccessTime,
+			&i.GroupName,
+			&i.DisplayTitle,
+			&i.Note,
+			&i.LastReadChapter,
+			&i.Uuid,
+			&i.Url,
+			&i.Title,
+			&i.Content,
+			&i.UpdateTime,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserWebsitesPageByAccessTime = `-- name: ListUserWebsitesPageByAccessTime :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=?1 and websites.status != 'inactive'
+and (CAST(?2 AS TEXT) = '' or group_name=CAST(?2 AS TEXT))
+and (json_array_length(CAST(?3 AS TEXT)) = 0 or json_array_length(CAST(?3 AS TEXT)) = (
+  -- sqlc does not number named arg in subquery, so tags is referred as ?3
+  SELECT count(*) FROM user_website_tags JOIN json_each(CAST(?3 AS TEXT)) filter ON tag=filter.value
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+))
+and (CAST(?4 AS BOOLEAN) = FALSE
+  or access_time < ?5
+  or (access_time = ?5 and user_websites.website_uuid > ?6))
+ORDER BY access_time DESC, website_uuid ASC
+LIMIT ?7
+`
+
+type ListUserWebsitesPageByAccessTimeParams struct {
+	UserUuid   sql.NullString
+	GroupName  string
+	Tags       string
+	HasCursor  bool
+	CursorTime sql.NullTime
+	CursorUuid sql.NullString
+	PageLimit  int64
+}
+
+type ListUserWebsitesPageByAccessTimeRow struct {
+	WebsiteUuid     sql.NullString
+	UserUuid        sql.NullString
+	AccessTime      sql.NullTime
+	GroupName       sql.NullString
+	DisplayTitle    string
+	Note            string
+	LastReadChapter string
+	Uuid            sql.NullString
+	Url             sql.NullString
+	Title           sql.NullString
+	Content         sql.NullString
+	UpdateTime      sql.NullTime
+	Status          string
+}
+
+func (q *Queries) ListUserWebsitesPageByAccessTime(ctx context.Context, arg ListUserWebsitesPageByAccessTimeParams) ([]ListUserWebsitesPageByAccessTimeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserWebsitesPageByAccessTime,
+		arg.UserUuid,
+		arg.GroupName,
+		arg.Tags,
+		arg.HasCursor,
+		arg.CursorTime,
+		arg.CursorUuid,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserWebsitesPageByAccessTimeRow
+	for rows.Next() {
+		var i ListUserWebsitesPageByAccessTimeRow
+		if err := rows.Scan(
+			&i.WebsiteUuid,
+			&i.UserUuid,
+			&i.AccessTime,
+			&i.GroupName,
+			&i.DisplayTitle,
+			&i.Note,
+			&i.LastReadChapter,
+			&i.Uuid,
+			&i.Url,
+			&i.Title,
+			&i.Content,
+			&i.UpdateTime,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserWebsitesPageByTitle = `-- name: ListUserWebsitesPageByTitle :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=?1 and websites.status != 'inactive'
+and (CAST(?2 AS TEXT) = '' or group_name=CAST(?2 AS TEXT))
+and (json_array_length(CAST(?3 AS TEXT)) = 0 or json_array_length(CAST(?3 AS TEXT)) = (
+  -- sqlc does not number named arg in subquery, so tags is referred as ?3
+  SELECT count(*) FROM user_website_tags JOIN json_each(CAST(?3 AS TEXT)) filter ON tag=filter.value
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+))
+and (CAST(?4 AS BOOLEAN) = FALSE
+  or coalesce(nullif(display_title, ''), title, '') > CAST(?5 AS TEXT)
+  or (coalesce(nullif(display_title, ''), title, '') = CAST(?5 AS TEXT) and user_websites.website_uuid > ?6))
+ORDER BY coalesce(nullif(display_title, ''), title, '') ASC, website_uuid ASC
+LIMIT ?7
+`
+
+type ListUserWebsitesPageByTitleParams struct {
+	UserUuid    sql.NullString
+	GroupName   string
+	Tags        string
+	HasCursor   bool
+	CursorTitle string
+	CursorUuid  sql.NullString
+	PageLimit   int64
+}
+
+type ListUserWebsitesPageByTitleRow struct {
+	WebsiteUuid     sql.NullString
+	UserUuid        sql.NullString
+	AccessTime      sql.NullTime
+	GroupName       sql.NullString
+	DisplayTitle    string
+	Note            string
+	LastReadChapter string
+	Uuid            sql.NullString
+	Url             sql.NullString
+	Title           sql.NullString
+	Content         sql.NullString
+	UpdateTime      sql.NullTime
+	Status          string
+}
+
+func (q *Queries) ListUserWebsitesPageByTitle(ctx context.Context, arg ListUserWebsitesPageByTitleParams) ([]ListUserWebsitesPageByTitleRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserWebsitesPageByTitle,
+		arg.UserUuid,
+		arg.GroupName,
+		arg.Tags,
+		arg.HasCursor,
+		arg.CursorTitle,
+		arg.CursorUuid,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserWebsitesPageByTitleRow
+	for rows.Next() {
+		var i ListUserWebsitesPageByTitleRow
+		if err := rows.Scan(
+			&i.WebsiteUuid,
+			&i.UserUuid,
+			&i.AccessTime,
+			&i.GroupName,
+			&i.DisplayTitle,
+			&i.Note,
+			&i.LastReadChapter,
+			&i.Uuid,
+			&i.Url,
+			&i.Title,
+			&i.Content,
+			&i.UpdateTime,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserWebsitesPageByUnread = `-- name: ListUserWebsitesPageByUnread :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=?1 and websites.status != 'inactive'
+and (CAST(?2 AS TEXT) = '' or group_name=CAST(?2 AS TEXT))
+and (json_array_length(CAST(?3 AS TEXT)) = 0 or json_array_length(CAST(?3 AS TEXT)) = (
+  -- sqlc does not number named arg in subquery, so tags is referred as ?3
+  SELECT count(*) FROM user_website_tags JOIN json_each(CAST(?3 AS TEXT)) filter ON tag=filter.value
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+))
+and (CAST(?4 AS BOOLEAN) = FALSE
+  or (update_time > access_time) < CAST(?5 AS BOOLEAN)
+  or ((update_time > access_time) = CAST(?5 AS BOOLEAN) and (
+    update_time < ?6
+    or (update_time = ?6 and user_websites.website_uuid > ?7)
+  )))
+ORDER BY (update_time > access_time) DESC, update_time DESC, website_uuid ASC
+LIMIT ?8
+`
+
+type ListUserWebsitesPageByUnreadParams struct {
+	UserUuid     sql.NullString
+	GroupName    string
+	Tags         string
+	HasCursor    bool
+	CursorUnread bool
+	CursorTime   sql.NullTime
+	CursorUuid   sql.NullString
+	PageLimit    int64
+}
+
+type ListUserWebsitesPageByUnreadRow struct {
+	WebsiteUuid     sql.NullString
+	UserUuid        sql.NullString
+	AccessTime      sql.NullTime
+	GroupName       sql.NullString
+	DisplayTitle    string
+	Note            string
+	LastReadChapter string
+	Uuid            sql.NullString
+	Url             sql.NullString
+	Title           sql.NullString
+	Content         sql.NullString
+	UpdateTime      sql.NullTime
+	Status          string
+}
+
+func (q *Queries) ListUserWebsitesPageByUnread(ctx context.Context, arg ListUserWebsitesPageByUnreadParams) ([]ListUserWebsitesPageByUnreadRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserWebsitesPageByUnread,
+		arg.UserUuid,
+		arg.GroupName,
+		arg.Tags,
+		arg.HasCursor,
+		arg.CursorUnread,
+		arg.CursorTime,
+		arg.CursorUuid,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserWebsitesPageByUnreadRow
+	for rows.Next() {
+		var i ListUserWebsitesPageByUnreadRow
+		if err := rows.Scan(
+			&i.WebsiteUuid,
+			&i.UserUuid,
+			&i.AccessTime,
+			&i.GroupName,
+			&i.DisplayTitle,
+			&i.Note,
+			&i.LastReadChapter,
+			&i.Uuid,
+			&i.Url,
+			&i.Title,
+			&i.Content,
+			&i.UpdateTime,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserWebsitesPageByUpdateTime = `-- name: ListUserWebsitesPageByUpdateTime :many
+SELECT website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter,
+uuid, url, title, content, update_time, status
+FROM user_websites JOIN websites ON user_websites.website_uuid=websites.uuid
+WHERE user_websites.user_uuid=?1 and websites.status != 'inactive'
+and (CAST(?2 AS TEXT) = '' or group_name=CAST(?2 AS TEXT))
+and (json_array_length(CAST(?3 AS TEXT)) = 0 or json_array_length(CAST(?3 AS TEXT)) = (
+  -- sqlc does not number named arg in subquery, so tags is referred as ?3
+  SELECT count(*) FROM user_website_tags JOIN json_each(CAST(?3 AS TEXT)) filter ON tag=filter.value
+  WHERE user_website_tags.user_uuid=user_websites.user_uuid
+  and user_website_tags.website_uuid=user_websites.website_uuid
+))
+and (CAST(?4 AS BOOLEAN) = FALSE
+  or update_time < ?5
+  or (update_time = ?5 and user_websites.website_uuid > ?6))
+ORDER BY update_time DESC, website_uuid ASC
+LIMIT ?7
+`
+
+type ListUserWebsitesPageByUpdateTimeParams struct {
+	UserUuid   sql.NullString
+	GroupName  string
+	Tags       string
+	HasCursor  bool
+	CursorTime sql.NullTime
+	CursorUuid sql.NullString
+	PageLimit  int64
+}
+
+type ListUserWebsitesPageByUpdateTimeRow struct {
+	WebsiteUuid     sql.NullString
+	UserUuid        sql.NullString
+	AccessTime      sql.NullTime
+	GroupName       sql.NullString
+	DisplayTitle    string
+	Note            string
+	LastReadChapter string
+	Uuid            sql.NullString
+	Url             sql.NullString
+	Title           sql.NullString
+	Content         sql.NullString
+	UpdateTime      sql.NullTime
+	Status          string
+}
+
+func (q *Queries) ListUserWebsitesPageByUpdateTime(ctx context.Context, arg ListUserWebsitesPageByUpdateTimeParams) ([]ListUserWebsitesPageByUpdateTimeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserWebsitesPageByUpdateTime,
+		arg.UserUuid,
+		arg.GroupName,
+		arg.Tags,
+		arg.HasCursor,
+		arg.CursorTime,
+		arg.CursorUuid,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserWebsitesPageByUpdateTimeRow
+	for rows.Next() {
+		var i ListUserWebsitesPageByUpdateTimeRow
+		if err := rows.Scan(
+			&i.WebsiteUuid,
+			&i.UserUuid,
+			&i.AccessTime,
+			&i.GroupName,
+			&i.DisplayTitle,
+			&i.Note,
+			&i.LastReadChapter,
+			&i.Uuid,
+			&i.Url,
+			&i.Title,
+			&i.Content,
+			&i.UpdateTime,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT uuid, webhook_uuid, website_uuid, status, payload, attempts, response_status, error, created_at, updated_at FROM webhook_deliveries
+WHERE webhook_uuid=?1
+ORDER BY created_at DESC, uuid DESC
+LIMIT ?2
+`
+
+type ListWebhookDeliveriesParams struct {
+	WebhookUuid string
+	PageLimit   int64
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveries, arg.WebhookUuid, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.WebhookUuid,
+			&i.WebsiteUuid,
+			&i.Status,
+			&i.Payload,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.Error,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebsiteUserUUIDs = `-- name: ListWebsiteUserUUIDs :many
+SELECT user_uuid FROM user_websites
+WHERE website_uuid=?
+ORDER BY user_uuid
+`
+
+func (q *Queries) ListWebsiteUserUUIDs(ctx context.Context, websiteUuid sql.NullString) ([]sql.NullString, error) {
+	rows, err := q.db.QueryContext(ctx, listWebsiteUserUUIDs, websiteUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []sql.NullString
+	for rows.Next() {
+		var user_uuid sql.NullString
+		if err := rows.Scan(&user_uuid); err != nil {
+			return nil, err
+		}
+		items = append(items, user_uuid)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebsiteWebhooks = `-- name: ListWebsiteWebhooks :many
+SELECT webhooks.uuid, webhooks.user_uuid, webhooks.url, webhooks.group_name, webhooks.secret, webhooks.enabled, webhooks.failure_count, webhooks.created_at, webhooks.updated_at FROM webhooks
+JOIN user_websites ON user_websites.user_uuid=webhooks.user_uuid
+WHERE user_websites.website_uuid=?1 and webhooks.enabled
+and (webhooks.group_name='' or webhooks.group_name=user_websites.group_name)
+ORDER BY webhooks.uuid
+`
+
+func (q *Queries) ListWebsiteWebhooks(ctx context.Context, websiteUuid sql.NullString) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, listWebsiteWebhooks, websiteUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.UserUuid,
+			&i.Url,
+			&i.GroupName,
+			&i.Secret,
+			&i.Enabled,
+			&i.FailureCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOrphanedWebsites = `-- name: MarkOrphanedWebsites :execrows
+UPDATE websites SET orphaned_at=?1
+WHERE status='active' and orphaned_at IS NULL and NOT EXISTS (
+  SELECT 1 FROM user_websites WHERE user_websites.website_uuid=websites.uuid
+)
+`
+
+func (q *Queries) MarkOrphanedWebsites(ctx context.Context, orphanedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markOrphanedWebsites, orphanedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const moveUserWebsiteTags = `-- name: MoveUserWebsiteTags :exec
+UPDATE OR IGNORE user_website_tags SET website_uuid=?1
+WHERE website_uuid=?2
+`
+
+type MoveUserWebsiteTagsParams struct {
+	ToUuid   string
+	FromUuid string
+}
+
+func (q *Queries) MoveUserWebsiteTags(ctx context.Context, arg MoveUserWebsiteTagsParams) error {
+	_, err := q.db.ExecContext(ctx, moveUserWebsiteTags, arg.ToUuid, arg.FromUuid)
+	return err
+}
+
+const moveUserWebsites = `-- name: MoveUserWebsites :exec
+UPDATE OR IGNORE user_websites SET website_uuid=?1
+WHERE website_uuid=?2
+`
+
+type MoveUserWebsitesParams struct {
+	ToUuid   sql.NullString
+	FromUuid sql.NullString
+}
+
+func (q *Queries) MoveUserWebsites(ctx context.Context, arg MoveUserWebsitesParams) error {
+	_, err := q.db.ExecContext(ctx, moveUserWebsites, arg.ToUuid, arg.FromUuid)
+	return err
+}
+
+const reactivateOrphanedWebsite = `-- name: ReactivateOrphanedWebsite :exec
+UPDATE websites SET
+status=(CASE WHEN status='inactive' THEN 'active' ELSE status END),
+orphaned_at=NULL
+WHERE uuid=? and orphaned_at IS NOT NULL
+`
+
+func (q *Queries) ReactivateOrphanedWebsite(ctx context.Context, uuid sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, reactivateOrphanedWebsite, uuid)
+	return err
+}
+
+const recordWebsiteMissing = `-- name: RecordWebsiteMissing :one
+UPDATE websites SET
+missing_count=missing_count+1,
+status=(CASE WHEN status='active' and missing_count+1 >= CAST(?1 AS INTEGER) THEN 'gone' ELSE status END)
+WHERE uuid=?2
+RETURNING uuid, url, title, content, update_time, status, redirect_uuid, missing_count, orphaned_at
+`
+
+type RecordWebsiteMissingParams struct {
+	Threshold int64
+	Uuid      sql.NullString
+}
+
+func (q *Queries) RecordWebsiteMissing(ctx context.Context, arg RecordWebsiteMissingParams) (Website, error) {
+	row := q.db.QueryRowContext(ctx, recordWebsiteMissing, arg.Threshold, arg.Uuid)
+	var i Website
+	err := row.Scan(
+		&i.Uuid,
+		&i.Url,
+		&i.Title,
+		&i.Content,
+		&i.UpdateTime,
+		&i.Status,
+		&i.RedirectUuid,
+		&i.MissingCount,
+		&i.OrphanedAt,
+	)
+	return i, err
+}
+
+const redirectWebsite = `-- name: RedirectWebsite :exec
+UPDATE websites SET status='inactive', redirect_uuid=?1, orphaned_at=NULL
+WHERE uuid=?2 or redirect_uuid=?2
+`
+
+type RedirectWebsiteParams struct {
+	ToUuid   sql.NullString
+	FromUuid sql.NullString
+}
+
+func (q *Queries) RedirectWebsite(ctx context.Context, arg RedirectWebsiteParams) error {
+	_, err := q.db.ExecContext(ctx, redirectWebsite, arg.ToUuid, arg.FromUuid)
+	return err
+}
+
+const refreshUserWebsites = `-- name: RefreshUserWebsites :execrows
+UPDATE user_websites SET access_time=?1
+WHERE user_uuid=?2
+and (CAST(?3 AS TEXT) = '' or group_name=CAST(?3 AS TEXT))
+`
+
+type RefreshUserWebsitesParams struct {
+	AccessTime sql.NullTime
+	UserUuid   sql.NullString
+	GroupName  string
+}
+
+func (q *Queries) RefreshUserWebsites(ctx context.Context, arg RefreshUserWebsitesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, refreshUserWebsites, arg.AccessTime, arg.UserUuid, arg.GroupName)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const renameUserGroup = `-- name: RenameUserGroup :exec
+UPDATE user_websites SET group_name=?1
+WHERE user_uuid=?2 and group_name=?3
+`
+
+type RenameUserGroupParams struct {
+	ToGroup   sql.NullString
+	UserUuid  sql.NullString
+	FromGroup sql.NullString
+}
+
+func (q *Queries) RenameUserGroup(ctx context.Context, arg RenameUserGroupParams) error {
+	_, err := q.db.ExecContext(ctx, renameUserGroup, arg.ToGroup, arg.UserUuid, arg.FromGroup)
+	return err
+}
+
+const renameUserTag = `-- name: RenameUserTag :exec
+UPDATE OR IGNORE user_website_tags SET tag=?1
+WHERE user_uuid=?2 and tag=?3
+`
+
+type RenameUserTagParams struct {
+	ToTag    string
+	UserUuid string
+	FromTag  string
+}
+
+func (q *Queries) RenameUserTag(ctx context.Context, arg RenameUserTagParams) error {
+	_, err := q.db.ExecContext(ctx, renameUserTag, arg.ToTag, arg.UserUuid, arg.FromTag)
+	return err
+}
+
+const resetWebsiteMissing = `-- name: ResetWebsiteMissing :one
+UPDATE websites SET
+missing_count=0,
+status=(CASE WHEN status='gone' THEN 'active' ELSE status END)
+WHERE uuid=?
+RETURNING uuid, url, title, content, update_time, status, redirect_uuid, missing_count, orphaned_at
+`
+
+func (q *Queries) ResetWebsiteMissing(ctx context.Context, uuid sql.NullString) (Website, error) {
+	row := q.db.QueryRowContext(ctx, resetWebsiteMissing, uuid)
+	var i Website
+	err := row.Scan(
+		&i.Uuid,
+		&i.Url,
+		&i.Title,
+		&i.Content,
+		&i.UpdateTime,
+		&i.Status,
+		&i.RedirectUuid,
+		&i.MissingCount,
+		&i.OrphanedAt,
+	)
+	return i, err
+}
+
+const saveUserFeedToken = `-- name: SaveUserFeedToken :one
+INSERT INTO user_feed_tokens
+(user_uuid, token, created_at)
+VALUES
+(?, ?, ?)
+ON CONFLICT(user_uuid) DO
+UPDATE SET token=excluded.token, created_at=excluded.created_at
+RETURNING user_uuid, token, created_at
+`
+
+type SaveUserFeedTokenParams struct {
+	UserUuid  string
+	Token     string
+	CreatedAt time.Time
+}
+
+func (q *Queries) SaveUserFeedToken(ctx context.Context, arg SaveUserFeedTokenParams) (UserFeedToken, error) {
+	row := q.db.QueryRowContext(ctx, saveUserFeedToken, arg.UserUuid, arg.Token, arg.CreatedAt)
+	var i UserFeedToken
+	err := row.Scan(&i.UserUuid, &i.Token, &i.CreatedAt)
+	return i, err
+}
+
+const ungroupUserGroup = `-- name: UngroupUserGroup :exec
+UPDATE user_websites SET group_name=(
+  SELECT title FROM websites WHERE websites.uuid=user_websites.website_uuid
+)
+WHERE user_uuid=? and group_name=?
+`
+
+type UngroupUserGroupParams struct {
+	UserUuid  sql.NullString
+	GroupName sql.NullString
+}
+
+func (q *Queries) UngroupUserGroup(ctx context.Context, arg UngroupUserGroupParams) error {
+	_, err := q.db.ExecContext(ctx, ungroupUserGroup, arg.UserUuid, arg.GroupName)
+	return err
+}
+
+const updateJob = `-- name: UpdateJob :one
+UPDATE jobs SET status=?1, result=?2, updated_at=?3
+WHERE uuid=?4
+RETURNING uuid, user_uuid, kind, status, result, created_at, updated_at
+`
+
+type UpdateJobParams struct {
+	Status    string
+	Result    string
+	UpdatedAt time.Time
+	Uuid      string
+}
+
+func (q *Queries) UpdateJob(ctx context.Context, arg UpdateJobParams) (Job, error) {
+	row := q.db.QueryRowContext(ctx, updateJob,
+		arg.Status,
+		arg.Result,
+		arg.UpdatedAt,
+		arg.Uuid,
+	)
+	var i Job
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Kind,
+		&i.Status,
+		&i.Result,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateUserWebsite = `-- name: UpdateUserWebsite :one
+UPDATE user_websites SET
+access_time=?, group_name=?, display_title=?, note=?, last_read_chapter=?
+WHERE user_uuid=? and website_uuid=?
+RETURNING website_uuid, user_uuid, access_time, group_name, display_title, note, last_read_chapter
+`
+
+type UpdateUserWebsiteParams struct {
+	AccessTime      sql.NullTime
+	GroupName       sql.NullString
+	DisplayTitle    string
+	Note            string
+	LastReadChapter string
+	UserUuid        sql.NullString
+	WebsiteUuid     sql.NullString
+}
+
+func (q *Queries) UpdateUserWebsite(ctx context.Context, arg UpdateUserWebsiteParams) (UserWebsite, error) {
+	row := q.db.QueryRowContext(ctx, updateUserWebsite,
+		arg.AccessTime,
+		arg.GroupName,
+		arg.DisplayTitle,
+		arg.Note,
+		arg.LastReadChapter,
+		arg.UserUuid,
+		arg.WebsiteUuid,
+	)
+	var i UserWebsite
+	err := row.Scan(
+		&i.WebsiteUuid,
+		&i.UserUuid,
+		&i.AccessTime,
+		&i.GroupName,
+		&i.DisplayTitle,
+		&i.Note,
+		&i.LastReadChapter,
+	)
+	return i, err
+}
+
+const updateWebhook = `-- name: UpdateWebhook :one
+UPDATE webhooks SET
+url=?1, group_name=?2, enabled=?3,
+failure_count=?4, updated_at=?5
+WHERE uuid=?6
+RETURNING uuid, user_uuid, url, group_name, secret, enabled, failure_count, created_at, updated_at
+`
+
+type UpdateWebhookParams struct {
+	Url          string
+	GroupName    string
+	Enabled      bool
+	FailureCount int64
+	UpdatedAt    time.Time
+	Uuid         string
+}
+
+func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, updateWebhook,
+		arg.Url,
+		arg.GroupName,
+		arg.Enabled,
+		arg.FailureCount,
+		arg.UpdatedAt,
+		arg.Uuid,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Url,
+		&i.GroupName,
+		&i.Secret,
+		&i.Enabled,
+		&i.FailureCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateWebhookDelivery = `-- name: UpdateWebhookDelivery :one
+UPDATE webhook_deliveries SET
+status=?1, attempts=?2, response_status=?3,
+error=?4, updated_at=?5
+WHERE uuid=?6
+RETURNING uuid, webhook_uuid, website_uuid, status, payload, attempts, response_status, error, created_at, updated_at
+`
+
+type UpdateWebhookDeliveryParams struct {
+	Status         string
+	Attempts       int64
+	ResponseStatus int64
+	Error          string
+	UpdatedAt      time.Time
+	Uuid           string
+}
+
+func (q *Queries) UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, updateWebhookDelivery,
+		arg.Status,
+		arg.Attempts,
+		arg.ResponseStatus,
+		arg.Error,
+		arg.UpdatedAt,
+		arg.Uuid,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.Uuid,
+		&i.WebhookUuid,
+		&i.WebsiteUuid,
+		&i.Status,
+		&i.Payload,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateWebsite = `-- name: UpdateWebsite :one
+UPDATE websites SET
+url=?, title=?, content=?, update_time=?
+WHERE uuid=?
+RETURNING uuid, url, title, content, update_time, status, redirect_uuid, missing_count, orphaned_at
+`
+
+type UpdateWebsiteParams struct {
+	Url        sql.NullString
+	Title      sql.NullString
+	Content    sql.NullString
+	UpdateTime sql.NullTime
+	Uuid       sql.NullString
+}
+
+func (q *Queries) UpdateWebsite(ctx context.Context, arg UpdateWebsiteParams) (Website, error) {
+	row := q.db.QueryRowContext(ctx, updateWebsite,
+		arg.Url,
+		arg.Title,
+		arg.Content,
+		arg.UpdateTime,
+		arg.Uuid,
+	)
+	var i Website
+	err := row.Scan(
+		&i.Uuid,
+		&i.Url,
+		&i.Title,
+		&i.Content,
+		&i.UpdateTime,
+		&i.Status,
+		&i.RedirectUuid,
+		&i.MissingCount,
+		&i.OrphanedAt,
+	)
+	return i, err
+}
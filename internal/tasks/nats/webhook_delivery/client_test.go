@@ -0,0 +1,92 @@
+package webhookdelivery
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedIP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ip     string
+		expect bool
+	}{
+		{ip: "93.184.216.34", expect: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", expect: true},
+		{ip: "127.0.0.1", expect: false},
+		{ip: "::1", expect: false},
+		{ip: "10.0.0.1", expect: false},
+		{ip: "172.16.0.1", expect: false},
+		{ip: "192.168.1.1", expect: false},
+		{ip: "169.254.169.254", expect: false},
+		{ip: "100.64.0.1", expect: false},
+		{ip: "0.0.0.0", expect: false},
+		{ip: "fd00::1", expect: false},
+		{ip: "fe80::1", expect: false},
+		{ip: "::ffff:127.0.0.1", expect: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ip, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, AllowedIP(net.ParseIP(test.ip)))
+		})
+	}
+}
+
+func TestAllowedHost(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		host   string
+		expect bool
+	}{
+		{host: "example.com", expect: true},
+		{host: "93.184.216.34", expect: true},
+		{host: "localhost", expect: false},
+		{host: "LOCALHOST.", expect: false},
+		{host: "api.localhost", expect: false},
+		{host: "127.0.0.1", expect: false},
+		{host: "169.254.169.254", expect: false},
+		{host: "::1", expect: false},
+		{host: "", expect: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, AllowedHost(test.host))
+		})
+	}
+}
+
+func Test_newClient(t *testing.T) {
+	t.Parallel()
+
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requested = true
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := newClient(time.Second).Post(server.URL, "application/json", nil)
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+	assert.False(t, requested)
+}
+
+func Test_newClient_redirect(t *testing.T) {
+	t.Parallel()
+
+	client := newClient(time.Second)
+	req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+
+	assert.ErrorIs(t, client.CheckRedirect(req, []*http.Request{req}), http.ErrUseLastResponse)
+}
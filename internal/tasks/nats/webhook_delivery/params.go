@@ -0,0 +1,53 @@
+package webhookdelivery
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const EventWebsiteUpdated = "website_updated"
+
+// WebhookDeliveryParams refers to the delivery to be sent, the payload is
+// kept in the delivery log instead of the message
+type WebhookDeliveryParams struct {
+	DeliveryUUID string `json:"delivery_uuid"`
+	WebhookUUID  string `json:"webhook_uuid"`
+	UserUUID     string `json:"user_uuid"`
+}
+
+type PayloadWebsite struct {
+	UUID       string    `json:"uuid"`
+	URL        string    `json:"url"`
+	Title      string    `json:"title"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+// Payload is the body posted to webhook url
+type Payload struct {
+	Event        string         `json:"event"`
+	DeliveryUUID string         `json:"delivery_uuid"`
+	WebhookUUID  string         `json:"webhook_uuid"`
+	Website      PayloadWebsite `json:"website"`
+}
+
+func ParamsFromData(ctx context.Context, data []byte) (context.Context, *WebhookDeliveryParams, error) {
+	params := new(WebhookDeliveryParams)
+	if jsonErr := json.Unmarshal(data, params); jsonErr != nil {
+		return ctx, nil, jsonErr
+	}
+
+	ctx = log.With().
+		Str("delivery_uuid", params.DeliveryUUID).
+		Str("webhook_uuid", params.WebhookUUID).
+		Str("user_uuid", params.UserUUID).
+		Logger().WithContext(ctx)
+
+	return ctx, params, nil
+}
+
+func (params *WebhookDeliveryParams) ToData() ([]byte, error) {
+	return json.Marshal(params)
+}
@@ -0,0 +1,427 @@
+package webhookdelivery
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	websiteevents "github.com/htchan/WebHistory/internal/tasks/nats/website_events"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrWebhookDisabled  = errors.New("webhook disabled")
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+)
+
+// WebhookDeliveryTask turns the website changes into deliveries of matching
+// webhooks, and posts the signed payload of each delivery with retries
+type WebhookDeliveryTask struct {
+	nc     *nats.Conn
+	rpo    repository.Repository
+	client *http.Client
+	conf   *config.WebhookConfig
+}
+
+func getTracer() trace.Tracer {
+	return otel.Tracer("htchan/WebHistory/webhook-delivery")
+}
+
+func NewTask(nc *nats.Conn, rpo repository.Repository, conf *config.WebhookConfig) *WebhookDeliveryTask {
+	return &WebhookDeliveryTask{
+		nc:     nc,
+		rpo:    rpo,
+		client: newClient(conf.Timeout),
+		conf:   conf,
+	}
+}
+
+func (task *WebhookDeliveryTask) subject() string {
+	return "web_history.webhooks.deliver"
+}
+
+// Publish waits for the delivery to be stored in stream. Delivery uuid is
+// used as message id, so delivery published again is dropped by the stream.
+func (task *WebhookDeliveryTask) Publish(ctx context.Context, params *WebhookDeliveryParams) error {
+	data, err := params.ToData()
+	if err != nil {
+		return err
+	}
+
+	js, err := jetstream.New(task.nc)
+	if err != nil {
+		return fmt.Errorf("init jetstream fail: %v", err)
+	}
+
+	_, err = js.Publish(ctx, task.subject(), data, jetstream.WithMsgID(params.DeliveryUUID))
+
+	return err
+}
+
+// SubscribeChanges creates the deliveries of the website changes published
+// by website update tasks
+func (task *WebhookDeliveryTask) SubscribeChanges(ctx context.Context) (jetstream.ConsumeContext, error) {
+	stream, err := websiteevents.CreateStream(ctx, task.nc)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ReplaceAll(task.subject(), ".", "-") + "-changes"
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Name:          name,
+		Durable:       name,
+		FilterSubject: websiteevents.Subject,
+		// changes before the first start are not delivered to any webhook
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       time.Minute,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create / update consumer fail: %v", err)
+	}
+
+	return consumer.Consume(task.changeHandler)
+}
+
+func (task *WebhookDeliveryTask) Subscribe(ctx context.Context) (jetstream.ConsumeContext, error) {
+	js, err := jetstream.New(task.nc)
+	if err != nil {
+		return nil, fmt.Errorf("init jetstream fail: %v", err)
+	}
+
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     strings.ReplaceAll(task.subject(), ".", "-"),
+		Subjects: []string{task.subject()},
+		MaxAge:   time.Hour * 24 * 7,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create / update stream fail: %v", err)
+	}
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Name:       strings.ReplaceAll(task.subject(), ".", "-"),
+		Durable:    strings.ReplaceAll(task.subject(), ".", "-"),
+		AckPolicy:  jetstream.AckExplicitPolicy,
+		AckWait:    task.conf.Timeout + time.Minute,
+		MaxDeliver: task.conf.MaxAttempts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create / update consumer fail: %v", err)
+	}
+
+	return consumer.Consume(task.handler)
+}
+
+func (task *WebhookDeliveryTask) changeHandler(msg jetstream.Msg) {
+	ctx := log.With().
+		Str("task", "webhook-delivery-changes").
+		Logger().WithContext(context.Background())
+
+	var change websiteevents.WebsiteChange
+	if err := json.Unmarshal(msg.Data(), &change); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("data", string(msg.Data())).
+			Msg("failed to parse message body")
+		task.ack(ctx, msg)
+
+		return
+	}
+
+	// change is redelivered if any delivery is not created or published,
+	// the created deliveries are reused and not sent again
+	paramsList, err := task.CreateDeliveries(ctx, &change)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("website_uuid", change.WebsiteUUID).Msg("create webhook deliveries failed")
+		task.nak(ctx, msg)
+
+		return
+	}
+
+	for _, params := range paramsList {
+		if err := task.Publish(ctx, &params); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).
+				Str("delivery_uuid", params.DeliveryUUID).
+				Msg("publish webhook delivery failed")
+			task.nak(ctx, msg)
+
+			return
+		}
+	}
+
+	task.ack(ctx, msg)
+}
+
+// deliveryUUID identifies the delivery of a change to webhook, so the
+// delivery is not created again when the change is redelivered
+func deliveryUUID(webhookUUID string, change *websiteevents.WebsiteChange) string {
+	name := webhookUUID + "/" + change.WebsiteUUID + "/" + strconv.FormatInt(change.UpdateTime.UnixNano(), 10)
+
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(name)).String()
+}
+
+// CreateDeliveries logs a pending delivery for every webhook receiving the
+// change, and returns the params to send the pending ones. Deliveries are
+// created together, none of them is created if it returns error.
+func (task *WebhookDeliveryTask) CreateDeliveries(ctx context.Context, change *websiteevents.WebsiteChange) ([]WebhookDeliveryParams, error) {
+	ctx, span := getTracer().Start(ctx, "Create Webhook Deliveries", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+
+	span.SetAttributes(attribute.String("website_uuid", change.WebsiteUUID))
+
+	var paramsList []WebhookDeliveryParams
+	err := task.rpo.WithTx(ctx, func(rpo repository.Repository) error {
+		paramsList = nil
+
+		webhooks, err := rpo.FindWebsiteWebhooks(ctx, change.WebsiteUUID)
+		if err != nil {
+			return fmt.Errorf("find website webhooks fail: %w", err)
+		}
+
+		for _, webhook := range webhooks {
+			params := WebhookDeliveryParams{
+				DeliveryUUID: deliveryUUID(webhook.UUID, change),
+				WebhookUUID:  webhook.UUID,
+				UserUUID:     webhook.UserUUID,
+			}
+
+			stored, err := rpo.FindWebhookDelivery(ctx, params.DeliveryUUID)
+			if err == nil {
+				if stored.Status == model.WebhookDeliveryStatusPending {
+					paramsList = append(paramsList, params)
+				}
+
+				continue
+			}
+
+			if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			delivery := model.NewWebhookDelivery(webhook.UUID, change.WebsiteUUID, "")
+			delivery.UUID = params.DeliveryUUID
+
+			payload, err := json.Marshal(Payload{
+				Event:        EventWebsiteUpdated,
+				DeliveryUUID: delivery.UUID,
+				WebhookUUID:  webhook.UUID,
+				Website: PayloadWebsite{
+					UUID:       change.WebsiteUUID,
+					URL:        change.URL,
+					Title:      change.Title,
+					UpdateTime: change.UpdateTime,
+				},
+			})
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Str("webhook_uuid", webhook.UUID).Msg("marshal webhook payload failed")
+
+				continue
+			}
+
+			delivery.Payload = string(payload)
+			if err := rpo.CreateWebhookDelivery(ctx, &delivery); err != nil {
+				return err
+			}
+
+			paramsList = append(paramsList, params)
+		}
+
+		return nil
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return nil, err
+	}
+
+	return paramsList, nil
+}
+
+// retryDelay doubles the retry interval after every failed attempt
+func (task *WebhookDeliveryTask) retryDelay(attempts int) time.Duration {
+	return task.conf.RetryInterval << (attempts - 1)
+}
+
+func (task *WebhookDeliveryTask) handler(msg jetstream.Msg) {
+	ctx := log.With().
+		Str("task", "webhook-delivery").
+		Logger().WithContext(context.Background())
+
+	ctx, params, err := ParamsFromData(ctx, msg.Data())
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("data", string(msg.Data())).
+			Msg("failed to parse message body")
+		task.ack(ctx, msg)
+
+		return
+	}
+
+	ctx, span := getTracer().Start(ctx, "Webhook Delivery", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("delivery_uuid", params.DeliveryUUID),
+		attribute.String("webhook_uuid", params.WebhookUUID),
+	)
+
+	delivery, err := task.rpo.FindWebhookDelivery(ctx, params.DeliveryUUID)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("find webhook delivery failed")
+		task.ack(ctx, msg)
+
+		return
+	}
+
+	// delivery published again by a redelivered change is sent already
+	if delivery.Status == model.WebhookDeliveryStatusSucceeded || delivery.Status == model.WebhookDeliveryStatusFailed {
+		task.ack(ctx, msg)
+
+		return
+	}
+
+	webhook, err := task.rpo.FindWebhook(ctx, params.UserUUID, delivery.WebhookUUID)
+	if err != nil || !webhook.Enabled {
+		deliveryErr := ErrWebhookDisabled
+		if err != nil {
+			deliveryErr = ErrWebhookNotFound
+		}
+
+		delivery.Status, delivery.Error = model.WebhookDeliveryStatusFailed, deliveryErr.Error()
+		task.updateDelivery(ctx, delivery)
+		task.ack(ctx, msg)
+
+		return
+	}
+
+	delivery.Attempts = 1
+	if meta, metaErr := msg.Metadata(); metaErr == nil {
+		delivery.Attempts = int(meta.NumDelivered)
+	}
+
+	delivery.ResponseStatus, err = task.send(ctx, webhook, delivery)
+	if err == nil {
+		delivery.Status, delivery.Error = model.WebhookDeliveryStatusSucceeded, ""
+		task.updateDelivery(ctx, delivery)
+
+		if webhook.FailureCount > 0 {
+			webhook.FailureCount = 0
+			task.updateWebhook(ctx, webhook)
+		}
+
+		task.ack(ctx, msg)
+
+		return
+	}
+
+	span.RecordError(err)
+	zerolog.Ctx(ctx).Warn().Err(err).Int("attempts", delivery.Attempts).Msg("send webhook failed")
+
+	delivery.Error = err.Error()
+	if delivery.Attempts < task.conf.MaxAttempts {
+		delivery.Status = model.WebhookDeliveryStatusRetrying
+		task.updateDelivery(ctx, delivery)
+
+		if nakErr := msg.NakWithDelay(task.retryDelay(delivery.Attempts)); nakErr != nil {
+			zerolog.Ctx(ctx).Error().Err(nakErr).Msg("nak failed")
+		}
+
+		return
+	}
+
+	delivery.Status = model.WebhookDeliveryStatusFailed
+	task.updateDelivery(ctx, delivery)
+
+	webhook.FailureCount++
+	if webhook.FailureCount >= task.conf.DisableThreshold {
+		webhook.Enabled = false
+		zerolog.Ctx(ctx).Warn().Int("failure_count", webhook.FailureCount).Msg("disable webhook")
+	}
+	task.updateWebhook(ctx, webhook)
+
+	task.ack(ctx, msg)
+}
+
+// send posts the payload of delivery to webhook, and returns the response
+// status. Response other than 2xx is treated as failure.
+func (task *WebhookDeliveryTask) send(ctx context.Context, webhook *model.Webhook, delivery *model.WebhookDelivery) (int, error) {
+	ctx, span := getTracer().Start(ctx, "Send Webhook")
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-WebHistory-Event", EventWebsiteUpdated)
+	req.Header.Set("X-WebHistory-Delivery", delivery.UUID)
+	timestamp := time.Now().Unix()
+	req.Header.Set("X-WebHistory-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-WebHistory-Signature", "sha256="+webhook.Sign(timestamp, []byte(delivery.Payload)))
+
+	resp, err := task.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drain body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	span.SetAttributes(attribute.Int("response_status", resp.StatusCode))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (task *WebhookDeliveryTask) updateDelivery(ctx context.Context, delivery *model.WebhookDelivery) {
+	delivery.UpdatedAt = time.Now().UTC()
+	if err := task.rpo.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("update webhook delivery failed")
+	}
+}
+
+func (task *WebhookDeliveryTask) updateWebhook(ctx context.Context, webhook *model.Webhook) {
+	webhook.UpdatedAt = time.Now().UTC()
+	if err := task.rpo.UpdateWebhook(ctx, webhook); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("update webhook failed")
+	}
+}
+
+func (task *WebhookDeliveryTask) ack(ctx context.Context, msg jetstream.Msg) {
+	if ackErr := msg.Ack(); ackErr != nil {
+		zerolog.Ctx(ctx).Error().Err(ackErr).Msg("ack failed")
+	}
+}
+
+func (task *WebhookDeliveryTask) nak(ctx context.Context, msg jetstream.Msg) {
+	if nakErr := msg.NakWithDelay(task.conf.RetryInterval); nakErr != nil {
+		zerolog.Ctx(ctx).Error().Err(nakErr).Msg("nak failed")
+	}
+}
@@ -0,0 +1,286 @@
+package webhookdelivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	mocknats "github.com/htchan/WebHistory/internal/mock/nats"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/htchan/WebHistory/internal/repository/memory"
+	websiteevents "github.com/htchan/WebHistory/internal/tasks/nats/website_events"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewTask(t *testing.T) {
+	conf := &config.WebhookConfig{Timeout: time.Second}
+
+	task := NewTask(nil, nil, conf)
+	assert.Equal(t, time.Second, task.client.Timeout)
+	assert.NotNil(t, task.client.CheckRedirect)
+	assert.Equal(t, conf, task.conf)
+}
+
+func TestWebhookDeliveryTask_subject(t *testing.T) {
+	assert.Equal(t, "web_history.webhooks.deliver", (&WebhookDeliveryTask{}).subject())
+}
+
+func TestWebhookDeliveryTask_retryDelay(t *testing.T) {
+	task := NewTask(nil, nil, &config.WebhookConfig{RetryInterval: time.Second})
+
+	assert.Equal(t, time.Second, task.retryDelay(1))
+	assert.Equal(t, 2*time.Second, task.retryDelay(2))
+	assert.Equal(t, 8*time.Second, task.retryDelay(4))
+}
+
+func TestWebhookDeliveryTask_CreateDeliveries(t *testing.T) {
+	t.Parallel()
+
+	updateTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rpo := memory.NewRepo(&config.WebsiteConfig{})
+
+	web := model.NewWebsite("http://example.com", &config.WebsiteConfig{})
+	require.NoError(t, rpo.CreateWebsite(context.Background(), &web))
+	userWeb := model.NewUserWebsite(web, "user")
+	userWeb.GroupName = "group"
+	require.NoError(t, rpo.CreateUserWebsite(context.Background(), &userWeb))
+
+	all := model.NewWebhook("user", "http://example.com/all", "")
+	require.NoError(t, rpo.CreateWebhook(context.Background(), &all))
+	other := model.NewWebhook("user", "http://example.com/other", "other group")
+	require.NoError(t, rpo.CreateWebhook(context.Background(), &other))
+
+	task := NewTask(nil, rpo, &config.WebhookConfig{})
+	change := &websiteevents.WebsiteChange{
+		WebsiteUUID: web.UUID,
+		URL:         web.URL,
+		Title:       "title",
+		UpdateTime:  updateTime,
+		UserUUIDs:   []string{"user"},
+	}
+	paramsList, err := task.CreateDeliveries(context.Background(), change)
+	require.NoError(t, err)
+
+	require.Len(t, paramsList, 1)
+	assert.Equal(t, all.UUID, paramsList[0].WebhookUUID)
+	assert.Equal(t, "user", paramsList[0].UserUUID)
+
+	delivery, err := rpo.FindWebhookDelivery(context.Background(), paramsList[0].DeliveryUUID)
+	require.NoError(t, err)
+	assert.Equal(t, model.WebhookDeliveryStatusPending, delivery.Status)
+	assert.Equal(t, web.UUID, delivery.WebsiteUUID)
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal([]byte(delivery.Payload), &payload))
+	assert.Equal(t, Payload{
+		Event:        EventWebsiteUpdated,
+		DeliveryUUID: delivery.UUID,
+		WebhookUUID:  all.UUID,
+		Website: PayloadWebsite{
+			UUID:       web.UUID,
+			URL:        web.URL,
+			Title:      "title",
+			UpdateTime: updateTime,
+		},
+	}, payload)
+
+	// redelivered change reuses the pending delivery
+	redelivered, err := task.CreateDeliveries(context.Background(), change)
+	require.NoError(t, err)
+	assert.Equal(t, paramsList, redelivered)
+
+	deliveries, err := rpo.FindWebhookDeliveries(context.Background(), all.UUID, 10)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+
+	// delivery which is sent is not published again
+	delivery.Status = model.WebhookDeliveryStatusSucceeded
+	require.NoError(t, rpo.UpdateWebhookDelivery(context.Background(), delivery))
+
+	redelivered, err = task.CreateDeliveries(context.Background(), change)
+	require.NoError(t, err)
+	assert.Empty(t, redelivered)
+}
+
+func TestWebhookDeliveryTask_changeHandler(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	rpo := mockrepo.NewMockRepository(ctrl)
+	rpo.EXPECT().WithTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, fn func(repository.Repository) error) error {
+			return fn(rpo)
+		},
+	)
+	rpo.EXPECT().FindWebsiteWebhooks(gomock.Any(), "web_uuid").Return(nil, errors.New("some error"))
+
+	// change is redelivered if deliveries cannot be created
+	msg := mocknats.NewMockNatsMsg(ctrl)
+	msg.EXPECT().Data().Return([]byte(`{"website_uuid":"web_uuid"}`)).AnyTimes()
+	msg.EXPECT().NakWithDelay(time.Second).Return(nil).Times(1)
+
+	task := NewTask(nil, rpo, &config.WebhookConfig{RetryInterval: time.Second})
+	task.changeHandler(jetstream.Msg(msg))
+}
+
+func TestWebhookDeliveryTask_handler(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.WebhookConfig{
+		Timeout:          time.Second,
+		MaxAttempts:      3,
+		RetryInterval:    time.Second,
+		DisableThreshold: 2,
+	}
+
+	tests := []struct {
+		name               string
+		statusCode         int
+		deliveryStatus     string
+		disabled           bool
+		failureCount       int
+		numDelivered       uint64
+		expectRetryDelay   time.Duration
+		expectRequest      bool
+		expectDelivery     model.WebhookDelivery
+		expectEnabled      bool
+		expectFailureCount int
+	}{
+		{
+			name:               "happy flow",
+			statusCode:         http.StatusNoContent,
+			failureCount:       1,
+			numDelivered:       1,
+			expectRequest:      true,
+			expectDelivery:     model.WebhookDelivery{Status: model.WebhookDeliveryStatusSucceeded, Attempts: 1, ResponseStatus: http.StatusNoContent},
+			expectEnabled:      true,
+			expectFailureCount: 0,
+		},
+		{
+			name:               "retry failed delivery with backoff",
+			statusCode:         http.StatusInternalServerError,
+			numDelivered:       2,
+			expectRetryDelay:   2 * time.Second,
+			expectRequest:      true,
+			expectDelivery:     model.WebhookDelivery{Status: model.WebhookDeliveryStatusRetrying, Attempts: 2, ResponseStatus: http.StatusInternalServerError, Error: "unexpected response status: 500"},
+			expectEnabled:      true,
+			expectFailureCount: 0,
+		},
+		{
+			name:               "fail delivery after max attempts",
+			statusCode:         http.StatusInternalServerError,
+			numDelivered:       3,
+			expectRequest:      true,
+			expectDelivery:     model.WebhookDelivery{Status: model.WebhookDeliveryStatusFailed, Attempts: 3, ResponseStatus: http.StatusInternalServerError, Error: "unexpected response status: 500"},
+			expectEnabled:      true,
+			expectFailureCount: 1,
+		},
+		{
+			name:               "disable webhook after repeated failures",
+			statusCode:         http.StatusInternalServerError,
+			failureCount:       1,
+			numDelivered:       3,
+			expectRequest:      true,
+			expectDelivery:     model.WebhookDelivery{Status: model.WebhookDeliveryStatusFailed, Attempts: 3, ResponseStatus: http.StatusInternalServerError, Error: "unexpected response status: 500"},
+			expectEnabled:      false,
+			expectFailureCount: 2,
+		},
+		{
+			name:               "skip delivery sent already",
+			deliveryStatus:     model.WebhookDeliveryStatusSucceeded,
+			numDelivered:       1,
+			expectDelivery:     model.WebhookDelivery{Status: model.WebhookDeliveryStatusSucceeded},
+			expectEnabled:      true,
+			expectFailureCount: 0,
+		},
+		{
+			name:               "skip disabled webhook",
+			disabled:           true,
+			numDelivered:       1,
+			expectDelivery:     model.WebhookDelivery{Status: model.WebhookDeliveryStatusFailed, Error: "webhook disabled"},
+			expectEnabled:      false,
+			expectFailureCount: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			requested := make(chan *http.Request, 1)
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				body, _ = io.ReadAll(req.Body)
+				requested <- req
+				res.WriteHeader(test.statusCode)
+			}))
+			t.Cleanup(server.Close)
+
+			ctrl := gomock.NewController(t)
+			rpo := memory.NewRepo(&config.WebsiteConfig{})
+
+			webhook := model.NewWebhook("user", server.URL, "")
+			webhook.Enabled, webhook.FailureCount = !test.disabled, test.failureCount
+			require.NoError(t, rpo.CreateWebhook(context.Background(), &webhook))
+
+			delivery := model.NewWebhookDelivery(webhook.UUID, "web_uuid", `{"event":"website_updated"}`)
+			if test.deliveryStatus != "" {
+				delivery.Status = test.deliveryStatus
+			}
+			require.NoError(t, rpo.CreateWebhookDelivery(context.Background(), &delivery))
+
+			msg := mocknats.NewMockNatsMsg(ctrl)
+			msg.EXPECT().Data().Return([]byte(`{"delivery_uuid":"` + delivery.UUID + `","webhook_uuid":"` + webhook.UUID + `","user_uuid":"user"}`)).AnyTimes()
+			msg.EXPECT().Metadata().Return(&jetstream.MsgMetadata{NumDelivered: test.numDelivered}, nil).AnyTimes()
+			if test.expectRetryDelay > 0 {
+				msg.EXPECT().NakWithDelay(test.expectRetryDelay).Return(nil).Times(1)
+			} else {
+				msg.EXPECT().Ack().Return(nil).Times(1)
+			}
+
+			task := NewTask(nil, rpo, conf)
+			// test server listens on loopback address, which is refused by default
+			task.client = server.Client()
+			task.handler(jetstream.Msg(msg))
+
+			if test.expectRequest {
+				req := <-requested
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+				assert.Equal(t, "website_updated", req.Header.Get("X-WebHistory-Event"))
+				assert.Equal(t, delivery.UUID, req.Header.Get("X-WebHistory-Delivery"))
+				timestamp, err := strconv.ParseInt(req.Header.Get("X-WebHistory-Timestamp"), 10, 64)
+				require.NoError(t, err)
+				assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+				assert.Equal(t, "sha256="+webhook.Sign(timestamp, []byte(delivery.Payload)), req.Header.Get("X-WebHistory-Signature"))
+				assert.Equal(t, delivery.Payload, string(body))
+			} else {
+				assert.Empty(t, requested)
+			}
+
+			gotDelivery, err := rpo.FindWebhookDelivery(context.Background(), delivery.UUID)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectDelivery.Status, gotDelivery.Status)
+			assert.Equal(t, test.expectDelivery.Attempts, gotDelivery.Attempts)
+			assert.Equal(t, test.expectDelivery.ResponseStatus, gotDelivery.ResponseStatus)
+			assert.Equal(t, test.expectDelivery.Error, gotDelivery.Error)
+
+			gotWebhook, err := rpo.FindWebhook(context.Background(), "user", webhook.UUID)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectEnabled, gotWebhook.Enabled)
+			assert.Equal(t, test.expectFailureCount, gotWebhook.FailureCount)
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"strings"
 	"time"
 
@@ -20,10 +22,21 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// BatchPageSize is the number of websites loaded from db at a time, a
+// checkpoint is saved after publishing each page
+const BatchPageSize = 100
+
 type WebsiteBatchUpdateTask struct {
 	nc                 *nats.Conn
 	websiteUpdateTasks websiteupdate.WebsiteUpdateTasks
 	rpo                repository.Repository
+	checkpoints        jetstream.KeyValue
+}
+
+// batchUpdateParams optionally limits the batch update to websites of a host,
+// message which is not a json object updates websites of all hosts
+type batchUpdateParams struct {
+	Host string `json:"host"`
 }
 
 func getTracer() trace.Tracer {
@@ -61,6 +74,14 @@ func (task *WebsiteBatchUpdateTask) Subscribe(ctx context.Context) (jetstream.Co
 		return nil, err
 	}
 
+	task.checkpoints, err = js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket: strings.ReplaceAll(task.subject(), ".", "-") + "-checkpoints",
+		TTL:    time.Hour * 24 * 7,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
 		Name:      strings.ReplaceAll(task.subject(), ".", "-"),
 		Durable:   strings.ReplaceAll(task.subject(), ".", "-"),
@@ -75,9 +96,11 @@ func (task *WebsiteBatchUpdateTask) Subscribe(ctx context.Context) (jetstream.Co
 }
 
 func (task *WebsiteBatchUpdateTask) handler(msg jetstream.Msg) {
+	data := msg.Data()
+	checkpointKey := hashData(data)
 	ctx := log.With().
 		Str("task", "website-batch-update").
-		Str("task_id", hashData(msg.Data())).
+		Str("task_id", checkpointKey).
 		Logger().WithContext(context.Background())
 
 	defer func() {
@@ -90,27 +113,90 @@ func (task *WebsiteBatchUpdateTask) handler(msg jetstream.Msg) {
 	ctx, span := getTracer().Start(ctx, "Website Batch Update", trace.WithSpanKind(trace.SpanKindConsumer))
 	defer span.End()
 
-	// load all webstes from db
-	_, dbSpan := getTracer().Start(ctx, "Load Websites From DB")
-	defer dbSpan.End()
+	var params batchUpdateParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		params = batchUpdateParams{}
+	}
+
+	// resume from the checkpoint if the same batch was interrupted before
+	query := repository.WebsitesQuery{
+		Host:      params.Host,
+		AfterUUID: task.loadCheckpoint(ctx, checkpointKey),
+		Limit:     BatchPageSize,
+	}
+	if query.AfterUUID != "" {
+		zerolog.Ctx(ctx).Info().
+			Str("after_uuid", query.AfterUUID).
+			Msg("resume batch update from checkpoint")
+	}
+
+	// publish update job for websites page by page
+	iterateCtx, iterateSpan := getTracer().Start(ctx, "Iterate Websites")
+	defer iterateSpan.End()
+
+	iterateSpan.SetAttributes(
+		attribute.String("host", query.Host),
+		attribute.String("after_uuid", query.AfterUUID),
+	)
+
+	count := 0
+	for web, err := range repository.IterateWebsites(iterateCtx, task.rpo, query) {
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("load website from db failed")
+			iterateSpan.SetStatus(codes.Error, err.Error())
+			iterateSpan.RecordError(err)
+
+			return
+		}
+
+		task.publishWebsiteUpdateTask(iterateCtx, &web)
+
+		count++
+		if count%BatchPageSize == 0 {
+			task.saveCheckpoint(ctx, checkpointKey, web.UUID)
+		}
+	}
+
+	iterateSpan.SetAttributes(attribute.Int("website_count", count))
+	task.deleteCheckpoint(ctx, checkpointKey)
+}
+
+// loadCheckpoint returns the last published website uuid of the batch
+func (task *WebsiteBatchUpdateTask) loadCheckpoint(ctx context.Context, key string) string {
+	if task.checkpoints == nil {
+		return ""
+	}
 
-	websites, err := task.rpo.FindWebsites(ctx)
+	entry, err := task.checkpoints.Get(ctx, key)
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("load website from db failed")
-		dbSpan.SetStatus(codes.Error, err.Error())
-		dbSpan.RecordError(err)
+		if !errors.Is(err, jetstream.ErrKeyNotFound) {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("load checkpoint failed")
+		}
+
+		return ""
+	}
+
+	return string(entry.Value())
+}
 
+func (task *WebsiteBatchUpdateTask) saveCheckpoint(ctx context.Context, key, websiteUUID string) {
+	if task.checkpoints == nil {
 		return
 	}
 
-	dbSpan.End()
+	if _, err := task.checkpoints.PutString(ctx, key, websiteUUID); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("save checkpoint failed")
+	}
+}
+
+func (task *WebsiteBatchUpdateTask) deleteCheckpoint(ctx context.Context, key string) {
+	if task.checkpoints == nil {
+		return
+	}
 
-	// publish update job for all website
-	iterateCtx, iterateSpan := getTracer().Start(ctx, "Iterate Websites")
-	for _, web := range websites {
-		task.publishWebsiteUpdateTask(iterateCtx, &web)
+	if err := task.checkpoints.Purge(ctx, key); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("delete checkpoint failed")
 	}
-	iterateSpan.End()
 }
 
 func (task *WebsiteBatchUpdateTask) publishWebsiteUpdateTask(ctx context.Context, web *model.Website) {
@@ -137,7 +137,7 @@ func TestWebsiteBatchUpdateTask_handler(t *testing.T) {
 			},
 			getRpo: func(ctrl *gomock.Controller) repository.Repository {
 				rpo := mockrepo.NewMockRepository(ctrl)
-				rpo.EXPECT().FindWebsites(gomock.Any()).Return(
+				rpo.EXPECT().FindWebsitesPage(gomock.Any(), repository.WebsitesQuery{Limit: BatchPageSize}).Return(
 					[]model.Website{web},
 					nil,
 				).Times(1)
@@ -178,7 +178,7 @@ func TestWebsiteBatchUpdateTask_handler(t *testing.T) {
 			},
 			getRpo: func(ctrl *gomock.Controller) repository.Repository {
 				rpo := mockrepo.NewMockRepository(ctrl)
-				rpo.EXPECT().FindWebsites(gomock.Any()).Return(
+				rpo.EXPECT().FindWebsitesPage(gomock.Any(), repository.WebsitesQuery{Limit: BatchPageSize}).Return(
 					nil,
 					errors.New("fail to query website"),
 				).AnyTimes()
@@ -206,7 +206,7 @@ func TestWebsiteBatchUpdateTask_handler(t *testing.T) {
 			},
 			getRpo: func(ctrl *gomock.Controller) repository.Repository {
 				rpo := mockrepo.NewMockRepository(ctrl)
-				rpo.EXPECT().FindWebsites(gomock.Any()).Return(
+				rpo.EXPECT().FindWebsitesPage(gomock.Any(), repository.WebsitesQuery{Limit: BatchPageSize}).Return(
 					[]model.Website{web},
 					nil,
 				).Times(1)
@@ -375,3 +375,42 @@ func TestWebsiteBatchUpdateTask_hashData(t *testing.T) {
 		})
 	}
 }
+
+func TestWebsiteBatchUpdateTask_handler_resume(t *testing.T) {
+	nc, err := nats.Connect(connString)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		nc.Close()
+	})
+
+	js, err := jetstream.New(nc)
+	assert.NoError(t, err)
+
+	kv, err := js.CreateOrUpdateKeyValue(t.Context(), jetstream.KeyValueConfig{Bucket: "batch-update-resume-test"})
+	assert.NoError(t, err)
+
+	data := []byte(`{"host":"example.com"}`)
+	_, err = kv.PutString(t.Context(), hashData(data), "checkpoint-uuid")
+	assert.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpo := mockrepo.NewMockRepository(ctrl)
+	rpo.EXPECT().FindWebsitesPage(gomock.Any(), repository.WebsitesQuery{
+		Host:      "example.com",
+		AfterUUID: "checkpoint-uuid",
+		Limit:     BatchPageSize,
+	}).Return([]model.Website{}, nil).Times(1)
+
+	msg := mocknats.NewMockNatsMsg(ctrl)
+	msg.EXPECT().Data().Return(data).AnyTimes()
+	msg.EXPECT().Ack().Return(nil).Times(1)
+
+	task := NewTask(nc, nil, rpo)
+	task.checkpoints = kv
+	task.handler(msg)
+
+	_, err = kv.Get(t.Context(), hashData(data))
+	assert.ErrorIs(t, err, jetstream.ErrKeyNotFound)
+}
@@ -0,0 +1,218 @@
+package websiteevents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// MaxReplayEvents limits the events looked up for a resuming client
+	MaxReplayEvents = 1000
+
+	subscriptionBuffer = 64
+)
+
+var ErrBrokerNotStarted = errors.New("website events broker not started")
+
+// Event is a change with its sequence in stream as id, so client can resume
+// after the last received event
+type Event struct {
+	ID     uint64
+	Change WebsiteChange
+}
+
+// Subscription receives the events of user. Its channel is closed once it is
+// unsubscribed, or the client is too slow to keep up and should resume later.
+type Subscription struct {
+	userUUID string
+	events   chan Event
+}
+
+func (sub *Subscription) Events() <-chan Event {
+	return sub.events
+}
+
+// Broker fans out the changes published by worker to the subscriptions of
+// connected users
+type Broker struct {
+	nc     *nats.Conn
+	stream jetstream.Stream
+	lock   sync.Mutex
+	subs   map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+func NewBroker(nc *nats.Conn) *Broker {
+	return &Broker{
+		nc:   nc,
+		subs: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Start consumes the changes published from now on
+func (b *Broker) Start(ctx context.Context) (jetstream.ConsumeContext, error) {
+	stream, err := CreateStream(ctx, b.nc)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := stream.OrderedConsumer(ctx, jetstream.OrderedConsumerConfig{
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create consumer fail: %v", err)
+	}
+
+	b.lock.Lock()
+	b.stream = stream
+	b.lock.Unlock()
+
+	return consumer.Consume(b.handler)
+}
+
+func (b *Broker) handler(msg jetstream.Msg) {
+	meta, err := msg.Metadata()
+	if err != nil {
+		log.Error().Err(err).Msg("read website change metadata failed")
+
+		return
+	}
+
+	var change WebsiteChange
+	if err := json.Unmarshal(msg.Data(), &change); err != nil {
+		log.Error().Err(err).Str("data", string(msg.Data())).Msg("parse website change failed")
+
+		return
+	}
+
+	b.Dispatch(Event{ID: meta.Sequence.Stream, Change: change})
+}
+
+// Dispatch sends event to the subscriptions of users in the change
+func (b *Broker) Dispatch(event Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for _, userUUID := range event.Change.UserUUIDs {
+		for sub := range b.subs[userUUID] {
+			select {
+			case sub.events <- event:
+			default:
+				b.remove(sub)
+			}
+		}
+	}
+}
+
+func (b *Broker) Subscribe(userUUID string) *Subscription {
+	sub := &Subscription{
+		userUUID: userUUID,
+		events:   make(chan Event, subscriptionBuffer),
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		close(sub.events)
+
+		return sub
+	}
+
+	if b.subs[userUUID] == nil {
+		b.subs[userUUID] = make(map[*Subscription]struct{})
+	}
+
+	b.subs[userUUID][sub] = struct{}{}
+
+	return sub
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.remove(sub)
+}
+
+// remove closes sub if it is still subscribed, lock must be held by caller
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subs[sub.userUUID][sub]; !ok {
+		return
+	}
+
+	delete(b.subs[sub.userUUID], sub)
+	if len(b.subs[sub.userUUID]) == 0 {
+		delete(b.subs, sub.userUUID)
+	}
+
+	close(sub.events)
+}
+
+// Close ends all subscriptions, so the connected clients are released on
+// server shutdown
+func (b *Broker) Close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.closed = true
+	for _, subs := range b.subs {
+		for sub := range subs {
+			b.remove(sub)
+		}
+	}
+}
+
+// Replay lists the events of user after event id. Only the latest
+// MaxReplayEvents events are looked up, and events out of stream retention
+// are skipped.
+func (b *Broker) Replay(ctx context.Context, userUUID string, afterID uint64) ([]Event, error) {
+	b.lock.Lock()
+	stream := b.stream
+	b.lock.Unlock()
+
+	if stream == nil {
+		return nil, ErrBrokerNotStarted
+	}
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get stream info fail: %w", err)
+	}
+
+	from := max(afterID+1, info.State.FirstSeq)
+	if info.State.LastSeq >= MaxReplayEvents {
+		from = max(from, info.State.LastSeq-MaxReplayEvents+1)
+	}
+
+	events := []Event{}
+	for seq := from; seq <= info.State.LastSeq; seq++ {
+		msg, err := stream.GetMsg(ctx, seq)
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("get event fail: %w", err)
+		}
+
+		var change WebsiteChange
+		if err := json.Unmarshal(msg.Data, &change); err != nil {
+			log.Error().Err(err).Uint64("seq", seq).Msg("parse website change failed")
+
+			continue
+		}
+
+		if slices.Contains(change.UserUUIDs, userUUID) {
+			events = append(events, Event{ID: seq, Change: change})
+		}
+	}
+
+	return events, nil
+}
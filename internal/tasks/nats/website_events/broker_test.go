@@ -0,0 +1,283 @@
+package websiteevents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	mocknats "github.com/htchan/WebHistory/internal/mock/nats"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeStream serves messages by sequence, sequences missing in msgs are
+// treated as deleted
+type fakeStream struct {
+	jetstream.Stream
+	firstSeq, lastSeq uint64
+	msgs              map[uint64]WebsiteChange
+	infoErr           error
+}
+
+func (stream *fakeStream) Info(context.Context, ...jetstream.StreamInfoOpt) (*jetstream.StreamInfo, error) {
+	if stream.infoErr != nil {
+		return nil, stream.infoErr
+	}
+
+	return &jetstream.StreamInfo{
+		State: jetstream.StreamState{FirstSeq: stream.firstSeq, LastSeq: stream.lastSeq},
+	}, nil
+}
+
+func (stream *fakeStream) GetMsg(_ context.Context, seq uint64, _ ...jetstream.GetMsgOpt) (*jetstream.RawStreamMsg, error) {
+	change, ok := stream.msgs[seq]
+	if !ok {
+		return nil, jetstream.ErrMsgNotFound
+	}
+
+	data, _ := json.Marshal(change)
+
+	return &jetstream.RawStreamMsg{Subject: Subject, Sequence: seq, Data: data}, nil
+}
+
+func receive(t *testing.T, sub *Subscription) (Event, bool) {
+	t.Helper()
+
+	select {
+	case event, ok := <-sub.Events():
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+
+		return Event{}, false
+	}
+}
+
+func TestBroker_Dispatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("send event to subscriptions of users in change", func(t *testing.T) {
+		t.Parallel()
+
+		b := NewBroker(nil)
+		subA, subB, other := b.Subscribe("user_a"), b.Subscribe("user_b"), b.Subscribe("other")
+		event := Event{ID: 1, Change: WebsiteChange{WebsiteUUID: "web", UserUUIDs: []string{"user_a", "user_b"}}}
+
+		b.Dispatch(event)
+
+		for _, sub := range []*Subscription{subA, subB} {
+			received, ok := receive(t, sub)
+			assert.True(t, ok)
+			assert.Equal(t, event, received)
+		}
+		assert.Empty(t, other.Events())
+	})
+
+	t.Run("close subscription which cannot keep up", func(t *testing.T) {
+		t.Parallel()
+
+		b := NewBroker(nil)
+		sub := b.Subscribe("user")
+
+		for i := range subscriptionBuffer + 1 {
+			b.Dispatch(Event{ID: uint64(i + 1), Change: WebsiteChange{UserUUIDs: []string{"user"}}})
+		}
+
+		for range subscriptionBuffer {
+			_, ok := receive(t, sub)
+			assert.True(t, ok)
+		}
+
+		_, ok := receive(t, sub)
+		assert.False(t, ok)
+		assert.Empty(t, b.subs)
+	})
+}
+
+func TestBroker_Unsubscribe(t *testing.T) {
+	t.Parallel()
+
+	b := NewBroker(nil)
+	sub := b.Subscribe("user")
+
+	b.Unsubscribe(sub)
+	b.Unsubscribe(sub)
+	b.Dispatch(Event{ID: 1, Change: WebsiteChange{UserUUIDs: []string{"user"}}})
+
+	_, ok := receive(t, sub)
+	assert.False(t, ok)
+	assert.Empty(t, b.subs)
+}
+
+func TestBroker_Close(t *testing.T) {
+	t.Parallel()
+
+	b := NewBroker(nil)
+	sub := b.Subscribe("user")
+
+	b.Close()
+
+	_, ok := receive(t, sub)
+	assert.False(t, ok)
+
+	_, ok = receive(t, b.Subscribe("user"))
+	assert.False(t, ok)
+}
+
+func TestBroker_handler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		getMsg      func(*gomock.Controller) jetstream.Msg
+		expectEvent *Event
+	}{
+		{
+			name: "dispatch change with stream sequence as id",
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Metadata().Return(&jetstream.MsgMetadata{Sequence: jetstream.SequencePair{Stream: 5}}, nil)
+				msg.EXPECT().Data().Return([]byte(`{"website_uuid":"web","user_uuids":["user"]}`))
+
+				return msg
+			},
+			expectEvent: &Event{ID: 5, Change: WebsiteChange{WebsiteUUID: "web", UserUUIDs: []string{"user"}}},
+		},
+		{
+			name: "skip non json data",
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Metadata().Return(&jetstream.MsgMetadata{Sequence: jetstream.SequencePair{Stream: 5}}, nil)
+				msg.EXPECT().Data().Return([]byte(`non json data`)).Times(2)
+
+				return msg
+			},
+		},
+		{
+			name: "skip message without metadata",
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Metadata().Return(nil, errors.New("some error"))
+
+				return msg
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			b := NewBroker(nil)
+			sub := b.Subscribe("user")
+
+			b.handler(test.getMsg(ctrl))
+
+			if test.expectEvent == nil {
+				assert.Empty(t, sub.Events())
+
+				return
+			}
+
+			event, ok := receive(t, sub)
+			assert.True(t, ok)
+			assert.Equal(t, *test.expectEvent, event)
+		})
+	}
+}
+
+func TestBroker_Replay(t *testing.T) {
+	t.Parallel()
+
+	userChange := WebsiteChange{WebsiteUUID: "web", UserUUIDs: []string{"user"}}
+	otherChange := WebsiteChange{WebsiteUUID: "web", UserUUIDs: []string{"other"}}
+
+	tests := []struct {
+		name      string
+		stream    jetstream.Stream
+		afterID   uint64
+		expect    []Event
+		expectErr bool
+	}{
+		{
+			name: "list events of user after id",
+			stream: &fakeStream{
+				firstSeq: 1,
+				lastSeq:  4,
+				msgs:     map[uint64]WebsiteChange{1: userChange, 2: userChange, 3: otherChange, 4: userChange},
+			},
+			afterID: 1,
+			expect:  []Event{{ID: 2, Change: userChange}, {ID: 4, Change: userChange}},
+		},
+		{
+			name: "skip events out of retention",
+			stream: &fakeStream{
+				firstSeq: 3,
+				lastSeq:  4,
+				msgs:     map[uint64]WebsiteChange{3: userChange, 4: userChange},
+			},
+			afterID: 1,
+			expect:  []Event{{ID: 3, Change: userChange}, {ID: 4, Change: userChange}},
+		},
+		{
+			name: "skip deleted events",
+			stream: &fakeStream{
+				firstSeq: 1,
+				lastSeq:  3,
+				msgs:     map[uint64]WebsiteChange{1: userChange, 3: userChange},
+			},
+			afterID: 0,
+			expect:  []Event{{ID: 1, Change: userChange}, {ID: 3, Change: userChange}},
+		},
+		{
+			name: "look up the latest events only",
+			stream: &fakeStream{
+				firstSeq: 1,
+				lastSeq:  MaxReplayEvents + 1,
+				msgs:     map[uint64]WebsiteChange{1: userChange, MaxReplayEvents + 1: userChange},
+			},
+			afterID: 0,
+			expect:  []Event{{ID: MaxReplayEvents + 1, Change: userChange}},
+		},
+		{
+			name:    "no event after id",
+			stream:  &fakeStream{firstSeq: 1, lastSeq: 1, msgs: map[uint64]WebsiteChange{1: userChange}},
+			afterID: 1,
+			expect:  []Event{},
+		},
+		{
+			name:      "return error if stream info failed",
+			stream:    &fakeStream{infoErr: errors.New("some error")},
+			expectErr: true,
+		},
+		{
+			name:      "return error if broker not started",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := NewBroker(nil)
+			b.stream = test.stream
+
+			events, err := b.Replay(context.Background(), "user", test.afterID)
+			if test.expectErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expect, events)
+		})
+	}
+}
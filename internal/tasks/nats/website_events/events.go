@@ -0,0 +1,57 @@
+package websiteevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const Subject = "web_history.websites.changed"
+
+// WebsiteChange is published once vendor update changes website, UserUUIDs
+// are the users subscribing the website at that time
+type WebsiteChange struct {
+	WebsiteUUID string    `json:"website_uuid"`
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	UpdateTime  time.Time `json:"update_time"`
+	UserUUIDs   []string  `json:"user_uuids"`
+}
+
+func streamName() string {
+	return strings.ReplaceAll(Subject, ".", "-")
+}
+
+// CreateStream keeps the changes for a day, so clients reconnecting within
+// the day can resume from their last event
+func CreateStream(ctx context.Context, nc *nats.Conn) (jetstream.Stream, error) {
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, fmt.Errorf("init jetstream fail: %v", err)
+	}
+
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     streamName(),
+		Subjects: []string{Subject},
+		MaxAge:   time.Hour * 24,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create / update stream fail: %v", err)
+	}
+
+	return stream, nil
+}
+
+func Publish(nc *nats.Conn, change *WebsiteChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	return nc.Publish(Subject, data)
+}
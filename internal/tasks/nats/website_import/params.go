@@ -0,0 +1,112 @@
+package websiteimport
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	RowStatusCreated           = "created"
+	RowStatusDuplicate         = "duplicate"
+	RowStatusUnsupportedVendor = "unsupported_vendor"
+	RowStatusInvalidURL        = "invalid_url"
+	RowStatusFailed            = "failed"
+)
+
+// ImportRow is a subscription read from the imported file
+type ImportRow struct {
+	URL        string    `json:"url"`
+	Title      string    `json:"title,omitempty"`
+	GroupName  string    `json:"group_name,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	AccessTime time.Time `json:"access_time,omitzero"`
+}
+
+type WebsiteImportParams struct {
+	JobUUID    string      `json:"job_uuid"`
+	UserUUID   string      `json:"user_uuid"`
+	RequestID  string      `json:"request_id"`
+	Rows       []ImportRow `json:"rows"`
+	TraceID    string      `json:"trace_id"`
+	SpanID     string      `json:"span_id"`
+	TraceFlags byte        `json:"trace_flags"`
+}
+
+// RowResult is the outcome of importing the row-th (1-based) row
+type RowResult struct {
+	Row         int    `json:"row"`
+	URL         string `json:"url"`
+	Status      string `json:"status"`
+	WebsiteUUID string `json:"website_uuid,omitempty"`
+}
+
+// ImportReport is kept as the result of import job
+type ImportReport struct {
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"`
+	Rows   []RowResult    `json:"rows"`
+}
+
+func newImportReport(total int) ImportReport {
+	return ImportReport{
+		Total:  total,
+		Counts: make(map[string]int),
+		Rows:   make([]RowResult, 0, total),
+	}
+}
+
+func (report *ImportReport) add(result RowResult) {
+	report.Counts[result.Status]++
+	report.Rows = append(report.Rows, result)
+}
+
+// Failed reports whether none of the rows is imported because of error
+func (report ImportReport) Failed() bool {
+	return report.Total > 0 && report.Counts[RowStatusFailed] == report.Total
+}
+
+func ParamsFromData(ctx context.Context, data []byte) (context.Context, *WebsiteImportParams, error) {
+	params := new(WebsiteImportParams)
+	if jsonErr := json.Unmarshal(data, params); jsonErr != nil {
+		return ctx, nil, jsonErr
+	}
+
+	ctx = log.With().
+		Str("trace_id", params.TraceID).
+		Str("job_uuid", params.JobUUID).
+		Str("user_uuid", params.UserUUID).
+		Int("rows", len(params.Rows)).
+		Logger().WithContext(ctx)
+
+	if params.TraceID != "" && params.SpanID != "" {
+		traceID, traceErr := trace.TraceIDFromHex(params.TraceID)
+		spanID, spanErr := trace.SpanIDFromHex(params.SpanID)
+		if traceErr != nil || spanErr != nil {
+			return ctx, params, nil
+		}
+
+		spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.TraceFlags(params.TraceFlags),
+			Remote:     true,
+		})
+
+		return trace.ContextWithSpanContext(ctx, spanContext), params, nil
+	}
+
+	return ctx, params, nil
+}
+
+func (params *WebsiteImportParams) ToData(ctx context.Context) ([]byte, error) {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	params.TraceID = spanCtx.TraceID().String()
+	params.SpanID = spanCtx.SpanID().String()
+	params.TraceFlags = byte(spanCtx.TraceFlags())
+
+	return json.Marshal(params)
+}
@@ -0,0 +1,291 @@
+package websiteimport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	websiteupdate "github.com/htchan/WebHistory/internal/tasks/nats/website_update"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WebsiteImportTask subscribes user to the websites of imported file and
+// keeps the report of each row in the import job
+type WebsiteImportTask struct {
+	nc          *nats.Conn
+	updateTasks websiteupdate.WebsiteUpdateTasks
+	rpo         repository.Repository
+	websiteConf *config.WebsiteConfig
+}
+
+func getTracer() trace.Tracer {
+	return otel.Tracer("htchan/WebHistory/website-import")
+}
+
+func NewTask(
+	nc *nats.Conn,
+	updateTasks websiteupdate.WebsiteUpdateTasks,
+	rpo repository.Repository,
+	websiteConf *config.WebsiteConfig,
+) *WebsiteImportTask {
+	return &WebsiteImportTask{
+		nc:          nc,
+		updateTasks: updateTasks,
+		rpo:         rpo,
+		websiteConf: websiteConf,
+	}
+}
+
+func (task *WebsiteImportTask) subject() string {
+	return "web_history.websites.import"
+}
+
+func (task *WebsiteImportTask) Publish(ctx context.Context, params *WebsiteImportParams) error {
+	data, err := params.ToData(ctx)
+	if err != nil {
+		return err
+	}
+
+	return task.nc.Publish(task.subject(), data)
+}
+
+func (task *WebsiteImportTask) Subscribe(ctx context.Context) (jetstream.ConsumeContext, error) {
+	js, err := jetstream.New(task.nc)
+	if err != nil {
+		return nil, fmt.Errorf("init jetstream fail: %v", err)
+	}
+
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     strings.ReplaceAll(task.subject(), ".", "-"),
+		Subjects: []string{task.subject()},
+		MaxAge:   time.Hour * 24 * 7,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create / update stream fail: %v", err)
+	}
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Name:      strings.ReplaceAll(task.subject(), ".", "-"),
+		Durable:   strings.ReplaceAll(task.subject(), ".", "-"),
+		AckPolicy: jetstream.AckExplicitPolicy,
+		AckWait:   time.Minute * 10,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create / update consumer fail: %v", err)
+	}
+
+	return consumer.Consume(task.handler)
+}
+
+func (task *WebsiteImportTask) handler(msg jetstream.Msg) {
+	ctx := log.With().
+		Str("task", "website-import").
+		Logger().WithContext(context.Background())
+
+	defer func() {
+		ackErr := msg.Ack()
+		if ackErr != nil {
+			zerolog.Ctx(ctx).Error().Err(ackErr).Msg("ack failed")
+		}
+	}()
+
+	ctx, params, err := ParamsFromData(ctx, msg.Data())
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).
+			Str("data", string(msg.Data())).
+			Msg("failed to parse message body")
+
+		return
+	}
+
+	ctx, span := getTracer().Start(ctx, "Website Import", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("job_uuid", params.JobUUID),
+		attribute.String("user_uuid", params.UserUUID),
+		attribute.Int("rows", len(params.Rows)),
+	)
+
+	job, err := task.rpo.FindJob(ctx, params.UserUUID, params.JobUUID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("find job failed")
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return
+	}
+
+	job.Status = model.JobStatusRunning
+	if err := task.updateJob(ctx, job); err != nil {
+		return
+	}
+
+	report := task.Import(ctx, params)
+
+	result, err := json.Marshal(report)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("encode import report failed")
+	}
+
+	job.Status = model.JobStatusDone
+	if err != nil || report.Failed() {
+		job.Status = model.JobStatusFailed
+	}
+	job.Result = string(result)
+
+	if err := task.updateJob(ctx, job); err != nil {
+		return
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Str("status", job.Status).
+		Interface("counts", report.Counts).
+		Msg("websites imported")
+}
+
+func (task *WebsiteImportTask) updateJob(ctx context.Context, job *model.Job) error {
+	job.UpdatedAt = time.Now().UTC()
+
+	err := task.rpo.UpdateJob(ctx, job)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("status", job.Status).Msg("update job failed")
+	}
+
+	return err
+}
+
+// Import subscribes user to website of each row and reports the outcome of
+// them in the same order
+func (task *WebsiteImportTask) Import(ctx context.Context, params *WebsiteImportParams) ImportReport {
+	report := newImportReport(len(params.Rows))
+
+	for i, row := range params.Rows {
+		result := task.importRow(ctx, params, row)
+		result.Row = i + 1
+
+		report.add(result)
+	}
+
+	return report
+}
+
+func (task *WebsiteImportTask) importRow(ctx context.Context, params *WebsiteImportParams, row ImportRow) RowResult {
+	ctx, span := getTracer().Start(ctx, "Import Row")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("website_url", row.URL))
+
+	result := RowResult{URL: row.URL}
+	if !validURL(row.URL) {
+		result.Status = RowStatusInvalidURL
+
+		return result
+	}
+
+	web := model.NewWebsite(row.URL, task.websiteConf)
+	if !task.updateTasks.Supported(&web) {
+		result.Status = RowStatusUnsupportedVendor
+
+		return result
+	}
+
+	err := task.rpo.WithTx(ctx, func(txRepo repository.Repository) error {
+		err := txRepo.CreateWebsite(ctx, &web)
+		if err != nil {
+			return err
+		}
+
+		result.WebsiteUUID = web.UUID
+
+		if _, err := txRepo.FindUserWebsite(ctx, params.UserUUID, web.UUID); err == nil {
+			result.Status = RowStatusDuplicate
+
+			return nil
+		}
+
+		userWeb := newUserWebsite(web, params.UserUUID, row)
+		err = txRepo.CreateUserWebsite(ctx, &userWeb)
+		if err != nil {
+			return err
+		}
+
+		event := model.NewAuditEvent(
+			userWeb.UserUUID, userWeb.WebsiteUUID, model.AuditActionImport,
+			params.RequestID, nil, &userWeb,
+		)
+		err = txRepo.CreateAuditEvent(ctx, &event)
+		if err != nil {
+			return err
+		}
+
+		result.Status = RowStatusCreated
+
+		return nil
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("website_url", row.URL).Msg("import website failed")
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return RowResult{URL: row.URL, Status: RowStatusFailed}
+	}
+
+	if result.Status == RowStatusCreated {
+		task.publishUpdate(ctx, &web)
+	}
+
+	return result
+}
+
+// publishUpdate publishes update job only if website is updated more than
+// 24 hr ago, failing to publish does not fail the import
+func (task *WebsiteImportTask) publishUpdate(ctx context.Context, web *model.Website) {
+	if time.Since(web.UpdateTime) <= 24*time.Hour {
+		return
+	}
+
+	if _, err := task.updateTasks.Publish(ctx, web); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("website_url", web.URL).Msg("publish website update task failed")
+	}
+}
+
+func newUserWebsite(web model.Website, userUUID string, row ImportRow) model.UserWebsite {
+	userWeb := model.NewUserWebsite(web, userUUID)
+	userWeb.DisplayTitle = row.Title
+	userWeb.Note = row.Note
+
+	if row.GroupName != "" {
+		userWeb.GroupName = row.GroupName
+	} else if row.Title != "" {
+		userWeb.GroupName = row.Title
+	}
+
+	if !row.AccessTime.IsZero() {
+		userWeb.AccessTime = row.AccessTime.UTC()
+	}
+
+	return userWeb
+}
+
+func validURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
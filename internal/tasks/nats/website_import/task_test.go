@@ -0,0 +1,176 @@
+package websiteimport
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	mocknats "github.com/htchan/WebHistory/internal/mock/nats"
+	mockvendor "github.com/htchan/WebHistory/internal/mock/vendor"
+	"github.com/htchan/WebHistory/internal/model"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/htchan/WebHistory/internal/repository/memory"
+	websiteupdate "github.com/htchan/WebHistory/internal/tasks/nats/website_update"
+	"github.com/htchan/WebHistory/internal/vendors"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newUpdateTasks(ctrl *gomock.Controller) websiteupdate.WebsiteUpdateTasks {
+	serv := mockvendor.NewMockVendorService(ctrl)
+	serv.EXPECT().Support(gomock.Any()).DoAndReturn(func(web *model.Website) bool {
+		return strings.HasPrefix(web.URL, "https://supported.com")
+	}).AnyTimes()
+
+	return websiteupdate.NewTaskSet(nil, []vendors.VendorService{serv}, nil, nil)
+}
+
+func TestNewTask(t *testing.T) {
+	conf := &config.WebsiteConfig{}
+
+	task := NewTask(nil, nil, nil, conf)
+	assert.Equal(t, &WebsiteImportTask{websiteConf: conf}, task)
+}
+
+func TestWebsiteImportTask_subject(t *testing.T) {
+	assert.Equal(t, "web_history.websites.import", (&WebsiteImportTask{}).subject())
+}
+
+func TestWebsiteImportTask_Import(t *testing.T) {
+	t.Parallel()
+
+	accessTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	rpo := memory.NewRepo(&config.WebsiteConfig{})
+
+	existing := model.NewWebsite("https://supported.com/existing", nil)
+	require.NoError(t, rpo.CreateWebsite(context.Background(), &existing))
+	existingUserWeb := model.NewUserWebsite(existing, "user")
+	require.NoError(t, rpo.CreateUserWebsite(context.Background(), &existingUserWeb))
+
+	task := NewTask(nil, newUpdateTasks(ctrl), rpo, &config.WebsiteConfig{})
+	report := task.Import(context.Background(), &WebsiteImportParams{
+		UserUUID:  "user",
+		RequestID: "request",
+		Rows: []ImportRow{
+			{URL: "https://supported.com/1", Title: "title", GroupName: "group", Note: "note", AccessTime: accessTime},
+			{URL: "https://supported.com/existing"},
+			{URL: "https://unsupported.com/1"},
+			{URL: "ftp://supported.com/1"},
+			{URL: "https://supported.com/1"},
+			{URL: "https://supported.com/2", Title: "other title"},
+		},
+	})
+
+	assert.Equal(t, 6, report.Total)
+	assert.Equal(t, map[string]int{
+		RowStatusCreated:           2,
+		RowStatusDuplicate:         2,
+		RowStatusUnsupportedVendor: 1,
+		RowStatusInvalidURL:        1,
+	}, report.Counts)
+	assert.False(t, report.Failed())
+
+	statuses := make([]string, 0, len(report.Rows))
+	for i, row := range report.Rows {
+		assert.Equal(t, i+1, row.Row)
+		statuses = append(statuses, row.Status)
+	}
+	assert.Equal(t, []string{
+		RowStatusCreated, RowStatusDuplicate, RowStatusUnsupportedVendor,
+		RowStatusInvalidURL, RowStatusDuplicate, RowStatusCreated,
+	}, statuses)
+	assert.Equal(t, existing.UUID, report.Rows[1].WebsiteUUID)
+	assert.Equal(t, report.Rows[0].WebsiteUUID, report.Rows[4].WebsiteUUID)
+	assert.Empty(t, report.Rows[2].WebsiteUUID)
+
+	web, err := rpo.FindUserWebsite(context.Background(), "user", report.Rows[0].WebsiteUUID)
+	require.NoError(t, err)
+	assert.Equal(t, "title", web.DisplayTitle)
+	assert.Equal(t, "group", web.GroupName)
+	assert.Equal(t, "note", web.Note)
+	assert.Equal(t, accessTime, web.AccessTime)
+
+	web, err = rpo.FindUserWebsite(context.Background(), "user", report.Rows[5].WebsiteUUID)
+	require.NoError(t, err)
+	assert.Equal(t, "other title", web.GroupName)
+
+	events, err := rpo.FindAuditEvents(context.Background(), repository.AuditEventsQuery{UserUUID: "user", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+	for _, event := range events {
+		assert.Equal(t, model.AuditActionImport, event.Action)
+		assert.Equal(t, "request", event.RequestID)
+	}
+}
+
+func TestWebsiteImportTask_handler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		data         func(job model.Job) []byte
+		expectStatus string
+		expectResult func(t *testing.T, result string)
+	}{
+		{
+			name: "happy flow",
+			data: func(job model.Job) []byte {
+				return []byte(`{"job_uuid":"` + job.UUID + `","user_uuid":"user","rows":[{"url":"https://supported.com/1"},{"url":"not url"}]}`)
+			},
+			expectStatus: model.JobStatusDone,
+			expectResult: func(t *testing.T, result string) {
+				var report ImportReport
+				require.NoError(t, json.Unmarshal([]byte(result), &report))
+				assert.Equal(t, 2, report.Total)
+				assert.Equal(t, map[string]int{RowStatusCreated: 1, RowStatusInvalidURL: 1}, report.Counts)
+			},
+		},
+		{
+			name: "error/job of other user",
+			data: func(job model.Job) []byte {
+				return []byte(`{"job_uuid":"` + job.UUID + `","user_uuid":"other user","rows":[{"url":"https://supported.com/1"}]}`)
+			},
+			expectStatus: model.JobStatusQueued,
+			expectResult: func(t *testing.T, result string) { assert.Empty(t, result) },
+		},
+		{
+			name: "error/invalid data",
+			data: func(job model.Job) []byte {
+				return []byte(`invalid`)
+			},
+			expectStatus: model.JobStatusQueued,
+			expectResult: func(t *testing.T, result string) { assert.Empty(t, result) },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			rpo := memory.NewRepo(&config.WebsiteConfig{})
+
+			job := model.NewJob("user", model.JobKindImport)
+			require.NoError(t, rpo.CreateJob(context.Background(), &job))
+
+			msg := mocknats.NewMockNatsMsg(ctrl)
+			msg.EXPECT().Data().Return(test.data(job)).AnyTimes()
+			msg.EXPECT().Ack().Return(nil).Times(1)
+
+			task := NewTask(nil, newUpdateTasks(ctrl), rpo, &config.WebsiteConfig{})
+			task.handler(jetstream.Msg(msg))
+
+			got, err := rpo.FindJob(context.Background(), "user", job.UUID)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectStatus, got.Status)
+			test.expectResult(t, got.Result)
+		})
+	}
+}
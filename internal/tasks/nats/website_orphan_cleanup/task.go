@@ -0,0 +1,120 @@
+package websiteorphancleanup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WebsiteOrphanCleanupTask deactivates websites nobody subscribes to, so the
+// batch update stops fetching them
+type WebsiteOrphanCleanupTask struct {
+	nc          *nats.Conn
+	rpo         repository.Repository
+	websiteConf *config.WebsiteConfig
+}
+
+func getTracer() trace.Tracer {
+	return otel.Tracer("htchan/WebHistory/website-orphan-cleanup")
+}
+
+func NewTask(
+	nc *nats.Conn,
+	rpo repository.Repository,
+	websiteConf *config.WebsiteConfig,
+) WebsiteOrphanCleanupTask {
+	return WebsiteOrphanCleanupTask{
+		nc:          nc,
+		rpo:         rpo,
+		websiteConf: websiteConf,
+	}
+}
+
+func (task WebsiteOrphanCleanupTask) subject() string {
+	return "web_history.websites.orphan_cleanup"
+}
+
+func (task *WebsiteOrphanCleanupTask) Subscribe(ctx context.Context) (jetstream.ConsumeContext, error) {
+	js, err := jetstream.New(task.nc)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     strings.ReplaceAll(task.subject(), ".", "-"),
+		Subjects: []string{task.subject()},
+		MaxAge:   time.Hour * 24 * 7,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Name:      strings.ReplaceAll(task.subject(), ".", "-"),
+		Durable:   strings.ReplaceAll(task.subject(), ".", "-"),
+		AckPolicy: jetstream.AckExplicitPolicy,
+		AckWait:   time.Minute * 10,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return consumer.Consume(task.handler)
+}
+
+func (task *WebsiteOrphanCleanupTask) handler(msg jetstream.Msg) {
+	ctx := log.With().
+		Str("task", "website-orphan-cleanup").
+		Str("task_id", hashData(msg.Data())).
+		Logger().WithContext(context.Background())
+
+	defer func() {
+		ackErr := msg.Ack()
+		if ackErr != nil {
+			zerolog.Ctx(ctx).Error().Err(ackErr).Msg("ack message failed")
+		}
+	}()
+
+	ctx, span := getTracer().Start(ctx, "Website Orphan Cleanup", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+
+	result, err := task.rpo.CleanupOrphanedWebsites(ctx, time.Now().UTC(), task.websiteConf.OrphanGracePeriod)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("cleanup orphaned websites failed")
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("marked", result.Marked),
+		attribute.Int("deactivated", result.Deactivated),
+		attribute.Int("fetches_saved", result.FetchesSaved),
+	)
+
+	zerolog.Ctx(ctx).Info().
+		Int("marked", result.Marked).
+		Int("deactivated", result.Deactivated).
+		Int("fetches_saved", result.FetchesSaved).
+		Msg("orphaned websites cleaned up")
+}
+
+func hashData(input []byte) string {
+	hash := sha256.Sum256(input)
+
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,108 @@
+package websiteorphancleanup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	mocknats "github.com/htchan/WebHistory/internal/mock/nats"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
+	"github.com/htchan/WebHistory/internal/repository"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewTask(t *testing.T) {
+	conf := &config.WebsiteConfig{OrphanGracePeriod: time.Hour}
+
+	task := NewTask(nil, nil, conf)
+	assert.Equal(t, WebsiteOrphanCleanupTask{websiteConf: conf}, task)
+}
+
+func TestWebsiteOrphanCleanupTask_subject(t *testing.T) {
+	assert.Equal(t, "web_history.websites.orphan_cleanup", WebsiteOrphanCleanupTask{}.subject())
+}
+
+func TestWebsiteOrphanCleanupTask_handler(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.WebsiteConfig{OrphanGracePeriod: 48 * time.Hour}
+
+	tests := []struct {
+		name   string
+		getRpo func(*gomock.Controller) repository.Repository
+		getMsg func(*gomock.Controller) jetstream.Msg
+	}{
+		{
+			name: "happy flow",
+			getRpo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().CleanupOrphanedWebsites(gomock.Any(), gomock.Any(), 48*time.Hour).Return(
+					repository.OrphanCleanupResult{Marked: 1, Deactivated: 2, FetchesSaved: 3},
+					nil,
+				).Times(1)
+
+				return rpo
+			},
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Data().Return([]byte(`trigger`)).Times(1)
+				msg.EXPECT().Ack().Return(nil).Times(1)
+
+				return msg
+			},
+		},
+		{
+			name: "error/fail to cleanup",
+			getRpo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().CleanupOrphanedWebsites(gomock.Any(), gomock.Any(), 48*time.Hour).Return(
+					repository.OrphanCleanupResult{},
+					errors.New("fail to cleanup"),
+				).Times(1)
+
+				return rpo
+			},
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Data().Return([]byte(`trigger`)).Times(1)
+				msg.EXPECT().Ack().Return(nil).Times(1)
+
+				return msg
+			},
+		},
+		{
+			name: "error/fail to ack",
+			getRpo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().CleanupOrphanedWebsites(gomock.Any(), gomock.Any(), 48*time.Hour).Return(
+					repository.OrphanCleanupResult{},
+					nil,
+				).Times(1)
+
+				return rpo
+			},
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Data().Return([]byte(`trigger`)).Times(1)
+				msg.EXPECT().Ack().Return(errors.New("fail to ack")).Times(1)
+
+				return msg
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			task := NewTask(nil, test.getRpo(ctrl), conf)
+			task.handler(test.getMsg(ctrl))
+		})
+	}
+}
@@ -11,13 +11,32 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// WebsiteUpdateParams refers to the job tracking the update if it is
+// requested by user
 type WebsiteUpdateParams struct {
 	Website    model.Website `json:"website"`
+	JobUUID    string        `json:"job_uuid,omitempty"`
+	UserUUID   string        `json:"user_uuid,omitempty"`
 	TraceID    string        `json:"trace_id"`
 	SpanID     string        `json:"span_id"`
 	TraceFlags byte          `json:"trace_flags"`
 }
 
+// CheckResult is kept in the job of update requested by user, website is the
+// state after update
+type CheckResult struct {
+	Changed bool          `json:"changed"`
+	Website *CheckWebsite `json:"website,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+type CheckWebsite struct {
+	UUID       string    `json:"uuid"`
+	URL        string    `json:"url"`
+	Title      string    `json:"title"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
 func ParamsFromData(ctx context.Context, data []byte, conf *config.WebsiteConfig) (context.Context, *WebsiteUpdateParams, error) {
 	// parse message body
 	params := new(WebsiteUpdateParams)
@@ -36,6 +55,7 @@ func ParamsFromData(ctx context.Context, data []byte, conf *config.WebsiteConfig
 		Str("website_uuid", params.Website.UUID).
 		Str("website_url", params.Website.URL).
 		Str("website_title", params.Website.Title).
+		Str("job_uuid", params.JobUUID).
 		Logger().WithContext(ctx)
 
 	if params.TraceID != "" && params.SpanID != "" {
@@ -62,11 +82,12 @@ func ParamsFromData(ctx context.Context, data []byte, conf *config.WebsiteConfig
 func (params WebsiteUpdateParams) MarshalJSON() ([]byte, error) {
 	type Alias WebsiteUpdateParams
 	type WebsiteParams struct {
-		UUID       string    `json:"uuid"`
-		URL        string    `json:"url"`
-		Title      string    `json:"title"`
-		RawContent string    `json:"raw_content"`
-		UpdateTime time.Time `json:"update_time"`
+		UUID         string    `json:"uuid"`
+		URL          string    `json:"url"`
+		Title        string    `json:"title"`
+		RawContent   string    `json:"raw_content"`
+		UpdateTime   time.Time `json:"update_time"`
+		MissingCount int       `json:"missing_count,omitempty"` // worker resets it once website is accessible again
 	}
 	return json.Marshal(&struct {
 		Website WebsiteParams `json:"website"`
@@ -74,11 +95,12 @@ func (params WebsiteUpdateParams) MarshalJSON() ([]byte, error) {
 	}{
 		Alias: Alias(params),
 		Website: WebsiteParams{
-			UUID:       params.Website.UUID,
-			URL:        params.Website.URL,
-			Title:      params.Website.Title,
-			RawContent: params.Website.RawContent,
-			UpdateTime: params.Website.UpdateTime.UTC(),
+			UUID:         params.Website.UUID,
+			URL:          params.Website.URL,
+			Title:        params.Website.Title,
+			RawContent:   params.Website.RawContent,
+			UpdateTime:   params.Website.UpdateTime.UTC(),
+			MissingCount: params.Website.MissingCount,
 		},
 	})
 }
@@ -121,6 +121,43 @@ func TestWebsiteUpdateParams_MarshalJSON(t *testing.T) {
 			expect:      `{"website":{"uuid":"test uuid","url":"https://example.com","title":"test title","raw_content":"test content","update_time":"2020-05-01T00:00:00Z"},"trace_id":"XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX","span_id":"XXXXXXXXXXXXXXXX","trace_flags":1}`,
 			expectError: nil,
 		},
+		{
+			name: "success/with job",
+			params: &WebsiteUpdateParams{
+				Website: model.Website{
+					UUID:       "test uuid",
+					URL:        "https://example.com",
+					Title:      "test title",
+					RawContent: "test content",
+					UpdateTime: time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC),
+				},
+				JobUUID:    "job uuid",
+				UserUUID:   "user uuid",
+				TraceID:    "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+				SpanID:     "XXXXXXXXXXXXXXXX",
+				TraceFlags: 0x1,
+			},
+			expect:      `{"website":{"uuid":"test uuid","url":"https://example.com","title":"test title","raw_content":"test content","update_time":"2020-05-01T00:00:00Z"},"job_uuid":"job uuid","user_uuid":"user uuid","trace_id":"XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX","span_id":"XXXXXXXXXXXXXXXX","trace_flags":1}`,
+			expectError: nil,
+		},
+		{
+			name: "success/with missing count",
+			params: &WebsiteUpdateParams{
+				Website: model.Website{
+					UUID:         "test uuid",
+					URL:          "https://example.com",
+					Title:        "test title",
+					RawContent:   "test content",
+					UpdateTime:   time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC),
+					MissingCount: 2,
+				},
+				TraceID:    "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+				SpanID:     "XXXXXXXXXXXXXXXX",
+				TraceFlags: 0x1,
+			},
+			expect:      `{"website":{"uuid":"test uuid","url":"https://example.com","title":"test title","raw_content":"test content","update_time":"2020-05-01T00:00:00Z","missing_count":2},"trace_id":"XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX","span_id":"XXXXXXXXXXXXXXXX","trace_flags":1}`,
+			expectError: nil,
+		},
 	}
 
 	for _, test := range tests {
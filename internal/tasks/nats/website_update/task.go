@@ -2,6 +2,8 @@ package websiteupdate
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -9,6 +11,7 @@ import (
 	"github.com/htchan/WebHistory/internal/config"
 	"github.com/htchan/WebHistory/internal/model"
 	"github.com/htchan/WebHistory/internal/repository"
+	websiteevents "github.com/htchan/WebHistory/internal/tasks/nats/website_events"
 	"github.com/htchan/WebHistory/internal/vendors"
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
@@ -53,10 +56,10 @@ func (task *WebsiteUpdateTask) Publish(
 	ctx context.Context,
 	web *model.Website,
 ) error {
-	params := WebsiteUpdateParams{
-		Website: *web,
-	}
+	return task.publish(ctx, &WebsiteUpdateParams{Website: *web})
+}
 
+func (task *WebsiteUpdateTask) publish(ctx context.Context, params *WebsiteUpdateParams) error {
 	data, err := params.ToData(ctx)
 	if err != nil {
 		return err
@@ -151,10 +154,13 @@ func (task *WebsiteUpdateTask) handler(msg jetstream.Msg) {
 		)...,
 	)
 
+	job := task.startJob(ctx, params)
+
 	// validate params
 	validateErr := task.Validate(ctx, params)
 	if validateErr != nil {
 		zerolog.Ctx(ctx).Error().Err(validateErr).Msg("validate params failed")
+		task.finishJob(ctx, job, CheckResult{Error: validateErr.Error()})
 
 		return
 	}
@@ -168,14 +174,165 @@ func (task *WebsiteUpdateTask) handler(msg jetstream.Msg) {
 	updateCtx, updateSpan := getTracer().Start(ctx, "Vendor Service Call")
 	defer updateSpan.End()
 
+	before := params.Website
 	updateErr := task.Service.Update(updateCtx, &params.Website)
 	if updateErr != nil {
 		zerolog.Ctx(ctx).Error().Err(updateErr).Msg("update website failed")
 		updateSpan.SetStatus(codes.Error, updateErr.Error())
 		updateSpan.RecordError(updateErr)
 
+		task.recordMissing(ctx, &params.Website, updateErr)
+		task.finishJob(ctx, job, CheckResult{Error: updateErr.Error()})
+
 		return
 	}
 
 	updateSpan.End()
+
+	task.resetMissing(ctx, &params.Website)
+
+	changed := websiteChanged(&before, &params.Website)
+	if changed {
+		task.publishChange(ctx, &params.Website)
+	}
+
+	task.finishJob(ctx, job, CheckResult{
+		Changed: changed,
+		Website: &CheckWebsite{
+			UUID:       params.Website.UUID,
+			URL:        params.Website.URL,
+			Title:      params.Website.Title,
+			UpdateTime: params.Website.UpdateTime,
+		},
+	})
+}
+
+// startJob marks the job of params running, it returns nil if the update is
+// not requested by user or the job cannot be found
+func (task *WebsiteUpdateTask) startJob(ctx context.Context, params *WebsiteUpdateParams) *model.Job {
+	if params.JobUUID == "" {
+		return nil
+	}
+
+	job, err := task.rpo.FindJob(ctx, params.UserUUID, params.JobUUID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("find job failed")
+
+		return nil
+	}
+
+	job.Status = model.JobStatusRunning
+	task.updateJob(ctx, job)
+
+	return job
+}
+
+// finishJob keeps result in job, job is failed if result has error
+func (task *WebsiteUpdateTask) finishJob(ctx context.Context, job *model.Job, result CheckResult) {
+	if job == nil {
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("encode check result failed")
+	}
+
+	job.Status = model.JobStatusDone
+	if err != nil || result.Error != "" {
+		job.Status = model.JobStatusFailed
+	}
+	job.Result = string(data)
+
+	task.updateJob(ctx, job)
+}
+
+func (task *WebsiteUpdateTask) updateJob(ctx context.Context, job *model.Job) {
+	job.UpdatedAt = time.Now().UTC()
+
+	err := task.rpo.UpdateJob(ctx, job)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("status", job.Status).Msg("update job failed")
+	}
+}
+
+func websiteChanged(before, after *model.Website) bool {
+	return !before.UpdateTime.Equal(after.UpdateTime) ||
+		before.RawContent != after.RawContent ||
+		before.Title != after.Title
+}
+
+// publishChange notifies the users subscribing web that it is changed
+func (task *WebsiteUpdateTask) publishChange(ctx context.Context, web *model.Website) {
+	ctx, span := getTracer().Start(ctx, "Publish Website Change")
+	defer span.End()
+
+	userUUIDs, err := task.rpo.FindWebsiteUserUUIDs(ctx, web.UUID)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("find website users failed")
+
+		return
+	}
+
+	if len(userUUIDs) == 0 {
+		return
+	}
+
+	err = websiteevents.Publish(task.nc, &websiteevents.WebsiteChange{
+		WebsiteUUID: web.UUID,
+		URL:         web.URL,
+		Title:       web.Title,
+		UpdateTime:  web.UpdateTime,
+		UserUUIDs:   userUUIDs,
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("publish website change failed")
+	}
+}
+
+// recordMissing counts consecutive not found response of website
+func (task *WebsiteUpdateTask) recordMissing(ctx context.Context, web *model.Website, updateErr error) {
+	var statusErr vendors.StatusCodeError
+	if !errors.As(updateErr, &statusErr) || !statusErr.NotFound() {
+		return
+	}
+
+	ctx, span := getTracer().Start(ctx, "Record Website Missing")
+	defer span.End()
+
+	err := task.rpo.RecordWebsiteMissing(ctx, web, task.websiteConf.GoneThreshold)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("record website missing failed")
+
+		return
+	}
+
+	if web.Gone() {
+		zerolog.Ctx(ctx).Warn().
+			Int("missing_count", web.MissingCount).
+			Msg("website is gone")
+	}
+}
+
+// resetMissing clears missing count once website is accessible again
+func (task *WebsiteUpdateTask) resetMissing(ctx context.Context, web *model.Website) {
+	if web.MissingCount == 0 {
+		return
+	}
+
+	ctx, span := getTracer().Start(ctx, "Reset Website Missing")
+	defer span.End()
+
+	err := task.rpo.ResetWebsiteMissing(ctx, web)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("reset website missing failed")
+	}
 }
@@ -3,11 +3,13 @@ package websiteupdate
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/htchan/WebHistory/internal/config"
 	mocknats "github.com/htchan/WebHistory/internal/mock/nats"
+	mockrepo "github.com/htchan/WebHistory/internal/mock/repository"
 	mockvendor "github.com/htchan/WebHistory/internal/mock/vendor"
 	"github.com/htchan/WebHistory/internal/model"
 	"github.com/htchan/WebHistory/internal/repository"
@@ -353,9 +355,13 @@ func TestWebsiteUpdateTask_Validate(t *testing.T) {
 }
 
 func TestWebsiteUpdateTask_handler(t *testing.T) {
+	webConf := &config.WebsiteConfig{Separator: "\n", GoneThreshold: 2}
+
 	tests := []struct {
 		name    string
 		getServ func(*gomock.Controller) vendors.VendorService
+		getRepo func(*gomock.Controller) repository.Repository
+		webConf *config.WebsiteConfig
 		getMsg  func(*gomock.Controller) jetstream.Msg
 	}{
 		{
@@ -383,6 +389,243 @@ func TestWebsiteUpdateTask_handler(t *testing.T) {
 				return msg
 			},
 		},
+		{
+			name: "happy flow/reset missing count",
+			getServ: func(ctrl *gomock.Controller) vendors.VendorService {
+				serv := mockvendor.NewMockVendorService(ctrl)
+				web := &model.Website{
+					UUID:         "test uuid",
+					URL:          "https://example.com",
+					Title:        "test",
+					RawContent:   "content",
+					UpdateTime:   time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+					MissingCount: 1,
+					Conf:         webConf,
+				}
+				serv.EXPECT().Name().Return("reset_missing_count").AnyTimes()
+				serv.EXPECT().Support(web).Return(true)
+				serv.EXPECT().Update(gomock.Any(), web).Return(nil)
+
+				return serv
+			},
+			getRepo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().ResetWebsiteMissing(gomock.Any(), &model.Website{
+					UUID:         "test uuid",
+					URL:          "https://example.com",
+					Title:        "test",
+					RawContent:   "content",
+					UpdateTime:   time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+					MissingCount: 1,
+					Conf:         webConf,
+				}).Return(nil)
+
+				return rpo
+			},
+			webConf: webConf,
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				params := WebsiteUpdateParams{Website: model.Website{
+					UUID:         "test uuid",
+					URL:          "https://example.com",
+					Title:        "test",
+					RawContent:   "content",
+					UpdateTime:   time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+					MissingCount: 1,
+				}}
+				// built as published, so the test fails if missing count is not sent
+				data, _ := params.ToData(context.Background())
+
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Data().Return(data)
+				msg.EXPECT().Ack()
+
+				return msg
+			},
+		},
+		{
+			name: "happy flow/publish change to subscribers",
+			getServ: func(ctrl *gomock.Controller) vendors.VendorService {
+				serv := mockvendor.NewMockVendorService(ctrl)
+				serv.EXPECT().Name().Return("publish_change").AnyTimes()
+				serv.EXPECT().Support(gomock.Any()).Return(true)
+				serv.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, web *model.Website) error {
+					web.RawContent = "new content"
+					web.UpdateTime = time.Date(2020, 5, 2, 0, 0, 0, 0, time.UTC)
+
+					return nil
+				})
+
+				return serv
+			},
+			getRepo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWebsiteUserUUIDs(gomock.Any(), "test uuid").Return([]string{"user_uuid"}, nil)
+
+				return rpo
+			},
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Data().Return([]byte(`{"website":{"uuid":"test uuid","url":"https://example.com","title":"test","raw_content":"content","update_time":"2020-05-01T00:00:00Z"},"trace_id":"01234567890123456789012345678901","span_id":"0123456789012345","trace_flags":1}`))
+				msg.EXPECT().Ack()
+
+				return msg
+			},
+		},
+		{
+			name: "happy flow/skip publish if find subscribers failed",
+			getServ: func(ctrl *gomock.Controller) vendors.VendorService {
+				serv := mockvendor.NewMockVendorService(ctrl)
+				serv.EXPECT().Name().Return("find_subscribers_failed").AnyTimes()
+				serv.EXPECT().Support(gomock.Any()).Return(true)
+				serv.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, web *model.Website) error {
+					web.Title = "new title"
+
+					return nil
+				})
+
+				return serv
+			},
+			getRepo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWebsiteUserUUIDs(gomock.Any(), "test uuid").Return(nil, errors.New("some error"))
+
+				return rpo
+			},
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Data().Return([]byte(`{"website":{"uuid":"test uuid","url":"https://example.com","title":"test","raw_content":"content","update_time":"2020-05-01T00:00:00Z"},"trace_id":"01234567890123456789012345678901","span_id":"0123456789012345","trace_flags":1}`))
+				msg.EXPECT().Ack()
+
+				return msg
+			},
+		},
+		{
+			name: "happy flow/keep result in job",
+			getServ: func(ctrl *gomock.Controller) vendors.VendorService {
+				serv := mockvendor.NewMockVendorService(ctrl)
+				serv.EXPECT().Name().Return("keep_result_in_job").AnyTimes()
+				serv.EXPECT().Support(gomock.Any()).Return(true)
+				serv.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+				return serv
+			},
+			getRepo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindJob(gomock.Any(), "user_uuid", "job_uuid").Return(&model.Job{UUID: "job_uuid", UserUUID: "user_uuid", Kind: model.JobKindCheck}, nil)
+				gomock.InOrder(
+					rpo.EXPECT().UpdateJob(gomock.Any(), gomock.Cond(func(job *model.Job) bool {
+						return job.Status == model.JobStatusRunning
+					})).Return(nil),
+					rpo.EXPECT().UpdateJob(gomock.Any(), gomock.Cond(func(job *model.Job) bool {
+						return job.Status == model.JobStatusDone &&
+							job.Result == `{"changed":false,"website":{"uuid":"test uuid","url":"https://example.com","title":"test","update_time":"2020-05-01T00:00:00Z"}}`
+					})).Return(nil),
+				)
+
+				return rpo
+			},
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Data().Return([]byte(`{"website":{"uuid":"test uuid","url":"https://example.com","title":"test","raw_content":"content","update_time":"2020-05-01T00:00:00Z"},"job_uuid":"job_uuid","user_uuid":"user_uuid"}`))
+				msg.EXPECT().Ack()
+
+				return msg
+			},
+		},
+		{
+			name: "error/fail job if update failed",
+			getServ: func(ctrl *gomock.Controller) vendors.VendorService {
+				serv := mockvendor.NewMockVendorService(ctrl)
+				serv.EXPECT().Name().Return("fail_job").AnyTimes()
+				serv.EXPECT().Support(gomock.Any()).Return(true)
+				serv.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+				return serv
+			},
+			getRepo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindJob(gomock.Any(), "user_uuid", "job_uuid").Return(&model.Job{UUID: "job_uuid", UserUUID: "user_uuid", Kind: model.JobKindCheck}, nil)
+				gomock.InOrder(
+					rpo.EXPECT().UpdateJob(gomock.Any(), gomock.Cond(func(job *model.Job) bool {
+						return job.Status == model.JobStatusRunning
+					})).Return(nil),
+					rpo.EXPECT().UpdateJob(gomock.Any(), gomock.Cond(func(job *model.Job) bool {
+						return job.Status == model.JobStatusFailed && job.Result == `{"changed":false,"error":"some error"}`
+					})).Return(nil),
+				)
+
+				return rpo
+			},
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Data().Return([]byte(`{"website":{"uuid":"test uuid","url":"https://example.com","title":"test","raw_content":"content","update_time":"2020-05-01T00:00:00Z"},"job_uuid":"job_uuid","user_uuid":"user_uuid"}`))
+				msg.EXPECT().Ack()
+
+				return msg
+			},
+		},
+		{
+			name: "error/website not found",
+			getServ: func(ctrl *gomock.Controller) vendors.VendorService {
+				serv := mockvendor.NewMockVendorService(ctrl)
+				serv.EXPECT().Name().Return("website_not_found").AnyTimes()
+				serv.EXPECT().Support(gomock.Any()).Return(true)
+				serv.EXPECT().Update(gomock.Any(), gomock.Any()).Return(
+					fmt.Errorf("fetch website failed: %w", vendors.StatusCodeError{StatusCode: 404}),
+				)
+
+				return serv
+			},
+			getRepo: func(ctrl *gomock.Controller) repository.Repository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().RecordWebsiteMissing(gomock.Any(), &model.Website{
+					UUID:       "test uuid",
+					URL:        "https://example.com",
+					Title:      "test",
+					RawContent: "content",
+					UpdateTime: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+					Conf:       webConf,
+				}, 2).DoAndReturn(func(_ context.Context, web *model.Website, _ int) error {
+					web.MissingCount = 1
+
+					return nil
+				})
+
+				return rpo
+			},
+			webConf: webConf,
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Data().Return([]byte(`{"website":{"uuid":"test uuid","url":"https://example.com","title":"test","raw_content":"content","update_time":"2020-05-01T00:00:00Z"},"trace_id":"01234567890123456789012345678901","span_id":"0123456789012345","trace_flags":1}`))
+				msg.EXPECT().Ack()
+
+				return msg
+			},
+		},
+		{
+			name: "error/other status code is not counted as missing",
+			getServ: func(ctrl *gomock.Controller) vendors.VendorService {
+				serv := mockvendor.NewMockVendorService(ctrl)
+				serv.EXPECT().Name().Return("server_error").AnyTimes()
+				serv.EXPECT().Support(gomock.Any()).Return(true)
+				serv.EXPECT().Update(gomock.Any(), gomock.Any()).Return(
+					fmt.Errorf("fetch website failed: %w", vendors.StatusCodeError{StatusCode: 500}),
+				)
+
+				return serv
+			},
+			getRepo: func(ctrl *gomock.Controller) repository.Repository {
+				return mockrepo.NewMockRepository(ctrl)
+			},
+			webConf: webConf,
+			getMsg: func(ctrl *gomock.Controller) jetstream.Msg {
+				msg := mocknats.NewMockNatsMsg(ctrl)
+				msg.EXPECT().Data().Return([]byte(`{"website":{"uuid":"test uuid","url":"https://example.com","title":"test","raw_content":"content","update_time":"2020-05-01T00:00:00Z"},"trace_id":"01234567890123456789012345678901","span_id":"0123456789012345","trace_flags":1}`))
+				msg.EXPECT().Ack()
+
+				return msg
+			},
+		},
 		{
 			name: "error/not supported web",
 			getServ: func(ctrl *gomock.Controller) vendors.VendorService {
@@ -456,7 +699,12 @@ func TestWebsiteUpdateTask_handler(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			task := NewTask(nil, test.getServ(ctrl), nil, nil)
+			var rpo repository.Repository
+			if test.getRepo != nil {
+				rpo = test.getRepo(ctrl)
+			}
+
+			task := NewTask(nil, test.getServ(ctrl), rpo, test.webConf)
 
 			task.handler(test.getMsg(ctrl))
 		})
@@ -21,15 +21,35 @@ func NewTaskSet(nc *nats.Conn, services []vendors.VendorService, rpo repository.
 	return updateTasks
 }
 
+// Supported reports whether any vendor service of tasks supports web
+func (tasks WebsiteUpdateTasks) Supported(web *model.Website) bool {
+	for _, t := range tasks {
+		if t.Service.Support(web) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (tasks WebsiteUpdateTasks) Publish(ctx context.Context, web *model.Website) ([]string, error) {
+	return tasks.publish(ctx, &WebsiteUpdateParams{Website: *web})
+}
+
+// PublishJob publishes update of web tracked by job to supported services
+func (tasks WebsiteUpdateTasks) PublishJob(ctx context.Context, web *model.Website, job *model.Job) ([]string, error) {
+	return tasks.publish(ctx, &WebsiteUpdateParams{Website: *web, JobUUID: job.UUID, UserUUID: job.UserUUID})
+}
+
+func (tasks WebsiteUpdateTasks) publish(ctx context.Context, params *WebsiteUpdateParams) ([]string, error) {
 	supportedTasks := make([]string, 0, len(tasks))
 
 	// publish for supported service
 	for _, t := range tasks {
-		if t.Service.Support(web) {
+		if t.Service.Support(&params.Website) {
 			supportedTasks = append(supportedTasks, t.Service.Name())
 
-			err := t.Publish(ctx, web)
+			err := t.publish(ctx, params)
 			if err != nil {
 				return supportedTasks, err
 			}
@@ -58,6 +58,63 @@ func TestNewTaskSet(t *testing.T) {
 	}
 }
 
+func TestWebsiteUpdateTasks_Supported(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		getServs func(*gomock.Controller) []vendors.VendorService
+		web      *model.Website
+		expect   bool
+	}{
+		{
+			name: "happy flow/supported service found",
+			getServs: func(ctrl *gomock.Controller) []vendors.VendorService {
+				serv1 := mockvendor.NewMockVendorService(ctrl)
+				serv1.EXPECT().Support(&model.Website{URL: "https://example.com"}).Return(false).Times(1)
+
+				serv2 := mockvendor.NewMockVendorService(ctrl)
+				serv2.EXPECT().Support(&model.Website{URL: "https://example.com"}).Return(true).Times(1)
+
+				return []vendors.VendorService{serv1, serv2}
+			},
+			web:    &model.Website{URL: "https://example.com"},
+			expect: true,
+		},
+		{
+			name: "happy flow/no supported service",
+			getServs: func(ctrl *gomock.Controller) []vendors.VendorService {
+				serv := mockvendor.NewMockVendorService(ctrl)
+				serv.EXPECT().Support(&model.Website{URL: "https://example.com"}).Return(false).Times(1)
+
+				return []vendors.VendorService{serv}
+			},
+			web:    &model.Website{URL: "https://example.com"},
+			expect: false,
+		},
+		{
+			name: "happy flow/no service",
+			getServs: func(ctrl *gomock.Controller) []vendors.VendorService {
+				return nil
+			},
+			web:    &model.Website{URL: "https://example.com"},
+			expect: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tasks := NewTaskSet(nil, test.getServs(ctrl), nil, nil)
+			assert.Equal(t, test.expect, tasks.Supported(test.web))
+		})
+	}
+}
+
 func TestWebsiteUpdateTasks_Publish(t *testing.T) {
 	nc, err := nats.Connect(connString)
 	assert.NoError(t, err)
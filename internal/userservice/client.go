@@ -0,0 +1,201 @@
+package userservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/htchan/WebHistory/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrUnauthorized = errors.New("unauthorized")
+var ErrUnavailable = errors.New("user service unavailable")
+
+const (
+	AuthenticatePath      = "/api/users/authenticate"
+	HeaderKeyServiceToken = "X-SERVICE-TOKEN"
+)
+
+func getTracer() trace.Tracer {
+	return otel.Tracer("htchan/WebHistory/userservice")
+}
+
+// Authenticator verifies token of user and returns the uuid of the user
+//
+//go:generate go tool mockgen -destination=../mock/userservice/authenticator.go -package=mockuserservice . Authenticator
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+type authenticateResp struct {
+	UserUUID string `json:"user_uuid"`
+}
+
+// Client verifies token with user service. Verified tokens are cached for
+// CacheTTL, so revoked token is accepted until its cache expires.
+type Client struct {
+	cli   *http.Client
+	conf  *config.UserServiceConfig
+	cache *tokenCache
+}
+
+var _ Authenticator = (*Client)(nil)
+
+func NewClient(conf *config.UserServiceConfig) *Client {
+	return &Client{
+		cli:   &http.Client{Timeout: conf.Timeout},
+		conf:  conf,
+		cache: newTokenCache(conf.CacheTTL, conf.CacheSize),
+	}
+}
+
+func (c *Client) Authenticate(ctx context.Context, token string) (string, error) {
+	if token == "" {
+		return "", ErrUnauthorized
+	}
+
+	// raw token is not kept in memory
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+
+	if userUUID, ok := c.cache.get(key); ok {
+		return userUUID, nil
+	}
+
+	userUUID, err := c.authenticate(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	c.cache.set(key, userUUID)
+
+	return userUUID, nil
+}
+
+func (c *Client) authenticate(ctx context.Context, token string) (string, error) {
+	ctx, span := getTracer().Start(ctx, "user service authenticate")
+	defer span.End()
+
+	userUUID, err := c.request(ctx, token)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+	}
+
+	return userUUID, err
+}
+
+func (c *Client) request(ctx context.Context, token string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.conf.Addr, "/")+AuthenticatePath, nil)
+	if err != nil {
+		return "", fmt.Errorf("create authenticate request fail: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(HeaderKeyServiceToken, c.conf.Token)
+
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", ErrUnauthorized
+	default:
+		return "", fmt.Errorf("%w: unexpected response status: %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var body authenticateResp
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("%w: decode response fail: %w", ErrUnavailable, err)
+	}
+
+	if _, err := uuid.Parse(body.UserUUID); err != nil {
+		return "", fmt.Errorf("%w: invalid user uuid: %w", ErrUnavailable, err)
+	}
+
+	return body.UserUUID, nil
+}
+
+type cachedUser struct {
+	userUUID  string
+	expiredAt time.Time
+}
+
+// tokenCache keeps at most size verified tokens. Expired tokens are dropped
+// when it is full, and an arbitrary token is dropped if none expired.
+type tokenCache struct {
+	lock  sync.Mutex
+	ttl   time.Duration
+	size  int
+	items map[string]cachedUser
+	now   func() time.Time
+}
+
+func newTokenCache(ttl time.Duration, size int) *tokenCache {
+	return &tokenCache{
+		ttl:   ttl,
+		size:  size,
+		items: make(map[string]cachedUser),
+		now:   time.Now,
+	}
+}
+
+func (cache *tokenCache) get(key string) (string, bool) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	item, ok := cache.items[key]
+	if !ok {
+		return "", false
+	}
+
+	if !cache.now().Before(item.expiredAt) {
+		delete(cache.items, key)
+		return "", false
+	}
+
+	return item.userUUID, true
+}
+
+func (cache *tokenCache) set(key, userUUID string) {
+	if cache.ttl <= 0 || cache.size <= 0 {
+		return
+	}
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	now := cache.now()
+	if _, ok := cache.items[key]; !ok && len(cache.items) >= cache.size {
+		for k, item := range cache.items {
+			if !now.Before(item.expiredAt) {
+				delete(cache.items, k)
+			}
+		}
+
+		for k := range cache.items {
+			if len(cache.items) < cache.size {
+				break
+			}
+
+			delete(cache.items, k)
+		}
+	}
+
+	cache.items[key] = cachedUser{userUUID: userUUID, expiredAt: now.Add(cache.ttl)}
+}
@@ -0,0 +1,196 @@
+package userservice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/htchan/WebHistory/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const testUserUUID = "e3fb5a59-9b06-4c0b-9b2b-4b1f6e4bd6f0"
+
+func TestNewClient(t *testing.T) {
+	conf := &config.UserServiceConfig{Timeout: time.Second, CacheTTL: time.Minute, CacheSize: 10}
+
+	client := NewClient(conf)
+	assert.Equal(t, &http.Client{Timeout: time.Second}, client.cli)
+	assert.Equal(t, conf, client.conf)
+	assert.Equal(t, time.Minute, client.cache.ttl)
+	assert.Equal(t, 10, client.cache.size)
+}
+
+func TestClient_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		token         string
+		statusCode    int
+		body          string
+		delay         time.Duration
+		expectUUID    string
+		expectError   error
+		expectRequest bool
+	}{
+		{
+			name:          "happy flow",
+			token:         "token",
+			statusCode:    http.StatusOK,
+			body:          `{"user_uuid":"` + testUserUUID + `"}`,
+			expectUUID:    testUserUUID,
+			expectRequest: true,
+		},
+		{
+			name:        "empty token",
+			token:       "",
+			expectError: ErrUnauthorized,
+		},
+		{
+			name:          "unauthorized token",
+			token:         "token",
+			statusCode:    http.StatusUnauthorized,
+			expectError:   ErrUnauthorized,
+			expectRequest: true,
+		},
+		{
+			name:          "forbidden token",
+			token:         "token",
+			statusCode:    http.StatusForbidden,
+			expectError:   ErrUnauthorized,
+			expectRequest: true,
+		},
+		{
+			name:          "user service error",
+			token:         "token",
+			statusCode:    http.StatusInternalServerError,
+			expectError:   ErrUnavailable,
+			expectRequest: true,
+		},
+		{
+			name:          "invalid user uuid",
+			token:         "token",
+			statusCode:    http.StatusOK,
+			body:          `{"user_uuid":"invalid"}`,
+			expectError:   ErrUnavailable,
+			expectRequest: true,
+		},
+		{
+			name:          "user service timeout",
+			token:         "token",
+			statusCode:    http.StatusOK,
+			body:          `{"user_uuid":"` + testUserUUID + `"}`,
+			delay:         200 * time.Millisecond,
+			expectError:   ErrUnavailable,
+			expectRequest: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requested atomic.Bool
+			server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				requested.Store(true)
+				assert.Equal(t, AuthenticatePath, req.URL.Path)
+				assert.Equal(t, "Bearer "+test.token, req.Header.Get("Authorization"))
+				assert.Equal(t, "service_token", req.Header.Get(HeaderKeyServiceToken))
+
+				time.Sleep(test.delay)
+				res.WriteHeader(test.statusCode)
+				res.Write([]byte(test.body))
+			}))
+			t.Cleanup(server.Close)
+
+			client := NewClient(&config.UserServiceConfig{
+				Addr:      server.URL,
+				Token:     "service_token",
+				Timeout:   100 * time.Millisecond,
+				CacheTTL:  time.Minute,
+				CacheSize: 10,
+			})
+
+			userUUID, err := client.Authenticate(context.Background(), test.token)
+			assert.ErrorIs(t, err, test.expectError)
+			assert.Equal(t, test.expectUUID, userUUID)
+			assert.Equal(t, test.expectRequest, requested.Load())
+		})
+	}
+}
+
+func TestClient_Authenticate_Cache(t *testing.T) {
+	t.Parallel()
+
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		count.Add(1)
+		if req.Header.Get("Authorization") != "Bearer token" {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		res.Write([]byte(`{"user_uuid":"` + testUserUUID + `"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(&config.UserServiceConfig{
+		Addr: server.URL, Timeout: time.Second, CacheTTL: time.Minute, CacheSize: 10,
+	})
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	client.cache.now = func() time.Time { return now }
+
+	for range 2 {
+		userUUID, err := client.Authenticate(context.Background(), "token")
+		assert.NoError(t, err)
+		assert.Equal(t, testUserUUID, userUUID)
+	}
+	assert.Equal(t, int32(1), count.Load(), "verified token is cached")
+
+	for range 2 {
+		_, err := client.Authenticate(context.Background(), "invalid")
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	}
+	assert.Equal(t, int32(3), count.Load(), "rejected token is not cached")
+
+	now = now.Add(time.Minute)
+	_, err := client.Authenticate(context.Background(), "token")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), count.Load(), "expired token is verified again")
+}
+
+func Test_tokenCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	cache := newTokenCache(time.Minute, 2)
+	cache.now = func() time.Time { return now }
+
+	cache.set("a", "user a")
+	now = now.Add(30 * time.Second)
+	cache.set("b", "user b")
+
+	userUUID, ok := cache.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "user a", userUUID)
+
+	// a is expired and dropped to keep c
+	now = now.Add(30 * time.Second)
+	cache.set("c", "user c")
+	assert.Len(t, cache.items, 2)
+
+	_, ok = cache.get("a")
+	assert.False(t, ok)
+
+	// one of b and c is dropped to keep d
+	cache.set("d", "user d")
+	assert.Len(t, cache.items, 2)
+
+	userUUID, ok = cache.get("d")
+	assert.True(t, ok)
+	assert.Equal(t, "user d", userUUID)
+}
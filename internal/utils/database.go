@@ -2,13 +2,17 @@ package utils
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/htchan/WebHistory/internal/config"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
+var ErrUnsupportedDriver = errors.New("unsupported database driver")
+
 // open database for psql
 func openPostgresDatabase(conf *config.DatabaseConfig) (*sql.DB, error) {
 	conn := fmt.Sprintf(
@@ -27,6 +31,32 @@ func openPostgresDatabase(conf *config.DatabaseConfig) (*sql.DB, error) {
 	return database, err
 }
 
+// SqliteConnString enables WAL and waits for lock instead of failing concurrent writes
+func SqliteConnString(path string) string {
+	return fmt.Sprintf(
+		"file:%s?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_txlock=immediate",
+		path,
+	)
+}
+
+// open database for sqlite
+func openSqliteDatabase(conf *config.DatabaseConfig) (*sql.DB, error) {
+	database, err := sql.Open(conf.Driver, SqliteConnString(conf.SqlitePath))
+	if err != nil {
+		return database, err
+	}
+	database.SetMaxIdleConns(2)
+	database.SetMaxOpenConns(4)
+	return database, err
+}
+
 func OpenDatabase(conf *config.DatabaseConfig) (*sql.DB, error) {
-	return openPostgresDatabase(conf)
+	switch conf.Driver {
+	case config.DatabaseDriverPostgres:
+		return openPostgresDatabase(conf)
+	case config.DatabaseDriverSqlite:
+		return openSqliteDatabase(conf)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, conf.Driver)
+	}
 }
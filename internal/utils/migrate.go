@@ -13,35 +13,55 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
+func migrationDriver(conf *config.DatabaseConfig) (database.Driver, string, error) {
+	switch conf.Driver {
+	case config.DatabaseDriverPostgres:
+		connString := fmt.Sprintf(
+			"postgres://%s:%s@%s:%s/%s?sslmode=disable",
+			conf.User, conf.Password, conf.Host, conf.Port, conf.Database,
+		)
+
+		db, err := sql.Open(conf.Driver, connString)
+		if err != nil {
+			return nil, "", err
+		}
+
+		driver, err := postgres.WithInstance(db, &postgres.Config{})
+
+		return driver, "file:///migrations", err
+	case config.DatabaseDriverSqlite:
+		db, err := sql.Open(conf.Driver, SqliteConnString(conf.SqlitePath))
+		if err != nil {
+			return nil, "", err
+		}
+
+		driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+
+		return driver, "file:///migrations/sqlite", err
+	default:
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedDriver, conf.Driver)
+	}
+}
+
 func Migrate(conf *config.DatabaseConfig) error {
 	tr := otel.Tracer("github.com/htchan/WebHistory/migrate")
 	_, span := tr.Start(context.Background(), "migrate", trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
-	connString := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		conf.User, conf.Password, conf.Host, conf.Port, conf.Database,
-	)
-
-	db, err := sql.Open(conf.Driver, connString)
-	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		return fmt.Errorf("migrate fail: %w", err)
-	}
-
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	driver, sourceURL, err := migrationDriver(conf)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 		return fmt.Errorf("migrate fail: %w", err)
 	}
 
-	m, err := migrate.NewWithDatabaseInstance("file:///migrations", conf.Driver, driver)
+	m, err := migrate.NewWithDatabaseInstance(sourceURL, conf.Driver, driver)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
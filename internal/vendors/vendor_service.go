@@ -12,6 +12,24 @@ import (
 var ErrInvalidStatusCode = fmt.Errorf("invalid status code")
 var ErrUnknownHost = fmt.Errorf("unknown host")
 
+// StatusCodeError keeps the status code of failed response for caller to inspect
+type StatusCodeError struct {
+	StatusCode int
+}
+
+func (err StatusCodeError) Error() string {
+	return fmt.Sprintf("%s (%d)", ErrInvalidStatusCode, err.StatusCode)
+}
+
+func (err StatusCodeError) Unwrap() error {
+	return ErrInvalidStatusCode
+}
+
+// NotFound reports if the website is removed from vendor
+func (err StatusCodeError) NotFound() bool {
+	return err.StatusCode == http.StatusNotFound || err.StatusCode == http.StatusGone
+}
+
 //go:generate go tool mockgen -destination=../mock/vendor/vendor_service.go -package=mockvendor . VendorService
 type VendorService interface {
 	Support(*model.Website) bool
@@ -27,6 +45,6 @@ func RaiseStatusCodeErrorMiddleware(f goclient.Requester) goclient.Requester {
 			return resp, err
 		}
 
-		return resp, fmt.Errorf("fetch website failed: %w (%d)", ErrInvalidStatusCode, resp.StatusCode)
+		return resp, fmt.Errorf("fetch website failed: %w", StatusCodeError{resp.StatusCode})
 	}
 }